@@ -0,0 +1,115 @@
+// Package agent exposes the TmuxAI agent loop for use from other Go programs.
+//
+// An Agent drives a tmux exec pane the same way the interactive chat does: it
+// captures the panes, asks the model, and executes the commands and keys from
+// the response. Low level tmux helpers live in the system package.
+//
+// The package is a thin facade over the internal Manager, which stays internal
+// together with the model clients. Only what this package declares is public API;
+// Message and Provider are aliases of internal types so that values pass through.
+// Confirmations are asked on the terminal like in the chat.
+//
+//	cfg, _ := config.Load()
+//	a, err := agent.New(agent.Options{Config: cfg})
+//	if err != nil { ... }
+//	a.Send(ctx, "find the largest files in this directory")
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Message is one entry of the conversation
+type Message = internal.ChatMessage
+
+// Provider answers a conversation; implement it to use a model other than the configured one
+type Provider = internal.ChatProvider
+
+// Pane describes a tmux pane
+type Pane = system.TmuxPaneDetails
+
+// Options configures a new Agent
+type Options struct {
+	// Config is the TmuxAI configuration, required
+	Config *config.Config
+	// PaneID is the pane the agent belongs to; defaults to the current tmux pane
+	PaneID string
+	// ExecPaneID is the pane commands are run in; defaults to the first other pane in the window
+	ExecPaneID string
+	// Provider overrides the OpenRouter client built from Config
+	Provider Provider
+}
+
+// Agent is an embeddable TmuxAI agent
+type Agent struct {
+	m *internal.Manager
+}
+
+// New creates an agent. The calling process must run inside tmux.
+func New(opts Options) (*Agent, error) {
+	if opts.Config == nil {
+		return nil, fmt.Errorf("config is required")
+	}
+
+	paneId := opts.PaneID
+	if paneId == "" {
+		id, err := system.TmuxCurrentPaneId()
+		if err != nil {
+			return nil, fmt.Errorf("not running inside tmux: %w", err)
+		}
+		paneId = id
+	}
+
+	provider := opts.Provider
+	if provider == nil {
+		p, err := internal.NewChatProvider(opts.Config)
+		if err != nil {
+			return nil, err
+		}
+		provider = p
+	}
+
+	m := internal.NewManagerForPane(opts.Config, paneId, provider)
+	if opts.ExecPaneID != "" {
+		panes, err := system.TmuxPanesDetails(opts.ExecPaneID)
+		if err != nil || len(panes) == 0 {
+			return nil, fmt.Errorf("exec pane %s not found: %v", opts.ExecPaneID, err)
+		}
+		m.ExecPane = &panes[0]
+	} else {
+		m.InitExecPane()
+	}
+
+	return &Agent{m: m}, nil
+}
+
+// Send runs one full turn for the message, including executing the commands the
+// model asks for. It returns true when the model reports the request as accomplished.
+func (a *Agent) Send(ctx context.Context, message string) bool {
+	return a.m.RunTurn(ctx, message)
+}
+
+// Command runs a chat /command such as "/prepare" or "/squash"
+func (a *Agent) Command(command string) {
+	a.m.ProcessSubCommand(command)
+}
+
+// Messages returns a copy of the conversation history
+func (a *Agent) Messages() []Message {
+	return a.m.MessageHistory()
+}
+
+// ExecPane returns the pane the agent runs commands in
+func (a *Agent) ExecPane() Pane {
+	return *a.m.ExecPane
+}
+
+// Reset clears the conversation history
+func (a *Agent) Reset() {
+	a.m.ResetMessages()
+}
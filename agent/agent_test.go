@@ -0,0 +1,67 @@
+// Unit tests for the embeddable agent in agent.go
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/internal"
+)
+
+// scriptedProvider answers with its responses in order, a Provider written outside internal
+type scriptedProvider struct {
+	responses []string
+	calls     int
+}
+
+func (p *scriptedProvider) GetResponseFromChatMessages(ctx context.Context, messages []Message, model string) (string, error) {
+	response := p.responses[p.calls%len(p.responses)]
+	p.calls++
+	return response, nil
+}
+
+func newTestAgent(provider Provider) *Agent {
+	return &Agent{m: internal.NewManagerForPane(config.DefaultConfig(), "", provider)}
+}
+
+// Test: New requires a config
+func TestNewWithoutConfig(t *testing.T) {
+	if _, err := New(Options{}); err == nil || !strings.Contains(err.Error(), "config is required") {
+		t.Errorf("expected a missing config error, got %v", err)
+	}
+}
+
+// Test: New reports an exec pane that doesn't exist
+func TestNewUnknownExecPane(t *testing.T) {
+	opts := Options{Config: config.DefaultConfig(), PaneID: "%999998", ExecPaneID: "%999999", Provider: &scriptedProvider{}}
+	if _, err := New(opts); err == nil || !strings.Contains(err.Error(), "exec pane %999999 not found") {
+		t.Errorf("expected an unknown exec pane error, got %v", err)
+	}
+}
+
+// Test: Send runs a turn with a custom provider and records it in Messages until Reset
+func TestSendMessagesReset(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{"All done\n<RequestAccomplished>1</RequestAccomplished>"}}
+	a := newTestAgent(provider)
+
+	if !a.Send(context.Background(), "say hi") {
+		t.Errorf("expected the request to be accomplished")
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected one model call, got %d", provider.calls)
+	}
+	messages := a.Messages()
+	if len(messages) != 2 || !messages[0].FromUser || messages[1].FromUser {
+		t.Fatalf("expected the user message and the response, got %+v", messages)
+	}
+	if !strings.Contains(messages[1].Content, "All done") {
+		t.Errorf("unexpected response: %q", messages[1].Content)
+	}
+
+	a.Reset()
+	if len(a.Messages()) != 0 {
+		t.Errorf("expected Reset to clear the history, got %+v", a.Messages())
+	}
+}
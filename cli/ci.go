@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ciPolicyFlag string
+	ciReportFlag string
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci [request message]",
+	Short: "Run a task non-interactively with confirmations resolved by a policy file",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		task := readInitMessage(args)
+		if task == "" {
+			fmt.Fprintln(os.Stderr, "Error: a request message or -f task file is required")
+			os.Exit(1)
+		}
+
+		ok, err := internal.RunCIMode(cfg, task, ciPolicyFlag, ciReportFlag)
+		if err != nil {
+			logger.Error("CI run failed: %v", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Report written to %s\n", ciReportFlag)
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ciCmd.Flags().StringVar(&ciPolicyFlag, "policy", "", "Approval policy file (yaml, json or toml)")
+	ciCmd.Flags().StringVar(&ciReportFlag, "report", "tmuxai-report.json", "Path of the JSON run report")
+	ciCmd.Flags().StringVarP(&taskFileFlag, "file", "f", "", "Read request from specified file")
+	ciCmd.MarkFlagRequired("policy")
+	rootCmd.AddCommand(ciCmd)
+}
@@ -3,6 +3,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -16,6 +17,13 @@ import (
 var (
 	initMessage  string
 	taskFileFlag string
+	jsonFlag     bool
+	profileFlag  string
+	demoFlag     bool
+
+	commandFlag     string
+	headlessFlag    bool
+	headlessOptions internal.HeadlessOptions
 )
 
 var rootCmd = &cobra.Command{
@@ -27,49 +35,148 @@ var rootCmd = &cobra.Command{
 			fmt.Printf("tmuxai version: %s\ncommit: %s\nbuild date: %s\n", internal.Version, internal.Commit, internal.Date)
 			os.Exit(0)
 		}
+		// a headless run prints only its result on stdout
+		if jsonFlag && !headlessFlag {
+			internal.EnableJSONEvents()
+		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.Load()
-		if err != nil {
-			logger.Error("Error loading configuration: %v", err)
-			fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
-			os.Exit(1)
-		}
+		cfg := loadConfig()
+		initMessage = readInitMessage(args)
 
-		if len(args) > 0 {
-			initMessage = strings.Join(args, " ")
+		if headlessFlag {
+			runHeadless(cfg)
+			return
 		}
-
-		if taskFileFlag != "" {
-			content, err := os.ReadFile(taskFileFlag)
-			if err != nil {
-				logger.Error("Error reading task file: %v", err)
-				fmt.Fprintf(os.Stderr, "Error reading task file: %v\n", err)
+		if internal.StdinIsPiped() {
+			if err := internal.RunPipeMode(cfg, os.Stdin, initMessage); err != nil {
+				logger.Error("Pipe mode failed: %v", err)
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
-			initMessage = string(content)
-			logger.Info("Read request from file: %s", taskFileFlag)
+			return
 		}
 
-		mgr, err := internal.NewManager(cfg)
+		mgr := newManager(cfg)
+		startManager(mgr)
+	},
+}
+
+// loadConfig loads the configuration or exits
+func loadConfig() *config.Config {
+	cfg, err := config.LoadProfile(profileFlag)
+	if err != nil {
+		logger.Error("Error loading configuration: %v", err)
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	applyFlags(cfg)
+	level, err := logger.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid log_level: %v\n", err)
+	}
+	if cfg.Debug {
+		level = logger.LevelDebug
+	}
+	logger.Configure(level, logger.Rotation{
+		MaxSizeMB:  cfg.LogRotation.MaxSizeMB,
+		MaxAgeDays: cfg.LogRotation.MaxAgeDays,
+		MaxBackups: cfg.LogRotation.MaxBackups,
+	})
+	return cfg
+}
+
+// applyFlags applies the flags that override config keys; reloads of the config apply them again
+func applyFlags(cfg *config.Config) {
+	if demoFlag {
+		cfg.Provider = "mock"
+	}
+}
+
+// readInitMessage returns the initial request from args or the task file flag
+func readInitMessage(args []string) string {
+	message := commandFlag
+	if len(args) > 0 {
+		message = strings.Join(args, " ")
+	}
+
+	if taskFileFlag != "" {
+		content, err := os.ReadFile(taskFileFlag)
 		if err != nil {
-			logger.Error("manager.NewManager failed: %v", err)
+			logger.Error("Error reading task file: %v", err)
+			fmt.Fprintf(os.Stderr, "Error reading task file: %v\n", err)
 			os.Exit(1)
 		}
-		if initMessage != "" {
-			logger.Info("Starting with initial subcommand: %s", initMessage)
-		}
+		message = string(content)
+		logger.Info("Read request from file: %s", taskFileFlag)
+	}
+	return message
+}
 
-		if err := mgr.Start(initMessage); err != nil {
-			logger.Error("manager.Start failed: %v", err)
-			os.Exit(1)
+// runHeadless runs the request to the end without asking anything and prints the result,
+// as JSON with --json; it exits 1 unless the task was accomplished
+func runHeadless(cfg *config.Config) {
+	if initMessage == "" {
+		fmt.Fprintln(os.Stderr, "Error: --headless needs a request, e.g. -c \"run the tests\"")
+		os.Exit(1)
+	}
+	stdout := os.Stdout
+	result, err := internal.RunHeadless(cfg, initMessage, headlessOptions)
+	if err != nil {
+		logger.Error("Headless run failed: %v", err)
+		if jsonFlag {
+			data, _ := json.Marshal(map[string]interface{}{"success": false, "status": internal.CIStatusFailed, "error": err.Error()})
+			fmt.Fprintln(stdout, string(data))
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		}
-	},
+		os.Exit(1)
+	}
+	if jsonFlag {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(stdout, string(data))
+	} else if text := result.Text(); text != "" {
+		fmt.Fprintln(stdout, text)
+	}
+	if !result.Success {
+		os.Exit(1)
+	}
+}
+
+// newManager creates the manager or exits
+func newManager(cfg *config.Config) *internal.Manager {
+	mgr, err := internal.NewManager(cfg)
+	if err != nil {
+		logger.Error("manager.NewManager failed: %v", err)
+		os.Exit(1)
+	}
+	mgr.FlagOverrides = applyFlags
+	return mgr
+}
+
+// startManager runs the chat interface with the initial message until exit
+func startManager(mgr *internal.Manager) {
+	if initMessage != "" {
+		logger.Info("Starting with initial subcommand: %s", initMessage)
+	}
+
+	if err := mgr.Start(initMessage); err != nil {
+		logger.Error("manager.Start failed: %v", err)
+		os.Exit(1)
+	}
 }
 
 func init() {
 	rootCmd.Flags().StringVarP(&taskFileFlag, "file", "f", "", "Read request from specified file")
 	rootCmd.Flags().BoolP("version", "v", false, "Print version information")
+	rootCmd.Flags().StringVarP(&commandFlag, "command", "c", "", "Request to send, like the positional message")
+	rootCmd.Flags().BoolVar(&headlessFlag, "headless", false, "Run the request to the end without prompts, print the result and exit")
+	rootCmd.Flags().StringVar(&headlessOptions.Pane, "pane", "", "Exec pane of a headless run (default: the window's exec pane, or a new session outside tmux)")
+	rootCmd.Flags().StringVar(&headlessOptions.Policy, "policy", "", "Approval policy file of a headless run, as in tmuxai ci (default: whitelisted commands only)")
+	rootCmd.Flags().IntVar(&headlessOptions.Timeout, "timeout", 600, "Seconds a headless run may take without a --policy")
+	rootCmd.PersistentFlags().BoolVar(&demoFlag, "demo", false, "Answer with the scripted mock provider, no API key or network needed")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Config profile to apply, one of the names under profiles:")
+	rootCmd.PersistentFlags().BoolVar(&jsonFlag, "json", false, "Emit events as JSON lines on stdout; human output goes to stderr")
 }
 
 func Execute() error {
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with the config file",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the config file: values, unknown keys, rules, the API key and MCP servers",
+	Long: `Check the config file without starting a session: the values, keys that are no settings
+(with the setting each one probably meant), the patterns of the command rules, whether the
+selected provider has an API key and whether each MCP server can be reached. The exit code
+is 1 when a check fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadProfile(profileFlag)
+		if err != nil {
+			cfg = config.DefaultConfig()
+		}
+		if httpErr := system.ConfigureHTTP(cfg.HTTP.CAFile, cfg.HTTP.InsecureSkipVerify); httpErr != nil && err == nil {
+			err = fmt.Errorf("http settings: %w", httpErr)
+		}
+		if !internal.PrintDoctorReport(os.Stdout, internal.ValidateConfig(cfg, err)) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
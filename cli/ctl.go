@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/spf13/cobra"
+)
+
+var ctlCmd = &cobra.Command{
+	Use:   "ctl",
+	Short: "Control an already running TmuxAI instance",
+}
+
+var ctlSendCmd = &cobra.Command{
+	Use:   "send <message>",
+	Short: "Send a message or /command to the running instance",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCtl(internal.ControlRequest{Command: "send", Args: strings.Join(args, " ")})
+	},
+}
+
+var ctlStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of the running instance",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCtl(internal.ControlRequest{Command: "status"})
+	},
+}
+
+var (
+	ctlFileFlag     string
+	ctlLinesFlag    string
+	ctlFiletypeFlag string
+)
+
+var ctlContextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Add an editor selection read from stdin to the chat context",
+	Run: func(cmd *cobra.Command, args []string) {
+		runCtl(internal.ControlRequest{Command: "context", Selection: readSelection()})
+	},
+}
+
+var ctlAskCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Ask about an editor selection read from stdin",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runCtl(internal.ControlRequest{Command: "ask", Args: strings.Join(args, " "), Selection: readSelection()})
+	},
+}
+
+var ctlEditCmd = &cobra.Command{
+	Use:   "edit <instruction>",
+	Short: "Print an AI rewrite of the selection read from stdin",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resp := sendCtl(internal.ControlRequest{Command: "edit", Args: strings.Join(args, " "), Selection: readSelection()})
+		var result internal.EditResult
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid edit result: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(result.Replacement)
+	},
+}
+
+// readSelection builds the editor selection from stdin and the selection flags
+func readSelection() *internal.EditorSelection {
+	text, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading selection: %v\n", err)
+		os.Exit(1)
+	}
+	sel := &internal.EditorSelection{File: ctlFileFlag, Filetype: ctlFiletypeFlag, Text: string(text)}
+	if ctlLinesFlag != "" {
+		if _, err := fmt.Sscanf(ctlLinesFlag, "%d:%d", &sel.StartLine, &sel.EndLine); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --lines must look like 10:20\n")
+			os.Exit(1)
+		}
+	}
+	return sel
+}
+
+func runCtl(req internal.ControlRequest) {
+	resp := sendCtl(req)
+	if len(resp.Result) > 0 {
+		var pretty interface{}
+		json.Unmarshal(resp.Result, &pretty)
+		out, _ := json.MarshalIndent(pretty, "", "  ")
+		fmt.Println(string(out))
+	}
+}
+
+// sendCtl sends the request and exits on failure
+func sendCtl(req internal.ControlRequest) internal.ControlResponse {
+	resp, err := internal.SendControlRequest(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if !resp.OK {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		os.Exit(1)
+	}
+	return resp
+}
+
+func init() {
+	for _, c := range []*cobra.Command{ctlContextCmd, ctlAskCmd, ctlEditCmd} {
+		c.Flags().StringVar(&ctlFileFlag, "file", "", "File the selection comes from")
+		c.Flags().StringVar(&ctlLinesFlag, "lines", "", "Selected line range, e.g. 10:20")
+		c.Flags().StringVar(&ctlFiletypeFlag, "filetype", "", "Language of the selection")
+	}
+	ctlCmd.AddCommand(ctlSendCmd, ctlStatusCmd, ctlContextCmd, ctlAskCmd, ctlEditCmd)
+	rootCmd.AddCommand(ctlCmd)
+}
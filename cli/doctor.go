@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check tmux, the config, the API key, MCP servers and shell integration",
+	Long: `Check that everything TmuxAI needs works: tmux and its version, the config file,
+the API key (with a free request listing the models), each configured MCP server and whether
+the shell supports /prepare. Each problem is printed with a fix; the exit code is 1 when a
+check fails.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadProfile(profileFlag)
+		if err != nil {
+			cfg = config.DefaultConfig()
+		}
+		if httpErr := system.ConfigureHTTP(cfg.HTTP.CAFile, cfg.HTTP.InsecureSkipVerify); httpErr != nil && err == nil {
+			err = fmt.Errorf("http settings: %w", httpErr)
+		}
+		if !internal.PrintDoctorReport(os.Stdout, internal.RunDoctor(context.Background(), cfg, err)) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <command>",
+	Short: "Run a command in the exec pane of the running instance through its safety checks",
+	Long: `Run a command in the exec pane of the running TmuxAI instance.
+The running instance checks the command against its command rules and confirms it in the
+chat pane unless whitelisted; it runs through the exec hooks and is recorded in the chat history.
+The exit code is passed through when the exec pane is prepared.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		command := strings.Join(args, " ")
+
+		resp := sendCtl(internal.ControlRequest{Command: "exec", Args: command})
+		var result internal.ExecResult
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid exec result: %v\n", err)
+			os.Exit(1)
+		}
+		if result.Output != "" {
+			fmt.Println(result.Output)
+		}
+		if result.ExitCode != nil {
+			os.Exit(*result.ExitCode)
+		}
+	},
+}
+
+func init() {
+	// everything after the command name belongs to the command: tmuxai exec ls -la
+	execCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(execCmd)
+}
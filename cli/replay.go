@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayAskFlag    bool
+	replayWatchFlag  bool
+	replayGoldenFlag bool
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <debug-file>",
+	Short: "Run a debug dump through the response parser, and optionally the model again",
+	Long: `Read a debug file from the debug directory of the config dir, rebuild the messages that were
+sent and run the recorded response through the parser and the guideline checks. With --ask the
+messages are sent to the configured model again and its answer is checked too. --golden prints
+the expected result kept next to a dump in internal/testdata/replay, which turns the dump into a
+regression test.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		dump, err := internal.ParseDebugDump(string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var provider internal.ChatProvider
+		if replayAskFlag {
+			if provider, err = internal.NewChatProvider(cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		results, err := internal.ReplayDump(context.Background(), cfg, dump, provider, replayWatchFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+
+		if replayGoldenFlag {
+			golden, err := internal.ReplayGolden(results)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			os.Stdout.Write(golden)
+			return
+		}
+		internal.PrintReplay(os.Stdout, dump, results)
+	},
+}
+
+func init() {
+	replayCmd.Flags().BoolVar(&replayAskFlag, "ask", false, "Send the messages to the configured model again and check its answer")
+	replayCmd.Flags().BoolVar(&replayWatchFlag, "watch", false, "Check the responses as watch mode answers, which need no tag")
+	replayCmd.Flags().BoolVar(&replayGoldenFlag, "golden", false, "Print the expected result of the recorded response as JSON")
+	rootCmd.AddCommand(replayCmd)
+}
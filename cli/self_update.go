@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/spf13/cobra"
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Replace tmuxai with the latest release from GitHub",
+	Long: `Download the latest release for this platform from GitHub, check it against the checksums
+published with the release and replace the running binary. Installs managed by Homebrew or a
+package manager should be updated with it instead.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		if err := system.ConfigureHTTP(cfg.HTTP.CAFile, cfg.HTTP.InsecureSkipVerify); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := internal.SelfUpdate(context.Background(), os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+}
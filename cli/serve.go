@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/internal"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/spf13/cobra"
+)
+
+var serveAddrFlag string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [request message]",
+	Short: "Start TmuxAI with a local HTTP API for editors and dashboards",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := loadConfig()
+		initMessage = readInitMessage(args)
+		if serveAddrFlag != "" {
+			cfg.Server.Addr = serveAddrFlag
+		}
+
+		mgr := newManager(cfg)
+
+		token, err := internal.ServerToken(cfg)
+		if err != nil {
+			logger.Error("Failed to get API token: %v", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		server := internal.NewAPIServer(mgr, cfg.Server.Addr, token)
+		if err := server.Start(); err != nil {
+			logger.Error("Failed to start API server: %v", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		tokenSource := "server.token from config"
+		if cfg.Server.Token == "" {
+			tokenSource = config.GetConfigFilePath("server_token")
+		}
+		mgr.Println(fmt.Sprintf("API server listening on http://%s (token: %s)", cfg.Server.Addr, tokenSource))
+		mgr.Println("Read-only transcript: " + server.ShareURL())
+
+		startManager(mgr)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddrFlag, "addr", "", "Address to listen on (default from config, 127.0.0.1:8765)")
+	serveCmd.Flags().StringVarP(&taskFileFlag, "file", "f", "", "Read request from specified file")
+	rootCmd.AddCommand(serveCmd)
+}
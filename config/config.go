@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -12,41 +13,131 @@ import (
 
 // McpServer holds the configuration for a single MCP server
 type McpServer struct {
-	Name        string `mapstructure:"name"`
-	URL         string `mapstructure:"url"`
-	APIKey      string `mapstructure:"api_key"`
-	Model       string `mapstructure:"model"`
-	BaseURL     string `mapstructure:"base_url"`
-	Type        string `mapstructure:"type"`        // "http", "sse", "websocket"
-	StreamMode  bool   `mapstructure:"stream_mode"` // 是否启用流式响应
-	Timeout     int    `mapstructure:"timeout"`    // 连接超时时间
-	RetryCount  int    `mapstructure:"retry_count"` // 重试次数
+	Name       string `mapstructure:"name"`
+	URL        string `mapstructure:"url"`
+	APIKey     string `mapstructure:"api_key"` // sent as a bearer token to sse and http servers; may use ${VAR}
+	Model      string `mapstructure:"model"`
+	BaseURL    string `mapstructure:"base_url"`
+	Type       string `mapstructure:"type"`        // "http", "sse", "websocket"
+	StreamMode bool   `mapstructure:"stream_mode"` // 是否启用流式响应
+	Timeout    int    `mapstructure:"timeout"`     // 连接超时时间
+	RetryCount int    `mapstructure:"retry_count"` // restarts of a stdio server that exits, in a row; 0 means 3, -1 none
 	// 添加缺失的字段
-	Command     string            `mapstructure:"command"`     // stdio 模式下的命令
-	Args        []string          `mapstructure:"args"`        // 命令参数
-	Env         map[string]string `mapstructure:"env"`         // 环境变量
-	Headers     map[string]string `mapstructure:"headers"`     // HTTP 头部
+	Command string            `mapstructure:"command"` // stdio 模式下的命令
+	Args    []string          `mapstructure:"args"`    // 命令参数
+	Env     map[string]string `mapstructure:"env"`     // 环境变量
+	Headers map[string]string `mapstructure:"headers"` // HTTP 头部; values may use ${VAR}
+	OAuth   McpOAuthConfig    `mapstructure:"oauth"`   // MCP OAuth for sse and http servers
+}
+
+// McpOAuthConfig enables the MCP OAuth flow for a server; /mcp login authorizes it
+type McpOAuthConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	ClientID     string   `mapstructure:"client_id"`     // registered with the server when empty
+	ClientSecret string   `mapstructure:"client_secret"` // for confidential clients
+	Scopes       []string `mapstructure:"scopes"`
+	RedirectURI  string   `mapstructure:"redirect_uri"` // default http://127.0.0.1:<free port>/callback
 }
 
 // McpConfig holds the MCP configuration
 type McpConfig struct {
-	Servers []McpServer `mapstructure:"servers"`
+	Servers             []McpServer   `mapstructure:"servers"`
+	HealthCheckInterval int           `mapstructure:"health_check_interval"` // seconds between pings, failed servers reconnect with backoff; 0 disables
+	ToolPolicy          []McpToolRule `mapstructure:"tool_policy"`           // first matching rule wins, other tools run without asking
+	Lazy                bool          `mapstructure:"lazy"`                  // connect servers on first use instead of when selected
+	ToolCacheTTL        int           `mapstructure:"tool_cache_ttl"`        // seconds listed tools are reused, also across sessions; 0 disables
+	ParallelCalls       int           `mapstructure:"parallel_calls"`        // tool calls of one response run at once, 1 runs them one by one
+}
+
+// McpToolRule sets how calls of the MCP tools matching Tool are approved
+type McpToolRule struct {
+	Tool   string `mapstructure:"tool"`   // server.tool, * matches any part, e.g. github.* or *.delete_*
+	Action string `mapstructure:"action"` // auto, confirm or deny
+}
+
+// CommandRule sets how the commands or keys the AI proposes that match Pattern are handled
+type CommandRule struct {
+	Pattern string `mapstructure:"pattern"` // regex
+	Action  string `mapstructure:"action"`  // auto, confirm or block
+	Target  string `mapstructure:"target"`  // exec, keys or all; exec when empty
 }
 
 // Config holds the application configuration
 type Config struct {
-	Debug                 bool             `mapstructure:"debug"`
-	MaxCaptureLines       int              `mapstructure:"max_capture_lines"`
-	MaxContextSize        int              `mapstructure:"max_context_size"`
-	WaitInterval          int              `mapstructure:"wait_interval"`
-	SendKeysConfirm       bool             `mapstructure:"send_keys_confirm"`
-	PasteMultilineConfirm bool             `mapstructure:"paste_multiline_confirm"`
-	ExecConfirm           bool             `mapstructure:"exec_confirm"`
-	WhitelistPatterns     []string         `mapstructure:"whitelist_patterns"`
-	BlacklistPatterns     []string         `mapstructure:"blacklist_patterns"`
-	OpenRouter            OpenRouterConfig `mapstructure:"openrouter"`
-	Mcp                   McpConfig        `mapstructure:"mcp"`
-	Prompts               PromptsConfig    `mapstructure:"prompts"`
+	Debug                 bool                `mapstructure:"debug"`
+	PprofAddr             string              `mapstructure:"pprof_addr"` // loopback address for pprof while debug is on, "" disables
+	MaxCaptureLines       int                 `mapstructure:"max_capture_lines"`
+	CaptureCacheTTL       int                 `mapstructure:"capture_cache_ttl"` // milliseconds a pane capture is reused, 0 disables
+	MaxContextSize        int                 `mapstructure:"max_context_size"`
+	WaitInterval          int                 `mapstructure:"wait_interval"`
+	SendKeysConfirm       bool                `mapstructure:"send_keys_confirm"`
+	PasteMultilineConfirm bool                `mapstructure:"paste_multiline_confirm"`
+	ExecConfirm           bool                `mapstructure:"exec_confirm"`
+	TeachMode             bool                `mapstructure:"teach_mode"` // explain each command before it runs and its output after
+	WhitelistPatterns     []string            `mapstructure:"whitelist_patterns"`
+	BlacklistPatterns     []string            `mapstructure:"blacklist_patterns"`
+	CommandRules          []CommandRule       `mapstructure:"command_rules"` // checked before the whitelist and blacklist, first match wins
+	Provider              string              `mapstructure:"provider"`      // openrouter, openai, anthropic, azure, gemini or mock
+	OpenRouter            OpenRouterConfig    `mapstructure:"openrouter"`
+	OpenAI                ProviderConfig      `mapstructure:"openai"`
+	Anthropic             ProviderConfig      `mapstructure:"anthropic"`
+	Azure                 ProviderConfig      `mapstructure:"azure"`
+	Gemini                ProviderConfig      `mapstructure:"gemini"`
+	Mock                  MockConfig          `mapstructure:"mock"`
+	Mcp                   McpConfig           `mapstructure:"mcp"`
+	Prompts               PromptsConfig       `mapstructure:"prompts"`
+	Context               ContextConfig       `mapstructure:"context"`
+	Server                ServerConfig        `mapstructure:"server"`
+	ControlSocket         bool                `mapstructure:"control_socket"`
+	ConfigReload          bool                `mapstructure:"config_reload"` // apply changes of the config file to running sessions
+	FifoInput             bool                `mapstructure:"fifo_input"`
+	Hooks                 HooksConfig         `mapstructure:"hooks"`
+	Notifications         NotificationsConfig `mapstructure:"notifications"`
+	Highlight             HighlightConfig     `mapstructure:"highlight"`
+	Theme                 ThemeConfig         `mapstructure:"theme"`
+	Interface             string              `mapstructure:"interface"`     // "readline" or "tui"
+	EditingMode           string              `mapstructure:"editing_mode"`  // "emacs" or "vi"
+	PromptFormat          string              `mapstructure:"prompt_format"` // e.g. "{name} {state} {model} » "
+	DiffStyle             string              `mapstructure:"diff_style"`    // "unified" or "side-by-side"
+	ASCII                 bool                `mapstructure:"ascii"`         // plain ASCII symbols instead of unicode and emoji
+	StatusHeader          bool                `mapstructure:"status_header"` // session summary in the chat pane's top border
+	Language              string              `mapstructure:"language"`      // interface language: en or zh
+	History               HistoryConfig       `mapstructure:"history"`
+	Suggestions           SuggestionsConfig   `mapstructure:"suggestions"`
+	Watch                 WatchConfig         `mapstructure:"watch"`
+	SessionStore          SessionStoreConfig  `mapstructure:"session_store"`
+	Compaction            CompactionConfig    `mapstructure:"compaction"`
+	HTTP                  HTTPConfig          `mapstructure:"http"`
+	LogLevel              string              `mapstructure:"log_level"` // error, warn, info or debug
+	LogRotation           LogRotationConfig   `mapstructure:"log_rotation"`
+	SaveOnExit            bool                `mapstructure:"save_on_exit"` // keep the session on exit and offer it on the next start
+	AuditLog              bool                `mapstructure:"audit_log"`    // record the actions sent to tmux in ~/.config/tmuxai/audit.jsonl
+	Updates               UpdatesConfig       `mapstructure:"updates"`
+	SubAgents             SubAgentsConfig     `mapstructure:"sub_agents"`
+	// Profiles are named sets of config values, e.g. another model, API key or stricter
+	// confirmations, applied over the rest of the config by --profile or /config profile
+	Profiles map[string]map[string]interface{} `mapstructure:"profiles"`
+	Profile  string                            `mapstructure:"profile"` // the profile applied, empty for none
+}
+
+// SubAgentsConfig controls the sub-agents working on delegated subtasks in their own panes
+type SubAgentsConfig struct {
+	Max     int    `mapstructure:"max"`     // sub-agents running at once
+	Policy  string `mapstructure:"policy"`  // approval policy file like --policy; empty approves whitelisted commands only
+	Timeout int    `mapstructure:"timeout"` // seconds a sub-agent may run
+}
+
+// UpdatesConfig controls the check for new releases on GitHub
+type UpdatesConfig struct {
+	Check         bool `mapstructure:"check"`          // show a notice at startup when a newer release exists
+	IntervalHours int  `mapstructure:"interval_hours"` // hours between checks
+}
+
+// LogRotationConfig limits the size of ~/.config/tmuxai/tmuxai.log, 0 turns a limit off
+type LogRotationConfig struct {
+	MaxSizeMB  int `mapstructure:"max_size_mb"`
+	MaxAgeDays int `mapstructure:"max_age_days"`
+	MaxBackups int `mapstructure:"max_backups"`
 }
 
 // OpenRouterConfig holds OpenRouter API configuration
@@ -54,6 +145,98 @@ type OpenRouterConfig struct {
 	APIKey  string `mapstructure:"api_key"`
 	Model   string `mapstructure:"model"`
 	BaseURL string `mapstructure:"base_url"`
+	Stream  bool   `mapstructure:"stream"`  // show the response while it is generated
+	Timeout int    `mapstructure:"timeout"` // seconds a request may take, 0 for no limit
+	// offer the actions and MCP tools as native tools instead of parsing XML tags from the text
+	ToolCalling bool `mapstructure:"tool_calling"`
+	// ask for the response as a JSON object following a schema instead of XML tags, asking again when it doesn't validate
+	JSONMode bool `mapstructure:"json_mode"`
+	// USD per million tokens, for the cost estimate of /cost and /stats; 0 uses the pricing OpenRouter publishes
+	InputPrice  float64 `mapstructure:"input_price"`
+	OutputPrice float64 `mapstructure:"output_price"`
+}
+
+// ProviderConfig is the section of a provider other than OpenRouter. Stream, timeout,
+// prices, tool_calling and json_mode are shared from the openrouter section.
+type ProviderConfig struct {
+	APIKey     string `mapstructure:"api_key"`     // the provider's usual environment variable when empty
+	Model      string `mapstructure:"model"`       // the deployment name for azure
+	BaseURL    string `mapstructure:"base_url"`    // the provider's API when empty; for azure the resource endpoint
+	APIVersion string `mapstructure:"api_version"` // azure only
+}
+
+// providerDefaults are the environment variable of the key and the OpenAI compatible
+// endpoint of each provider
+var providerDefaults = map[string]struct{ env, baseURL string }{
+	"openai":    {"OPENAI_API_KEY", "https://api.openai.com/v1"},
+	"anthropic": {"ANTHROPIC_API_KEY", "https://api.anthropic.com/v1/"},
+	"azure":     {"AZURE_OPENAI_API_KEY", ""},
+	"gemini":    {"GEMINI_API_KEY", "https://generativelanguage.googleapis.com/v1beta/openai/"},
+}
+
+// DefaultAzureAPIVersion is used when azure.api_version is not set
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// IsProvider reports whether name is a provider with its own config section
+func IsProvider(name string) bool {
+	_, ok := providerDefaults[name]
+	return ok || name == "" || name == "openrouter"
+}
+
+// ProviderSection returns the config section of the selected provider, nil for
+// openrouter and mock
+func (c *Config) ProviderSection() *ProviderConfig {
+	switch c.Provider {
+	case "openai":
+		return &c.OpenAI
+	case "anthropic":
+		return &c.Anthropic
+	case "azure":
+		return &c.Azure
+	case "gemini":
+		return &c.Gemini
+	}
+	return nil
+}
+
+// Endpoint returns the openrouter section with the API key, model and base URL of the
+// selected provider filled in, which is all the client needs to talk to it
+func (c *Config) Endpoint() OpenRouterConfig {
+	endpoint := c.OpenRouter
+	section := c.ProviderSection()
+	if section == nil {
+		return endpoint
+	}
+	defaults := providerDefaults[c.Provider]
+	endpoint.APIKey, endpoint.Model, endpoint.BaseURL = section.APIKey, section.Model, section.BaseURL
+	if endpoint.APIKey == "" {
+		endpoint.APIKey = os.Getenv(defaults.env)
+	}
+	if endpoint.BaseURL == "" {
+		endpoint.BaseURL = defaults.baseURL
+	}
+	return endpoint
+}
+
+// APIKeyHint says where the key of the selected provider is read from
+func (c *Config) APIKeyHint() string {
+	if c.ProviderSection() == nil {
+		return "openrouter.api_key or TMUXAI_OPENROUTER_API_KEY"
+	}
+	return fmt.Sprintf("%s.api_key, TMUXAI_%s_API_KEY or %s", c.Provider, strings.ToUpper(c.Provider), providerDefaults[c.Provider].env)
+}
+
+// MockConfig scripts the answers of the mock provider, for demos, tests and CI without an API
+type MockConfig struct {
+	Responses []string `mapstructure:"responses"` // answered in turn, starting over after the last; a built-in demo when empty
+	File      string   `mapstructure:"file"`      // responses separated by lines of ---, used instead of responses
+	Latency   int      `mapstructure:"latency"`   // milliseconds before each answer
+}
+
+// HTTPConfig holds the TLS options of the connections to the model API and MCP servers
+type HTTPConfig struct {
+	CAFile             string `mapstructure:"ca_file"`              // PEM certificates trusted besides the system ones
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"` // only for self-signed test servers
 }
 
 // PromptsConfig holds customizable prompt templates
@@ -64,34 +247,249 @@ type PromptsConfig struct {
 	Watch                 string `mapstructure:"watch"`
 }
 
+// ContextConfig controls additional context sent along with the pane captures
+type ContextConfig struct {
+	ProjectTree      bool     `mapstructure:"project_tree"`       // include the exec pane cwd tree
+	ProjectTreeDepth int      `mapstructure:"project_tree_depth"` // max depth of the project tree
+	Git              bool     `mapstructure:"git"`                // include branch, dirty files and recent commits
+	GitCommits       int      `mapstructure:"git_commits"`        // number of recent commits to include
+	GitStagedDiff    bool     `mapstructure:"git_staged_diff"`    // also include the staged diff
+	Tasks            bool     `mapstructure:"tasks"`              // include Makefile/justfile/package.json targets
+	ExecHistory      bool     `mapstructure:"exec_history"`       // include the parsed commands of a prepared pane
+	TrimOrder        []string `mapstructure:"trim_order"`         // labels trimmed first when over budget
+	// Per-source token budgets, 0 disables the limit
+	ExecPaneTokens    int `mapstructure:"exec_pane_tokens"`    // exec pane capture
+	OtherPaneTokens   int `mapstructure:"other_pane_tokens"`   // each read-only pane capture
+	ExecHistoryTokens int `mapstructure:"exec_history_tokens"` // parsed exec history of a prepared pane
+}
+
+// ServerConfig holds the local HTTP API settings used by `tmuxai serve`
+type ServerConfig struct {
+	Addr        string                   `mapstructure:"addr"`
+	AllowRemote bool                     `mapstructure:"allow_remote"` // allow an addr that isn't loopback
+	Token       string                   `mapstructure:"token"`        // generated into the config dir when empty
+	Webhooks    map[string]WebhookConfig `mapstructure:"webhooks"`
+}
+
+// NotificationsConfig controls messages sent to chat webhooks while away from the terminal
+type NotificationsConfig struct {
+	Sinks           []NotificationSink `mapstructure:"sinks"`
+	LongTaskSeconds int                `mapstructure:"long_task_seconds"` // notify when a task ran longer than this
+	Alerts          AlertsConfig       `mapstructure:"alerts"`
+}
+
+// AlertsConfig picks the events that ring the terminal bell, show a message in the tmux
+// status line or a desktop notification
+type AlertsConfig struct {
+	Bell     []string `mapstructure:"bell"`      // done, confirm, watch, waiting
+	Tmux     []string `mapstructure:"tmux"`      // same events, with tmux display-message
+	Desktop  []string `mapstructure:"desktop"`   // same events; uses notify-send or osascript
+	OnlyAway bool     `mapstructure:"only_away"` // skip alerts while the chat pane is in view
+}
+
+// NotificationSink is a Slack or Discord incoming webhook
+type NotificationSink struct {
+	Type   string   `mapstructure:"type"`   // "slack" or "discord"
+	URL    string   `mapstructure:"url"`    // incoming webhook URL
+	Events []string `mapstructure:"events"` // watch, task, budget; empty for all
+}
+
+// HighlightConfig controls syntax highlighting of code blocks in AI output
+type HighlightConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Theme   string `mapstructure:"theme"` // chroma style name, e.g. monokai, dracula, github; empty follows the color theme
+}
+
+// ThemeConfig selects the colors used for prompts, info output and confirmations
+type ThemeConfig struct {
+	Preset string            `mapstructure:"preset"` // "dark" or "light"
+	Colors map[string]string `mapstructure:"colors"` // per-color overrides, e.g. label: "cyan bold"
+}
+
+// HistoryConfig controls the chat input history kept across sessions
+type HistoryConfig struct {
+	File string `mapstructure:"file"` // defaults to history in the config dir
+	Size int    `mapstructure:"size"` // max entries kept, 0 disables saving
+}
+
+// SuggestionsConfig controls the ghost-text suggestions of the chat input, accepted with Tab
+type SuggestionsConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Model    string `mapstructure:"model"`    // small model completing what the history can't, "" for history only
+	BaseURL  string `mapstructure:"base_url"` // e.g. a local Ollama at http://localhost:11434/v1, openrouter.base_url when empty
+	APIKey   string `mapstructure:"api_key"`  // openrouter.api_key when empty
+	Debounce int    `mapstructure:"debounce"` // milliseconds without typing before the model is asked
+}
+
+// WatchConfig controls how watch mode notices that the panes changed
+type WatchConfig struct {
+	PollInterval int `mapstructure:"poll_interval"` // milliseconds between pane checks, no API calls are made
+	Debounce     int `mapstructure:"debounce"`      // milliseconds the panes must be quiet before the model is asked
+	Interval     int `mapstructure:"interval"`      // minimum seconds between model calls, /watch --interval overrides it
+	MaxCalls     int `mapstructure:"max_calls"`     // model calls after which a watch stops, 0 for no limit; caps /watch --max-calls
+}
+
+// SessionStoreConfig caps the history kept in memory; older entries move to a file on disk
+type SessionStoreConfig struct {
+	MaxMessages    int    `mapstructure:"max_messages"`     // chat messages kept in memory, 0 for no limit
+	MaxExecHistory int    `mapstructure:"max_exec_history"` // exec pane commands kept in memory, 0 for no limit
+	Dir            string `mapstructure:"dir"`              // defaults to sessions in the config dir
+}
+
+// CompactionConfig controls how the chat history is summarized as it nears max_context_size
+type CompactionConfig struct {
+	Auto         bool `mapstructure:"auto"`          // summarize automatically, otherwise only on /squash
+	Threshold    int  `mapstructure:"threshold"`     // percent of max_context_size that triggers it
+	KeepMessages int  `mapstructure:"keep_messages"` // most recent messages kept verbatim
+}
+
+// HooksConfig holds shell commands run around every command executed in the exec pane
+type HooksConfig struct {
+	PreExec  []string `mapstructure:"pre_exec"`  // a non-zero exit blocks the command
+	PostExec []string `mapstructure:"post_exec"` // run after the command finished
+	Timeout  int      `mapstructure:"timeout"`   // seconds per hook
+}
+
+// WebhookConfig describes what a named incoming webhook does in serve mode
+type WebhookConfig struct {
+	Action   string `mapstructure:"action"`   // "message" (default) or "watch"
+	Template string `mapstructure:"template"` // Go text/template rendered with the JSON payload
+}
+
 // DefaultConfig returns a configuration with default values
 func DefaultConfig() *Config {
 	return &Config{
 		Debug:                 false,
+		PprofAddr:             "localhost:6060",
 		MaxCaptureLines:       200,
+		CaptureCacheTTL:       300,
 		MaxContextSize:        20000,
 		WaitInterval:          5,
 		SendKeysConfirm:       true,
 		PasteMultilineConfirm: true,
 		ExecConfirm:           true,
-		WhitelistPatterns:     []string{},
-		BlacklistPatterns:     []string{},
+		Interface:             "readline",
+		EditingMode:           "emacs",
+		PromptFormat:          "{name} {state} » ",
+		Language:              "en",
+		LogLevel:              "info",
+		SessionStore: SessionStoreConfig{
+			MaxMessages:    200,
+			MaxExecHistory: 100,
+		},
+		Compaction: CompactionConfig{
+			Auto:         true,
+			Threshold:    80,
+			KeepMessages: 4,
+		},
+		Watch: WatchConfig{
+			PollInterval: 1000,
+			Debounce:     2000,
+			MaxCalls:     200,
+		},
+		LogRotation: LogRotationConfig{
+			MaxSizeMB:  10,
+			MaxAgeDays: 30,
+			MaxBackups: 3,
+		},
+		DiffStyle:         "unified",
+		ControlSocket:     true,
+		FifoInput:         true,
+		AuditLog:          true,
+		ConfigReload:      true,
+		WhitelistPatterns: []string{},
+		BlacklistPatterns: []string{},
+		CommandRules:      []CommandRule{},
+		Provider:          "openrouter",
 		OpenRouter: OpenRouterConfig{
 			BaseURL: "https://openrouter.ai/api/v1",
 			Model:   "google/gemini-flash-1.5",
+			Timeout: 300,
+		},
+		OpenAI:    ProviderConfig{Model: "gpt-4.1"},
+		Anthropic: ProviderConfig{Model: "claude-sonnet-4-20250514"},
+		Gemini:    ProviderConfig{Model: "gemini-2.5-pro"},
+		Mock: MockConfig{
+			Responses: []string{},
+			Latency:   500,
 		},
 		Mcp: McpConfig{
-			Servers: []McpServer{},
+			Servers:             []McpServer{},
+			HealthCheckInterval: 30,
+			Lazy:                true,
+			ToolCacheTTL:        3600,
+			ParallelCalls:       4,
 		},
 		Prompts: PromptsConfig{
 			BaseSystem:    ``,
 			ChatAssistant: ``,
 		},
+		Context: ContextConfig{
+			ProjectTree:       false,
+			ProjectTreeDepth:  2,
+			Git:               false,
+			GitCommits:        5,
+			GitStagedDiff:     false,
+			Tasks:             true,
+			ExecHistory:       false,
+			TrimOrder:         []string{"file", "exec_history", "pane", "chat"},
+			ExecPaneTokens:    4000,
+			OtherPaneTokens:   2000,
+			ExecHistoryTokens: 1000,
+		},
+		Server: ServerConfig{
+			Addr:     "127.0.0.1:8765",
+			Webhooks: map[string]WebhookConfig{},
+		},
+		Notifications: NotificationsConfig{
+			Sinks:           []NotificationSink{},
+			LongTaskSeconds: 60,
+			Alerts: AlertsConfig{
+				Bell:     []string{"done", "confirm"},
+				Tmux:     []string{"done", "confirm", "watch", "waiting"},
+				Desktop:  []string{},
+				OnlyAway: true,
+			},
+		},
+		Hooks: HooksConfig{
+			PreExec:  []string{},
+			PostExec: []string{},
+			Timeout:  10,
+		},
+		History: HistoryConfig{
+			Size: 1000,
+		},
+		Suggestions: SuggestionsConfig{
+			Enabled:  false,
+			Debounce: 400,
+		},
+		Updates: UpdatesConfig{
+			Check:         false,
+			IntervalHours: 24,
+		},
+		Highlight: HighlightConfig{
+			Enabled: true,
+		},
+		Theme: ThemeConfig{
+			Preset: "dark",
+			Colors: map[string]string{},
+		},
+		SubAgents: SubAgentsConfig{
+			Max:     3,
+			Timeout: 600,
+		},
 	}
 }
 
-// Load loads the configuration from file or environment variables
+// Load loads the configuration from file or environment variables, with the profile
+// selected by the profile key applied
 func Load() (*Config, error) {
+	return LoadProfile("")
+}
+
+// LoadProfile loads the configuration with the named profile applied over it; an empty
+// name applies the profile selected by the profile key, if any
+func LoadProfile(name string) (*Config, error) {
 	config := DefaultConfig()
 
 	viper.SetConfigName("config")
@@ -129,15 +527,138 @@ func Load() (*Config, error) {
 		}
 	}
 
+	if name == "" {
+		name = viper.GetString("profile")
+	}
+	if name != "" {
+		// viper lowercases keys, profile names included
+		values, ok := viper.GetStringMap("profiles")[strings.ToLower(name)].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q, the config has: %s", name, strings.Join(profileNames(viper.GetStringMap("profiles")), ", "))
+		}
+		if err := viper.MergeConfigMap(values); err != nil {
+			return nil, fmt.Errorf("failed to apply profile %s: %w", name, err)
+		}
+	}
+
 	if err := viper.Unmarshal(config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	config.Profile = strings.ToLower(name)
+
 	ResolveEnvKeyInConfig(config)
 
 	return config, nil
 }
 
+// FileUsed returns the path of the config file that was loaded, empty when there was none
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// ProfileNames returns the names of the profiles in the config, sorted
+func (c *Config) ProfileNames() []string {
+	profiles := make(map[string]interface{}, len(c.Profiles))
+	for name := range c.Profiles {
+		profiles[name] = nil
+	}
+	return profileNames(profiles)
+}
+
+func profileNames(profiles map[string]interface{}) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnknownKey is a key of the config file that is no setting
+type UnknownKey struct {
+	Key        string
+	Suggestion string // the closest setting, "" when none is close
+}
+
+// UnknownKeys reads the config file that was loaded again and returns its keys, also those
+// in profiles, that are no settings. Viper ignores them, so they are usually typos.
+func UnknownKeys() ([]UnknownKey, error) {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil, nil
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	known := EnumerateConfigKeys(reflect.TypeOf(Config{}), "")
+	var unknown []UnknownKey
+	for _, key := range v.AllKeys() {
+		setting := key
+		if rest, ok := strings.CutPrefix(key, "profiles."); ok {
+			_, setting, _ = strings.Cut(rest, ".")
+		}
+		if isKnownKey(setting, known) {
+			continue
+		}
+		unknown = append(unknown, UnknownKey{Key: key, Suggestion: closestKey(setting, known)})
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Key < unknown[j].Key })
+	return unknown, nil
+}
+
+// isKnownKey reports whether key is a setting or lies within a map or list setting,
+// like theme.colors.prompt
+func isKnownKey(key string, known []string) bool {
+	for _, k := range known {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// closestKey suggests the setting a mistyped key probably meant: the one with the same
+// last part, e.g. openrouter.model for model, or else the one within a few typos
+func closestKey(key string, known []string) string {
+	last := key[strings.LastIndex(key, ".")+1:]
+	for _, k := range known {
+		if k[strings.LastIndex(k, ".")+1:] == last {
+			return k
+		}
+	}
+	best, bestDistance := "", len(key)/3+2
+	for _, k := range known {
+		if d := editDistance(key, k); d < bestDistance {
+			best, bestDistance = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance of two keys
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
 // EnumerateConfigKeys returns all config keys (dot notation) for the given struct type.
 func EnumerateConfigKeys(cfgType reflect.Type, prefix string) []string {
 	var keys []string
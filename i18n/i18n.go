@@ -0,0 +1,84 @@
+// Package i18n translates the user-facing strings of TmuxAI.
+//
+// Messages are written in English in the code and double as catalog keys, like
+// fmt format strings; a language catalog maps them to their translation. Messages
+// without a translation are shown in English.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language of the messages in the code
+const DefaultLanguage = "en"
+
+var catalogs = map[string]map[string]string{
+	"zh": zh,
+}
+
+var (
+	mu       sync.RWMutex
+	language = DefaultLanguage
+)
+
+// Languages returns the supported language codes
+func Languages() []string {
+	languages := []string{DefaultLanguage}
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	slices.Sort(languages[1:])
+	return languages
+}
+
+// SetLanguage selects the language of the interface. Locale names like zh_CN.UTF-8
+// select their language; an empty name selects English.
+func SetLanguage(lang string) error {
+	lang, err := ParseLanguage(lang)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	language = lang
+	mu.Unlock()
+	return nil
+}
+
+// ParseLanguage returns the supported language a name like zh_CN.UTF-8 selects
+func ParseLanguage(lang string) (string, error) {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "" {
+		lang = DefaultLanguage
+	}
+	if !slices.Contains(Languages(), lang) {
+		return "", fmt.Errorf("unsupported language: %s (available: %s)", lang, strings.Join(Languages(), ", "))
+	}
+	return lang, nil
+}
+
+// Language returns the selected language code
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T translates msg to the selected language and, when args are given, formats it
+// like fmt.Sprintf
+func T(msg string, args ...any) string {
+	mu.RLock()
+	if translated, ok := catalogs[language][msg]; ok {
+		msg = translated
+	}
+	mu.RUnlock()
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,114 @@
+// Unit tests for the string catalog in i18n.go
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Test: messages are translated in the selected language and fall back to English
+func TestT(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	if got := T("Set %s = %s", "a", "b"); got != "Set a = b" {
+		t.Errorf("unexpected English message: %q", got)
+	}
+	if err := SetLanguage("zh_CN.UTF-8"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if Language() != "zh" {
+		t.Errorf("expected zh, got %q", Language())
+	}
+	if got := T("Set %s = %s", "a", "b"); got != "已设置 a = b" {
+		t.Errorf("unexpected Chinese message: %q", got)
+	}
+	if got := T("not in the catalog 100%"); got != "not in the catalog 100%" {
+		t.Errorf("expected untranslated messages unchanged, got %q", got)
+	}
+}
+
+// Test: unknown languages are rejected and keep the current one
+func TestSetLanguageUnknown(t *testing.T) {
+	if err := SetLanguage("xx"); err == nil {
+		t.Errorf("expected an error for an unknown language")
+	}
+	if Language() != DefaultLanguage {
+		t.Errorf("expected the language to stay %s, got %s", DefaultLanguage, Language())
+	}
+	if !slices.Equal(Languages(), []string{"en", "zh"}) {
+		t.Errorf("unexpected languages: %v", Languages())
+	}
+}
+
+// Test: translations keep the format verbs of their message in order
+func TestCatalogVerbs(t *testing.T) {
+	verbs := regexp.MustCompile(`%[-+# 0-9.*]*[a-zA-Z%]`)
+	for lang, catalog := range catalogs {
+		for msg, translated := range catalog {
+			if !slices.Equal(verbs.FindAllString(msg, -1), verbs.FindAllString(translated, -1)) {
+				t.Errorf("%s: verbs of %q differ in %q", lang, msg, translated)
+			}
+		}
+	}
+}
+
+// Test: every literal message passed to i18n.T in the module has a zh translation
+func TestCatalogComplete(t *testing.T) {
+	fset := token.NewFileSet()
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != ".." && (strings.HasPrefix(name, ".") || name == "i18n") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "T" {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			msg, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				t.Errorf("%s: %v", fset.Position(lit.Pos()), err)
+				return true
+			}
+			if _, ok := zh[msg]; !ok {
+				t.Errorf("%s: no zh translation for %q", fset.Position(lit.Pos()), msg)
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
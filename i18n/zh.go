@@ -0,0 +1,416 @@
+package i18n
+
+// zh is the Simplified Chinese catalog
+var zh = map[string]string{
+	// chat
+	"Type '/help' for a list of commands, '/exit' to quit": "输入 '/help' 查看命令列表，输入 '/exit' 退出",
+	"Empty command": "空命令",
+	"Unknown command: %s. Use '/help' for more info.":                                                                    "未知命令：%s。使用 '/help' 查看更多信息。",
+	"OpenRouter API key is required. Set it in the config file or as an environment variable: TMUXAI_OPENROUTER_API_KEY": "需要 OpenRouter API 密钥。请在配置文件中设置，或设置环境变量：TMUXAI_OPENROUTER_API_KEY",
+	"API key for %s is required. Set %s":                                                                                 "需要 %s 的 API 密钥。请设置 %s",
+	"Exec pane prepared successfully":                                                                                    "执行窗格已准备就绪",
+	"Usage: /watch [--panes <id,id,...>|all] [--interval 10s] [--max-calls n] [--pattern <regex> [--action comment|notify|command] [--command <cmd>]] <description>": "用法：/watch [--panes <ID,ID,...>|all] [--interval 10s] [--max-calls n] [--pattern <正则> [--action comment|notify|command] [--command <命令>]] <描述>",
+	"Invalid interval %s, e.g. 10s or 2m":                                             "无效的间隔 %s，例如 10s 或 2m",
+	"Invalid --max-calls %s, give a positive number":                                  "无效的 --max-calls %s，请给出正整数",
+	"Watch stopped after %d model calls, the limit of watch.max_calls or --max-calls": "监视已在 %d 次模型调用后停止，达到 watch.max_calls 或 --max-calls 的上限",
+	"TmuxAI watch budget exhausted":                                                   "TmuxAI 监视预算已用尽",
+	"Invalid pattern: %v":                                                             "无效的模式：%v",
+	"Unknown watch action %s, use comment, notify or command":                         "未知的监视动作 %s，请使用 comment、notify 或 command",
+	"--action and --command need --pattern":                                           "--action 和 --command 需要配合 --pattern 使用",
+	"--action command needs --command <cmd>":                                          "--action command 需要 --command <命令>",
+	"Watching for %s (%s), Ctrl+C to stop":                                            "正在监视 %s（%s），按 Ctrl+C 停止",
+	"TmuxAI watch: %s matched in pane %s":                                             "TmuxAI 监视：%s 在窗格 %s 中匹配",
+	"Pattern matched in pane %s, running: %s":                                         "窗格 %s 中匹配到模式，正在运行：%s",
+	"Watch command failed: %v %s":                                                     "监视命令失败：%v %s",
+	"Unknown /watch option %s. %s":                                                    "未知的 /watch 选项 %s。%s",
+
+	// /help
+	"Available commands:":                                           "可用命令：",
+	"Display system information":                                    "显示系统信息",
+	"Clear the chat history":                                        "清空聊天记录",
+	"Reset the chat history":                                        "重置聊天记录",
+	"Prepare the pane for TmuxAI automation":                        "为 TmuxAI 自动化准备窗格",
+	"Start watch mode":                                              "启动监视模式",
+	"Summarize the chat history":                                    "总结聊天记录",
+	"Search the whole session, including history moved to disk":     "搜索整个会话，包括已移到磁盘的记录",
+	"Save the conversation, overrides and MCP servers under a name": "以名称保存对话、会话覆盖设置和 MCP 服务器",
+	"Load a saved session, or list them":                            "加载已保存的会话，或列出已保存的会话",
+	"Usage: /save <name>":                                           "用法：/save <名称>",
+	"Invalid session name %q, use letters, digits, dots, dashes and underscores": "无效的会话名称 %q，请使用字母、数字、点、短横线和下划线",
+	"Nothing to save yet":                                                     "还没有可保存的内容",
+	"Failed to save session: %v":                                              "保存会话失败：%v",
+	"Session saved as %s (%d messages), /load %s restores it":                 "会话已保存为 %s（%d 条消息），/load %s 可恢复",
+	"Failed to load session: %v":                                              "加载会话失败：%v",
+	"No saved session named %s, /load lists them":                             "没有名为 %s 的已保存会话，/load 可列出所有会话",
+	"Loaded session %s from %s (%d messages)":                                 "已加载会话 %s（保存于 %s，%d 条消息）",
+	"No saved sessions, /save <name> saves the current one":                   "没有已保存的会话，/save <名称> 可保存当前会话",
+	"(%s, %d messages)":                                                       "（%s，%d 条消息）",
+	"Show session analytics: commands, exit codes, latency, tokens and tools": "显示会话统计：命令、退出码、延迟、令牌和工具",
+	"Show token usage and estimated cost by model":                            "按模型显示令牌用量和估算费用",
+	"No requests yet":                                                         "还没有请求",
+	"%d requests, %s in, %s out, %s":                                          "%d 次请求，输入 %s，输出 %s，%s",
+	"%s in, %s out, %s":                                                       "输入 %s，输出 %s，%s",
+	"Total:":                                                                  "合计：",
+	"Last request:":                                                           "上次请求：",
+	"Some token counts are estimated, the API did not report usage for every request":      "部分令牌数为估算值，API 并未报告每次请求的用量",
+	"Prices unknown, set openrouter.input_price and output_price (USD per million tokens)": "价格未知，请设置 openrouter.input_price 和 output_price（每百万令牌的美元价格）",
+	"Usage":      "用量",
+	"Tokens In":  "输入令牌",
+	"Tokens Out": "输出令牌",
+	"Cost~":      "费用~",
+	"Explain the last (or nth last) command output and suggest next steps": "解释最近一条（或倒数第 n 条）命令的输出并建议下一步",
+	"Queue requests and work through them one after another":               "将请求排队并依次处理",
+	"Check tmux, the config, the API, MCP servers and the shell":           "检查 tmux、配置、API、MCP 服务器和 shell",
+	"Show runtime stats or write CPU and memory profiles":                  "显示运行时统计或写入 CPU 和内存分析文件",
+	"Add the exec pane's project tree to the context":                      "将执行窗格的项目目录树加入上下文",
+	"Add panes of any window as read-only context":                         "将任意窗口的窗格添加为只读上下文",
+	"Usage: /context [add <pane-id>|remove <pane-id>|clear]":               "用法：/context [add <窗格ID>|remove <窗格ID>|clear]",
+	"Pane %s added as read-only context":                                   "窗格 %s 已添加为只读上下文",
+	"Pane %s is not a context pane":                                        "窗格 %s 不是上下文窗格",
+	"Pane %s removed from the context":                                     "窗格 %s 已从上下文中移除",
+	"Context panes cleared":                                                "上下文窗格已清空",
+	"Pane %s is the TmuxAI chat pane":                                      "窗格 %s 是 TmuxAI 聊天窗格",
+	"Pane %s is the exec pane, the AI runs commands there":                 "窗格 %s 是执行窗格，AI 会在其中运行命令",
+	"Pane %s is already a context pane":                                    "窗格 %s 已经是上下文窗格",
+	"No tmux pane %s, the ids are listed by: tmux list-panes -a":           "没有 tmux 窗格 %s，可用 tmux list-panes -a 查看窗格ID",
+	"No context panes, /context add <pane-id> adds one":                    "没有上下文窗格，/context add <窗格ID> 可添加",
+	"(closed)": "（已关闭）",
+	"Show the request that would be sent next, without sending it":                 "显示下一次将发送的请求，但不发送",
+	"List the project's Makefile, justfile and package.json targets":               "列出项目的 Makefile、justfile 和 package.json 目标",
+	"Generate a commit message for the staged changes and commit":                  "为暂存的更改生成提交信息并提交",
+	"Draft a pull request description from the branch diff":                        "根据分支差异起草拉取请求描述",
+	"Save the executed commands as a runnable shell script":                        "将已执行的命令保存为可运行的 shell 脚本",
+	"Save the conversation with the commands and their output as a shareable file": "将对话及执行的命令和输出保存为可分享的文件",
+	"Mirror the chat transcript read-only to a new tmux window":                    "将聊天记录以只读方式镜像到新的 tmux 窗口",
+	"Manage MCP servers for the current session":                                   "管理当前会话的 MCP 服务器",
+	"List the prompts of the MCP servers, or run one":                              "列出 MCP 服务器的提示模板，或运行其中一个",
+	"Exit the application":         "退出程序",
+	"Script command":               "脚本命令",
+	"Script command %s failed: %v": "脚本命令 %s 失败：%v",
+
+	// /info
+	"General":           "概况",
+	"Version":           "版本",
+	"Max Capture Lines": "最大捕获行数",
+	"Wait Interval":     "等待间隔",
+	"Context":           "上下文",
+	"Messages":          "消息数",
+	"Context Size~":     "上下文大小~",
+	"Max Size":          "最大大小",
+	"%d tokens":         "%d 个令牌",
+	"Tmux Window Panes": "Tmux 窗口窗格",
+	"Pane %s":           "窗格 %s",
+	"TmuxAI Exec Pane":  "TmuxAI 执行窗格",
+	"Read Only":         "只读",
+	"Command":           "命令",
+	"Args":              "参数",
+	"Shell":             "Shell",
+	"OS":                "操作系统",
+	"Size":              "尺寸",
+	"Exec Pane":         "执行窗格",
+	"Prepared":          "已准备",
+	"Sub Shell":         "子 Shell",
+	"yes":               "是",
+	"no":                "否",
+
+	// /config
+	"Usage: /config <get|set|profile|validate> [key] [value]":         "用法：/config <get|set|profile|validate> [键] [值]",
+	"Current configuration:":                                          "当前配置：",
+	"Config key '%s' is not allowed to be modified. Allowed keys: %s": "配置项 '%s' 不允许修改。允许的配置项：%s",
+	"Usage: /config get [key]":                                        "用法：/config get [键]",
+	"Usage: /config set <key> <value>":                                "用法：/config set <键> <值>",
+	"Error setting config: %v":                                        "设置配置出错：%v",
+	"Set %s = %s":                                                     "已设置 %s = %s",
+	"Unknown /config subcommand: %s. Use 'get', 'set', 'profile' or 'validate'.": "未知的 /config 子命令：%s。请使用 'get'、'set'、'profile' 或 'validate'。",
+	"The config is valid": "配置有效",
+	"The config file changed but can't be loaded, keeping the current config: %v":  "配置文件已更改但无法加载，继续使用当前配置：%v",
+	"The config file changed but can't be applied, keeping the current config: %v": "配置文件已更改但无法应用，继续使用当前配置：%v",
+	"Config reloaded, changed: %s":                             "配置已重新加载，已更改：%s",
+	"Session overrides still apply to: %s":                     "会话覆盖仍然生效：%s",
+	"Usage: /config profile [name]":                            "用法：/config profile [名称]",
+	"No profiles, add them under profiles: in the config file": "没有配置方案，请在配置文件的 profiles: 下添加",
+	"Failed to switch profile: %v":                             "切换配置方案失败：%v",
+	"Switched to profile %s, model %s":                         "已切换到配置方案 %s，模型 %s",
+
+	// confirmations
+	"Execute this command?":                                      "执行此命令？",
+	"Send this key?":                                             "发送此按键？",
+	"Send all these keys?":                                       "发送所有这些按键？",
+	"Paste multiline content?":                                   "粘贴多行内容？",
+	"Read this file?":                                            "读取此文件？",
+	"Write this file?":                                           "写入此文件？",
+	"Call this MCP tool?":                                        "调用此 MCP 工具？",
+	"[Y]es/No/Always/Pattern/Edit/View: ":                        "[Y]是/N否/A总是/P按模式/E编辑/V查看：",
+	"[Y]es/No/Always/Pattern/View: ":                             "[Y]是/N否/A总是/P按模式/V查看：",
+	"Approve actions matching: ":                                 "批准匹配以下模式的操作：",
+	"The pattern doesn't match this action":                      "该模式与此操作不匹配",
+	"Approving actions matching %s for the rest of this session": "本次会话内将自动批准匹配 %s 的操作",
+	"Editor failed, editing here instead: %v":                    "编辑器出错，改为在此编辑：%v",
+	"Error reading confirmation: %v":                             "读取确认输入出错：%v",
+	"Approved for the rest of this session":                      "本次会话内不再询问",
+	"Edit command: ":                                             "编辑命令：",
+	"(enter keeps %s) ":                                          "（回车保留 %s）",
+	"Error reading edited command: %v":                           "读取编辑后的命令出错：%v",
+	"Explanation":                                                "说明",
+	"Target":                                                     "目标",
+	"Exec pane":                                                  "执行窗格",
+	"Directory":                                                  "目录",
+	"Content":                                                    "内容",
+	"Policy %s: %s (%s)":                                         "策略 %s：%s（%s）",
+	"TmuxAI is waiting for approval":                             "TmuxAI 正在等待确认",
+	"TmuxAI is waiting for your answer":                          "TmuxAI 正在等待你的回答",
+
+	// requests
+	"Steps:":                     "步骤：",
+	"Executing command: %s":      "正在执行命令：%s",
+	"✓ Step %d done":             "✓ 第 %d 步完成",
+	"✗ Step %d failed (exit %d)": "✗ 第 %d 步失败（退出码 %d）",
+	"exit %d":                    "退出码 %d",
+	"Exceeded context size, squashing history...":               "超出上下文大小，正在压缩历史记录……",
+	"AI didn't follow guidelines, trying again...":              "AI 未遵循规则，正在重试……",
+	"AI response didn't match the JSON schema, trying again...": "AI 响应不符合 JSON 架构，正在重试……",
+	"Command blocked by an on_exec hook: %s":                    "命令被 on_exec 钩子阻止：%s",
+	"Keys to send:":                                             "将发送的按键：",
+	"Sending keys: %s":                                          "正在发送按键：%s",
+	"Pasting...":                                                "正在粘贴……",
+	"Error opening keyboard: %v":                                "打开键盘出错：%v",
+	"[Space: Pause/Resume | Enter: To continue]":                "[空格：暂停/继续 | 回车：继续]",
+
+	// status
+	"idle":                 "空闲",
+	"running":              "运行中",
+	"waiting":              "等待中",
+	"done":                 "已完成",
+	"watching":             "监视中",
+	"waiting for model %s": "等待模型 %s",
+	"context %d%%":         "上下文 %d%%",
+	"exec %s":              "执行 %s",
+	"~%d tokens (%d%%)":    "~%d 个令牌（%d%%）",
+	"scroll %d%%":          "滚动 %d%%",
+
+	// notifications
+	"TmuxAI task finished after %s":                               "TmuxAI 任务已完成，用时 %s",
+	"TmuxAI context budget exceeded":                              "TmuxAI 上下文超出预算",
+	"The chat history is being squashed to fit max_context_size.": "正在压缩聊天记录以符合 max_context_size。",
+	"TmuxAI watch alert":                                          "TmuxAI 监视提醒",
+
+	// /mcp
+	"Unknown /mcp subcommand: %s. Use '/mcp help' for more info.":        "未知的 /mcp 子命令：%s。使用 '/mcp help' 查看更多信息。",
+	"No MCP servers configured. Please add servers to your config file.": "未配置 MCP 服务器。请在配置文件中添加服务器。",
+	"Select MCP servers":                                      "选择 MCP 服务器",
+	"Error running interactive selection: %v":                 "交互式选择出错：%v",
+	"No MCP servers are currently selected for this session.": "当前会话未选择任何 MCP 服务器。",
+	"Error listing tools: %v":                                 "列出工具出错：%v",
+	"%s (tools: unavailable)":                                 "%s（工具：不可用）",
+	"%s (tools: %d available)":                                "%s（工具：%d 个可用）",
+	"🧰 Current MCP servers for this session: %s":              "🧰 当前会话的 MCP 服务器：%s",
+	`
+/mcp: Manage MCP (Multi-Context Prompts) servers for the current session.
+
+Available subcommands:
+
+  /mcp or /mcp list
+    Show a list of available MCP servers from your config file and interactively select/deselect servers for the current session.
+
+  /mcp current
+    Show the list of MCP servers currently selected for this session.
+
+  /prompt [server] [name [key=value|value ...]]
+    List the prompt templates the selected servers offer, or fill one in and send it as a request.
+
+  /mcp status
+    Show the connection state, ping latency and last error of each selected server.
+
+  /mcp login <server>
+    Authorize with a server that has oauth enabled, in the browser; /mcp logout <server> forgets the token.
+
+  /mcp help
+    Show this help message.
+`: `
+/mcp：管理当前会话的 MCP（多上下文提示）服务器。
+
+可用子命令：
+
+  /mcp 或 /mcp list
+    列出配置文件中可用的 MCP 服务器，并交互式地为当前会话选择或取消选择服务器。
+
+  /mcp current
+    显示当前会话已选择的 MCP 服务器。
+
+  /prompt [server] [name [key=value|value ...]]
+    列出所选服务器提供的提示模板，或填写其中一个并作为请求发送。
+
+  /mcp status
+    显示所选服务器的连接状态、ping 延迟和最近的错误。
+
+  /mcp login <server>
+    在浏览器中向启用了 oauth 的服务器授权；/mcp logout <server> 删除令牌。
+
+  /mcp help
+    显示此帮助信息。
+`,
+	"connected":          "已连接",
+	"connected, ping %s": "已连接，ping %s",
+	"not connected yet, connects on first use": "尚未连接，首次使用时连接",
+	"disconnected": "已断开",
+	"stopped, select it again with /mcp to restart it": "已停止，用 /mcp 再次选择以重启",
+	"disconnected, %d failed attempts, retry in %s":    "已断开，%d 次尝试失败，%s 后重试",
+	"(checked %s ago)": "（%s 前检查）",
+	"Last error:":      "最近的错误：",
+	"Health checks are off, set mcp.health_check_interval to reconnect dropped servers": "健康检查已关闭，设置 mcp.health_check_interval 以重新连接断开的服务器",
+	"Usage: /mcp login <server>":                                                  "用法：/mcp login <服务器>",
+	"No MCP server named %s in the config":                                        "配置中没有名为 %s 的 MCP 服务器",
+	"MCP server %s doesn't use OAuth, set oauth.enabled for it":                   "MCP 服务器 %s 未使用 OAuth，请为其设置 oauth.enabled",
+	"Authorize TmuxAI in the browser, waiting for the callback (Ctrl+C cancels):": "请在浏览器中授权 TmuxAI，正在等待回调（Ctrl+C 取消）：",
+	"Could not open a browser, open the url above yourself":                       "无法打开浏览器，请自行打开上面的链接",
+	"Login to %s failed: %v":                                                      "登录 %s 失败：%v",
+	"Logged in to %s":                                                             "已登录 %s",
+	"Usage: /mcp logout <server>":                                                 "用法：/mcp logout <服务器>",
+	"Failed to remove the token: %v":                                              "删除令牌失败：%v",
+	"Logged out of %s":                                                            "已退出 %s",
+	"MCP tool %s is denied by mcp.tool_policy":                                    "MCP 工具 %s 被 mcp.tool_policy 拒绝",
+	"No prompt %s on MCP server %s, /prompt %s lists them":                        "没有提示模板 %s（MCP 服务器 %s），/prompt %s 可列出它们",
+	"Prompt %s returned no text":                                                  "提示模板 %s 未返回文本",
+	"Too many arguments: %s":                                                      "参数过多：%s",
+	"Missing required arguments: %s":                                              "缺少必需的参数：%s",
+	"no prompts":                                                                  "没有提示模板",
+	"MCP server %s is not selected, /mcp current lists the selected ones":         "未选择 MCP 服务器 %s，/mcp current 可列出已选择的服务器",
+
+	// selection
+	"no matches": "无匹配项",
+	"Numbers to select (enter keeps the current selection, - clears it): ":                               "输入要选择的编号（回车保留当前选择，- 清空）：",
+	"This build has no TUI (build with -tags tui), using the chat":                                       "此版本未包含 TUI（使用 -tags tui 构建），改用聊天界面",
+	"%d/%d selected · ↑↓ move · space toggle · ctrl+a all · type to filter · enter confirm · esc cancel": "已选 %d/%d · ↑↓ 移动 · 空格 切换 · ctrl+a 全选 · 输入以过滤 · 回车 确认 · esc 取消",
+
+	// /tree, /tasks, /export-script, /share, editor
+	"Usage: /tree [depth]":                                           "用法：/tree [深度]",
+	"Failed to build project tree: %v":                               "生成项目目录树失败：%v",
+	"Project tree added to the context":                              "项目目录树已加入上下文",
+	"Could not determine exec pane working directory":                "无法确定执行窗格的工作目录",
+	"No Makefile, justfile or package.json scripts found in %s":      "在 %s 中未找到 Makefile、justfile 或 package.json 脚本",
+	"No commands have been executed in this session yet":             "本次会话尚未执行任何命令",
+	"Failed to write script: %v":                                     "写入脚本失败：%v",
+	"Exported %d commands to %s":                                     "已将 %d 条命令导出到 %s",
+	"Usage: /export md|json|html [path]":                             "用法：/export md|json|html [路径]",
+	"Nothing to export yet":                                          "还没有可导出的内容",
+	"Failed to write transcript: %v":                                 "写入对话记录失败：%v",
+	"Usage: /rules [test <command>|test-keys <keys>]":                "用法：/rules [test <命令>|test-keys <按键>]",
+	"No command rules configured":                                    "未配置命令规则",
+	"command_rules (first match wins):":                              "command_rules（第一个匹配的规则生效）：",
+	"blacklist_patterns (never auto approved):":                      "blacklist_patterns（从不自动批准）：",
+	"whitelist_patterns (auto approved):":                            "whitelist_patterns（自动批准）：",
+	"Invalid rule: %v":                                               "无效的规则：%v",
+	"Blocked by %s":                                                  "已被 %s 阻止",
+	"Always confirmed, by %s":                                        "总是需要确认，由 %s 决定",
+	"Auto approved by %s":                                            "由 %s 自动批准",
+	"Not auto approved, by %s; asked when confirmations are on":      "不会自动批准，由 %s 决定；开启确认时会询问",
+	"No rule matches; asked when confirmations are on":               "没有匹配的规则；开启确认时会询问",
+	"Usage: /audit [n]":                                              "用法：/audit [n]",
+	"Failed to read the audit log: %v":                               "读取审计日志失败：%v",
+	"The audit log is empty":                                         "审计日志为空",
+	"Nothing to undo, TmuxAI hasn't run a command yet":               "没有可撤销的内容，TmuxAI 还没有执行过命令",
+	"Undoing: %s":                                                    "正在撤销：%s",
+	"Sub-agent %d %s: %s":                                            "子代理 %d %s：%s",
+	"TmuxAI sub-agent %d %s":                                         "TmuxAI 子代理 %d %s",
+	"Usage: /delegate <task>":                                        "用法：/delegate <任务>",
+	"Failed to start a sub-agent: %v":                                "启动子代理失败：%v",
+	"Sub-agent %d started in pane %s, /agents shows how it is doing": "子代理 %d 已在窗格 %s 中启动，/agents 可查看其进展",
+	"Sub-agent %d is not running":                                    "子代理 %d 未在运行",
+	"Stopping sub-agent %d":                                          "正在停止子代理 %d",
+	"No sub-agents yet, start one with /delegate <task>":             "还没有子代理，使用 /delegate <任务> 启动一个",
+	"Usage: /agents [stop <n>]":                                      "用法：/agents [stop <n>]",
+	"Usage: /tasks [resume [n]|targets]":                             "用法：/tasks [resume [n]|targets]",
+	"Resuming task %d: %s":                                           "正在恢复任务 %d：%s",
+	"No task to resume":                                              "没有可恢复的任务",
+	"No tasks yet. /tasks targets lists the project's Makefile, justfile and package.json targets": "还没有任务。/tasks targets 列出项目的 Makefile、justfile 和 package.json 目标",
+	"/tasks resume [n] continues an interrupted or incomplete task":                                "/tasks resume [n] 继续被中断或未完成的任务",
+	"keys: %s":                  "按键：%s",
+	"Exported %d entries to %s": "已将 %d 条记录导出到 %s",
+	"Usage: /share pane (in serve mode the transcript is also available at /share on the API server)": "用法：/share pane（在 serve 模式下，也可以通过 API 服务器的 /share 获取聊天记录）",
+	"The transcript is already mirrored to pane %s":                                                   "聊天记录已镜像到窗格 %s",
+	"Failed to start the transcript mirror: %v":                                                       "启动聊天记录镜像失败：%v",
+	"Failed to open the mirror window: %v":                                                            "打开镜像窗口失败：%v",
+	"Transcript mirrored read-only to the tmuxai-share window":                                        "聊天记录已以只读方式镜像到 tmuxai-share 窗口",
+	"Proposed an edit for %s:%d-%d":                                                                   "已为 %s:%d-%d 提出修改",
+	"Added %s:%d-%d from the editor to the context":                                                   "已将编辑器中的 %s:%d-%d 加入上下文",
+
+	// /commit, /pr
+	"The exec pane is not inside a git repository":        "执行窗格不在 git 仓库中",
+	"Failed to read staged diff: %v":                      "读取暂存区差异失败：%v",
+	"Nothing is staged, stage changes with git add first": "没有暂存的更改，请先使用 git add 暂存",
+	"Failed to generate commit message: %v":               "生成提交信息失败：%v",
+	"Commit cancelled, run /commit again to regenerate":   "已取消提交，再次运行 /commit 以重新生成",
+	"Failed to write commit message: %v":                  "写入提交信息失败：%v",
+	"Could not determine the base branch, use /pr <base>": "无法确定基准分支，请使用 /pr <基准分支>",
+	"Failed to read branch commits: %v":                   "读取分支提交失败：%v",
+	"No changes between %s and HEAD":                      "%s 与 HEAD 之间没有更改",
+	"Failed to draft PR description: %v":                  "起草拉取请求描述失败：%v",
+
+	// /doctor
+	"fix: %s": "修复：%s",
+
+	// /debug
+	"Usage: /debug [stats] | /debug profile [seconds]":       "用法：/debug [stats] | /debug profile [秒数]",
+	"Profiling the CPU for %d seconds, Ctrl+C to stop early": "正在分析 CPU %d 秒，按 Ctrl+C 提前结束",
+	"Failed to write profiles: %v":                           "写入分析文件失败：%v",
+	"Wrote %s":                                               "已写入 %s",
+	"Inspect them with go tool pprof <file>":                 "使用 go tool pprof <文件> 查看",
+
+	// /search
+	"Usage: /search <text>": "用法：/search <文本>",
+	"No matches for %q":     "没有与 %q 匹配的内容",
+	"%d matches for %q:":    "%d 条与 %q 匹配的内容：",
+
+	// watch mode
+	"Watching for changes, Ctrl+C to stop": "正在监视变化，按 Ctrl+C 停止",
+
+	// crash recovery
+	"TmuxAI crashed, the session was saved to %s and can be restored on the next start": "TmuxAI 崩溃了，会话已保存到 %s，可在下次启动时恢复",
+	"Restore the previous session from %s (%d messages)? [Y/n] ":                        "恢复 %s 的上一个会话（%d 条消息）？[Y/n] ",
+	"Session restored": "会话已恢复",
+
+	// updates
+	"TmuxAI %s is available, run tmuxai self-update": "TmuxAI %s 已发布，运行 tmuxai self-update 更新",
+	"TmuxAI %s is up to date":                        "TmuxAI %s 已是最新版本",
+	"Downloading %s %s":                              "正在下载 %s %s",
+	"Updated %s from %s to %s":                       "已将 %s 从 %s 更新到 %s",
+
+	// /explain
+	"No command output to explain yet, /prepare the exec pane so commands and their output are tracked": "还没有可解释的命令输出，请先 /prepare 执行窗格以记录命令及其输出",
+	"Usage: /explain [n], n from 1 (the last command) to %d":                                            "用法：/explain [n]，n 从 1（最近一条命令）到 %d",
+	"Usage: /explain [n]": "用法：/explain [n]",
+
+	// /queue
+	"Usage: /queue [add <request>|run|skip [n]|remove <n>|clear]":         "用法：/queue [add <请求>|run|skip [n]|remove <n>|clear]",
+	"Queued as task %d, /queue run works through the queue":               "已加入为任务 %d，/queue run 依次处理队列",
+	"No pending task to skip":                                             "没有可跳过的待处理任务",
+	"Skipped task %d":                                                     "已跳过任务 %d",
+	"Removed task %d":                                                     "已移除任务 %d",
+	"Queue cleared":                                                       "队列已清空",
+	"No pending tasks, add one with /queue add <request>":                 "没有待处理的任务，用 /queue add <请求> 添加",
+	"Task %d/%d: %s":                                                      "任务 %d/%d：%s",
+	"Queue paused at task %d, /queue run continues, /queue skip skips it": "队列已在任务 %d 暂停，/queue run 继续，/queue skip 跳过该任务",
+	"Queue finished: %d tasks in %s":                                      "队列完成：%d 个任务，用时 %s",
+	"TmuxAI queue finished":                                               "TmuxAI 队列已完成",
+	"%d done, %d incomplete, %d skipped, %d pending":                      "%d 个完成，%d 个未完成，%d 个已跳过，%d 个待处理",
+	"The queue is empty, add requests with /queue add <request>":          "队列为空，用 /queue add <请求> 添加",
+
+	// /stats
+	"Session:":                 "会话：",
+	"Commands:":                "命令：",
+	"Requests:":                "请求：",
+	"Tokens:":                  "令牌：",
+	"MCP tools:":               "MCP 工具：",
+	"MCP tool:":                "MCP 工具：",
+	"Tool:":                    "工具：",
+	"%d executed, %d rejected": "已执行 %d 条，已拒绝 %d 条",
+	"exit codes %d ok, %d failed (%d%% success)": "退出码 %d 个成功，%d 个失败（成功率 %d%%）",
+	"%d (%d failed)":                 "%d 次（%d 次失败）",
+	"latency p50 %s, p90 %s, p99 %s": "延迟 p50 %s，p90 %s，p99 %s",
+	"~%s in, ~%s out":                "输入约 %s，输出约 %s",
+	"~%s per hour":                   "每小时约 %s",
+	"cost ~$%.2f ($%.2f per hour)":   "费用约 $%.2f（每小时 $%.2f）",
+
+	// telemetry
+	"Help improve TmuxAI by sending anonymous usage counts (version, provider, how often each command is used, never any content)? Change it any time with /config set telemetry. [y/N] ": "发送匿名使用统计（版本、提供商、各命令的使用次数，绝不包含任何内容）来帮助改进 TmuxAI？可随时用 /config set telemetry 更改。[y/N] ",
+}
@@ -0,0 +1,60 @@
+-- Reference Neovim integration for TmuxAI.
+-- Talks to the running instance through `tmuxai ctl`, which uses the control socket.
+local M = {}
+
+M.config = {
+  cmd = "tmuxai",
+}
+
+local function selection(opts)
+  return vim.api.nvim_buf_get_lines(0, opts.line1 - 1, opts.line2, false)
+end
+
+local function ctl(subcommand, opts, extra)
+  local args = {
+    M.config.cmd, "ctl", subcommand,
+    "--file", vim.api.nvim_buf_get_name(0),
+    "--lines", string.format("%d:%d", opts.line1, opts.line2),
+    "--filetype", vim.bo.filetype,
+  }
+  if extra and extra ~= "" then
+    table.insert(args, extra)
+  end
+  local out = vim.fn.system(args, table.concat(selection(opts), "\n"))
+  if vim.v.shell_error ~= 0 then
+    vim.notify(vim.trim(out), vim.log.levels.ERROR, { title = "TmuxAI" })
+    return nil
+  end
+  return out
+end
+
+-- Add the selected lines to the chat context
+function M.context(opts)
+  if ctl("context", opts) then
+    vim.notify("Selection sent to TmuxAI", vim.log.levels.INFO, { title = "TmuxAI" })
+  end
+end
+
+-- Ask a question about the selected lines, the answer shows up in the TmuxAI pane
+function M.ask(opts)
+  ctl("ask", opts, opts.args)
+end
+
+-- Replace the selected lines with the AI's rewrite following the instruction
+function M.edit(opts)
+  local out = ctl("edit", opts, opts.args)
+  if not out then
+    return
+  end
+  out = out:gsub("\n$", "")
+  vim.api.nvim_buf_set_lines(0, opts.line1 - 1, opts.line2, false, vim.split(out, "\n", { plain = true }))
+end
+
+function M.setup(config)
+  M.config = vim.tbl_extend("force", M.config, config or {})
+  vim.api.nvim_create_user_command("TmuxAIContext", M.context, { range = true, desc = "Send selection to TmuxAI" })
+  vim.api.nvim_create_user_command("TmuxAIAsk", M.ask, { range = true, nargs = "+", desc = "Ask TmuxAI about the selection" })
+  vim.api.nvim_create_user_command("TmuxAIEdit", M.edit, { range = true, nargs = "+", desc = "Rewrite the selection with TmuxAI" })
+end
+
+return M
@@ -0,0 +1,8 @@
+if vim.g.loaded_tmuxai then
+  return
+end
+vim.g.loaded_tmuxai = true
+
+if vim.g.tmuxai_no_setup ~= true then
+  require("tmuxai").setup(vim.g.tmuxai_config)
+end
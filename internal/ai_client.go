@@ -2,21 +2,88 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/alvinunreal/tmuxai/config"
 	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
 	"github.com/cloudwego/eino-ext/components/model/openai"
+	aclopenai "github.com/cloudwego/eino-ext/libs/acl/openai"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 )
 
+// ChatProvider is implemented by anything that can answer a chat conversation.
+// AiClient is the default implementation; embedders can plug in their own.
+type ChatProvider interface {
+	GetResponseFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName string) (string, error)
+}
+
+// StreamingProvider is a ChatProvider that can also deliver the response while it is generated
+type StreamingProvider interface {
+	ChatProvider
+	StreamResponseFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName string, onDelta func(string)) (string, error)
+}
+
+// ToolCallingProvider is a ChatProvider that can offer the actions as tools and return
+// the model's structured tool calls next to the text
+type ToolCallingProvider interface {
+	ChatProvider
+	GetToolCallsFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName string, tools []*schema.ToolInfo, onDelta func(string)) (string, []schema.ToolCall, error)
+}
+
+// JSONSchemaProvider is a ChatProvider that can constrain the response to a JSON schema
+// (structured output)
+type JSONSchemaProvider interface {
+	ChatProvider
+	GetJSONResponseFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName, name string, jsonSchema map[string]interface{}) (string, error)
+}
+
+// TokenUsage is the token count the API reported for a response
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// UsageReporter is a ChatProvider that knows the token usage the API reported for its
+// last response; nil when the API reported none
+type UsageReporter interface {
+	LastUsage() *TokenUsage
+}
+
 // AiClient represents an AI client using Eino framework
 type AiClient struct {
 	config    *config.OpenRouterConfig
 	chatModel model.ToolCallingChatModel
+	// azureAPIVersion switches the client to Azure OpenAI's deployment URLs and api-key auth
+	azureAPIVersion string
+
+	usageMu   sync.Mutex
+	lastUsage *TokenUsage
+}
+
+// LastUsage returns the token usage of the last response
+func (c *AiClient) LastUsage() *TokenUsage {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	return c.lastUsage
+}
+
+// setUsage keeps the usage of a response; streamed responses carry it in the last chunk
+func (c *AiClient) setUsage(meta *schema.ResponseMeta) {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	if meta == nil || meta.Usage == nil {
+		c.lastUsage = nil
+		return
+	}
+	c.lastUsage = &TokenUsage{PromptTokens: meta.Usage.PromptTokens, CompletionTokens: meta.Usage.CompletionTokens}
 }
 
 // NewAiClient creates a new AI client using Eino framework
@@ -26,6 +93,37 @@ func NewAiClient(cfg *config.OpenRouterConfig) *AiClient {
 	}
 }
 
+// ErrNoAPIKey is returned by NewChatProvider when the selected provider has no key
+var ErrNoAPIKey = errors.New("API key is required")
+
+// NewChatProvider creates the provider selected by cfg.Provider. OpenAI, Anthropic and
+// Gemini are reached through their OpenAI compatible endpoints, Azure OpenAI through
+// its deployment URLs.
+func NewChatProvider(cfg *config.Config) (ChatProvider, error) {
+	switch cfg.Provider {
+	case "mock":
+		return NewMockProvider(cfg.Mock)
+	case "", "openrouter", "openai", "anthropic", "gemini", "azure":
+		endpoint := cfg.Endpoint()
+		if endpoint.APIKey == "" {
+			return nil, ErrNoAPIKey
+		}
+		client := NewAiClient(&endpoint)
+		if cfg.Provider == "azure" {
+			if endpoint.BaseURL == "" {
+				return nil, fmt.Errorf("azure.base_url is required, e.g. https://<resource>.openai.azure.com")
+			}
+			client.azureAPIVersion = cfg.Azure.APIVersion
+			if client.azureAPIVersion == "" {
+				client.azureAPIVersion = config.DefaultAzureAPIVersion
+			}
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", cfg.Provider)
+	}
+}
+
 // initChatModel initializes the Eino ChatModel with OpenRouter configuration
 func (c *AiClient) initChatModel(ctx context.Context) error {
 	if c.chatModel != nil {
@@ -34,9 +132,12 @@ func (c *AiClient) initChatModel(ctx context.Context) error {
 
 	// Configure OpenAI ChatModel to work with OpenRouter
 	chatModel, err := openai.NewChatModel(ctx, &openai.ChatModelConfig{
-		APIKey:  c.config.APIKey,
-		BaseURL: c.config.BaseURL, // OpenRouter endpoint
-		Model:   c.config.Model,
+		APIKey:     c.config.APIKey,
+		BaseURL:    c.config.BaseURL, // OpenRouter endpoint
+		Model:      c.config.Model,
+		HTTPClient: system.HTTPClient(time.Duration(c.config.Timeout) * time.Second),
+		ByAzure:    c.azureAPIVersion != "",
+		APIVersion: c.azureAPIVersion,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create chat model: %w", err)
@@ -53,26 +154,7 @@ func (c *AiClient) GetResponseFromChatMessages(ctx context.Context, chatMessages
 		return "", err
 	}
 
-	// Convert chat messages to Eino schema format
-	einoMessages := make([]*schema.Message, 0, len(chatMessages))
-
-	for i, msg := range chatMessages {
-		var role schema.RoleType
-
-		if i == 0 && !msg.FromUser {
-			role = schema.System
-		} else if msg.FromUser {
-			role = schema.User
-		} else {
-			role = schema.Assistant
-		}
-
-		einoMessages = append(einoMessages, &schema.Message{
-			Role:    role,
-			Content: msg.Content,
-		})
-	}
-
+	einoMessages := toEinoMessages(chatMessages)
 	logger.Info("Sending %d messages to AI", len(einoMessages))
 
 	// Generate response using Eino ChatModel
@@ -87,9 +169,11 @@ func (c *AiClient) GetResponseFromChatMessages(ctx context.Context, chatMessages
 	}
 
 	if err != nil {
+		c.setUsage(nil)
 		logger.Error("Failed to generate response: %v", err)
 		return "", fmt.Errorf("failed to generate response: %w", err)
 	}
+	c.setUsage(response.ResponseMeta)
 
 	responseContent := response.Content
 	logger.Debug("Received AI response (%d characters): %s", len(responseContent), responseContent)
@@ -100,6 +184,168 @@ func (c *AiClient) GetResponseFromChatMessages(ctx context.Context, chatMessages
 	return responseContent, nil
 }
 
+// StreamResponseFromChatMessages is GetResponseFromChatMessages delivering the response
+// to onDelta piece by piece as the model generates it; the full response is returned at the end
+func (c *AiClient) StreamResponseFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName string, onDelta func(string)) (string, error) {
+	if err := c.initChatModel(ctx); err != nil {
+		return "", err
+	}
+
+	einoMessages := toEinoMessages(chatMessages)
+	logger.Info("Streaming %d messages to AI", len(einoMessages))
+
+	var opts []model.Option
+	if modelName != "" && modelName != c.config.Model {
+		opts = append(opts, model.WithModel(modelName))
+	}
+	stream, err := c.chatModel.Stream(ctx, einoMessages, opts...)
+	if err != nil {
+		logger.Error("Failed to stream response: %v", err)
+		return "", fmt.Errorf("failed to generate response: %w", err)
+	}
+	defer stream.Close()
+
+	c.setUsage(nil)
+	var response strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Error("Failed to stream response: %v", err)
+			return "", fmt.Errorf("failed to generate response: %w", err)
+		}
+		if chunk.ResponseMeta != nil && chunk.ResponseMeta.Usage != nil {
+			c.setUsage(chunk.ResponseMeta)
+		}
+		if chunk.Content != "" {
+			response.WriteString(chunk.Content)
+			onDelta(chunk.Content)
+		}
+	}
+
+	responseContent := response.String()
+	logger.Debug("Received AI response (%d characters): %s", len(responseContent), responseContent)
+	debugChatMessages(chatMessages, responseContent)
+	return responseContent, nil
+}
+
+// GetToolCallsFromChatMessages asks the model with tools bound and returns its text and
+// tool calls; with onDelta set the text is streamed to it. The caller writes the debug
+// dump, since only it knows how the calls are recorded.
+func (c *AiClient) GetToolCallsFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName string, tools []*schema.ToolInfo, onDelta func(string)) (string, []schema.ToolCall, error) {
+	if err := c.initChatModel(ctx); err != nil {
+		return "", nil, err
+	}
+
+	einoMessages := toEinoMessages(chatMessages)
+	logger.Info("Sending %d messages to AI with %d tools", len(einoMessages), len(tools))
+
+	opts := []model.Option{model.WithTools(tools)}
+	if modelName != "" && modelName != c.config.Model {
+		opts = append(opts, model.WithModel(modelName))
+	}
+
+	c.setUsage(nil)
+	if onDelta == nil {
+		response, err := c.chatModel.Generate(ctx, einoMessages, opts...)
+		if err != nil {
+			logger.Error("Failed to generate response: %v", err)
+			return "", nil, fmt.Errorf("failed to generate response: %w", err)
+		}
+		c.setUsage(response.ResponseMeta)
+		logger.Debug("Received AI response (%d characters, %d tool calls): %s", len(response.Content), len(response.ToolCalls), response.Content)
+		return response.Content, response.ToolCalls, nil
+	}
+
+	stream, err := c.chatModel.Stream(ctx, einoMessages, opts...)
+	if err != nil {
+		logger.Error("Failed to stream response: %v", err)
+		return "", nil, fmt.Errorf("failed to generate response: %w", err)
+	}
+	defer stream.Close()
+
+	// tool call arguments arrive in pieces too, they are merged at the end
+	var chunks []*schema.Message
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			logger.Error("Failed to stream response: %v", err)
+			return "", nil, fmt.Errorf("failed to generate response: %w", err)
+		}
+		chunks = append(chunks, chunk)
+		if chunk.Content != "" {
+			onDelta(chunk.Content)
+		}
+	}
+	if len(chunks) == 0 {
+		return "", nil, nil
+	}
+	response, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to merge streamed response: %w", err)
+	}
+	c.setUsage(response.ResponseMeta)
+	logger.Debug("Received AI response (%d characters, %d tool calls): %s", len(response.Content), len(response.ToolCalls), response.Content)
+	return response.Content, response.ToolCalls, nil
+}
+
+// GetJSONResponseFromChatMessages asks the model for a response following jsonSchema,
+// sent as the response_format of the request
+func (c *AiClient) GetJSONResponseFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName, name string, jsonSchema map[string]interface{}) (string, error) {
+	if err := c.initChatModel(ctx); err != nil {
+		return "", err
+	}
+
+	einoMessages := toEinoMessages(chatMessages)
+	logger.Info("Sending %d messages to AI for a JSON response", len(einoMessages))
+
+	opts := []model.Option{aclopenai.WithExtraFields(map[string]any{
+		"response_format": map[string]any{
+			"type":        "json_schema",
+			"json_schema": map[string]any{"name": name, "schema": jsonSchema},
+		},
+	})}
+	if modelName != "" && modelName != c.config.Model {
+		opts = append(opts, model.WithModel(modelName))
+	}
+	response, err := c.chatModel.Generate(ctx, einoMessages, opts...)
+	if err != nil {
+		c.setUsage(nil)
+		logger.Error("Failed to generate response: %v", err)
+		return "", fmt.Errorf("failed to generate response: %w", err)
+	}
+	c.setUsage(response.ResponseMeta)
+	logger.Debug("Received AI response (%d characters): %s", len(response.Content), response.Content)
+	return response.Content, nil
+}
+
+// toEinoMessages converts chat messages to the Eino schema, the first non-user message is the system prompt
+func toEinoMessages(chatMessages []ChatMessage) []*schema.Message {
+	einoMessages := make([]*schema.Message, 0, len(chatMessages))
+	for i, msg := range chatMessages {
+		var role schema.RoleType
+
+		if i == 0 && !msg.FromUser {
+			role = schema.System
+		} else if msg.FromUser {
+			role = schema.User
+		} else {
+			role = schema.Assistant
+		}
+
+		einoMessages = append(einoMessages, &schema.Message{
+			Role:    role,
+			Content: msg.Content,
+		})
+	}
+	return einoMessages
+}
+
 // ChatCompletion provides backward compatibility with the original interface
 // Deprecated: Use GetResponseFromChatMessages instead
 func (c *AiClient) ChatCompletion(ctx context.Context, messages []Message, modelName string) (string, error) {
@@ -0,0 +1,281 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// APIServer exposes a running manager over a local HTTP API
+type APIServer struct {
+	manager    *Manager
+	token      string
+	shareToken string // read-only access to the transcript
+	server     *http.Server
+	mux        *http.ServeMux
+}
+
+type apiMessageRequest struct {
+	Message string `json:"message"`
+}
+
+type apiWatchRequest struct {
+	Description string `json:"description"`
+}
+
+type apiTranscriptEntry struct {
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type apiPane struct {
+	Id             string `json:"id"`
+	CurrentCommand string `json:"current_command"`
+	CurrentPath    string `json:"current_path"`
+	Shell          string `json:"shell"`
+	IsActive       bool   `json:"is_active"`
+	IsTmuxAiPane   bool   `json:"is_tmuxai_pane"`
+	IsExecPane     bool   `json:"is_exec_pane"`
+	IsPrepared     bool   `json:"is_prepared"`
+}
+
+type apiStatus struct {
+	Status    string `json:"status"`
+	WatchMode bool   `json:"watch_mode"`
+	Model     string `json:"model"`
+	ExecPane  string `json:"exec_pane"`
+	Messages  int    `json:"messages"`
+}
+
+// NewAPIServer creates an API server for the manager listening on addr
+func NewAPIServer(m *Manager, addr, token string) *APIServer {
+	s := &APIServer{
+		manager:    m,
+		token:      token,
+		shareToken: newShareToken(),
+		mux:        http.NewServeMux(),
+	}
+	// start collecting events so viewers joining later get the backlog
+	sessionShare()
+	s.mux.HandleFunc("/api/status", s.auth(s.handleStatus))
+	s.mux.HandleFunc("/api/messages", s.auth(s.handleMessages))
+	s.mux.HandleFunc("/api/transcript", s.auth(s.handleTranscript))
+	s.mux.HandleFunc("/api/panes", s.auth(s.handlePanes))
+	s.mux.HandleFunc("/api/watch", s.auth(s.handleWatch))
+	s.mux.HandleFunc("/api/webhooks/", s.webhookAuth(s.handleWebhook))
+	s.mux.HandleFunc("/share", s.shareAuth(s.handleSharePage))
+	s.mux.HandleFunc("/share/events", s.shareAuth(s.handleShareEvents))
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Start begins serving in the background
+func (s *APIServer) Start() error {
+	host, _, err := net.SplitHostPort(s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid server address %q: %w", s.server.Addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		if !s.manager.GetConfig().Server.AllowRemote {
+			return fmt.Errorf("server address %s is not a loopback address, set server.allow_remote to serve on it", s.server.Addr)
+		}
+		logger.Info("API server is bound to non-loopback address %s", s.server.Addr)
+	}
+
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+	}
+	logger.Info("API server listening on %s", listener.Addr())
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Error("API server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops the server
+func (s *APIServer) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// auth wraps a handler with bearer token authentication
+func (s *APIServer) auth(next http.HandlerFunc) http.HandlerFunc {
+	return s.tokenAuth(next, false)
+}
+
+// webhookAuth is auth that also accepts ?token=, since webhook senders can't always set
+// headers. Other endpoints don't, a token in the URL ends up in logs and shell history.
+func (s *APIServer) webhookAuth(next http.HandlerFunc) http.HandlerFunc {
+	return s.tokenAuth(next, true)
+}
+
+func (s *APIServer) tokenAuth(next http.HandlerFunc, queryToken bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if provided == "" && queryToken {
+			provided = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *APIServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.manager.statusSnapshot())
+}
+
+func (s *APIServer) handleMessages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	var req apiMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Message) == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expected JSON body with a non-empty message"})
+		return
+	}
+	go s.manager.HandleExternalMessage("api", req.Message)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+func (s *APIServer) handleTranscript(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	messages := s.manager.MessageHistory()
+	entries := make([]apiTranscriptEntry, 0, len(messages))
+	for _, msg := range messages {
+		role := "assistant"
+		if msg.FromUser {
+			role = "user"
+		}
+		entries = append(entries, apiTranscriptEntry{Role: role, Content: msg.Content, Timestamp: msg.Timestamp})
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *APIServer) handlePanes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	s.manager.ready()
+	panes, err := s.manager.GetTmuxPanes()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	result := make([]apiPane, 0, len(panes))
+	for _, p := range panes {
+		result = append(result, apiPane{
+			Id:             p.Id,
+			CurrentCommand: p.CurrentCommand,
+			CurrentPath:    p.CurrentPath,
+			Shell:          p.Shell,
+			IsActive:       p.IsActive == 1,
+			IsTmuxAiPane:   p.IsTmuxAiPane,
+			IsExecPane:     p.IsTmuxAiExecPane,
+			IsPrepared:     p.IsPrepared,
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *APIServer) handleWatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	var req apiWatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Description) == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expected JSON body with a non-empty description"})
+		return
+	}
+	go s.manager.handleAPIWatch(context.Background(), req.Description)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "watching"})
+}
+
+// handleAPIWatch watches for what a POST /api/watch describes. The client controls the
+// text, so it is never parsed as /watch flags.
+func (m *Manager) handleAPIWatch(ctx context.Context, desc string) {
+	defer m.recoverPanic()
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+
+	logger.Info("External watch from api: %s", desc)
+	fmt.Fprintf(m.out(), "\n%s[api] /watch %s\n", m.GetPrompt(), desc)
+	m.ready()
+	m.startWatch(ctx, watchOptions{desc: desc})
+}
+
+// statusSnapshot returns the current manager state for external clients
+func (m *Manager) statusSnapshot() apiStatus {
+	m.ready()
+	return apiStatus{
+		Status:    m.GetStatus(),
+		WatchMode: m.GetWatchMode(),
+		Model:     m.GetOpenRouterModel(),
+		ExecPane:  m.ExecPane.Id,
+		Messages:  len(m.MessageHistory()),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Failed to encode API response: %v", err)
+	}
+}
+
+// ServerToken returns the configured API token, generating and persisting one
+// in the config directory when none is set
+func ServerToken(cfg *config.Config) (string, error) {
+	if cfg.Server.Token != "" {
+		return cfg.Server.Token, nil
+	}
+
+	tokenPath := config.GetConfigFilePath("server_token")
+	if data, err := os.ReadFile(tokenPath); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	if err := os.WriteFile(tokenPath, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to save token: %w", err)
+	}
+	return token, nil
+}
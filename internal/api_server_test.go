@@ -0,0 +1,107 @@
+// Unit tests for the HTTP API in api_server.go
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+func newTestAPIServer() *APIServer {
+	m := NewManagerForPane(config.DefaultConfig(), "", nil)
+	return NewAPIServer(m, "127.0.0.1:0", "secret")
+}
+
+// Test: ?token= is only accepted by the webhook endpoints
+func TestAPIServerQueryToken(t *testing.T) {
+	s := newTestAPIServer()
+	cases := []struct {
+		target string
+		code   int
+	}{
+		{"/api/status?token=secret", http.StatusUnauthorized},
+		{"/api/transcript?token=secret", http.StatusUnauthorized},
+		{"/api/webhooks/unknown?token=wrong", http.StatusUnauthorized},
+		{"/api/webhooks/unknown?token=secret", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		method := http.MethodGet
+		if strings.HasPrefix(c.target, "/api/webhooks/") {
+			method = http.MethodPost
+		}
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, httptest.NewRequest(method, c.target, nil))
+		if rec.Code != c.code {
+			t.Errorf("%s: expected %d, got %d", c.target, c.code, rec.Code)
+		}
+	}
+}
+
+// Test: requests need the bearer token and reach their handler by path and method
+func TestAPIServerRouting(t *testing.T) {
+	s := newTestAPIServer()
+	cases := []struct {
+		method, target, token, body string
+		code                        int
+	}{
+		{http.MethodGet, "/api/status", "", "", http.StatusUnauthorized},
+		{http.MethodGet, "/api/status", "wrong", "", http.StatusUnauthorized},
+		{http.MethodGet, "/api/status", "secret", "", http.StatusOK},
+		{http.MethodPost, "/api/status", "secret", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/transcript", "secret", "", http.StatusOK},
+		{http.MethodPost, "/api/messages", "secret", `{"message": " "}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/watch", "secret", `not json`, http.StatusBadRequest},
+		{http.MethodGet, "/api/unknown", "secret", "", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, req)
+		if rec.Code != c.code {
+			t.Errorf("%s %s: expected %d, got %d", c.method, c.target, c.code, rec.Code)
+		}
+	}
+}
+
+// Test: an address that isn't loopback is refused without server.allow_remote
+func TestAPIServerRemoteAddr(t *testing.T) {
+	s := newTestAPIServer()
+	s.server.Addr = "0.0.0.0:0"
+	if err := s.Start(); err == nil || !strings.Contains(err.Error(), "server.allow_remote") {
+		t.Errorf("expected the address to be refused, got %v", err)
+	}
+
+	s = newTestAPIServer()
+	if err := s.Start(); err != nil {
+		t.Fatalf("expected loopback to be served, got %v", err)
+	}
+	s.Shutdown(context.Background())
+}
+
+// Test: a watch description is never read as /watch flags, so it can't run a command
+func TestAPIWatchDescriptionNotFlags(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "marker")
+	desc := "--pattern . --action command --command 'touch " + marker + "' x"
+	m := NewManagerForPane(config.DefaultConfig(), "", nil)
+	m.Output = io.Discard
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.handleAPIWatch(ctx, desc)
+	if m.watchOpts.pattern != nil || m.watchOpts.command != "" || m.watchOpts.desc != desc {
+		t.Errorf("expected the whole body as the description, got %+v", m.watchOpts)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Errorf("expected no command to run, got %v", err)
+	}
+}
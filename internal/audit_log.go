@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// Decisions recorded in the audit log
+const (
+	auditApproved = "approved" // the user said yes
+	auditAuto     = "auto"     // approved by a rule, the whitelist or with confirmations off
+	auditDeclined = "declined"
+	auditBlocked  = "blocked" // by a command rule or an exec hook
+)
+
+const (
+	auditDefaultCount = 20
+	auditUsage        = "Usage: /audit [n]"
+)
+
+// auditMu keeps lines of concurrent sessions' writes from interleaving in this process
+var auditMu sync.Mutex
+
+// auditEntry is one line of the audit log: an action sent to tmux, or refused
+type auditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Pane      string    `json:"pane"`
+	Kind      string    `json:"kind"` // "exec", "keys", "paste" or "read"
+	Content   string    `json:"content"`
+	Decision  string    `json:"decision"`
+	ExitCode  *int      `json:"exit_code,omitempty"` // only known in prepared panes
+}
+
+// AuditLogPath returns the append-only log of the actions sent to tmux
+func AuditLogPath() string {
+	return config.GetConfigFilePath("audit.jsonl")
+}
+
+// appendAuditEntry writes an entry to the end of the log at path
+func appendAuditEntry(path string, entry auditEntry) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(entry)
+}
+
+// readAuditEntries returns the last n entries of the log at path, oldest first
+func readAuditEntries(path string, n int) ([]auditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		if len(entries) > n {
+			entries = entries[1:]
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// audit records an action in the audit log unless audit_log is off
+func (m *Manager) audit(kind, content, decision string, code *int) {
+	if !m.Config.AuditLog {
+		return
+	}
+	pane := ""
+	if m.ExecPane != nil {
+		pane = m.ExecPane.Id
+	}
+	entry := auditEntry{Timestamp: time.Now(), Pane: pane, Kind: kind, Content: content, Decision: decision, ExitCode: code}
+	if err := appendAuditEntry(AuditLogPath(), entry); err != nil {
+		logger.Error("Failed to write the audit log: %v", err)
+	}
+}
+
+// handleAuditCommand shows the last entries of the audit log
+func handleAuditCommand(m *Manager, args []string) {
+	n := auditDefaultCount
+	if len(args) > 1 {
+		m.Println(i18n.T(auditUsage))
+		return
+	}
+	if len(args) == 1 {
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v < 1 {
+			m.Println(i18n.T(auditUsage))
+			return
+		}
+		n = v
+	}
+	entries, err := readAuditEntries(AuditLogPath(), n)
+	if err != nil {
+		m.Println(i18n.T("Failed to read the audit log: %v", err))
+		return
+	}
+	if len(entries) == 0 {
+		m.Println(i18n.T("The audit log is empty"))
+		return
+	}
+	for _, e := range entries {
+		status := e.Decision
+		if e.ExitCode != nil {
+			status += fmt.Sprintf(", exit %d", *e.ExitCode)
+		}
+		content := strings.ReplaceAll(e.Content, "\n", `\n`)
+		m.Println(fmt.Sprintf("%s %s %-5s [%s] %s", e.Timestamp.Format("2006-01-02 15:04:05"), e.Pane, e.Kind, status, content))
+	}
+}
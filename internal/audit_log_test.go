@@ -0,0 +1,41 @@
+// Unit tests for the audit log in audit_log.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test: entries are appended as JSON lines and the last n come back oldest first
+func TestAuditLogAppendRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	code := 2
+	for _, e := range []auditEntry{
+		{Pane: "%1", Kind: "exec", Content: "ls", Decision: auditAuto},
+		{Pane: "%1", Kind: "keys", Content: "q", Decision: auditApproved},
+		{Pane: "%1", Kind: "exec", Content: "make test", Decision: auditApproved, ExitCode: &code},
+	} {
+		if err := appendAuditEntry(path, e); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entries, err := readAuditEntries(path, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Content != "q" || entries[1].Content != "make test" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if entries[1].ExitCode == nil || *entries[1].ExitCode != 2 {
+		t.Errorf("expected exit code 2, got %v", entries[1].ExitCode)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("expected a private log file, got %v %v", info, err)
+	}
+
+	if entries, err := readAuditEntries(filepath.Join(t.TempDir(), "none.jsonl"), 5); err != nil || entries != nil {
+		t.Errorf("expected no entries for a missing log, got %v %v", entries, err)
+	}
+}
@@ -9,11 +9,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
 	"github.com/nyaosorg/go-readline-ny"
 	"github.com/nyaosorg/go-readline-ny/completion"
 	"github.com/nyaosorg/go-readline-ny/keys"
-	"github.com/nyaosorg/go-readline-ny/simplehistory"
 )
 
 // Message represents a chat message
@@ -39,40 +38,54 @@ func NewCLIInterface(manager *Manager) *CLIInterface {
 func (c *CLIInterface) Start(initMessage string) error {
 	c.printWelcomeMessage()
 
-	// Initialize history
-	history := simplehistory.New()
-	historyFilePath := config.GetConfigFilePath("history")
-
-	// Load history from file if it exists
-	if historyData, err := os.ReadFile(historyFilePath); err == nil {
-		for _, line := range strings.Split(string(historyData), "\n") {
-			if line = strings.TrimSpace(line); line != "" {
-				history.Add(line)
-			}
-		}
-	}
+	history := newInputHistory(c.manager.GetConfig().History)
 
 	// Initialize editor
 	editor := &readline.Editor{
 		PromptWriter: func(w io.Writer) (int, error) {
 			return io.WriteString(w, c.manager.GetPrompt())
 		},
+		Writer:         c.manager.out(),
 		History:        history,
 		HistoryCycling: true,
 	}
 
-	// Bind TAB key to completion
-	editor.BindKey(keys.CtrlI, c.newCompleter())
+	// Bind TAB key to completion, or to taking the ghost-text suggestion when shown
+	if c.manager.GetConfig().Suggestions.Enabled {
+		suggest := newSuggester(c.manager, history)
+		editor.PredictColor = [2]string{"\x1B[3;90m", "\x1B[23;39m"}
+		editor.Predictor = func(B *readline.Buffer) string { return suggest.predict(B.String()) }
+		editor.BindKey(keys.CtrlI, suggest.acceptCommand(c.newCompleter()))
+	} else {
+		editor.BindKey(keys.CtrlI, c.newCompleter())
+	}
+
+	lineEditor := newLineEditor(c.manager.GetConfig().EditingMode, c.manager.out())
+	lineEditor.bind(editor)
 
 	if initMessage != "" {
-		fmt.Printf("%s%s\n", c.manager.GetPrompt(), initMessage)
+		fmt.Fprintf(c.manager.out(), "%s%s\n", c.manager.GetPrompt(), initMessage)
 		c.processInput(initMessage)
 	}
 
 	ctx := context.Background()
 
 	for {
+		lineEditor.reset()
+		c.manager.refreshStatusHeader()
+		c.manager.setChatReading()
 		line, err := editor.ReadLine(ctx)
+		if p := c.manager.takeChatPrompt(); p != nil {
+			// the line answers another turn's question, e.g. a tmuxai exec confirmation
+			if err != nil {
+				p.cancel()
+			} else {
+				p.answer(line)
+			}
+			if err == nil || err == readline.CtrlC || err == io.EOF {
+				continue
+			}
+		}
 
 		if err == readline.CtrlC {
 			// Ctrl+C pressed, clear the line and continue
@@ -84,18 +97,7 @@ func (c *CLIInterface) Start(initMessage string) error {
 			return err
 		}
 
-		// Save history
-		if line != "" {
-			history.Add(line)
-
-			// Build history data by iterating through all entries
-			historyLines := make([]string, 0, history.Len())
-			for i := 0; i < history.Len(); i++ {
-				historyLines = append(historyLines, history.At(i))
-			}
-			historyData := strings.Join(historyLines, "\n")
-			os.WriteFile(historyFilePath, []byte(historyData), 0644)
-		}
+		history.Add(line)
 
 		// Process the input (preserving multiline content)
 		input := line // Keep the original line including newlines
@@ -115,25 +117,25 @@ func (c *CLIInterface) Start(initMessage string) error {
 
 // printWelcomeMessage prints a welcome message
 func (c *CLIInterface) printWelcomeMessage() {
-	fmt.Println()
-	fmt.Println("Type '/help' for a list of commands, '/exit' to quit")
-	fmt.Println()
+	fmt.Fprintln(c.manager.out())
+	fmt.Fprintln(c.manager.out(), i18n.T("Type '/help' for a list of commands, '/exit' to quit"))
+	fmt.Fprintln(c.manager.out())
 }
 
 func (c *CLIInterface) processInput(input string) {
-	if c.manager.IsMessageSubcommand(input) {
-		c.manager.ProcessSubCommand(input)
-		return
-	}
+	c.manager.turnMu.Lock()
+	defer c.manager.turnMu.Unlock()
 
 	// Set up signal handling for Ctrl+C
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
+	defer signal.Stop(sigChan)
 
 	// Set up a notification channel
 	done := make(chan struct{})
+	defer close(done)
 
-	// Create a cancellable context
+	// Create a cancellable context, Ctrl+C cancels the AI request, MCP calls and pane waits
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -142,20 +144,18 @@ func (c *CLIInterface) processInput(input string) {
 		select {
 		case <-sigChan:
 			cancel()
-			c.manager.Status = ""
-			c.manager.WatchMode = false
+			c.manager.stopTurn()
 		case <-done:
 		}
 	}()
 
-	// Run the message processing in the main thread
-	c.manager.Status = "running"
-	c.manager.ProcessUserMessage(ctx, input)
-	c.manager.Status = ""
-
-	close(done)
+	if c.manager.IsMessageSubcommand(input) {
+		c.manager.ProcessSubCommandContext(ctx, input)
+		return
+	}
 
-	signal.Stop(sigChan)
+	// Run the message processing in the main thread
+	c.manager.runRequest(ctx, input)
 }
 
 // newCompleter creates a completion handler for command completion
@@ -166,7 +166,12 @@ func (c *CLIInterface) newCompleter() *completion.CmdCompletionOrList2 {
 		Candidates: func(field []string) (forComp []string, forList []string) {
 			// Handle top-level commands
 			if len(field) == 0 || (len(field) == 1 && !strings.HasSuffix(field[0], " ")) {
-				return commands, commands
+				all := commands
+				c.manager.ready()
+				if c.manager.Scripts != nil {
+					all = append(append([]string{}, commands...), c.manager.Scripts.Commands()...)
+				}
+				return all, all
 			}
 
 			// Handle /config subcommands
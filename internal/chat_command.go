@@ -1,10 +1,13 @@
 package internal
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/alvinunreal/tmuxai/i18n"
 	"github.com/alvinunreal/tmuxai/logger"
 	"github.com/alvinunreal/tmuxai/system"
 )
@@ -14,9 +17,33 @@ const helpMessage = `Available commands:
 - /clear: Clear the chat history
 - /reset: Reset the chat history
 - /prepare: Prepare the pane for TmuxAI automation
-- /watch <prompt>: Start watch mode
+- /watch [--panes <ids>|all] [--interval 10s] [--max-calls n] [--pattern <regex> --action comment|notify|command] <prompt>: Start watch mode
 - /squash: Summarize the chat history
+- /search <text>: Search the whole session, including history moved to disk
+- /save <name>: Save the conversation, overrides and MCP servers under a name
+- /load [name]: Load a saved session, or list them
+- /stats: Show session analytics: commands, exit codes, latency, tokens and tools
+- /cost: Show token usage and estimated cost by model
+- /doctor: Check tmux, the config, the API, MCP servers and the shell
+- /debug [stats|profile [seconds]]: Show runtime stats or write CPU and memory profiles
+- /explain [n]: Explain the last (or nth last) command output and suggest next steps
+- /queue [add <request>|run|skip [n]|remove <n>|clear]: Queue requests and work through them one after another
+- /tree [depth]: Add the exec pane's project tree to the context
+- /context [add <pane-id>|remove <pane-id>|clear]: Add panes of any window as read-only context
+- /preview [message]: Show the request that would be sent next, without sending it
+- /tasks [resume [n]|targets]: List the session's tasks and their steps, resume an interrupted one, or list the project's targets
+- /commit: Generate a commit message for the staged changes and commit
+- /pr [base]: Draft a pull request description from the branch diff
+- /export md|json|html [path]: Save the conversation with the commands and their output as a shareable file
+- /export-script [path]: Save the executed commands as a runnable shell script
+- /undo: Ask the AI for commands that reverse the last command TmuxAI ran, each confirmed
+- /audit [n]: Show the last n actions sent to tmux from the audit log
+- /rules [test <command>|test-keys <keys>]: List the command rules, or show what they decide for a command
+- /delegate <task>: Hand a task to a sub-agent working in a new pane beside the exec pane
+- /agents [stop <n>]: List the sub-agents with their status and result, or stop one
+- /share pane: Mirror the chat transcript read-only to a new tmux window
 - /mcp: Manage MCP servers for the current session
+- /prompt [server] [name [args]]: List the prompts of the MCP servers, or run one
 - /exit: Exit the application`
 
 var commands = []string{
@@ -29,7 +56,31 @@ var commands = []string{
 	"/prepare",
 	"/config",
 	"/squash",
+	"/search",
+	"/save",
+	"/load",
+	"/stats",
+	"/cost",
+	"/doctor",
+	"/debug",
 	"/mcp",
+	"/prompt",
+	"/tree",
+	"/context",
+	"/preview",
+	"/tasks",
+	"/commit",
+	"/pr",
+	"/export",
+	"/export-script",
+	"/explain",
+	"/queue",
+	"/share",
+	"/rules",
+	"/audit",
+	"/undo",
+	"/delegate",
+	"/agents",
 }
 
 // checks if the given content is a command
@@ -40,38 +91,54 @@ func (m *Manager) IsMessageSubcommand(content string) bool {
 	return strings.HasPrefix(content, "/")
 }
 
-// processes a command and returns a response
+// ProcessSubCommand runs a /command that can't be interrupted
 func (m *Manager) ProcessSubCommand(command string) {
+	m.ProcessSubCommandContext(context.Background(), command)
+}
+
+// ProcessSubCommandContext runs a /command; cancelling ctx stops long ones like /watch
+func (m *Manager) ProcessSubCommandContext(ctx context.Context, command string) {
+	m.ready()
 	commandLower := strings.ToLower(strings.TrimSpace(command))
 	logger.Info("Processing command: %s", command)
 
 	// Get the first word from the command (e.g., "/watch" from "/watch something")
 	parts := strings.Fields(commandLower)
 	if len(parts) == 0 {
-		m.Println("Empty command")
+		m.Println(i18n.T("Empty command"))
 		return
 	}
 
 	commandPrefix := parts[0]
+	m.countCommand(commandPrefix)
 
 	// Process the command using prefix matching
 	switch {
 	case prefixMatch(commandPrefix, "/help"):
-		m.Println(helpMessage)
+		if m.Scripts != nil {
+			m.Println(localizeHelp(helpMessage + m.Scripts.HelpMessage()))
+		} else {
+			m.Println(localizeHelp(helpMessage))
+		}
 		return
 
 	case prefixMatch(commandPrefix, "/info"):
 		m.formatInfo()
 		return
 
+	// exact match, otherwise /pr would be taken as a prefix of /prepare
+	case commandPrefix == "/pr":
+		handlePrCommand(m, strings.Fields(command)[1:])
+		return
+
 	case prefixMatch(commandPrefix, "/prepare"):
 		m.InitExecPane()
 		m.PrepareExecPane()
-		m.Messages = []ChatMessage{}
+		m.ResetMessages()
 		if m.ExecPane.IsPrepared {
-			m.Println("Exec pane prepared successfully")
+			m.Println(i18n.T("Exec pane prepared successfully"))
 		}
-		fmt.Println(m.ExecPane.String())
+		fmt.Fprintln(m.out(), m.ExecPane.String())
 		m.parseExecPaneCommandHistory()
 
 		logger.Debug("Parsed exec history:")
@@ -82,56 +149,202 @@ func (m *Manager) ProcessSubCommand(command string) {
 		return
 
 	case prefixMatch(commandPrefix, "/clear"):
-		m.Messages = []ChatMessage{}
+		m.ResetMessages()
 		system.TmuxClearPane(m.PaneId)
 		return
 
 	case prefixMatch(commandPrefix, "/reset"):
-		m.Status = ""
-		m.Messages = []ChatMessage{}
+		m.SetStatus("")
+		m.ResetMessages()
 		system.TmuxClearPane(m.PaneId)
 		system.TmuxClearPane(m.ExecPane.Id)
 		return
 
 	case prefixMatch(commandPrefix, "/exit"):
 		logger.Info("Exit command received, stopping watch mode (if active) and exiting.")
-		os.Exit(0)
+		if m.tui != nil {
+			// let the TUI restore the terminal before exiting
+			m.tui.quit()
+			return
+		}
+		m.exit(0)
 		return
 
 	case prefixMatch(commandPrefix, "/squash"):
-		m.squashHistory()
+		m.squashHistory(ctx)
+		return
+
+	case prefixMatch(commandPrefix, "/search"):
+		handleSearchCommand(m, strings.Fields(command)[1:])
+		return
+
+	// after /squash and /search, so /s and /se keep meaning them
+	case prefixMatch(commandPrefix, "/save"):
+		handleSaveCommand(m, strings.Fields(command)[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/load"):
+		handleLoadCommand(m, strings.Fields(command)[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/stats"):
+		handleStatsCommand(m)
+		return
+
+	case prefixMatch(commandPrefix, "/doctor"):
+		handleDoctorCommand(ctx, m)
+		return
+
+	case prefixMatch(commandPrefix, "/debug"):
+		handleDebugCommand(ctx, m, strings.Fields(command)[1:])
 		return
 
 	case prefixMatch(commandPrefix, "/watch") || commandPrefix == "/w":
-		parts := strings.Fields(command)
-		if len(parts) > 1 {
-			watchDesc := strings.Join(parts[1:], " ")
-			startWatch := `
-1. Find out if there is new content in the pane based on chat history.
-2. Comment only considering the new content in this pane output.
-
-Watch for: ` + watchDesc
-			m.Status = "running"
-			m.WatchMode = true
-			m.startWatchMode(startWatch)
-			return
-		}
-		m.Println("Usage: /watch <description>")
+		handleWatchCommand(ctx, m, splitArgs(command)[1:])
 		return
 
 	case prefixMatch(commandPrefix, "/config"):
-		handleConfigCommand(m, parts[1:])
+		// values keep their case, model names and prompt formats need it
+		handleConfigCommand(m, strings.Fields(command)[1:])
+		return
+
+	// after /config, so /co and /con keep meaning it
+	case prefixMatch(commandPrefix, "/context"):
+		handleContextCommand(m, parts[1:])
 		return
 
 	case prefixMatch(commandPrefix, "/mcp"):
-		handleMcpCommand(m, parts[1:])
+		// server names keep their case
+		handleMcpCommand(ctx, m, strings.Fields(command)[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/tree"):
+		handleTreeCommand(m, parts[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/preview"):
+		handlePreviewCommand(m, strings.Fields(command)[1:])
+		return
+
+	// after /prepare and /preview, so /p and /pre keep meaning them
+	case prefixMatch(commandPrefix, "/prompt"):
+		handlePromptCommand(ctx, m, splitArgs(command)[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/tasks"):
+		handleTasksCommand(ctx, m, parts[1:])
+		return
+
+	// exact match, otherwise /export would be taken as a prefix of /export-script
+	case commandPrefix == "/export":
+		handleExportCommand(m, strings.Fields(command)[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/export-script"):
+		handleExportScriptCommand(m, strings.Fields(command)[1:])
+		return
+
+	// after /export-script, so /exp keeps meaning it
+	case prefixMatch(commandPrefix, "/explain"):
+		handleExplainCommand(ctx, m, strings.Fields(command)[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/share"):
+		handleShareCommand(m, parts[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/undo"):
+		handleUndoCommand(ctx, m)
+		return
+
+	case prefixMatch(commandPrefix, "/audit"):
+		handleAuditCommand(m, parts[1:])
+		return
+
+	// after /audit, so /a keeps meaning it
+	case prefixMatch(commandPrefix, "/agents"):
+		handleAgentsCommand(m, parts[1:])
+		return
+
+	// after /doctor and /debug, so /d and /de keep meaning them
+	case prefixMatch(commandPrefix, "/delegate"):
+		_, task, _ := strings.Cut(strings.TrimSpace(command), " ")
+		handleDelegateCommand(m, strings.TrimSpace(task))
+		return
+
+	// after /reset, so /r keeps meaning it
+	case prefixMatch(commandPrefix, "/rules"):
+		// commands to test keep their case
+		handleRulesCommand(m, strings.Fields(command)[1:])
+		return
+
+	case prefixMatch(commandPrefix, "/queue"):
+		handleQueueCommand(ctx, m, command)
+		return
+
+	case prefixMatch(commandPrefix, "/commit"):
+		handleCommitCommand(m)
+		return
+
+	// after /config and /commit, so /co and /com keep meaning them
+	case prefixMatch(commandPrefix, "/cost"):
+		handleCostCommand(m)
 		return
 
 	default:
-		m.Println(fmt.Sprintf("Unknown command: %s. Use '/help' for more info.", commandPrefix))
+		if m.Scripts != nil && m.Scripts.HasCommand(commandPrefix) {
+			m.runScriptCommand(commandPrefix, strings.Fields(command)[1:])
+			return
+		}
+		m.Println(i18n.T("Unknown command: %s. Use '/help' for more info.", commandPrefix))
 	}
 }
 
+// splitArgs splits a command line at spaces, keeping the text between single or double
+// quotes together
+func splitArgs(line string) []string {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// localizeHelp translates the header and the descriptions of a "- /command: description" list
+func localizeHelp(help string) string {
+	lines := strings.Split(help, "\n")
+	for i, line := range lines {
+		if usage, description, ok := strings.Cut(line, ": "); ok && strings.HasPrefix(line, "- ") {
+			lines[i] = usage + ": " + i18n.T(description)
+		} else {
+			lines[i] = i18n.T(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Helper function to check if a command matches a prefix
 func prefixMatch(command, target string) bool {
 	return strings.HasPrefix(target, command)
@@ -142,18 +355,16 @@ func (m *Manager) formatInfo() {
 	formatter := system.NewInfoFormatter()
 	const labelWidth = 18 // Width of the label column
 	formatLine := func(key string, value any) {
-		fmt.Print(formatter.LabelColor.Sprintf("%-*s", labelWidth, key))
-		fmt.Print("  ")
-		fmt.Println(value)
+		fmt.Fprint(m.out(), formatter.FormatRow(formatter.Label(i18n.T(key), labelWidth)+" ", labelWidth+2, value))
 	}
 	// Display general information
-	fmt.Println(formatter.FormatSection("\nGeneral"))
+	fmt.Fprintln(m.out(), formatter.FormatSection("\n"+i18n.T("General")))
 	formatLine("Version", Version)
 	formatLine("Max Capture Lines", m.Config.MaxCaptureLines)
 	formatLine("Wait Interval", m.Config.WaitInterval)
 
 	// Display context information section
-	fmt.Println(formatter.FormatSection("\nContext"))
+	fmt.Fprintln(m.out(), formatter.FormatSection("\n"+i18n.T("Context")))
 	formatLine("Messages", len(m.Messages))
 	var totalTokens int
 	for _, msg := range m.Messages {
@@ -164,72 +375,90 @@ func (m *Manager) formatInfo() {
 	if m.GetMaxContextSize() > 0 {
 		usagePercent = float64(totalTokens) / float64(m.GetMaxContextSize()) * 100
 	}
-	fmt.Print(formatter.LabelColor.Sprintf("%-*s", labelWidth, "Context Size~"))
-	fmt.Print("  ") // Two spaces for separation
-	fmt.Printf("%s\n", fmt.Sprintf("%d tokens", totalTokens))
-	fmt.Printf("%-*s  %s\n", labelWidth, "", formatter.FormatProgressBar(usagePercent, 10))
-	formatLine("Max Size", fmt.Sprintf("%d tokens", m.GetMaxContextSize()))
+	formatLine("Context Size~", i18n.T("%d tokens", totalTokens))
+	fmt.Fprintf(m.out(), "%-*s  %s\n", labelWidth, "", formatter.FormatProgressBar(usagePercent, 10))
+	formatLine("Max Size", i18n.T("%d tokens", m.GetMaxContextSize()))
+
+	// Display token usage of the session
+	fmt.Fprintln(m.out(), formatter.FormatSection("\n"+i18n.T("Usage")))
+	m.stats.mu.Lock()
+	cost, priced := m.sessionCost()
+	formatLine("Tokens In", m.stats.inputTokens)
+	formatLine("Tokens Out", m.stats.outputTokens)
+	m.stats.mu.Unlock()
+	formatLine("Cost~", formatCost(cost, priced))
 
 	// Display tmux panes section
-	fmt.Println()
-	fmt.Println(formatter.FormatSection("Tmux Window Panes"))
+	fmt.Fprintln(m.out())
+	fmt.Fprintln(m.out(), formatter.FormatSection(i18n.T("Tmux Window Panes")))
 
 	panes, _ := m.GetTmuxPanes()
 	for _, pane := range panes {
 		pane.Refresh(m.GetMaxCaptureLines())
-		fmt.Println(pane.FormatInfo(formatter))
+		fmt.Fprintln(m.out(), pane.FormatInfo(formatter))
 	}
 }
 
 // handleConfigCommand processes /config subcommands
 func handleConfigCommand(m *Manager, args []string) {
 	if len(args) == 0 {
-		m.Println("Usage: /config <get|set> [key] [value]")
+		m.Println(i18n.T("Usage: /config <get|set|profile|validate> [key] [value]"))
 		return
 	}
 
-	subcommand := args[0]
+	subcommand := strings.ToLower(args[0])
 	switch subcommand {
 	case "get":
 		if len(args) == 1 {
 			// Show all config
-			m.Println("Current configuration:")
+			m.Println(i18n.T("Current configuration:"))
 			m.Println(m.FormatConfig())
 		} else if len(args) == 2 {
 			// Show specific config key
-			key := args[1]
+			key := strings.ToLower(args[1])
 			if !isAllowedConfigKey(key) {
-				m.Println(fmt.Sprintf("Config key '%s' is not allowed to be modified. Allowed keys: %s", key, strings.Join(AllowedConfigKeys, ", ")))
+				m.Println(i18n.T("Config key '%s' is not allowed to be modified. Allowed keys: %s", key, strings.Join(AllowedConfigKeys, ", ")))
 				return
 			}
 			value := getConfigValue(m, key)
 			m.Println(fmt.Sprintf("%s: %v", key, value))
 		} else {
-			m.Println("Usage: /config get [key]")
+			m.Println(i18n.T("Usage: /config get [key]"))
 		}
 
 	case "set":
-		if len(args) != 3 {
-			m.Println("Usage: /config set <key> <value>")
+		if len(args) < 3 {
+			m.Println(i18n.T("Usage: /config set <key> <value>"))
 			return
 		}
-		key := args[1]
-		value := args[2]
+		key := strings.ToLower(args[1])
+		// values with spaces may be quoted: /config set prompt_format "{state} » "
+		value := strings.Join(args[2:], " ")
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
 
 		if !isAllowedConfigKey(key) {
-			m.Println(fmt.Sprintf("Config key '%s' is not allowed to be modified. Allowed keys: %s", key, strings.Join(AllowedConfigKeys, ", ")))
+			m.Println(i18n.T("Config key '%s' is not allowed to be modified. Allowed keys: %s", key, strings.Join(AllowedConfigKeys, ", ")))
 			return
 		}
 
 		if err := setConfigValue(m, key, value); err != nil {
-			m.Println(fmt.Sprintf("Error setting config: %v", err))
+			m.Println(i18n.T("Error setting config: %v", err))
 			return
 		}
 
-		m.Println(fmt.Sprintf("Set %s = %s", key, value))
+		m.Println(i18n.T("Set %s = %s", key, value))
+
+	case "validate":
+		handleConfigValidateCommand(m)
+
+	case "profile":
+		// profile names are lowercased by the config loader anyway
+		handleProfileCommand(m, args[1:])
 
 	default:
-		m.Println(fmt.Sprintf("Unknown /config subcommand: %s. Use 'get' or 'set'.", subcommand))
+		m.Println(i18n.T("Unknown /config subcommand: %s. Use 'get', 'set', 'profile' or 'validate'.", subcommand))
 	}
 }
 
@@ -246,7 +475,7 @@ func isAllowedConfigKey(key string) bool {
 // getConfigValue gets the current value of a config key
 func getConfigValue(m *Manager, key string) interface{} {
 	// Check session overrides first
-	if override, exists := m.SessionOverrides[key]; exists {
+	if override, exists := m.sessionOverride(key); exists {
 		return override
 	}
 
@@ -264,8 +493,41 @@ func getConfigValue(m *Manager, key string) interface{} {
 		return m.Config.PasteMultilineConfirm
 	case "exec_confirm":
 		return m.Config.ExecConfirm
+	case "teach_mode":
+		return m.Config.TeachMode
 	case "openrouter.model":
-		return m.Config.OpenRouter.Model
+		return m.Config.Endpoint().Model
+	case "context.project_tree":
+		return m.Config.Context.ProjectTree
+	case "context.project_tree_depth":
+		return m.Config.Context.ProjectTreeDepth
+	case "context.git":
+		return m.Config.Context.Git
+	case "context.git_commits":
+		return m.Config.Context.GitCommits
+	case "context.git_staged_diff":
+		return m.Config.Context.GitStagedDiff
+	case "context.tasks":
+		return m.Config.Context.Tasks
+	case "context.exec_history":
+		return m.Config.Context.ExecHistory
+	case "highlight.enabled":
+		return m.Config.Highlight.Enabled
+	case "highlight.theme":
+		return m.Config.Highlight.Theme
+	case "theme.preset":
+		return m.Config.Theme.Preset
+	case "prompt_format":
+		return m.Config.PromptFormat
+	case "ascii":
+		return m.Config.ASCII
+	case "language":
+		return m.Config.Language
+	case "log_level":
+		return m.Config.LogLevel
+	case "telemetry":
+		consent, _ := loadTelemetryConsent(telemetryPath())
+		return consent.Enabled && !telemetryForcedOff()
 	default:
 		return nil
 	}
@@ -273,26 +535,54 @@ func getConfigValue(m *Manager, key string) interface{} {
 
 // setConfigValue sets a config value as a session override
 func setConfigValue(m *Manager, key, value string) error {
-	if m.SessionOverrides == nil {
-		m.SessionOverrides = make(map[string]interface{})
-	}
-
 	// Parse value based on the expected type
 	switch key {
-	case "max_capture_lines", "max_context_size", "wait_interval":
+	case "max_capture_lines", "max_context_size", "wait_interval", "context.project_tree_depth", "context.git_commits":
 		var intVal int
 		if _, err := fmt.Sscanf(value, "%d", &intVal); err != nil {
 			return fmt.Errorf("invalid integer value: %s", value)
 		}
-		m.SessionOverrides[key] = intVal
-	case "send_keys_confirm", "paste_multiline_confirm", "exec_confirm":
+		m.setSessionOverride(key, intVal)
+	case "send_keys_confirm", "paste_multiline_confirm", "exec_confirm", "teach_mode", "context.project_tree", "context.git", "context.git_staged_diff", "context.tasks", "context.exec_history", "highlight.enabled":
 		var boolVal bool
 		if _, err := fmt.Sscanf(value, "%t", &boolVal); err != nil {
 			return fmt.Errorf("invalid boolean value: %s (use true or false)", value)
 		}
-		m.SessionOverrides[key] = boolVal
-	case "openrouter.model":
-		m.SessionOverrides[key] = value
+		m.setSessionOverride(key, boolVal)
+	case "openrouter.model", "prompt_format":
+		m.setSessionOverride(key, value)
+	case "highlight.theme":
+		if !system.IsHighlightTheme(value) {
+			return fmt.Errorf("unknown theme: %s (available: %s)", value, strings.Join(system.HighlightThemes(), ", "))
+		}
+		m.setSessionOverride(key, value)
+	case "theme.preset":
+		if !slices.Contains(system.ThemePresets(), value) {
+			return fmt.Errorf("unknown theme preset: %s (available: %s)", value, strings.Join(system.ThemePresets(), ", "))
+		}
+		m.setSessionOverride(key, value)
+		m.applyTheme(value)
+	case "ascii":
+		var boolVal bool
+		if _, err := fmt.Sscanf(value, "%t", &boolVal); err != nil {
+			return fmt.Errorf("invalid boolean value: %s (use true or false)", value)
+		}
+		m.setSessionOverride(key, boolVal)
+		system.SetASCIIOnly(boolVal)
+	case "language":
+		if err := i18n.SetLanguage(value); err != nil {
+			return err
+		}
+		m.setSessionOverride(key, value)
+	case "log_level":
+		level, err := logger.ParseLevel(value)
+		if err != nil {
+			return err
+		}
+		logger.SetLevel(level)
+		m.setSessionOverride(key, level.String())
+	case "telemetry":
+		return m.setTelemetryConsent(value)
 	default:
 		return fmt.Errorf("unknown config key: %s", key)
 	}
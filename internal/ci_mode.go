@@ -0,0 +1,285 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/spf13/viper"
+)
+
+// CI run statuses
+const (
+	CIStatusSuccess         = "success"
+	CIStatusFailed          = "failed"
+	CIStatusPolicyViolation = "policy_violation"
+	CIStatusTimeout         = "timeout"
+)
+
+// CIPolicy decides confirmations in CI mode, where nobody is there to answer them
+type CIPolicy struct {
+	Allow          []string `mapstructure:"allow"`           // regexes of commands approved automatically
+	Deny           []string `mapstructure:"deny"`            // regexes of commands always refused, checked first
+	UseWhitelist   bool     `mapstructure:"use_whitelist"`   // also approve commands matching whitelist_patterns
+	SendKeys       bool     `mapstructure:"send_keys"`       // allow sending keys
+	PasteMultiline bool     `mapstructure:"paste_multiline"` // allow pasting multiline content
+	WriteFiles     bool     `mapstructure:"write_files"`     // allow writing files proposed with WriteFile
+	ReadFiles      bool     `mapstructure:"read_files"`      // allow reading files into the context with ReadFile
+	FetchURLs      bool     `mapstructure:"fetch_urls"`      // allow downloading web pages with FetchUrl
+	PluginTools    bool     `mapstructure:"plugin_tools"`    // allow running the executables of the tools dir
+	SubAgents      bool     `mapstructure:"sub_agents"`      // allow starting sub-agents with DelegateTask
+	Timeout        int      `mapstructure:"timeout"`         // seconds for the whole run
+
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// CIDecision records how the policy resolved one confirmation
+type CIDecision struct {
+	Prompt   string `json:"prompt"`
+	Content  string `json:"content"`
+	Approved bool   `json:"approved"`
+	Rule     string `json:"rule"`
+}
+
+// CICommand is a command executed during the run
+type CICommand struct {
+	Command  string `json:"command"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Output   string `json:"output,omitempty"`
+}
+
+// CIReport is the machine-readable artifact written at the end of a CI run
+type CIReport struct {
+	Task         string       `json:"task"`
+	Model        string       `json:"model"`
+	Policy       string       `json:"policy"`
+	StartedAt    time.Time    `json:"started_at"`
+	FinishedAt   time.Time    `json:"finished_at"`
+	Status       string       `json:"status"`
+	Error        string       `json:"error,omitempty"`
+	Decisions    []CIDecision `json:"decisions"`
+	Commands     []CICommand  `json:"commands"`
+	FinalMessage string       `json:"final_message,omitempty"`
+
+	mu sync.Mutex
+}
+
+// LoadCIPolicy reads a policy file (yaml, json or toml)
+func LoadCIPolicy(path string) (*CIPolicy, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read policy %s: %w", path, err)
+	}
+	policy := &CIPolicy{Timeout: 600}
+	if err := v.Unmarshal(policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	for _, pattern := range policy.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allow pattern '%s': %w", pattern, err)
+		}
+		policy.allow = append(policy.allow, re)
+	}
+	for _, pattern := range policy.Deny {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deny pattern '%s': %w", pattern, err)
+		}
+		policy.deny = append(policy.deny, re)
+	}
+	return policy, nil
+}
+
+// decide resolves a confirmation; anything the policy doesn't allow is refused
+func (p *CIPolicy) decide(m *Manager, content, prompt string) (bool, string) {
+	switch prompt {
+	case confirmExecPrompt:
+		for _, re := range p.deny {
+			if re.MatchString(content) {
+				return false, "deny: " + re.String()
+			}
+		}
+		for _, re := range p.allow {
+			if re.MatchString(content) {
+				return true, "allow: " + re.String()
+			}
+		}
+		if p.UseWhitelist {
+			if action, rule := m.rules().decide(ruleTargetExec, content); action == ruleAuto {
+				return true, rule.String()
+			}
+		}
+		return false, "no matching allow rule"
+	case confirmKeyPrompt, confirmKeysPrompt:
+		return p.SendKeys, "send_keys"
+	case confirmPastePrompt:
+		return p.PasteMultiline, "paste_multiline"
+	case confirmWritePrompt:
+		return p.WriteFiles, "write_files"
+	case confirmReadPrompt:
+		return p.ReadFiles, "read_files"
+	case confirmFetchPrompt:
+		return p.FetchURLs, "fetch_urls"
+	case confirmPluginPrompt:
+		return p.PluginTools, "plugin_tools"
+	case confirmDelegatePrompt:
+		return p.SubAgents, "sub_agents"
+	default:
+		return false, "unsupported confirmation"
+	}
+}
+
+// record collects executed commands and the final answer from emitted events
+func (r *CIReport) record(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch event.Type {
+	case EventExec:
+		command, _ := event.Data["command"].(string)
+		r.Commands = append(r.Commands, CICommand{Command: command})
+	case EventExecOutput:
+		if len(r.Commands) == 0 {
+			return
+		}
+		last := &r.Commands[len(r.Commands)-1]
+		if code, ok := event.Data["code"].(int); ok {
+			last.ExitCode = &code
+		}
+		last.Output, _ = event.Data["output"].(string)
+	case EventAIResponse:
+		if message, _ := event.Data["message"].(string); message != "" {
+			r.FinalMessage = message
+		}
+	}
+}
+
+func (r *CIReport) violated() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.Decisions {
+		if !d.Approved {
+			return true
+		}
+	}
+	return false
+}
+
+// RunCIMode runs a task non-interactively in a detached tmux session, resolving every
+// confirmation with the policy, and writes a JSON report to reportPath.
+// It returns true when the task was accomplished without policy violations.
+func RunCIMode(cfg *config.Config, task, policyPath, reportPath string) (bool, error) {
+	provider, err := NewChatProvider(cfg)
+	if err != nil {
+		return false, err
+	}
+	policy, err := LoadCIPolicy(policyPath)
+	if err != nil {
+		return false, err
+	}
+
+	paneId, err := system.TmuxCreateSession()
+	if err != nil {
+		return false, fmt.Errorf("failed to create tmux session: %w", err)
+	}
+	defer system.TmuxKillSession(paneId)
+
+	execPaneId, err := system.TmuxCreateNewPane(paneId)
+	if err != nil {
+		return false, fmt.Errorf("failed to create exec pane: %w", err)
+	}
+	panes, err := system.TmuxPanesDetails(execPaneId)
+	if err != nil || len(panes) == 0 {
+		return false, fmt.Errorf("failed to read exec pane %s: %v", execPaneId, err)
+	}
+
+	m := NewManagerForPane(cfg, paneId, provider)
+	m.ExecPane = &panes[0]
+	m.PrepareExecPane()
+
+	report := newCIReport(m, task, policyPath)
+	runWithPolicy(m, task, policy, report)
+	if err := writeCIReport(report, reportPath); err != nil {
+		return false, err
+	}
+	return report.Status == CIStatusSuccess, nil
+}
+
+func newCIReport(m *Manager, task, policyPath string) *CIReport {
+	return &CIReport{
+		Task:      task,
+		Model:     m.GetOpenRouterModel(),
+		Policy:    policyPath,
+		StartedAt: time.Now(),
+		Decisions: []CIDecision{},
+		Commands:  []CICommand{},
+	}
+}
+
+// runWithPolicy runs a task to the end with nobody to ask: the policy resolves every
+// confirmation and bounds the run time. The report collects what happened and its status.
+func runWithPolicy(m *Manager, task string, policy *CIPolicy, report *CIReport) {
+	defer AddEventListener(report.record)()
+	m.ConfirmFunc = func(content, prompt string) (bool, string) {
+		approved, rule := policy.decide(m, content, prompt)
+		logger.Info("CI policy: %s %q approved=%t (%s)", prompt, content, approved, rule)
+		verdict := "refused"
+		if approved {
+			verdict = "approved"
+		}
+		m.Println(i18n.T("Policy %s: %s (%s)", verdict, content, rule))
+		report.mu.Lock()
+		report.Decisions = append(report.Decisions, CIDecision{Prompt: prompt, Content: content, Approved: approved, Rule: rule})
+		report.mu.Unlock()
+		return approved, content
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(policy.Timeout)*time.Second)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		m.SetStatus("")
+	}()
+
+	m.SetStatus("running")
+	accomplished := m.ProcessUserMessage(ctx, task)
+	m.SetStatus("")
+
+	switch {
+	case report.violated():
+		report.Status = CIStatusPolicyViolation
+		report.Error = "a confirmation was refused by the policy"
+	case ctx.Err() == context.DeadlineExceeded:
+		report.Status = CIStatusTimeout
+		report.Error = fmt.Sprintf("run exceeded %ds", policy.Timeout)
+	case accomplished:
+		report.Status = CIStatusSuccess
+	default:
+		report.Status = CIStatusFailed
+		report.Error = "the task was not reported as accomplished"
+	}
+	report.FinishedAt = time.Now()
+}
+
+func writeCIReport(report *CIReport, path string) error {
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
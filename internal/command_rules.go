@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// Actions of command_rules
+const (
+	ruleAuto    = "auto"    // run without asking
+	ruleConfirm = "confirm" // always ask, even with confirmations off or approved with always
+	ruleBlock   = "block"   // never run, the AI is told why
+	ruleNoAuto  = "no-auto" // blacklist_patterns: not auto approved by the whitelist
+)
+
+// Targets of command_rules
+const (
+	ruleTargetExec  = "exec"
+	ruleTargetKeys  = "keys"
+	ruleTargetAll   = "all"
+	ruleTargetPaste = "paste" // multiline pastes, only rules for all apply
+)
+
+const rulesUsage = "Usage: /rules [test <command>|test-keys <keys>]"
+
+// commandRule is a compiled rule of command_rules, whitelist_patterns or blacklist_patterns
+type commandRule struct {
+	re     *regexp.Regexp
+	action string
+	target string
+	source string // the config key of the rule
+}
+
+// commandRules is the rule engine for proposed commands and keys. command_rules are
+// checked first and the first match wins; otherwise whitelisted commands that no
+// blacklist pattern matches are auto approved.
+type commandRules struct {
+	rules     []commandRule // command_rules in order
+	whitelist []commandRule
+	blacklist []commandRule
+	errs      []error // patterns that didn't compile, skipped
+}
+
+// compileCommandRules compiles the patterns of a config; invalid rules are skipped and
+// reported in errs
+func compileCommandRules(cfg *config.Config) *commandRules {
+	rs := &commandRules{}
+	for i, rule := range cfg.CommandRules {
+		action := strings.ToLower(rule.Action)
+		if action != ruleAuto && action != ruleConfirm && action != ruleBlock {
+			rs.errs = append(rs.errs, fmt.Errorf("command_rules[%d]: unknown action %q", i, rule.Action))
+			continue
+		}
+		target := strings.ToLower(rule.Target)
+		if target == "" {
+			target = ruleTargetExec
+		}
+		if target != ruleTargetExec && target != ruleTargetKeys && target != ruleTargetAll {
+			rs.errs = append(rs.errs, fmt.Errorf("command_rules[%d]: unknown target %q", i, rule.Target))
+			continue
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil || rule.Pattern == "" {
+			rs.errs = append(rs.errs, fmt.Errorf("command_rules[%d]: invalid pattern '%s': %v", i, rule.Pattern, err))
+			continue
+		}
+		rs.rules = append(rs.rules, commandRule{re: re, action: action, target: target, source: "command_rules"})
+	}
+	compile := func(patterns []string, action, source string) []commandRule {
+		var rules []commandRule
+		for _, pattern := range patterns {
+			if pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				rs.errs = append(rs.errs, fmt.Errorf("%s: invalid pattern '%s': %w", source, pattern, err))
+				continue
+			}
+			rules = append(rules, commandRule{re: re, action: action, target: ruleTargetAll, source: source})
+		}
+		return rules
+	}
+	rs.whitelist = compile(cfg.WhitelistPatterns, ruleAuto, "whitelist_patterns")
+	rs.blacklist = compile(cfg.BlacklistPatterns, ruleNoAuto, "blacklist_patterns")
+	return rs
+}
+
+// applies reports whether a rule is for the target
+func (r commandRule) applies(target string) bool {
+	return r.target == ruleTargetAll || r.target == target
+}
+
+// match returns the action for a command or key and the rule that decided it;
+// "" with a nil rule when nothing matches and the usual confirmation applies
+func (rs *commandRules) match(target, content string) (string, *commandRule) {
+	for i, rule := range rs.rules {
+		if rule.applies(target) && rule.re.MatchString(content) {
+			return rule.action, &rs.rules[i]
+		}
+	}
+	var whitelisted *commandRule
+	for i, rule := range rs.whitelist {
+		if rule.applies(target) && rule.re.MatchString(content) {
+			whitelisted = &rs.whitelist[i]
+			break
+		}
+	}
+	if whitelisted == nil {
+		return "", nil
+	}
+	for i, rule := range rs.blacklist {
+		if rule.applies(target) && rule.re.MatchString(content) {
+			return ruleNoAuto, &rs.blacklist[i]
+		}
+	}
+	return ruleAuto, whitelisted
+}
+
+// decide combines the actions for several keys sent together: any block blocks them
+// all, any confirm asks, and they are auto approved only when each one is
+func (rs *commandRules) decide(target string, contents ...string) (string, *commandRule) {
+	action, decidedBy := "", (*commandRule)(nil)
+	auto := len(contents) > 0
+	for _, content := range contents {
+		a, rule := rs.match(target, content)
+		switch {
+		case a == ruleBlock:
+			return a, rule
+		case a == ruleConfirm && action != ruleConfirm:
+			action, decidedBy = a, rule
+		}
+		if a != ruleAuto {
+			auto = false
+		} else if decidedBy == nil {
+			decidedBy = rule
+		}
+	}
+	if action == ruleConfirm {
+		return action, decidedBy
+	}
+	if auto {
+		return ruleAuto, decidedBy
+	}
+	return "", nil
+}
+
+// String describes a rule for messages and /rules
+func (r *commandRule) String() string {
+	return fmt.Sprintf("%s %s", r.source, r.re.String())
+}
+
+// rules returns the compiled rule engine, compiling the config on first use
+func (m *Manager) rules() *commandRules {
+	if m.commandRules == nil {
+		m.reloadRules()
+	}
+	return m.commandRules
+}
+
+// reloadRules compiles the rules of the current config, logging invalid patterns
+func (m *Manager) reloadRules() {
+	m.commandRules = compileCommandRules(m.Config)
+	for _, err := range m.commandRules.errs {
+		logger.Error("Skipping command rule: %v", err)
+	}
+}
+
+// decideRules returns the rules' action for a command or keys; while alwaysConfirm is on
+// everything not blocked asks
+func (m *Manager) decideRules(target string, contents ...string) (string, *commandRule) {
+	action, rule := m.rules().decide(target, contents...)
+	if m.alwaysConfirm && action != ruleBlock {
+		return ruleConfirm, rule
+	}
+	return action, rule
+}
+
+// confirmRequired reports whether an action the rules didn't block has to be confirmed:
+// always when a rule asks, ConfirmFunc decides instead of a person or alwaysConfirm is on,
+// otherwise unless an auto rule approves it or its *_confirm setting is off
+func (m *Manager) confirmRequired(action string, setting bool) bool {
+	if action == ruleConfirm || m.ConfirmFunc != nil || m.alwaysConfirm {
+		return true
+	}
+	return action != ruleAuto && setting
+}
+
+// ruleTarget returns the rule target of a confirmation prompt, "" for the actions rules
+// don't apply to
+func ruleTarget(prompt string) string {
+	switch prompt {
+	case confirmExecPrompt:
+		return ruleTargetExec
+	case confirmKeyPrompt, confirmKeysPrompt:
+		return ruleTargetKeys
+	case confirmPastePrompt:
+		return ruleTargetPaste
+	default:
+		return ""
+	}
+}
+
+// ruleAction returns the action of the rules for the content of a confirmation; while
+// alwaysConfirm is on the actions rules don't apply to ask too, even when approved before
+func (m *Manager) ruleAction(prompt, content string) (string, *commandRule) {
+	switch target := ruleTarget(prompt); target {
+	case ruleTargetExec, ruleTargetPaste:
+		return m.decideRules(target, content)
+	case ruleTargetKeys:
+		return m.decideRules(target, strings.Split(content, "\n")...)
+	default:
+		if m.alwaysConfirm {
+			return ruleConfirm, nil
+		}
+		return "", nil
+	}
+}
+
+// blockedByRule tells the user and the AI that a rule blocked an action
+func (m *Manager) blockedByRule(prompt, content string, rule *commandRule) {
+	emitConfirmation(prompt, content, false)
+	m.tellModel(fmt.Sprintf("Blocked by the rule %s, not run: %s", rule, content))
+}
+
+// handleRulesCommand lists the command rules in the order they are checked, or shows
+// what they decide for a command or keys
+func handleRulesCommand(m *Manager, args []string) {
+	rs := m.rules()
+	if len(args) == 0 {
+		if len(rs.rules)+len(rs.whitelist)+len(rs.blacklist) == 0 {
+			m.Println(i18n.T("No command rules configured"))
+		}
+		groups := []struct {
+			title string
+			rules []commandRule
+		}{
+			{i18n.T("command_rules (first match wins):"), rs.rules},
+			{i18n.T("blacklist_patterns (never auto approved):"), rs.blacklist},
+			{i18n.T("whitelist_patterns (auto approved):"), rs.whitelist},
+		}
+		for _, group := range groups {
+			if len(group.rules) == 0 {
+				continue
+			}
+			m.Println(group.title)
+			for _, rule := range group.rules {
+				m.Println(fmt.Sprintf("  %-8s %-5s %s", rule.action, rule.target, rule.re.String()))
+			}
+		}
+		for _, err := range rs.errs {
+			m.Println(i18n.T("Invalid rule: %v", err))
+		}
+		return
+	}
+
+	if len(args) < 2 || (args[0] != "test" && args[0] != "test-keys") {
+		m.Println(i18n.T(rulesUsage))
+		return
+	}
+	content := strings.Join(args[1:], " ")
+	target := ruleTargetExec
+	if args[0] == "test-keys" {
+		target = ruleTargetKeys
+	}
+	action, rule := rs.decide(target, content)
+	switch action {
+	case ruleBlock:
+		m.Println(i18n.T("Blocked by %s", rule))
+	case ruleConfirm:
+		m.Println(i18n.T("Always confirmed, by %s", rule))
+	case ruleAuto:
+		m.Println(i18n.T("Auto approved by %s", rule))
+	default:
+		if _, rule := rs.match(target, content); rule != nil {
+			m.Println(i18n.T("Not auto approved, by %s; asked when confirmations are on", rule))
+		} else {
+			m.Println(i18n.T("No rule matches; asked when confirmations are on"))
+		}
+	}
+}
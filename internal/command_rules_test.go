@@ -0,0 +1,97 @@
+// Unit tests for the command rule engine in command_rules.go
+package internal
+
+import (
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+func rulesConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.CommandRules = []config.CommandRule{
+		{Pattern: `^git\s+push\s+.*--force`, Action: "block"},
+		{Pattern: `^git\s+push`, Action: "confirm"},
+		{Pattern: `^make\s+test$`, Action: "auto"},
+		{Pattern: `^(C-c|q)$`, Action: "auto", Target: "keys"},
+		{Pattern: `^rm`, Action: "block", Target: "all"},
+		{Pattern: `(`, Action: "block"},
+		{Pattern: `^ls`, Action: "allow"},
+	}
+	cfg.WhitelistPatterns = []string{`^git\s`, `^ls`}
+	cfg.BlacklistPatterns = []string{`\|`}
+	return cfg
+}
+
+// Test: invalid patterns and actions are skipped and reported, the rest keep their order
+func TestCompileCommandRules(t *testing.T) {
+	rs := compileCommandRules(rulesConfig())
+	if len(rs.rules) != 5 || len(rs.whitelist) != 2 || len(rs.blacklist) != 1 {
+		t.Fatalf("unexpected rule counts %d/%d/%d", len(rs.rules), len(rs.whitelist), len(rs.blacklist))
+	}
+	if len(rs.errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", rs.errs)
+	}
+}
+
+// Test: command_rules win in order before the whitelist, which the blacklist vetoes
+func TestCommandRulesDecide(t *testing.T) {
+	rs := compileCommandRules(rulesConfig())
+	cases := []struct {
+		target, content, action string
+	}{
+		{ruleTargetExec, "git push --force origin main", ruleBlock},
+		{ruleTargetExec, "git push origin main", ruleConfirm},
+		{ruleTargetExec, "make test", ruleAuto},
+		{ruleTargetExec, "git status", ruleAuto},
+		{ruleTargetExec, "git log | head", ""},
+		{ruleTargetExec, "ls -la", ruleAuto},
+		{ruleTargetExec, "q", ""},
+		{ruleTargetExec, "rm -rf build", ruleBlock},
+		{ruleTargetKeys, "rm -rf build", ruleBlock},
+		{ruleTargetKeys, "q", ruleAuto},
+		{ruleTargetKeys, "make test", ""},
+	}
+	for _, c := range cases {
+		if action, _ := rs.decide(c.target, c.content); action != c.action {
+			t.Errorf("decide(%s, %q) = %q, expected %q", c.target, c.content, action, c.action)
+		}
+	}
+
+	// keys sent together: one block blocks all, all must be auto to skip asking
+	if action, _ := rs.decide(ruleTargetKeys, "q", "rm -rf /"); action != ruleBlock {
+		t.Errorf("expected the keys to be blocked, got %q", action)
+	}
+	if action, _ := rs.decide(ruleTargetKeys, "q", "C-c"); action != ruleAuto {
+		t.Errorf("expected the keys to be auto approved, got %q", action)
+	}
+	if action, _ := rs.decide(ruleTargetKeys, "q", "Enter"); action != "" {
+		t.Errorf("expected the keys to need the usual confirmation, got %q", action)
+	}
+}
+
+// Test: confirmations of commands, keys and pastes get the rules' action, other prompts none
+func TestRuleAction(t *testing.T) {
+	m := &Manager{Config: rulesConfig()}
+	cases := []struct {
+		prompt, content, action string
+	}{
+		{confirmExecPrompt, "git push origin main", ruleConfirm},
+		{confirmKeysPrompt, "q\nC-c", ruleAuto},
+		{confirmKeyPrompt, "q\nrm -rf /", ruleBlock},
+		{confirmPastePrompt, "rm -rf build", ruleBlock},
+		{confirmPastePrompt, "ls -la\nls build", ruleAuto},
+		{confirmPastePrompt, "ls | sh", ""},
+		{confirmWritePrompt, "rm.txt", ""},
+	}
+	for _, c := range cases {
+		if action, _ := m.ruleAction(c.prompt, c.content); action != c.action {
+			t.Errorf("ruleAction(%s, %q) = %q, expected %q", c.prompt, c.content, action, c.action)
+		}
+	}
+
+	// auto approved without asking
+	if ok, command := m.confirmAction("make test", confirmExecPrompt, true, ""); !ok || command != "make test" {
+		t.Errorf("expected make test to be approved, got %t %q", ok, command)
+	}
+}
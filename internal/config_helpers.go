@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/system"
 )
 
 // AllowedConfigKeys defines the list of configuration keys that users are allowed to modify
@@ -14,79 +17,198 @@ var AllowedConfigKeys = []string{
 	"send_keys_confirm",
 	"paste_multiline_confirm",
 	"exec_confirm",
+	"teach_mode",
 	"openrouter.model",
+	"context.project_tree",
+	"context.project_tree_depth",
+	"context.git",
+	"context.git_commits",
+	"context.git_staged_diff",
+	"context.tasks",
+	"context.exec_history",
+	"highlight.enabled",
+	"highlight.theme",
+	"theme.preset",
+	"prompt_format",
+	"ascii",
+	"language",
+	"log_level",
+	"telemetry",
 }
 
 // GetMaxCaptureLines returns the max capture lines value with session override if present
 func (m *Manager) GetMaxCaptureLines() int {
-	if override, exists := m.SessionOverrides["max_capture_lines"]; exists {
+	if override, exists := m.sessionOverride("max_capture_lines"); exists {
 		if val, ok := override.(int); ok {
 			return val
 		}
 	}
-	return m.Config.MaxCaptureLines
+	return m.GetConfig().MaxCaptureLines
 }
 
 // GetMaxContextSize returns the max context size value with session override if present
 func (m *Manager) GetMaxContextSize() int {
-	if override, exists := m.SessionOverrides["max_context_size"]; exists {
+	if override, exists := m.sessionOverride("max_context_size"); exists {
 		if val, ok := override.(int); ok {
 			return val
 		}
 	}
-	return m.Config.MaxContextSize
+	return m.GetConfig().MaxContextSize
 }
 
 // GetWaitInterval returns the wait interval value with session override if present
 func (m *Manager) GetWaitInterval() int {
-	if override, exists := m.SessionOverrides["wait_interval"]; exists {
+	if override, exists := m.sessionOverride("wait_interval"); exists {
 		if val, ok := override.(int); ok {
 			return val
 		}
 	}
-	return m.Config.WaitInterval
+	return m.GetConfig().WaitInterval
 }
 
 func (m *Manager) GetSendKeysConfirm() bool {
-	if override, exists := m.SessionOverrides["send_keys_confirm"]; exists {
+	if override, exists := m.sessionOverride("send_keys_confirm"); exists {
 		if val, ok := override.(bool); ok {
 			return val
 		}
 	}
-	return m.Config.SendKeysConfirm
+	return m.GetConfig().SendKeysConfirm
 }
 
 func (m *Manager) GetPasteMultilineConfirm() bool {
-	if override, exists := m.SessionOverrides["paste_multiline_confirm"]; exists {
+	if override, exists := m.sessionOverride("paste_multiline_confirm"); exists {
 		if val, ok := override.(bool); ok {
 			return val
 		}
 	}
-	return m.Config.PasteMultilineConfirm
+	return m.GetConfig().PasteMultilineConfirm
 }
 
 func (m *Manager) GetExecConfirm() bool {
-	if override, exists := m.SessionOverrides["exec_confirm"]; exists {
+	if override, exists := m.sessionOverride("exec_confirm"); exists {
 		if val, ok := override.(bool); ok {
 			return val
 		}
 	}
-	return m.Config.ExecConfirm
+	return m.GetConfig().ExecConfirm
+}
+
+// GetTeachMode returns whether commands are explained, with session override if present
+func (m *Manager) GetTeachMode() bool {
+	if override, exists := m.sessionOverride("teach_mode"); exists {
+		if val, ok := override.(bool); ok {
+			return val
+		}
+	}
+	return m.GetConfig().TeachMode
 }
 
 func (m *Manager) GetOpenRouterModel() string {
-	if override, exists := m.SessionOverrides["openrouter.model"]; exists {
+	if override, exists := m.sessionOverride("openrouter.model"); exists {
+		if val, ok := override.(string); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Endpoint().Model
+}
+
+func (m *Manager) GetPromptFormat() string {
+	if override, exists := m.sessionOverride("prompt_format"); exists {
 		if val, ok := override.(string); ok {
 			return val
 		}
 	}
-	return m.Config.OpenRouter.Model
+	return m.GetConfig().PromptFormat
+}
+
+func (m *Manager) GetProjectTree() bool {
+	if override, exists := m.sessionOverride("context.project_tree"); exists {
+		if val, ok := override.(bool); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Context.ProjectTree
+}
+
+func (m *Manager) GetProjectTreeDepth() int {
+	if override, exists := m.sessionOverride("context.project_tree_depth"); exists {
+		if val, ok := override.(int); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Context.ProjectTreeDepth
+}
+
+func (m *Manager) GetGitContext() bool {
+	if override, exists := m.sessionOverride("context.git"); exists {
+		if val, ok := override.(bool); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Context.Git
+}
+
+func (m *Manager) GetGitCommits() int {
+	if override, exists := m.sessionOverride("context.git_commits"); exists {
+		if val, ok := override.(int); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Context.GitCommits
+}
+
+func (m *Manager) GetGitStagedDiff() bool {
+	if override, exists := m.sessionOverride("context.git_staged_diff"); exists {
+		if val, ok := override.(bool); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Context.GitStagedDiff
+}
+
+func (m *Manager) GetExecHistoryContext() bool {
+	if override, exists := m.sessionOverride("context.exec_history"); exists {
+		if val, ok := override.(bool); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Context.ExecHistory
+}
+
+func (m *Manager) GetTaskContext() bool {
+	if override, exists := m.sessionOverride("context.tasks"); exists {
+		if val, ok := override.(bool); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Context.Tasks
+}
+
+func (m *Manager) GetHighlightEnabled() bool {
+	if override, exists := m.sessionOverride("highlight.enabled"); exists {
+		if val, ok := override.(bool); ok {
+			return val
+		}
+	}
+	return m.GetConfig().Highlight.Enabled
+}
+
+func (m *Manager) GetHighlightTheme() string {
+	if override, exists := m.sessionOverride("highlight.theme"); exists {
+		if val, ok := override.(string); ok {
+			return val
+		}
+	}
+	if m.GetConfig().Highlight.Theme != "" {
+		return m.GetConfig().Highlight.Theme
+	}
+	return system.CurrentTheme().CodeTheme
 }
 
 // FormatConfig returns a nicely formatted string of all config values with session overrides applied
 func (m *Manager) FormatConfig() string {
 	var result strings.Builder
-	formatConfigValue(&result, "", reflect.ValueOf(m.Config).Elem(), m.SessionOverrides, 1)
+	formatConfigValue(&result, "", reflect.ValueOf(m.Config).Elem(), m.sessionOverridesSnapshot(), 1)
 	return result.String()
 }
 
@@ -122,6 +244,13 @@ func formatConfigValue(sb *strings.Builder, prefix string, val reflect.Value, ov
 			continue
 		}
 
+		// profiles may hold API keys, only their names are shown
+		if profiles, ok := field.Interface().(map[string]map[string]interface{}); ok {
+			names := (&config.Config{Profiles: profiles}).ProfileNames()
+			sb.WriteString(fmt.Sprintf("%s%s: %s\n", indentStr, tag, strings.Join(names, ", ")))
+			continue
+		}
+
 		// Format the field value
 		var valueStr string
 		switch field.Kind() {
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/fsnotify/fsnotify"
+)
+
+// configReloadDelay lets an editor finish writing before the file is read; saves often
+// arrive as several events
+const configReloadDelay = 300 * time.Millisecond
+
+// ConfigWatcher reloads the config when its file changes
+type ConfigWatcher struct {
+	manager *Manager
+	path    string
+	watcher *fsnotify.Watcher
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// StartConfigWatcher watches the config file and applies its changes to the session.
+// The directory is watched, since editors often save by replacing the file.
+func StartConfigWatcher(m *Manager) (*ConfigWatcher, error) {
+	path := config.FileUsed()
+	if path == "" {
+		path = config.GetConfigFilePath("config.yaml")
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	w := &ConfigWatcher{manager: m, path: filepath.Clean(path), watcher: watcher}
+	go w.run()
+	logger.Info("Watching %s for changes", path)
+	return w, nil
+}
+
+func (w *ConfigWatcher) run() {
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.path || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			w.mu.Lock()
+			if w.timer != nil {
+				w.timer.Stop()
+			}
+			w.timer = time.AfterFunc(configReloadDelay, w.manager.reloadConfig)
+			w.mu.Unlock()
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Error("Config watcher: %v", err)
+		}
+	}
+}
+
+// Close stops watching
+func (w *ConfigWatcher) Close() error {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+	return w.watcher.Close()
+}
+
+// reloadConfig loads the changed config file with the session's profile and command-line
+// flags and applies it once the running turn is over. An invalid file keeps the current config.
+func (m *Manager) reloadConfig() {
+	defer m.recoverPanic()
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+
+	cfg, err := config.LoadProfile(m.Config.Profile)
+	if err != nil {
+		logger.Error("Config reload failed: %v", err)
+		m.Println(i18n.T("The config file changed but can't be loaded, keeping the current config: %v", err))
+		return
+	}
+	if m.FlagOverrides != nil {
+		m.FlagOverrides(cfg)
+	}
+	changed := changedConfigKeys(m.Config, cfg)
+	if len(changed) == 0 {
+		return
+	}
+	if err := m.applyConfig(cfg); err != nil {
+		logger.Error("Config reload failed: %v", err)
+		m.Println(i18n.T("The config file changed but can't be applied, keeping the current config: %v", err))
+		return
+	}
+	logger.Info("Config reloaded, changed: %s", strings.Join(changed, ", "))
+	m.Println(i18n.T("Config reloaded, changed: %s", strings.Join(changed, ", ")))
+	if overridden := m.overriddenKeys(changed); len(overridden) > 0 {
+		m.Println(i18n.T("Session overrides still apply to: %s", strings.Join(overridden, ", ")))
+	}
+}
+
+// applyConfig switches the session to a newly loaded config. The provider is created again
+// when its settings changed, the command rules are compiled again and the theme, symbols,
+// language and log level follow the config unless /config set overrides them.
+func (m *Manager) applyConfig(cfg *config.Config) error {
+	old := m.Config
+	if cfg.Provider != old.Provider || cfg.Endpoint() != old.Endpoint() || !reflect.DeepEqual(cfg.Mock, old.Mock) {
+		provider, err := NewChatProvider(cfg)
+		if err != nil {
+			return err
+		}
+		m.setAiClient(provider)
+	}
+	m.setConfig(cfg)
+	m.reloadRules()
+
+	if _, ok := m.sessionOverride("theme.preset"); !ok {
+		m.applyTheme(cfg.Theme.Preset)
+	}
+	if _, ok := m.sessionOverride("ascii"); !ok {
+		system.SetASCIIOnly(cfg.ASCII)
+	}
+	if _, ok := m.sessionOverride("language"); !ok {
+		if err := i18n.SetLanguage(cfg.Language); err != nil {
+			logger.Error("Invalid language: %v", err)
+		}
+	}
+	if _, ok := m.sessionOverride("log_level"); !ok {
+		if level, err := logger.ParseLevel(cfg.LogLevel); err == nil && !cfg.Debug {
+			logger.SetLevel(level)
+		}
+	}
+	return nil
+}
+
+// changedConfigKeys lists the top-level keys whose values differ between two configs
+func changedConfigKeys(old, cfg *config.Config) []string {
+	var keys []string
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(cfg).Elem()
+	for i := 0; i < ov.NumField(); i++ {
+		if reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			continue
+		}
+		tag := ov.Type().Field(i).Tag.Get("mapstructure")
+		if tag == "" {
+			tag = strings.ToLower(ov.Type().Field(i).Name)
+		}
+		keys = append(keys, tag)
+	}
+	return keys
+}
+
+// overriddenKeys returns the session overrides within the changed top-level keys
+func (m *Manager) overriddenKeys(changed []string) []string {
+	var keys []string
+	for key := range m.sessionOverridesSnapshot() {
+		section, _, _ := strings.Cut(key, ".")
+		for _, c := range changed {
+			if c == section {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
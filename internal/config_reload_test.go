@@ -0,0 +1,107 @@
+// Unit tests for reloading the config in config_reload.go
+package internal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: a reloaded config replaces the rules and provider while session overrides stay
+func TestApplyReloadedConfig(t *testing.T) {
+	old := config.DefaultConfig()
+	old.Provider = "mock"
+	m := &Manager{Config: old, SessionOverrides: map[string]interface{}{"openrouter.model": "session-model", "exec_confirm": false}}
+	m.AiClient, _ = NewMockProvider(old.Mock)
+
+	cfg := config.DefaultConfig()
+	cfg.Provider = "mock"
+	cfg.Mock.Responses = []string{"<RequestAccomplished>1</RequestAccomplished>"}
+	cfg.OpenRouter.Model = "file-model"
+	cfg.CommandRules = []config.CommandRule{{Pattern: "^make ", Action: ruleAuto}}
+
+	changed := changedConfigKeys(old, cfg)
+	if !slices.Equal(changed, []string{"command_rules", "openrouter", "mock"}) {
+		t.Errorf("unexpected changed keys: %v", changed)
+	}
+	if got := m.overriddenKeys(changed); !slices.Equal(got, []string{"openrouter.model"}) {
+		t.Errorf("unexpected overridden keys: %v", got)
+	}
+
+	provider := m.AiClient
+	if err := m.applyConfig(cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.AiClient == provider {
+		t.Error("expected the provider to be created again for the new mock responses")
+	}
+	if action, _ := m.ruleAction(confirmExecPrompt, "make test"); action != ruleAuto {
+		t.Errorf("expected the new command rule to apply, got %s", action)
+	}
+	if m.GetOpenRouterModel() != "session-model" {
+		t.Errorf("expected the session override to stay, got %s", m.GetOpenRouterModel())
+	}
+}
+
+// Test: reloads can swap the config and provider while other goroutines read them
+// (meaningful with -race)
+func TestApplyConfigConcurrentReads(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Provider = "mock"
+	m := &Manager{Config: cfg, SessionOverrides: map[string]interface{}{}}
+	m.AiClient, _ = NewMockProvider(cfg.Mock)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = m.GetExecConfirm()
+			_ = m.GetOpenRouterModel()
+			_ = m.aiClient()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		next := config.DefaultConfig()
+		next.Provider = "mock"
+		next.Mock.Latency = i
+		if err := m.applyConfig(next); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
+
+// Test: a reload applies the command-line flags again, so --demo keeps the mock provider
+func TestReloadConfigKeepsFlags(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "tmuxai")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := "provider: openrouter\nexec_confirm: false\nopenrouter:\n  api_key: test-key\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Provider = "mock"
+	m := NewManagerForPane(cfg, "", nil)
+	m.Output = io.Discard
+	m.AiClient, _ = NewMockProvider(cfg.Mock)
+	m.FlagOverrides = func(cfg *config.Config) { cfg.Provider = "mock" }
+
+	m.reloadConfig()
+	if m.GetExecConfirm() {
+		t.Error("expected the reloaded exec_confirm to apply")
+	}
+	if m.Config.Provider != "mock" {
+		t.Errorf("expected the mock provider of the flag to stay, got %q", m.Config.Provider)
+	}
+	if _, ok := m.AiClient.(*MockProvider); !ok {
+		t.Errorf("expected the mock client to stay, got %T", m.AiClient)
+	}
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+)
+
+// ValidateConfig checks the config file without using it: the values, keys that are no
+// settings, the patterns of the command rules, whether there is an API key and whether
+// each MCP server can be reached. configErr is what loading the config returned.
+func ValidateConfig(cfg *config.Config, configErr error) []DoctorCheck {
+	checks := []DoctorCheck{doctorConfig(cfg, configErr), validateKeys(), validateRules(cfg), validateAPIKey(cfg)}
+	return append(checks, doctorMcp(cfg)...)
+}
+
+// validateKeys reports the keys of the config file that are ignored, with the setting
+// each one probably meant
+func validateKeys() DoctorCheck {
+	check := DoctorCheck{Name: "keys"}
+	unknown, err := config.UnknownKeys()
+	if err != nil {
+		check.Status, check.Detail, check.Fix = "fail", err.Error(), "fix the YAML syntax of the config file"
+		return check
+	}
+	if len(unknown) == 0 {
+		check.Status, check.Detail = "ok", "no unknown keys"
+		return check
+	}
+	var problems []string
+	for _, u := range unknown {
+		if u.Suggestion != "" {
+			problems = append(problems, fmt.Sprintf("%s (did you mean %s?)", u.Key, u.Suggestion))
+		} else {
+			problems = append(problems, u.Key)
+		}
+	}
+	check.Status = "fail"
+	check.Detail = "unknown keys, ignored: " + strings.Join(problems, ", ")
+	check.Fix = "rename or remove them, see config.example.yaml for the settings"
+	return check
+}
+
+// validateRules compiles the command rules, whitelist and blacklist
+func validateRules(cfg *config.Config) DoctorCheck {
+	check := DoctorCheck{Name: "rules"}
+	rules := compileCommandRules(cfg)
+	if len(rules.errs) == 0 {
+		check.Status = "ok"
+		check.Detail = fmt.Sprintf("%d command rules, %d whitelist and %d blacklist patterns", len(cfg.CommandRules), len(cfg.WhitelistPatterns), len(cfg.BlacklistPatterns))
+		return check
+	}
+	var problems []string
+	for _, err := range rules.errs {
+		problems = append(problems, err.Error())
+	}
+	check.Status, check.Detail = "fail", strings.Join(problems, "; ")
+	check.Fix = "these rules are skipped; fix the regular expressions and actions"
+	return check
+}
+
+// validateAPIKey checks that the selected provider has a key, without calling the API
+func validateAPIKey(cfg *config.Config) DoctorCheck {
+	check := DoctorCheck{Name: "api key"}
+	section := cfg.Provider
+	if cfg.ProviderSection() == nil {
+		section = "openrouter"
+	}
+	switch {
+	case cfg.Provider == "mock":
+		check.Status, check.Detail = "ok", "not needed by the mock provider"
+	case cfg.Endpoint().APIKey == "":
+		check.Status, check.Detail, check.Fix = "fail", "no API key for "+section, "set "+cfg.APIKeyHint()
+	default:
+		check.Status, check.Detail = "ok", "set, "+maskAPIKey(cfg.Endpoint().APIKey)
+	}
+	return check
+}
+
+// handleConfigValidateCommand validates the config file as it is on disk now
+func handleConfigValidateCommand(m *Manager) {
+	cfg, err := config.LoadProfile(m.Config.Profile)
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	if PrintDoctorReport(os.Stdout, ValidateConfig(cfg, err)) {
+		m.Println(i18n.T("The config is valid"))
+	}
+}
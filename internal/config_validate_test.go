@@ -0,0 +1,68 @@
+// Unit tests for config validation in config_validate.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: unknown keys come with a suggestion and broken rules and a missing key fail
+func TestValidateConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OPENROUTER_API_KEY", "")
+	t.Setenv("TMUXAI_OPENROUTER_API_KEY", "")
+	dir := filepath.Join(home, ".config", "tmuxai")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := `exec_confirms: true
+openrouter:
+  modle: gpt-4o
+theme:
+  colors:
+    prompt: cyan
+command_rules:
+  - pattern: "(unclosed"
+    action: auto
+profiles:
+  work:
+    teach_mod: true
+    exec_confirm: true
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checks := map[string]DoctorCheck{}
+	for _, c := range ValidateConfig(cfg, nil) {
+		checks[c.Name] = c
+	}
+	keys := checks["keys"]
+	for _, want := range []string{
+		"exec_confirms (did you mean exec_confirm?)",
+		"openrouter.modle (did you mean openrouter.model?)",
+		"profiles.work.teach_mod (did you mean teach_mode?)",
+	} {
+		if keys.Status != "fail" || !strings.Contains(keys.Detail, want) {
+			t.Errorf("expected %q in the keys check, got %+v", want, keys)
+		}
+	}
+	if strings.Contains(keys.Detail, "theme.colors") || strings.Contains(keys.Detail, "work.exec_confirm ") {
+		t.Errorf("expected map entries and valid profile keys to pass, got %s", keys.Detail)
+	}
+	if checks["rules"].Status != "fail" || !strings.Contains(checks["rules"].Detail, "command_rules[0]") {
+		t.Errorf("expected the broken rule to fail, got %+v", checks["rules"])
+	}
+	if checks["api key"].Status != "fail" {
+		t.Errorf("expected a missing API key to fail, got %+v", checks["api key"])
+	}
+}
@@ -2,136 +2,306 @@ package internal
 
 import (
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
 	"github.com/chzyer/readline"
-	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// Confirmation prompts, also used by the CI policy to tell the kinds of actions apart
+const (
+	confirmExecPrompt     = "Execute this command?"
+	confirmKeyPrompt      = "Send this key?"
+	confirmKeysPrompt     = "Send all these keys?"
+	confirmPastePrompt    = "Paste multiline content?"
+	confirmWritePrompt    = "Write this file?"
+	confirmReadPrompt     = "Read this file?"
+	confirmToolPrompt     = "Call this MCP tool?"
+	confirmFetchPrompt    = "Fetch this URL?"
+	confirmPluginPrompt   = "Run this tool?"
+	confirmDelegatePrompt = "Start this sub-agent?"
 )
 
 func (m *Manager) confirmedToExec(command string, prompt string, edit bool) (bool, string) {
-	isSafe, _ := m.whitelistCheck(command)
-	if isSafe {
+	return m.confirmAction(command, prompt, edit, "")
+}
+
+// confirmAction asks to approve an action: yes, no, always (approve the same action
+// for the rest of the session), pattern (approve actions matching a regex for the rest
+// of the session), edit (when allowed, in $EDITOR) or view (the full action with the
+// AI's explanation and detail, e.g. the diff of a file change). It returns the
+// possibly edited content.
+func (m *Manager) confirmAction(command, prompt string, edit bool, detail string) (bool, string) {
+	if m.ConfirmFunc != nil {
+		return m.ConfirmFunc(command, prompt)
+	}
+
+	alwaysKey := prompt + "\x00" + command
+	action, _ := m.ruleAction(prompt, command)
+	if action == ruleAuto || (action != ruleConfirm && m.alwaysApproved[alwaysKey]) {
 		return true, command
 	}
+	for _, re := range m.approvedPatterns[prompt] {
+		if action != ruleConfirm && re.MatchString(command) {
+			return true, command
+		}
+	}
+
+	m.alert(AlertConfirm, i18n.T("TmuxAI is waiting for approval"), command)
 
-	promptColor := color.New(color.FgCyan, color.Bold)
+	promptColor := system.CurrentTheme().Confirm
 
 	var promptText string
 	if edit {
-		promptText = fmt.Sprintf("%s [Y]es/No/Edit: ", prompt)
+		promptText = i18n.T(prompt) + " " + i18n.T("[Y]es/No/Always/Pattern/Edit/View: ")
 	} else {
-		promptText = fmt.Sprintf("%s [Y]es/No: ", prompt)
+		promptText = i18n.T(prompt) + " " + i18n.T("[Y]es/No/Always/Pattern/View: ")
 	}
 
-	// Use readline for initial confirmation to properly handle Ctrl+C
-	rlConfig := &readline.Config{
-		Prompt:          promptColor.Sprint(promptText),
-		InterruptPrompt: "^C",
-		EOFPrompt:       "exit",
-	}
-
-	rl, err := readline.NewEx(rlConfig)
-	if err != nil {
-		fmt.Printf("Error initializing readline: %v\n", err)
-		return false, ""
-	}
-	defer rl.Close()
+	for {
+		confirmInput, err := m.readLine(promptColor.Sprint(promptText), "")
+		if err != nil {
+			if err == readline.ErrInterrupt {
+				m.SetStatus("")
+				return false, ""
+			}
 
-	confirmInput, err := rl.Readline()
-	if err != nil {
-		if err == readline.ErrInterrupt {
-			m.Status = ""
+			fmt.Fprintln(m.out(), i18n.T("Error reading confirmation: %v", err))
 			return false, ""
 		}
 
-		fmt.Printf("Error reading confirmation: %v\n", err)
-		return false, ""
-	}
-
-	confirmInput = strings.TrimSpace(strings.ToLower(confirmInput))
-
-	if confirmInput == "" {
-		confirmInput = "y"
-	}
+		confirmInput = strings.TrimSpace(strings.ToLower(confirmInput))
 
-	switch confirmInput {
-	case "y", "yes", "ok", "sure":
-		return true, command
-	case "e", "edit":
-		// Allow user to edit the command using readline for better editing experience
-		editConfig := &readline.Config{
-			Prompt:          "Edit command: ",
-			InterruptPrompt: "^C",
-			EOFPrompt:       "exit",
+		if confirmInput == "" {
+			confirmInput = "y"
 		}
 
-		editRl, editErr := readline.NewEx(editConfig)
-		if editErr != nil {
-			fmt.Printf("Error initializing readline for edit: %v\n", editErr)
-			return false, ""
-		}
-		defer editRl.Close()
+		switch confirmInput {
+		case "y", "yes", "ok", "sure":
+			return true, command
+		case "a", "always":
+			if m.alwaysApproved == nil {
+				m.alwaysApproved = make(map[string]bool)
+			}
+			m.alwaysApproved[alwaysKey] = true
+			m.Println(i18n.T("Approved for the rest of this session"))
+			return true, command
+		case "p", "pattern":
+			re, err := m.readApprovalPattern(command)
+			if err != nil {
+				if err == readline.ErrInterrupt {
+					m.SetStatus("")
+					return false, ""
+				}
+				m.Println(err.Error())
+				continue
+			}
+			if re == nil || !re.MatchString(command) {
+				m.Println(i18n.T("The pattern doesn't match this action"))
+				continue
+			}
+			if m.approvedPatterns == nil {
+				m.approvedPatterns = make(map[string][]*regexp.Regexp)
+			}
+			m.approvedPatterns[prompt] = append(m.approvedPatterns[prompt], re)
+			m.Println(i18n.T("Approving actions matching %s for the rest of this session", re.String()))
+			return true, command
+		case "e", "edit":
+			if !edit {
+				continue
+			}
+			editedCommand, editErr := m.editCommand(command)
+			if editErr != nil {
+				if editErr == readline.ErrInterrupt {
+					m.SetStatus("")
+					return false, ""
+				}
 
-		// Use ReadlineWithDefault to prefill the command
-		editedCommand, editErr := editRl.ReadlineWithDefault(command)
-		if editErr != nil {
-			if editErr == readline.ErrInterrupt {
-				m.Status = ""
+				fmt.Fprintln(m.out(), i18n.T("Error reading edited command: %v", editErr))
 				return false, ""
 			}
 
-			fmt.Printf("Error reading edited command: %v\n", editErr)
+			editedCommand = strings.TrimSpace(editedCommand)
+			if editedCommand != "" {
+				return true, editedCommand
+			} else {
+				// empty command
+				return false, ""
+			}
+		case "v", "view":
+			m.viewConfirmation(command, detail)
+		case "n", "no", "cancel":
 			return false, ""
 		}
+		// any other input asks again
+	}
+}
 
-		editedCommand = strings.TrimSpace(editedCommand)
-		if editedCommand != "" {
-			return true, editedCommand
-		} else {
-			// empty command
-			return false, ""
+// editCommand lets the user change a command before it runs: in $EDITOR on the
+// terminal, or in place on the input line with the TUI or when the editor fails
+func (m *Manager) editCommand(command string) (string, error) {
+	if m.tui == nil && !m.chatIsReading() && term.IsTerminal(int(os.Stdin.Fd())) {
+		edited, err := system.EditInEditor(command, ".sh")
+		if err == nil {
+			if edited != command {
+				fmt.Fprintln(m.out(), m.highlightCode("sh", edited))
+			}
+			return edited, nil
 		}
-	case "n", "no", "cancel":
-		return false, ""
-	default:
-		// any other input is retry confirmation
-		return m.confirmedToExec(command, prompt, edit)
+		m.Println(i18n.T("Editor failed, editing here instead: %v", err))
 	}
+	// Prefill the command so it can be edited in place
+	return m.readLine(i18n.T("Edit command: "), command)
 }
 
-func (m *Manager) whitelistCheck(command string) (bool, error) {
-	isWhitelisted := false
-	for _, pattern := range m.Config.WhitelistPatterns {
-		if pattern == "" {
-			continue
-		}
-		match, err := regexp.MatchString(pattern, command)
-		if err != nil {
-			return false, fmt.Errorf("invalid whitelist regex pattern '%s': %w", pattern, err)
-		}
-		if match {
-			isWhitelisted = true
-			break
-		}
+// suggestApprovalPattern proposes a regex for "pattern" approvals: the program and, when
+// it looks like one, its subcommand, e.g. ^git\s+status(\s|$) for git status -s
+func suggestApprovalPattern(command string) string {
+	fields := strings.Fields(strings.SplitN(command, "\n", 2)[0])
+	if len(fields) == 0 {
+		return ""
 	}
+	pattern := "^" + regexp.QuoteMeta(fields[0])
+	if len(fields) > 1 && subcommandRe.MatchString(fields[1]) {
+		pattern += `\s+` + regexp.QuoteMeta(fields[1])
+	}
+	return pattern + `(\s|$)`
+}
+
+var subcommandRe = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
 
-	if !isWhitelisted {
-		return false, nil
+// readApprovalPattern asks for the regex to approve, prefilled with the suggestion;
+// an empty answer cancels
+func (m *Manager) readApprovalPattern(command string) (*regexp.Regexp, error) {
+	input, err := m.readLine(i18n.T("Approve actions matching: "), suggestApprovalPattern(command))
+	if err != nil {
+		return nil, err
+	}
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, nil
 	}
+	re, err := regexp.Compile(input)
+	if err != nil {
+		return nil, fmt.Errorf("%s", i18n.T("Invalid pattern: %v", err))
+	}
+	return re, nil
+}
 
-	for _, pattern := range m.Config.BlacklistPatterns {
-		if pattern == "" {
-			continue
-		}
-		match, err := regexp.MatchString(pattern, command)
-		if err != nil {
-			return false, fmt.Errorf("invalid blacklist regex pattern '%s': %w", pattern, err)
-		}
-		if match {
-			return false, nil
+// viewConfirmation prints everything behind a confirmation: the AI's explanation,
+// where it runs and the full content
+func (m *Manager) viewConfirmation(command, detail string) {
+	formatter := system.NewInfoFormatter()
+	if m.lastAIMessage != "" {
+		fmt.Fprintln(m.out(), formatter.FormatSection(i18n.T("Explanation")))
+		fmt.Fprintln(m.out(), m.cosmetics(m.lastAIMessage))
+	}
+	if m.ExecPane != nil && m.ExecPane.Id != "" {
+		fmt.Fprintln(m.out())
+		fmt.Fprintln(m.out(), formatter.FormatSection(i18n.T("Target")))
+		fmt.Fprintln(m.out(), formatter.FormatKeyValue(i18n.T("Exec pane"), m.ExecPane.Id)+formatter.FormatKeyValue(i18n.T("Directory"), m.ExecPane.CurrentPath))
+	}
+	fmt.Fprintln(m.out(), formatter.FormatSection(i18n.T("Content")))
+	lines := strings.Split(command, "\n")
+	for i, line := range lines {
+		fmt.Fprintf(m.out(), "%s %s\n", formatter.NeutralColor.Sprintf(system.Sym("%3d│"), i+1), line)
+	}
+	if detail != "" {
+		fmt.Fprintln(m.out())
+		fmt.Fprint(m.out(), detail)
+	}
+	fmt.Fprintln(m.out())
+}
+
+// readLine reads one line of input for a prompt, through the input box when the TUI is running
+// and through the chat when it is waiting for input. Ctrl+C returns readline.ErrInterrupt.
+func (m *Manager) readLine(prompt, prefill string) (string, error) {
+	if m.tui != nil {
+		return m.tui.ask(prompt, prefill)
+	}
+	if p := m.askInChat(prefill); p != nil {
+		fmt.Fprint(m.out(), "\n"+prompt)
+		if prefill != "" {
+			fmt.Fprint(m.out(), i18n.T("(enter keeps %s) ", prefill))
 		}
+		answer := <-p.reply
+		return answer.text, answer.err
 	}
 
-	return true, nil
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:          prompt,
+		InterruptPrompt: "^C",
+		EOFPrompt:       "exit",
+	})
+	if err != nil {
+		return "", fmt.Errorf("initializing readline: %w", err)
+	}
+	defer rl.Close()
+	return rl.ReadlineWithDefault(prefill)
+}
+
+// chatPrompt is a question from a turn that doesn't run in the readline chat, e.g. the
+// confirmation of an API request or of tmuxai exec. The chat is reading the terminal,
+// so its next line is the answer rather than a second reader competing for the keys.
+type chatPrompt struct {
+	prefill string
+	reply   chan chatAnswer
+}
+
+type chatAnswer struct {
+	text string
+	err  error
+}
+
+// askInChat queues a question for the readline chat while it waits for input, nil otherwise
+func (m *Manager) askInChat(prefill string) *chatPrompt {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	if !m.chatReading || m.chatPrompt != nil {
+		return nil
+	}
+	m.chatPrompt = &chatPrompt{prefill: prefill, reply: make(chan chatAnswer, 1)}
+	return m.chatPrompt
+}
+
+// chatIsReading reports whether the readline chat waits for input
+func (m *Manager) chatIsReading() bool {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.chatReading
+}
+
+// setChatReading marks the readline chat as waiting for input
+func (m *Manager) setChatReading() {
+	m.stateMu.Lock()
+	m.chatReading = true
+	m.stateMu.Unlock()
+}
+
+// takeChatPrompt ends the chat's wait for input and returns the question its line
+// answers, if one was asked meanwhile
+func (m *Manager) takeChatPrompt() *chatPrompt {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	p := m.chatPrompt
+	m.chatReading = false
+	m.chatPrompt = nil
+	return p
+}
+
+// answer resolves the question with a line of the chat, an empty line keeps the prefill
+func (p *chatPrompt) answer(line string) {
+	if line == "" {
+		line = p.prefill
+	}
+	p.reply <- chatAnswer{text: line}
+}
+
+// cancel resolves the question like Ctrl+C
+func (p *chatPrompt) cancel() {
+	p.reply <- chatAnswer{err: readline.ErrInterrupt}
 }
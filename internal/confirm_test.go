@@ -0,0 +1,108 @@
+// Unit tests for approval patterns in confirm.go
+package internal
+
+import (
+	"io"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/chzyer/readline"
+)
+
+// Test: the suggested pattern covers the program and its subcommand, not the arguments
+func TestSuggestApprovalPattern(t *testing.T) {
+	cases := []struct {
+		command, matches, rejects string
+	}{
+		{"git status -s", "git status", "git push"},
+		{"ls -la /tmp", "ls", "lsof -i"},
+		{"./build.sh --fast\nmore", "./build.sh", "./build.shx"},
+	}
+	for _, c := range cases {
+		re := regexp.MustCompile(suggestApprovalPattern(c.command))
+		if !re.MatchString(c.command) || !re.MatchString(c.matches) || re.MatchString(c.rejects) {
+			t.Errorf("%q: pattern %s should match %q and not %q", c.command, re, c.matches, c.rejects)
+		}
+	}
+	if suggestApprovalPattern("  ") != "" {
+		t.Error("expected no pattern for an empty command")
+	}
+}
+
+// Test: an action matching a pattern approved for its prompt needs no confirmation
+func TestConfirmActionApprovedPattern(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig()}
+	m.Config.WhitelistPatterns = nil
+	m.approvedPatterns = map[string][]*regexp.Regexp{confirmExecPrompt: {regexp.MustCompile(`^make(\s|$)`)}}
+
+	if ok, command := m.confirmAction("make test", confirmExecPrompt, true, ""); !ok || command != "make test" {
+		t.Errorf("expected make test to be approved, got %t %q", ok, command)
+	}
+}
+
+// waitForChatPrompt waits until a question is queued for the chat and takes it
+func waitForChatPrompt(t *testing.T, m *Manager) *chatPrompt {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.stateMu.RLock()
+		pending := m.chatPrompt != nil
+		m.stateMu.RUnlock()
+		if pending {
+			return m.takeChatPrompt()
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected a question for the chat")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Test: while the readline chat waits for input, another turn's confirmation is answered
+// by the chat's next line instead of reading the terminal a second time
+func TestConfirmThroughChat(t *testing.T) {
+	m := NewManagerForPane(config.DefaultConfig(), "", nil)
+	m.Output = io.Discard
+	if m.askInChat("") != nil {
+		t.Fatalf("expected no question for the chat while it isn't reading")
+	}
+
+	type result struct {
+		approved bool
+		command  string
+	}
+	results := make(chan result, 1)
+	m.setChatReading()
+	go func() {
+		approved, command := m.confirmAction("make deploy", confirmExecPrompt, true, "")
+		results <- result{approved, command}
+	}()
+	waitForChatPrompt(t, m).answer("n")
+	if r := <-results; r.approved {
+		t.Errorf("expected the command to be refused, got %+v", r)
+	}
+
+	// an empty line keeps the prefill, Ctrl+C cancels
+	m.setChatReading()
+	go func() {
+		text, _ := m.readLine("Approve actions matching: ", `^make(\s|$)`)
+		results <- result{command: text}
+	}()
+	waitForChatPrompt(t, m).answer("")
+	if r := <-results; r.command != `^make(\s|$)` {
+		t.Errorf("expected the prefill, got %q", r.command)
+	}
+
+	errs := make(chan error, 1)
+	m.setChatReading()
+	go func() {
+		_, err := m.readLine("Edit command: ", "make deploy")
+		errs <- err
+	}()
+	waitForChatPrompt(t, m).cancel()
+	if err := <-errs; err != readline.ErrInterrupt {
+		t.Errorf("expected an interrupt, got %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// ContextLabel identifies the kind of content a context item carries
+type ContextLabel string
+
+const (
+	ContextSystem      ContextLabel = "system"
+	ContextPane        ContextLabel = "pane"
+	ContextFile        ContextLabel = "file"
+	ContextExecHistory ContextLabel = "exec_history"
+	ContextChat        ContextLabel = "chat"
+)
+
+const trimmedMarker = "[... earlier content trimmed to fit the context budget ...]"
+
+// contextItem is a labeled piece of content inside a message sent to the model
+type contextItem struct {
+	Label   ContextLabel
+	Content string
+}
+
+// contextMessage is a chat message assembled from labeled context items
+type contextMessage struct {
+	FromUser  bool
+	Timestamp time.Time
+	Items     []contextItem
+}
+
+// toChatMessage joins the non-empty items of the message into a ChatMessage
+func (cm contextMessage) toChatMessage() ChatMessage {
+	var parts []string
+	for _, item := range cm.Items {
+		if item.Content != "" {
+			parts = append(parts, item.Content)
+		}
+	}
+	return ChatMessage{
+		Content:   strings.Join(parts, "\n\n"),
+		FromUser:  cm.FromUser,
+		Timestamp: cm.Timestamp,
+	}
+}
+
+// currentContextItems collects the labeled context for the next user turn
+func (m *Manager) currentContextItems(message string) []contextItem {
+	items := []contextItem{
+		{Label: ContextPane, Content: m.GetTmuxPanesInXml(m.Config)},
+	}
+
+	if m.GetExecHistoryContext() && m.ExecPane.IsPrepared && len(m.ExecHistory) > 0 && !m.GetWatchMode() {
+		items = append(items, contextItem{Label: ContextExecHistory, Content: m.execHistoryContext()})
+	}
+
+	if m.GetProjectTree() && !m.GetWatchMode() {
+		if treeContext, err := m.projectTreeContext(m.GetProjectTreeDepth()); err == nil {
+			items = append(items, contextItem{Label: ContextFile, Content: treeContext})
+		} else {
+			logger.Error("Failed to build project tree: %v", err)
+		}
+	}
+	if m.GetGitContext() && !m.GetWatchMode() {
+		if gitContext, err := m.gitContext(); err == nil {
+			items = append(items, contextItem{Label: ContextFile, Content: gitContext})
+		} else {
+			logger.Error("Failed to build git context: %v", err)
+		}
+	}
+	if m.GetTaskContext() && !m.GetWatchMode() {
+		if tasksContext := m.tasksContext(); tasksContext != "" {
+			items = append(items, contextItem{Label: ContextFile, Content: tasksContext})
+		}
+	}
+
+	if !m.ExecPane.IsSubShell {
+		items = append(items, contextItem{
+			Label:   ContextSystem,
+			Content: fmt.Sprintf("Keep in mind, you are working within the shell: %s and OS: %s", m.ExecPane.Shell, m.ExecPane.OS),
+		})
+	}
+
+	items = append(items, contextItem{Label: ContextChat, Content: message})
+	return items
+}
+
+// assembleContext builds the labeled messages for the next request, trimming context
+// in the configured priority order when over budget
+func (m *Manager) assembleContext(message string) []contextMessage {
+	var systemPrompt ChatMessage
+	switch {
+	case m.GetWatchMode():
+		systemPrompt = m.watchPrompt()
+	case m.ExecPane.IsPrepared:
+		systemPrompt = m.chatAssistantPrompt(true)
+	default:
+		systemPrompt = m.chatAssistantPrompt(false)
+	}
+
+	msgs := []contextMessage{{
+		FromUser:  systemPrompt.FromUser,
+		Timestamp: systemPrompt.Timestamp,
+		Items:     []contextItem{{Label: ContextSystem, Content: systemPrompt.Content}},
+	}}
+	for _, msg := range m.Messages {
+		msgs = append(msgs, contextMessage{
+			FromUser:  msg.FromUser,
+			Timestamp: msg.Timestamp,
+			Items:     []contextItem{{Label: ContextChat, Content: msg.Content}},
+		})
+	}
+	msgs = append(msgs, contextMessage{
+		FromUser:  true,
+		Timestamp: time.Now(),
+		Items:     m.currentContextItems(message),
+	})
+
+	trimContextMessages(msgs, m.GetMaxContextSize(), m.Config.Context.TrimOrder)
+	return msgs
+}
+
+// assembleRequest returns the current user message and the full message list to send
+func (m *Manager) assembleRequest(message string) (ChatMessage, []ChatMessage) {
+	msgs := m.assembleContext(message)
+
+	sending := make([]ChatMessage, 0, len(msgs))
+	for i, msg := range msgs {
+		chatMsg := msg.toChatMessage()
+		// drop history messages that were trimmed away entirely
+		if chatMsg.Content == "" && i != 0 && i != len(msgs)-1 {
+			continue
+		}
+		sending = append(sending, chatMsg)
+	}
+
+	return sending[len(sending)-1], sending
+}
+
+// execHistoryContext renders the most recent exec history entries that fit the exec history budget
+func (m *Manager) execHistoryContext() string {
+	budget := m.Config.Context.ExecHistoryTokens
+	var entries []string
+	used := 0
+	for i := len(m.ExecHistory) - 1; i >= 0; i-- {
+		h := m.ExecHistory[i]
+		entry := fmt.Sprintf("<command code=\"%d\">%s</command>\n<output>\n%s\n</output>", h.Code, h.Command, h.Output)
+		tokens := system.EstimateTokenCount(entry)
+		if budget > 0 && used+tokens > budget {
+			if len(entries) == 0 {
+				// always keep the latest command, with its output trimmed to fit
+				entry = fmt.Sprintf("<command code=\"%d\">%s</command>\n<output>\n%s\n</output>", h.Code, h.Command, limitTokens(h.Output, budget/2))
+				entries = append(entries, entry)
+			}
+			break
+		}
+		used += tokens
+		entries = append(entries, entry)
+	}
+
+	var builder strings.Builder
+	builder.WriteString("<exec_history>\n")
+	for i := len(entries) - 1; i >= 0; i-- {
+		builder.WriteString(entries[i] + "\n")
+	}
+	builder.WriteString("</exec_history>")
+	return builder.String()
+}
+
+// limitTokens trims the head of content so it fits within budget tokens; budget <= 0 means unlimited
+func limitTokens(content string, budget int) string {
+	if budget <= 0 {
+		return content
+	}
+	tokens := system.EstimateTokenCount(content)
+	if tokens <= budget {
+		return content
+	}
+	return trimHead(content, tokens-budget)
+}
+
+// contextTokens estimates the token count of all items in msgs
+func contextTokens(msgs []contextMessage) int {
+	total := 0
+	for _, msg := range msgs {
+		for _, item := range msg.Items {
+			total += system.EstimateTokenCount(item.Content)
+		}
+	}
+	return total
+}
+
+// trimContextMessages shortens items label by label, in the given priority order and
+// oldest first, until the estimated size fits the budget
+func trimContextMessages(msgs []contextMessage, budget int, order []string) {
+	if budget <= 0 {
+		return
+	}
+	total := contextTokens(msgs)
+	for _, label := range order {
+		for i := range msgs {
+			for j := range msgs[i].Items {
+				if total <= budget {
+					return
+				}
+				item := &msgs[i].Items[j]
+				if string(item.Label) != label || item.Content == "" {
+					continue
+				}
+				before := system.EstimateTokenCount(item.Content)
+				item.Content = trimHead(item.Content, total-budget)
+				after := system.EstimateTokenCount(item.Content)
+				total -= before - after
+				logger.Debug("Trimmed %s context item from %d to %d tokens", label, before, after)
+			}
+		}
+	}
+}
+
+// trimHead drops leading lines of content until roughly excess tokens are removed,
+// keeping the most recent lines which are usually the most relevant
+func trimHead(content string, excess int) string {
+	// the marker itself costs tokens, so remove enough to pay for it
+	excess += system.EstimateTokenCount(trimmedMarker)
+	lines := strings.Split(content, "\n")
+	removed := 0
+	i := 0
+	for i < len(lines) && removed < excess {
+		removed += system.EstimateTokenCount(lines[i])
+		i++
+	}
+	if i >= len(lines) {
+		return ""
+	}
+	return trimmedMarker + "\n" + strings.Join(lines[i:], "\n")
+}
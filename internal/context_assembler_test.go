@@ -0,0 +1,106 @@
+// Unit tests for context trimming in context_assembler.go
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+func repeatLines(prefix string, n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = prefix + " line content here"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Test: nothing is trimmed when under budget
+func TestTrimContextMessages_UnderBudget(t *testing.T) {
+	msgs := []contextMessage{
+		{Items: []contextItem{{Label: ContextPane, Content: "pane"}, {Label: ContextChat, Content: "hello"}}},
+	}
+	trimContextMessages(msgs, 1000, []string{"pane", "chat"})
+	if msgs[0].Items[0].Content != "pane" || msgs[0].Items[1].Content != "hello" {
+		t.Errorf("expected content to be untouched, got %+v", msgs)
+	}
+}
+
+// Test: lower priority labels are trimmed before higher ones
+func TestTrimContextMessages_PriorityOrder(t *testing.T) {
+	file := repeatLines("file", 50)
+	pane := repeatLines("pane", 50)
+	msgs := []contextMessage{
+		{Items: []contextItem{
+			{Label: ContextPane, Content: pane},
+			{Label: ContextFile, Content: file},
+			{Label: ContextChat, Content: "question"},
+		}},
+	}
+	budget := system.EstimateTokenCount(pane) + 50
+	trimContextMessages(msgs, budget, []string{"file", "pane", "chat"})
+
+	if msgs[0].Items[0].Content != pane {
+		t.Errorf("expected pane to be kept while file trimming suffices")
+	}
+	if msgs[0].Items[1].Content != "" && !strings.HasPrefix(msgs[0].Items[1].Content, trimmedMarker) {
+		t.Errorf("expected file item to be trimmed, got %q", msgs[0].Items[1].Content)
+	}
+	if msgs[0].Items[2].Content != "question" {
+		t.Errorf("expected chat to be untouched")
+	}
+	if contextTokens(msgs) > budget {
+		t.Errorf("expected total %d to fit budget %d", contextTokens(msgs), budget)
+	}
+}
+
+// Test: trimming keeps the tail of the content
+func TestTrimHead_KeepsTail(t *testing.T) {
+	content := "first\n" + repeatLines("middle", 40) + "\nlast"
+	got := trimHead(content, 5)
+	if !strings.HasPrefix(got, trimmedMarker) || !strings.HasSuffix(got, "last") || strings.Contains(got, "first") {
+		t.Errorf("unexpected trimmed content: %q", got)
+	}
+}
+
+// Test: per-source budgets cap the content size
+func TestLimitTokens(t *testing.T) {
+	content := repeatLines("pane", 100)
+	if got := limitTokens(content, 0); got != content {
+		t.Errorf("expected unlimited budget to keep content")
+	}
+	got := limitTokens(content, 100)
+	if tokens := system.EstimateTokenCount(got); tokens > 100 {
+		t.Errorf("expected at most 100 tokens, got %d", tokens)
+	}
+	if !strings.HasSuffix(got, "pane line content here") {
+		t.Errorf("expected the tail to be kept, got %q", got)
+	}
+}
+
+// Test: the parsed exec history is only sent when context.exec_history is on
+func TestCurrentContextItems_ExecHistory(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Context.Tasks = false
+	m := NewManagerForPane(cfg, "", nil)
+	m.ExecPane = &system.TmuxPaneDetails{IsPrepared: true}
+	m.ExecHistory = []CommandExecHistory{{Command: "ls", Output: "a b", Code: 0}}
+
+	hasHistory := func() bool {
+		for _, item := range m.currentContextItems("question") {
+			if item.Label == ContextExecHistory {
+				return true
+			}
+		}
+		return false
+	}
+	if hasHistory() {
+		t.Errorf("expected no exec history by default")
+	}
+	m.setSessionOverride("context.exec_history", true)
+	if !hasHistory() {
+		t.Errorf("expected the exec history with context.exec_history on")
+	}
+}
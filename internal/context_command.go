@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const contextUsage = "Usage: /context [add <pane-id>|remove <pane-id>|clear]"
+
+// handleContextCommand manages the read-only context panes: panes of any window whose
+// content is sent with every request but which never become the exec pane
+func handleContextCommand(m *Manager, args []string) {
+	if len(args) == 0 || strings.EqualFold(args[0], "list") {
+		m.printContextPanes()
+		return
+	}
+	switch strings.ToLower(args[0]) {
+	case "add":
+		if len(args) < 2 {
+			m.Println(i18n.T(contextUsage))
+			return
+		}
+		id := normalizePaneId(args[1])
+		if err := m.addContextPane(id); err != nil {
+			m.Println(err.Error())
+			return
+		}
+		m.Println(i18n.T("Pane %s added as read-only context", id))
+	case "remove", "rm":
+		if len(args) < 2 {
+			m.Println(i18n.T(contextUsage))
+			return
+		}
+		id := normalizePaneId(args[1])
+		n := slices.Index(m.contextPanes, id)
+		if n < 0 {
+			m.Println(i18n.T("Pane %s is not a context pane", id))
+			return
+		}
+		m.contextPanes = slices.Delete(m.contextPanes, n, n+1)
+		m.Println(i18n.T("Pane %s removed from the context", id))
+	case "clear":
+		m.contextPanes = nil
+		m.Println(i18n.T("Context panes cleared"))
+	default:
+		m.Println(i18n.T(contextUsage))
+	}
+}
+
+// normalizePaneId accepts pane ids with or without the leading %
+func normalizePaneId(id string) string {
+	if strings.HasPrefix(id, "%") {
+		return id
+	}
+	return "%" + id
+}
+
+// addContextPane marks a pane as read-only context; the chat pane and the exec pane
+// can't be one
+func (m *Manager) addContextPane(id string) error {
+	switch {
+	case id == m.PaneId:
+		return fmt.Errorf("%s", i18n.T("Pane %s is the TmuxAI chat pane", id))
+	case m.ExecPane != nil && id == m.ExecPane.Id:
+		return fmt.Errorf("%s", i18n.T("Pane %s is the exec pane, the AI runs commands there", id))
+	case slices.Contains(m.contextPanes, id):
+		return fmt.Errorf("%s", i18n.T("Pane %s is already a context pane", id))
+	}
+	panes, err := system.TmuxPanesDetails(id)
+	if err != nil || len(panes) == 0 {
+		return fmt.Errorf("%s", i18n.T("No tmux pane %s, the ids are listed by: tmux list-panes -a", id))
+	}
+	m.contextPanes = append(m.contextPanes, id)
+	return nil
+}
+
+// isContextPane reports whether the pane was added with /context add
+func (m *Manager) isContextPane(id string) bool {
+	return slices.Contains(m.contextPanes, id)
+}
+
+// contextPaneDetails captures the context panes outside the current window; panes
+// closed since are dropped
+func (m *Manager) contextPaneDetails(inWindow []system.TmuxPaneDetails) []system.TmuxPaneDetails {
+	var details []system.TmuxPaneDetails
+	var alive []string
+	for _, id := range m.contextPanes {
+		if slices.ContainsFunc(inWindow, func(p system.TmuxPaneDetails) bool { return p.Id == id }) {
+			alive = append(alive, id)
+			continue
+		}
+		panes, err := system.TmuxPanesDetails(id)
+		if err != nil || len(panes) == 0 {
+			logger.Info("Context pane %s is gone, removing it", id)
+			continue
+		}
+		alive = append(alive, id)
+		pane := panes[0]
+		pane.OS = m.osDetails()
+		pane.Refresh(m.GetMaxCaptureLines())
+		details = append(details, pane)
+	}
+	m.contextPanes = alive
+	return details
+}
+
+// printContextPanes lists the context panes with what runs in them
+func (m *Manager) printContextPanes() {
+	if len(m.contextPanes) == 0 {
+		m.Println(i18n.T("No context panes, /context add <pane-id> adds one"))
+		return
+	}
+	theme := system.CurrentTheme()
+	var b strings.Builder
+	for _, id := range m.contextPanes {
+		panes, err := system.TmuxPanesDetails(id)
+		if err != nil || len(panes) == 0 {
+			fmt.Fprintf(&b, "  %s %s\n", id, theme.Muted.Sprint(i18n.T("(closed)")))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", id, panes[0].CurrentCommand, theme.Muted.Sprint(panes[0].CurrentPath))
+	}
+	fmt.Fprint(m.out(), b.String())
+}
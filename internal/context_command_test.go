@@ -0,0 +1,43 @@
+// Unit tests for the read-only context panes in context_command.go
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Test: the chat pane and the exec pane can't become context panes
+func TestAddContextPaneRejectsOwnPanes(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig(), PaneId: "%1", ExecPane: &system.TmuxPaneDetails{Id: "%2"}}
+	for _, id := range []string{"%1", "%2"} {
+		if err := m.addContextPane(id); err == nil {
+			t.Errorf("expected %s to be rejected", id)
+		}
+	}
+	if len(m.contextPanes) != 0 {
+		t.Errorf("unexpected context panes %v", m.contextPanes)
+	}
+}
+
+// Test: context panes are marked in the prompt and can be removed again
+func TestContextPanes(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig(), contextPanes: []string{"%2", "%7"}}
+	got := m.renderPanesXml(testPanes())
+	if !strings.Contains(got, " - Id: %2\n") || !strings.Contains(got, "added by the user as context to read") {
+		t.Errorf("context pane not marked in:\n%s", got)
+	}
+	if normalizePaneId("7") != "%7" || normalizePaneId("%7") != "%7" {
+		t.Error("pane ids not normalized")
+	}
+	handleContextCommand(m, []string{"remove", "7"})
+	if len(m.contextPanes) != 1 || !m.isContextPane("%2") {
+		t.Errorf("unexpected context panes %v", m.contextPanes)
+	}
+	handleContextCommand(m, []string{"clear"})
+	if len(m.contextPanes) != 0 {
+		t.Errorf("context panes not cleared: %v", m.contextPanes)
+	}
+}
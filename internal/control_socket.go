@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// the socket is created in a directory only the user can enter, so it is never
+// reachable by others, not even before its own mode is set
+const (
+	controlSocketDir  = "run"
+	controlSocketName = "control.sock"
+)
+
+// ControlRequest is a single newline-delimited JSON command sent over the control socket
+type ControlRequest struct {
+	Command   string           `json:"command"` // send, status, context, ask, edit, exec
+	Args      string           `json:"args,omitempty"`
+	Selection *EditorSelection `json:"selection,omitempty"`
+}
+
+// ControlResponse is the reply to a ControlRequest
+type ControlResponse struct {
+	OK     bool            `json:"ok"`
+	Error  string          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+// ControlServer accepts control commands from other processes on a unix socket
+type ControlServer struct {
+	manager  *Manager
+	listener net.Listener
+	path     string
+}
+
+// ControlSocketPath returns the path of the control socket in the config dir
+func ControlSocketPath() string {
+	return config.GetConfigFilePath(filepath.Join(controlSocketDir, controlSocketName))
+}
+
+// StartControlServer listens on the control socket; a stale socket file is replaced,
+// while a socket owned by another live instance is left alone
+func StartControlServer(m *Manager) (*ControlServer, error) {
+	path := ControlSocketPath()
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to restrict %s: %w", dir, err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, 500*time.Millisecond); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("control socket %s is in use by another tmuxai instance", path)
+		}
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	os.Chmod(path, 0o600)
+
+	cs := &ControlServer{manager: m, listener: listener, path: path}
+	go cs.serve()
+	logger.Info("Control socket listening on %s", path)
+	return cs, nil
+}
+
+// Close stops the server and removes the socket file
+func (cs *ControlServer) Close() error {
+	err := cs.listener.Close()
+	os.Remove(cs.path)
+	return err
+}
+
+func (cs *ControlServer) serve() {
+	for {
+		conn, err := cs.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Error("Control socket accept failed: %v", err)
+			}
+			return
+		}
+		go cs.handleConn(conn)
+	}
+}
+
+// controlTimeout returns how long a request may take; edits wait for the model
+// and exec waits for the command to finish
+func controlTimeout(command string) time.Duration {
+	switch command {
+	case "edit":
+		return 2 * time.Minute
+	case "exec":
+		return 30 * time.Minute
+	default:
+		return 10 * time.Second
+	}
+}
+
+func (cs *ControlServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(controlTimeout("")))
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return
+	}
+
+	var req ControlRequest
+	var resp ControlResponse
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp = ControlResponse{Error: "invalid request: " + err.Error()}
+	} else {
+		conn.SetDeadline(time.Now().Add(controlTimeout(req.Command)))
+		resp = cs.dispatch(req)
+	}
+
+	data, _ := json.Marshal(resp)
+	conn.Write(append(data, '\n'))
+}
+
+func (cs *ControlServer) dispatch(req ControlRequest) ControlResponse {
+	switch req.Command {
+	case "send":
+		if strings.TrimSpace(req.Args) == "" {
+			return ControlResponse{Error: "send requires a message"}
+		}
+		go cs.manager.HandleExternalMessage("ctl", req.Args)
+		return ControlResponse{OK: true}
+	case "status":
+		result, _ := json.Marshal(cs.manager.statusSnapshot())
+		return ControlResponse{OK: true, Result: result}
+	case "context", "ask", "edit":
+		return cs.dispatchEditor(req)
+	case "exec":
+		return cs.dispatchExec(req)
+	default:
+		return ControlResponse{Error: fmt.Sprintf("unknown command: %s", req.Command)}
+	}
+}
+
+// SendControlRequest sends a request to the running instance's control socket
+func SendControlRequest(req ControlRequest) (ControlResponse, error) {
+	conn, err := net.DialTimeout("unix", ControlSocketPath(), 2*time.Second)
+	if err != nil {
+		return ControlResponse{}, fmt.Errorf("no running tmuxai instance found: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(controlTimeout(req.Command)))
+
+	data, _ := json.Marshal(req)
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return ControlResponse{}, err
+	}
+
+	var resp ControlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return ControlResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+	return resp, nil
+}
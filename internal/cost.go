@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// modelPrice is what a model costs in USD per million tokens
+type modelPrice struct {
+	input  float64
+	output float64
+}
+
+// priceList holds the model prices OpenRouter publishes, fetched once per session
+type priceList struct {
+	once   sync.Once
+	prices map[string]modelPrice
+}
+
+// fetchModelPrices reads the pricing of the models endpoint; OpenRouter lists it in
+// USD per token as strings
+func fetchModelPrices(ctx context.Context, baseURL, apiKey string) (map[string]modelPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	resp, err := system.HTTPClient(0).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("models endpoint returned %s", resp.Status)
+	}
+	var body struct {
+		Data []struct {
+			ID      string `json:"id"`
+			Pricing struct {
+				Prompt     string `json:"prompt"`
+				Completion string `json:"completion"`
+			} `json:"pricing"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	prices := map[string]modelPrice{}
+	for _, model := range body.Data {
+		input, err1 := strconv.ParseFloat(model.Pricing.Prompt, 64)
+		output, err2 := strconv.ParseFloat(model.Pricing.Completion, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		prices[model.ID] = modelPrice{input: input * 1e6, output: output * 1e6}
+	}
+	return prices, nil
+}
+
+// priceOf returns the price of model: openrouter.input_price and output_price when set,
+// otherwise OpenRouter's pricing when OpenRouter is the provider
+func (m *Manager) priceOf(model string) (modelPrice, bool) {
+	configured := m.Config.OpenRouter
+	if configured.InputPrice > 0 || configured.OutputPrice > 0 {
+		return modelPrice{input: configured.InputPrice, output: configured.OutputPrice}, true
+	}
+	if m.Config.Provider == "mock" || m.Config.ProviderSection() != nil || configured.APIKey == "" {
+		return modelPrice{}, false
+	}
+	m.prices.once.Do(func() {
+		prices, err := fetchModelPrices(context.Background(), configured.BaseURL, configured.APIKey)
+		if err != nil {
+			logger.Error("Failed to fetch model pricing: %v", err)
+			return
+		}
+		m.prices.prices = prices
+	})
+	price, ok := m.prices.prices[model]
+	return price, ok
+}
+
+// usageCost is the cost of a token count at the price of model, false when the price is unknown
+func (m *Manager) usageCost(model string, inputTokens, outputTokens int) (float64, bool) {
+	price, ok := m.priceOf(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(inputTokens)*price.input + float64(outputTokens)*price.output) / 1e6, true
+}
+
+// sessionCost adds up the cost of the session's requests, false when no model's price
+// is known. The caller holds m.stats.mu.
+func (m *Manager) sessionCost() (float64, bool) {
+	var total float64
+	priced := false
+	for model, u := range m.stats.models {
+		if cost, ok := m.usageCost(model, u.inputTokens, u.outputTokens); ok {
+			total += cost
+			priced = true
+		}
+	}
+	return total, priced
+}
+
+// formatCost prints a cost, or a dash when it is unknown
+func formatCost(cost float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
+
+// costLines breaks the session's token usage and cost down by model
+func (m *Manager) costLines() []string {
+	s := &m.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.models) == 0 {
+		return []string{i18n.T("No requests yet")}
+	}
+	theme := system.CurrentTheme()
+	names := make([]string, 0, len(s.models))
+	for name := range s.models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	estimated := false
+	for _, name := range names {
+		u := s.models[name]
+		cost, ok := m.usageCost(name, u.inputTokens, u.outputTokens)
+		lines = append(lines, fmt.Sprintf("%s %s", theme.Label.Sprint(name+":"),
+			i18n.T("%d requests, %s in, %s out, %s", u.requests, formatTokens(u.inputTokens), formatTokens(u.outputTokens), formatCost(cost, ok))))
+		estimated = estimated || u.estimated > 0
+	}
+	cost, ok := m.sessionCost()
+	lines = append(lines, fmt.Sprintf("%s %s", theme.Label.Sprint(i18n.T("Total:")),
+		i18n.T("%s in, %s out, %s", formatTokens(s.inputTokens), formatTokens(s.outputTokens), formatCost(cost, ok))))
+	if s.last != nil {
+		cost, ok := m.usageCost(s.last.model, s.last.inputTokens, s.last.outputTokens)
+		lines = append(lines, fmt.Sprintf("%s %s", theme.Label.Sprint(i18n.T("Last request:")),
+			i18n.T("%s in, %s out, %s", formatTokens(s.last.inputTokens), formatTokens(s.last.outputTokens), formatCost(cost, ok))))
+	}
+	if estimated {
+		lines = append(lines, theme.Muted.Sprint(i18n.T("Some token counts are estimated, the API did not report usage for every request")))
+	}
+	if !ok {
+		lines = append(lines, theme.Muted.Sprint(i18n.T("Prices unknown, set openrouter.input_price and output_price (USD per million tokens)")))
+	}
+	return lines
+}
+
+// handleCostCommand prints the token usage and cost of the session
+func handleCostCommand(m *Manager) {
+	for _, line := range m.costLines() {
+		m.Println(line)
+	}
+}
@@ -1,22 +1,39 @@
 package internal
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
 	"github.com/eiannone/keyboard"
-	"github.com/fatih/color"
 )
 
-func (m *Manager) Countdown(seconds int) {
-	highlightColor := color.New(color.FgYellow, color.Bold).SprintFunc()
-	dimColor := color.New(color.FgBlue).SprintFunc()
-	pauseColor := color.New(color.FgRed, color.Bold).SprintFunc()
+// Countdown waits seconds before the next look at the pane, Space pauses and Enter skips
+// the wait. It returns early when ctx is cancelled.
+func (m *Manager) Countdown(ctx context.Context, seconds int) {
+	theme := system.CurrentTheme()
+	highlightColor := theme.Highlight.SprintFunc()
+	dimColor := theme.Neutral.SprintFunc()
+	pauseColor := theme.Pause.SprintFunc()
+
+	if m.tui != nil {
+		// the TUI owns the keyboard, Ctrl+C there stops the countdown
+		m.tuiCountdown(ctx, seconds, highlightColor, dimColor, pauseColor)
+		return
+	}
+	if !system.CursorControl() {
+		// the dots can't be redrawn on a dumb terminal or in a log, just wait
+		_ = sleepContext(ctx, time.Duration(seconds)*time.Second)
+		return
+	}
 
 	// Set up keyboard
 	if err := keyboard.Open(); err != nil {
-		fmt.Println("Error opening keyboard:", err)
+		fmt.Fprintln(m.out(), i18n.T("Error opening keyboard: %v", err))
 		return
 	}
 	defer keyboard.Close()
@@ -39,61 +56,71 @@ func (m *Manager) Countdown(seconds int) {
 	defer ticker.Stop()
 
 	// Initial render
-	renderCountdown(remaining, seconds, paused, highlightColor, dimColor, pauseColor)
+	renderCountdown(m.out(), remaining, seconds, paused, highlightColor, dimColor, pauseColor)
 
 	for remaining > 0 {
 		select {
+		case <-ctx.Done():
+			return
 		case key := <-keyChan:
 			switch key {
 			case keyboard.KeySpace: // Space key
 				paused = !paused
-				renderCountdown(remaining, seconds, paused, highlightColor, dimColor, pauseColor)
+				renderCountdown(m.out(), remaining, seconds, paused, highlightColor, dimColor, pauseColor)
 			case keyboard.KeyEnter: // Enter key
 				// Just continue execution without exiting the function
 				remaining = 0 // Set remaining to 0 to end the countdown loop
-				renderCountdown(remaining, seconds, paused, highlightColor, dimColor, pauseColor)
+				renderCountdown(m.out(), remaining, seconds, paused, highlightColor, dimColor, pauseColor)
 				break
 			case keyboard.KeyCtrlC: // Ctrl+C
-				m.Status = ""
-				m.WatchMode = false
+				m.stopTurn()
 				return
 			}
 		case <-ticker.C:
 			if !paused {
 				remaining--
-				renderCountdown(remaining, seconds, paused, highlightColor, dimColor, pauseColor)
+				renderCountdown(m.out(), remaining, seconds, paused, highlightColor, dimColor, pauseColor)
 			}
 		}
 	}
 }
 
+func (m *Manager) tuiCountdown(ctx context.Context, seconds int, highlightColor, dimColor, pauseColor func(a ...interface{}) string) {
+	renderCountdown(m.out(), seconds, seconds, false, highlightColor, dimColor, pauseColor)
+	for remaining := seconds - 1; remaining >= 0; remaining-- {
+		if sleepContext(ctx, time.Second) != nil || m.GetStatus() == "" {
+			return
+		}
+		renderCountdown(m.out(), remaining, seconds, false, highlightColor, dimColor, pauseColor)
+	}
+}
+
 // renderCountdown displays the current state of the countdown
-func renderCountdown(remaining, total int, paused bool, highlightColor, dimColor, pauseColor func(a ...interface{}) string) {
+func renderCountdown(w io.Writer, remaining, total int, paused bool, highlightColor, dimColor, pauseColor func(a ...interface{}) string) {
 	// Use ANSI escape sequences for complete control over line clearing
 	// \033[0G moves cursor to column 0 (beginning of line)
 	// \033[K clears from cursor to end of line
-	fmt.Print("\033[0G\033[K")
+	fmt.Fprint(w, "\033[0G\033[K")
 
 	// Build the dot display with consistent spacing
 	dots := make([]string, total)
 	for j := 0; j < total; j++ {
 		if j >= total-remaining {
-			dots[j] = dimColor("○")
+			dots[j] = dimColor(system.Sym("○"))
 		} else {
-			dots[j] = highlightColor("●")
+			dots[j] = highlightColor(system.Sym("●"))
 		}
 	}
 
 	// Use simple fixed-width characters for status indicators
 	var statusIndicator string
 	if paused {
-		statusIndicator = pauseColor("⏸")
+		statusIndicator = pauseColor(system.Sym("⏸"))
 	} else {
-		statusIndicator = highlightColor("▶")
+		statusIndicator = highlightColor(system.Sym("▶"))
 	}
 
 	// Ensure exact character count and consistent spacing with printf
 	// %2s gives a fixed width for the status indicator
-	fmt.Printf("%s %s [Space: Pause/Resume | Enter: To continue]",
-		statusIndicator, strings.Join(dots, " "))
+	fmt.Fprintf(w, "%s %s %s", statusIndicator, strings.Join(dots, " "), i18n.T("[Space: Pause/Resume | Enter: To continue]"))
 }
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"runtime"
+	runpprof "runtime/pprof"
+	"strconv"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// processStarted is when TmuxAI started, for the uptime in /debug stats
+var processStarted = time.Now()
+
+// startPprof serves the pprof handlers on addr, which must be a loopback address
+// since profiles show the conversation held in memory. The server's Addr is the
+// address it listens on.
+func startPprof(addr string) (*http.Server, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("pprof_addr %s is not a loopback address", addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	server := &http.Server{Addr: listener.Addr().String(), Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.Error("pprof server stopped: %v", err)
+		}
+	}()
+	logger.Info("pprof listening on http://%s/debug/pprof/", server.Addr)
+	return server, nil
+}
+
+// handleDebugCommand shows runtime stats or writes profiles: /debug [stats], /debug profile [seconds]
+func handleDebugCommand(ctx context.Context, m *Manager, args []string) {
+	if len(args) == 0 || args[0] == "stats" {
+		for _, line := range runtimeStats(m) {
+			m.Println(line)
+		}
+		return
+	}
+	if args[0] != "profile" {
+		m.Println(i18n.T("Usage: /debug [stats] | /debug profile [seconds]"))
+		return
+	}
+
+	seconds := 30
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			m.Println(i18n.T("Usage: /debug [stats] | /debug profile [seconds]"))
+			return
+		}
+		seconds = n
+	}
+	m.Println(i18n.T("Profiling the CPU for %d seconds, Ctrl+C to stop early", seconds))
+	paths, err := writeProfiles(ctx, config.GetConfigFilePath("debug"), time.Duration(seconds)*time.Second)
+	if err != nil {
+		m.Println(i18n.T("Failed to write profiles: %v", err))
+		return
+	}
+	for _, path := range paths {
+		m.Println(i18n.T("Wrote %s", path))
+	}
+	m.Println(i18n.T("Inspect them with go tool pprof <file>"))
+}
+
+// runtimeStats describes memory, goroutines and the session sizes
+func runtimeStats(m *Manager) []string {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return []string{
+		fmt.Sprintf("uptime: %s", time.Since(processStarted).Round(time.Second)),
+		fmt.Sprintf("goroutines: %d", runtime.NumGoroutine()),
+		fmt.Sprintf("heap: %.1f MB in use, %.1f MB from the OS", float64(mem.HeapAlloc)/(1<<20), float64(mem.Sys)/(1<<20)),
+		fmt.Sprintf("gc: %d runs, last pause %s", mem.NumGC, time.Duration(mem.PauseNs[(mem.NumGC+255)%256])),
+		fmt.Sprintf("session: %d messages, %d exec history entries", len(m.MessageHistory()), len(m.ExecHistory)),
+	}
+}
+
+// writeProfiles records a CPU profile for d (or until ctx is cancelled), then the heap
+// and goroutine profiles, into dir
+func writeProfiles(ctx context.Context, dir string, d time.Duration) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	prefix := filepath.Join(dir, "profile-"+time.Now().Format("20060102-150405"))
+
+	cpuPath := prefix + "-cpu.pprof"
+	f, err := os.Create(cpuPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := runpprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		os.Remove(cpuPath)
+		return nil, err
+	}
+	sleepContext(ctx, d)
+	runpprof.StopCPUProfile()
+	f.Close()
+
+	paths := []string{cpuPath}
+	for _, name := range []string{"heap", "goroutine"} {
+		path := prefix + "-" + name + ".pprof"
+		f, err := os.Create(path)
+		if err != nil {
+			return paths, err
+		}
+		err = runpprof.Lookup(name).WriteTo(f, 0)
+		f.Close()
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,68 @@
+// Unit tests for the pprof server and profiles in debug_command.go
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test: pprof only listens on loopback addresses
+func TestStartPprofLoopbackOnly(t *testing.T) {
+	if _, err := startPprof("0.0.0.0:0"); err == nil {
+		t.Error("expected an error for a public address")
+	}
+	server, err := startPprof("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+}
+
+// Test: the pprof index is served
+func TestPprofIndex(t *testing.T) {
+	server, err := startPprof("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	resp, err := http.Get("http://" + server.Addr + "/debug/pprof/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "goroutine") {
+		t.Errorf("unexpected index: %d %s", resp.StatusCode, body)
+	}
+}
+
+// Test: profiles are written to the directory, and a cancelled context ends the CPU profile early
+func TestWriteProfiles(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	started := time.Now()
+	paths, err := writeProfiles(ctx, dir, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(started) > 10*time.Second {
+		t.Error("cancelled profile took too long")
+	}
+	if len(paths) != 3 {
+		t.Fatalf("expected 3 profiles, got %v", paths)
+	}
+	for _, path := range paths {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 || filepath.Dir(path) != dir {
+			t.Errorf("profile %s not written: %v", path, err)
+		}
+	}
+}
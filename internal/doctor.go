@@ -0,0 +1,275 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// doctorMinTmux is the oldest tmux with everything TmuxAI uses (pane titles in borders)
+const doctorMinTmux = 3.0
+
+// DoctorCheck is one line of the doctor report
+type DoctorCheck struct {
+	Name   string
+	Status string // "ok", "warn" or "fail"
+	Detail string
+	Fix    string // what to do about a warning or failure
+}
+
+// RunDoctor checks tmux, the config, the API, the MCP servers and the shell of the exec
+// pane. configErr is what loading the config returned; cfg holds the defaults then.
+func RunDoctor(ctx context.Context, cfg *config.Config, configErr error) []DoctorCheck {
+	checks := []DoctorCheck{doctorTmux(), doctorConfig(cfg, configErr), doctorAPI(ctx, cfg)}
+	checks = append(checks, doctorMcp(cfg)...)
+	return append(checks, doctorShell())
+}
+
+// PrintDoctorReport prints the checks with their fixes and reports whether none failed
+func PrintDoctorReport(w io.Writer, checks []DoctorCheck) bool {
+	theme := system.CurrentTheme()
+	ok := true
+	for _, c := range checks {
+		var mark string
+		switch c.Status {
+		case "ok":
+			mark = theme.Success.Sprint(system.Sym("✓"))
+		case "warn":
+			mark = theme.Warning.Sprint("!")
+		default:
+			mark = theme.Error.Sprint(system.Sym("✗"))
+			ok = false
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", mark, theme.Label.Sprint(c.Name), c.Detail)
+		if c.Fix != "" && c.Status != "ok" {
+			fmt.Fprintf(w, "  %s\n", theme.Muted.Sprint(i18n.T("fix: %s", c.Fix)))
+		}
+	}
+	return ok
+}
+
+func doctorTmux() DoctorCheck {
+	check := DoctorCheck{Name: "tmux"}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		check.Status, check.Detail, check.Fix = "fail", "tmux not found in PATH", "install tmux 3.0 or newer"
+		return check
+	}
+	version, err := system.TmuxVersion()
+	if err != nil {
+		check.Status, check.Detail, check.Fix = "fail", fmt.Sprintf("tmux -V failed: %v", err), "check the tmux installation"
+		return check
+	}
+	check.Status, check.Detail = "ok", "version "+version
+	if n, ok := tmuxVersionNumber(version); ok && n < doctorMinTmux {
+		check.Status, check.Fix = "warn", "upgrade to tmux 3.0 or newer"
+	}
+	if os.Getenv("TMUX_PANE") == "" {
+		check.Status = "warn"
+		check.Detail += ", not running inside tmux"
+		check.Fix = "start tmuxai from a tmux pane to check the panes too"
+	}
+	return check
+}
+
+// tmuxVersionNumber reads versions like "3.4", "3.3a" or "next-3.5"
+func tmuxVersionNumber(version string) (float64, bool) {
+	version = strings.TrimPrefix(version, "next-")
+	end := strings.IndexFunc(version, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end >= 0 {
+		version = version[:end]
+	}
+	n, err := strconv.ParseFloat(version, 64)
+	return n, err == nil
+}
+
+func doctorConfig(cfg *config.Config, configErr error) DoctorCheck {
+	check := DoctorCheck{Name: "config", Status: "ok", Detail: configFileDescription()}
+	var problems []string
+	if configErr != nil {
+		problems = append(problems, configErr.Error())
+	}
+	if _, err := logger.ParseLevel(cfg.LogLevel); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := i18n.ParseLanguage(cfg.Language); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := system.LoadTheme(cfg.Theme.Preset, cfg.Theme.Colors); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.Highlight.Theme != "" && !system.IsHighlightTheme(cfg.Highlight.Theme) {
+		problems = append(problems, "unknown highlight.theme: "+cfg.Highlight.Theme)
+	}
+	switch cfg.Interface {
+	case "readline":
+	case "tui":
+		if !tuiAvailable {
+			problems = append(problems, "interface is tui but this build has no TUI")
+		}
+	default:
+		problems = append(problems, "unknown interface: "+cfg.Interface)
+	}
+	switch {
+	case cfg.Provider == "azure" && cfg.Azure.BaseURL == "":
+		problems = append(problems, "azure.base_url is required")
+	case config.IsProvider(cfg.Provider):
+	case cfg.Provider == "mock":
+		if _, err := NewMockProvider(cfg.Mock); err != nil {
+			problems = append(problems, "mock.file: "+err.Error())
+		}
+	default:
+		problems = append(problems, "unknown provider: "+cfg.Provider)
+	}
+	if cfg.HTTP.CAFile != "" {
+		if _, err := os.Stat(cfg.HTTP.CAFile); err != nil {
+			problems = append(problems, "http.ca_file: "+err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		check.Status = "fail"
+		check.Detail = strings.Join(problems, "; ")
+		check.Fix = "correct the values in " + config.GetConfigFilePath("config.yaml") + ", see config.example.yaml"
+	}
+	return check
+}
+
+// configFileDescription says which config file is used
+func configFileDescription() string {
+	path := config.GetConfigFilePath("config.yaml")
+	if _, err := os.Stat(path); err != nil {
+		return "no config file, using defaults and environment"
+	}
+	return path
+}
+
+// doctorAPI lists the models, which needs a valid key but costs nothing
+func doctorAPI(ctx context.Context, cfg *config.Config) DoctorCheck {
+	check := DoctorCheck{Name: "api"}
+	if cfg.Provider == "mock" {
+		check.Status, check.Detail = "ok", "mock provider, answers come from a script"
+		return check
+	}
+	section := cfg.Provider
+	if cfg.ProviderSection() == nil {
+		section = "openrouter"
+	}
+	endpoint := cfg.Endpoint()
+	if endpoint.APIKey == "" {
+		check.Status, check.Detail, check.Fix = "fail", "no API key", "set "+cfg.APIKeyHint()
+		return check
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	url := strings.TrimRight(endpoint.BaseURL, "/") + "/models"
+	if cfg.Provider == "azure" {
+		version := cfg.Azure.APIVersion
+		if version == "" {
+			version = config.DefaultAzureAPIVersion
+		}
+		url = strings.TrimRight(endpoint.BaseURL, "/") + "/openai/models?api-version=" + version
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		check.Status, check.Detail, check.Fix = "fail", err.Error(), "check "+section+".base_url"
+		return check
+	}
+	switch cfg.Provider {
+	case "azure":
+		req.Header.Set("api-key", endpoint.APIKey)
+	case "anthropic":
+		// the models list is on the native API, which does not take bearer tokens
+		req.Header.Set("x-api-key", endpoint.APIKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	default:
+		req.Header.Set("Authorization", "Bearer "+endpoint.APIKey)
+	}
+	started := time.Now()
+	resp, err := system.HTTPClient(0).Do(req)
+	if err != nil {
+		check.Status, check.Detail, check.Fix = "fail", err.Error(), "check the network, "+section+".base_url and the http settings"
+		return check
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		check.Status, check.Detail, check.Fix = "fail", "the API key was rejected ("+resp.Status+")", "check "+section+".api_key"
+	case resp.StatusCode >= 300:
+		check.Status, check.Detail, check.Fix = "warn", url+" returned "+resp.Status, "check "+section+".base_url"
+	default:
+		check.Status, check.Detail = "ok", fmt.Sprintf("%s reachable in %s", endpoint.BaseURL, time.Since(started).Round(time.Millisecond))
+	}
+	return check
+}
+
+// doctorMcp connects to each configured MCP server and lists its tools
+func doctorMcp(cfg *config.Config) []DoctorCheck {
+	var checks []DoctorCheck
+	for _, server := range cfg.Mcp.Servers {
+		check := DoctorCheck{Name: "mcp " + server.Name}
+		client := NewMcpClient([]config.McpServer{server}, McpClientOptions{})
+		tools, err := client.ListTools(server.Name)
+		switch {
+		case !client.IsConnected(server.Name):
+			check.Status, check.Detail, check.Fix = "fail", "could not connect: "+client.Status()[0].LastError, "check the command or url of the server"
+		case err != nil:
+			check.Status, check.Detail, check.Fix = "fail", "listing tools failed: "+err.Error(), "check the server"
+		default:
+			check.Status, check.Detail = "ok", fmt.Sprintf("%d tools", len(tools))
+		}
+		client.Close()
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// doctorShell checks that /prepare supports the shell, and whether the exec pane it
+// would use already is prepared
+func doctorShell() DoctorCheck {
+	check := DoctorCheck{Name: "shell"}
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if !slices.Contains([]string{"bash", "zsh", "fish"}, shell) {
+		check.Status, check.Detail, check.Fix = "warn", fmt.Sprintf("%q is not supported by /prepare", shell), "use bash, zsh or fish in the exec pane to get exit codes and output"
+		return check
+	}
+	check.Status, check.Detail = "ok", shell+" is supported by /prepare"
+
+	paneId := os.Getenv("TMUX_PANE")
+	if paneId == "" {
+		return check
+	}
+	panes, _ := system.TmuxWindowPanes(paneId)
+	for _, pane := range panes {
+		if pane.Id == paneId {
+			continue
+		}
+		pane.Refresh(10)
+		if strings.HasSuffix(pane.LastLine, "]»") {
+			check.Detail += ", exec pane " + pane.Id + " is prepared"
+		} else {
+			check.Detail += ", exec pane " + pane.Id + " is not prepared"
+			check.Fix = "run /prepare to read exit codes and output"
+			check.Status = "warn"
+		}
+		return check
+	}
+	check.Detail += ", no exec pane yet"
+	return check
+}
+
+// handleDoctorCommand runs the doctor checks from the chat
+func handleDoctorCommand(ctx context.Context, m *Manager) {
+	PrintDoctorReport(os.Stdout, RunDoctor(ctx, m.Config, nil))
+}
@@ -0,0 +1,66 @@
+// Unit tests for the doctor checks in doctor.go
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: tmux version strings are read with their suffixes
+func TestTmuxVersionNumber(t *testing.T) {
+	tests := map[string]float64{"3.4": 3.4, "3.3a": 3.3, "next-3.5": 3.5, "2.9": 2.9}
+	for in, want := range tests {
+		if got, ok := tmuxVersionNumber(in); !ok || got != want {
+			t.Errorf("tmuxVersionNumber(%q) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+	if _, ok := tmuxVersionNumber("master"); ok {
+		t.Error("expected no number for master")
+	}
+}
+
+// Test: invalid config values are reported together
+func TestDoctorConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if check := doctorConfig(cfg, nil); check.Status != "ok" {
+		t.Errorf("defaults reported as %s: %s", check.Status, check.Detail)
+	}
+	cfg.LogLevel = "loud"
+	cfg.Interface = "gui"
+	check := doctorConfig(cfg, nil)
+	if check.Status != "fail" || !strings.Contains(check.Detail, "loud") || !strings.Contains(check.Detail, "gui") {
+		t.Errorf("unexpected check: %+v", check)
+	}
+}
+
+// Test: a rejected key fails, an accepted one passes
+func TestDoctorAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.OpenRouter.BaseURL = server.URL
+	cfg.OpenRouter.APIKey = "good"
+	if check := doctorAPI(context.Background(), cfg); check.Status != "ok" {
+		t.Errorf("good key reported as %+v", check)
+	}
+	cfg.OpenRouter.APIKey = "bad"
+	if check := doctorAPI(context.Background(), cfg); check.Status != "fail" || check.Fix == "" {
+		t.Errorf("bad key reported as %+v", check)
+	}
+	cfg.OpenRouter.APIKey = ""
+	if check := doctorAPI(context.Background(), cfg); check.Status != "fail" {
+		t.Errorf("missing key reported as %+v", check)
+	}
+}
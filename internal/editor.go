@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+)
+
+// EditorSelection is a range of lines sent from an editor plugin
+type EditorSelection struct {
+	File      string `json:"file"`
+	Filetype  string `json:"filetype,omitempty"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Text      string `json:"text"`
+}
+
+// EditResult is returned to the editor for an edit request
+type EditResult struct {
+	Replacement string `json:"replacement"`
+}
+
+const editorEditPrompt = `Rewrite the code below from %s (lines %d-%d, %s) according to the instruction.
+Reply with the replacement code only: no explanations and no code fences. Keep the original indentation style.
+
+Instruction: %s
+
+Code:
+%s`
+
+// String renders the selection for the model
+func (s EditorSelection) String() string {
+	return fmt.Sprintf("<editor_selection file=\"%s\" lines=\"%d-%d\" filetype=\"%s\">\n%s\n</editor_selection>\n",
+		s.File, s.StartLine, s.EndLine, s.Filetype, s.Text)
+}
+
+// dispatchEditor handles the editor integration commands of the control socket:
+// context adds the selection to the chat, ask sends a question about it and edit
+// returns a replacement for the selection without touching the conversation
+func (cs *ControlServer) dispatchEditor(req ControlRequest) ControlResponse {
+	sel := req.Selection
+	if sel == nil || strings.TrimSpace(sel.Text) == "" {
+		return ControlResponse{Error: req.Command + " requires a non-empty selection"}
+	}
+	m := cs.manager
+
+	switch req.Command {
+	case "context":
+		go m.addEditorContext(*sel)
+		return ControlResponse{OK: true}
+	case "ask":
+		if strings.TrimSpace(req.Args) == "" {
+			return ControlResponse{Error: "ask requires a question"}
+		}
+		go m.HandleExternalMessage("editor", req.Args+"\n\n"+sel.String())
+		return ControlResponse{OK: true}
+	default:
+		if strings.TrimSpace(req.Args) == "" {
+			return ControlResponse{Error: "edit requires an instruction"}
+		}
+		filetype := sel.Filetype
+		if filetype == "" {
+			filetype = "plain text"
+		}
+		replacement, err := m.generateFromPrompt(fmt.Sprintf(editorEditPrompt, sel.File, sel.StartLine, sel.EndLine, filetype, req.Args, sel.Text))
+		if err != nil {
+			return ControlResponse{Error: err.Error()}
+		}
+		m.Println(i18n.T("Proposed an edit for %s:%d-%d", sel.File, sel.StartLine, sel.EndLine))
+		result, _ := json.Marshal(EditResult{Replacement: replacement})
+		return ControlResponse{OK: true, Result: result}
+	}
+}
+
+// addEditorContext adds an editor selection to the chat history for the next turn
+func (m *Manager) addEditorContext(sel EditorSelection) {
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+
+	m.appendMessages(ChatMessage{
+		Content:   "Here is a selection from my editor, keep it in mind:\n" + sel.String(),
+		FromUser:  true,
+		Timestamp: time.Now(),
+	})
+	m.Println(i18n.T("Added %s:%d-%d from the editor to the context", sel.File, sel.StartLine, sel.EndLine))
+}
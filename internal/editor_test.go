@@ -0,0 +1,113 @@
+// Unit tests for the editor commands of the control socket in editor.go
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+var testSelection = &EditorSelection{File: "main.go", Filetype: "go", StartLine: 3, EndLine: 4, Text: "x := 1\ny := 2"}
+
+func newEditorTestServer(t *testing.T, responses ...string) *ControlServer {
+	t.Helper()
+	provider, err := NewMockProvider(config.MockConfig{Responses: responses})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManagerForPane(config.DefaultConfig(), "", provider)
+	m.Output = io.Discard
+	return &ControlServer{manager: m}
+}
+
+// waitForMessages waits until the manager has at least n messages and returns them
+func waitForMessages(t *testing.T, m *Manager, n int) []ChatMessage {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		messages := m.MessageHistory()
+		if len(messages) >= n {
+			return messages
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d messages, got %+v", n, messages)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Test: the editor commands reject a missing selection, question or instruction
+func TestDispatchEditorInvalid(t *testing.T) {
+	cs := newEditorTestServer(t)
+	cases := []struct {
+		req ControlRequest
+		err string
+	}{
+		{ControlRequest{Command: "context"}, "context requires a non-empty selection"},
+		{ControlRequest{Command: "ask", Args: "why?", Selection: &EditorSelection{File: "a.go", Text: "  "}}, "ask requires a non-empty selection"},
+		{ControlRequest{Command: "ask", Selection: testSelection}, "ask requires a question"},
+		{ControlRequest{Command: "edit", Args: " ", Selection: testSelection}, "edit requires an instruction"},
+	}
+	for _, c := range cases {
+		resp := cs.dispatch(c.req)
+		if resp.OK || resp.Error != c.err {
+			t.Errorf("%s: expected error %q, got %+v", c.req.Command, c.err, resp)
+		}
+	}
+	if len(cs.manager.MessageHistory()) != 0 {
+		t.Errorf("expected no messages, got %+v", cs.manager.MessageHistory())
+	}
+}
+
+// Test: context adds the selection to the chat without asking the model
+func TestDispatchEditorContext(t *testing.T) {
+	cs := newEditorTestServer(t)
+	if resp := cs.dispatch(ControlRequest{Command: "context", Selection: testSelection}); !resp.OK {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	messages := waitForMessages(t, cs.manager, 1)
+	if len(messages) != 1 || !messages[0].FromUser || !strings.Contains(messages[0].Content, testSelection.String()) {
+		t.Errorf("expected the selection as a user message, got %+v", messages)
+	}
+}
+
+// Test: ask runs a turn for the question together with the selection
+func TestDispatchEditorAsk(t *testing.T) {
+	cs := newEditorTestServer(t, "It sets x and y\n<RequestAccomplished>1</RequestAccomplished>")
+	if resp := cs.dispatch(ControlRequest{Command: "ask", Args: "what does this do?", Selection: testSelection}); !resp.OK {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	messages := waitForMessages(t, cs.manager, 2)
+	// let the turn finish before the test ends
+	cs.manager.turnMu.Lock()
+	cs.manager.turnMu.Unlock()
+	if !messages[0].FromUser || !strings.Contains(messages[0].Content, "what does this do?\n\n"+testSelection.String()) {
+		t.Errorf("expected the question with the selection, got %+v", messages[0])
+	}
+	if messages[1].FromUser || !strings.Contains(messages[1].Content, "It sets x and y") {
+		t.Errorf("expected the answer, got %+v", messages[1])
+	}
+}
+
+// Test: edit returns the replacement and leaves the conversation alone
+func TestDispatchEditorEdit(t *testing.T) {
+	cs := newEditorTestServer(t, "x := 10\ny := 20")
+	resp := cs.dispatch(ControlRequest{Command: "edit", Args: "multiply by ten", Selection: testSelection})
+	if !resp.OK {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	var result EditResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("invalid result: %v", err)
+	}
+	if result.Replacement != "x := 10\ny := 20" {
+		t.Errorf("unexpected replacement: %q", result.Replacement)
+	}
+	if len(cs.manager.MessageHistory()) != 0 {
+		t.Errorf("expected no messages, got %+v", cs.manager.MessageHistory())
+	}
+}
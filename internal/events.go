@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event types emitted in --json mode
+const (
+	EventUserMessage  = "user_message"
+	EventAIResponse   = "ai_response"
+	EventConfirmation = "confirmation"
+	EventExec         = "exec"
+	EventExecOutput   = "exec_output"
+	EventSendKeys     = "send_keys"
+	EventPaste        = "paste"
+	EventFileWrite    = "file_write"
+	EventFileRead     = "file_read"
+	EventFetch        = "fetch"
+	EventToolCall     = "tool_call"
+	EventError        = "error"
+)
+
+// Event is a single machine-readable event, written as one JSON line
+type Event struct {
+	Type      string                 `json:"type"`
+	Timestamp time.Time              `json:"timestamp"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+type eventListener struct {
+	id int
+	fn func(Event)
+}
+
+var (
+	eventMu        sync.Mutex
+	eventOut       io.Writer
+	eventListeners []eventListener
+	lastListenerId int
+)
+
+// EnableJSONEvents switches to --json mode: events are written as JSON lines to
+// stdout and the human-readable output goes to stderr, see humanOutput
+func EnableJSONEvents() {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	eventOut = os.Stdout
+}
+
+// humanOutput is where human-readable output goes without a manager's Output: stderr
+// in --json mode, so stdout only carries the events, and stdout otherwise
+func humanOutput() io.Writer {
+	if JSONEventsEnabled() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// JSONEventsEnabled reports whether --json mode is active
+func JSONEventsEnabled() bool {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	return eventOut != nil
+}
+
+// AddEventListener registers fn to be called for every emitted event and returns a
+// func that removes it again
+func AddEventListener(fn func(Event)) (remove func()) {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	lastListenerId++
+	id := lastListenerId
+	eventListeners = append(eventListeners, eventListener{id: id, fn: fn})
+	return func() {
+		eventMu.Lock()
+		defer eventMu.Unlock()
+		eventListeners = slices.DeleteFunc(eventListeners, func(l eventListener) bool { return l.id == id })
+	}
+}
+
+// emitEvent passes an event to the listeners and writes it when --json mode is active
+func emitEvent(eventType string, data map[string]interface{}) {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	event := Event{Type: eventType, Timestamp: time.Now(), Data: data}
+	for _, l := range eventListeners {
+		l.fn(event)
+	}
+	if eventOut == nil {
+		return
+	}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	eventOut.Write(append(line, '\n'))
+}
+
+// emitAIResponse emits the parsed fields of an AI response
+func emitAIResponse(r AIResponse) {
+	emitEvent(EventAIResponse, map[string]interface{}{
+		"message":                   r.Message,
+		"exec_command":              r.ExecCommand,
+		"send_keys":                 r.SendKeys,
+		"paste_multiline_content":   r.PasteMultilineContent,
+		"request_accomplished":      r.RequestAccomplished,
+		"exec_pane_seems_busy":      r.ExecPaneSeemsBusy,
+		"waiting_for_user_response": r.WaitingForUserResponse,
+		"no_comment":                r.NoComment,
+		"file_edits":                r.FileEdits,
+		"file_patches":              r.FilePatches,
+		"file_reads":                r.FileReads,
+		"fetch_urls":                r.FetchURLs,
+		"plugin_tool_calls":         r.PluginToolCalls,
+		"delegations":               r.Delegations,
+	})
+}
+
+// emitConfirmation emits the outcome of a confirmation prompt
+func emitConfirmation(prompt, content string, approved bool) {
+	emitEvent(EventConfirmation, map[string]interface{}{
+		"prompt":   prompt,
+		"content":  content,
+		"approved": approved,
+	})
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,59 @@
+// Unit tests for the --json event stream in events.go
+package internal
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: --json mode leaves os.Stdout alone and sends the human output to stderr
+func TestEnableJSONEventsOutput(t *testing.T) {
+	stdout := os.Stdout
+	defer func() {
+		eventMu.Lock()
+		eventOut = nil
+		eventMu.Unlock()
+	}()
+
+	m := NewManagerForPane(config.DefaultConfig(), "", nil)
+	if m.out() != os.Stdout {
+		t.Errorf("expected the human output on stdout")
+	}
+	EnableJSONEvents()
+	if os.Stdout != stdout {
+		t.Errorf("expected os.Stdout to stay the same")
+	}
+	if m.out() != os.Stderr {
+		t.Errorf("expected the human output on stderr in --json mode")
+	}
+}
+
+// Test: a manager with Output set prints there
+func TestManagerOutput(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewManagerForPane(config.DefaultConfig(), "", nil)
+	m.Output = &buf
+	m.Println("hello")
+	if !strings.Contains(buf.String(), "hello") {
+		t.Errorf("expected the message in Output, got %q", buf.String())
+	}
+}
+
+// Test: a removed listener gets no more events and the others keep theirs
+func TestAddEventListenerRemove(t *testing.T) {
+	var first, second int
+	removeFirst := AddEventListener(func(Event) { first++ })
+	removeSecond := AddEventListener(func(Event) { second++ })
+	defer removeSecond()
+
+	emitEvent(EventError, nil)
+	removeFirst()
+	emitEvent(EventError, nil)
+	if first != 1 || second != 2 {
+		t.Errorf("expected 1 and 2 events, got %d and %d", first, second)
+	}
+}
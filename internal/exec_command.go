@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+)
+
+// ExecResult is returned to `tmuxai exec` once the command ran in the exec pane
+type ExecResult struct {
+	Command  string `json:"command"`
+	ExitCode *int   `json:"exit_code,omitempty"` // only known when the exec pane is prepared
+	Output   string `json:"output,omitempty"`
+}
+
+// execExternal runs a command given to `tmuxai exec` in the exec pane and records it in
+// the chat history so the model knows about it. The on_exec hooks, command rules and
+// exec_confirm of this session apply, confirmations are asked in the chat pane.
+func (m *Manager) execExternal(command string) (ExecResult, error) {
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+	m.ready()
+
+	fmt.Fprintf(m.out(), "\n%s[exec] %s\n", m.GetPrompt(), command)
+	m.SetStatus("running")
+	defer func() { m.SetStatus("") }()
+
+	if m.Scripts != nil {
+		original := command
+		var allowed bool
+		if command, allowed = m.Scripts.ProcessExec(command); !allowed {
+			m.Println(i18n.T("Command blocked by an on_exec hook: %s", command))
+			m.audit("exec", original, auditBlocked, nil)
+			return ExecResult{}, fmt.Errorf("blocked by an on_exec hook")
+		}
+	}
+	action, rule := m.decideRules(ruleTargetExec, command)
+	if action == ruleBlock {
+		m.Println(i18n.T("Blocked by %s", rule))
+		m.audit("exec", command, auditBlocked, nil)
+		return ExecResult{}, fmt.Errorf("blocked by %s", rule)
+	}
+	decision := auditAuto
+	if m.confirmRequired(action, m.GetExecConfirm()) {
+		approved, edited := m.confirmedToExec(command, confirmExecPrompt, true)
+		emitConfirmation(confirmExecPrompt, command, approved)
+		m.stats.recordConfirmation(approved)
+		if !approved {
+			m.audit("exec", command, auditDeclined, nil)
+			return ExecResult{}, fmt.Errorf("command not confirmed")
+		}
+		command, decision = edited, auditApproved
+	}
+
+	executed, err := m.execInPane(context.Background(), command, "Run with tmuxai exec")
+	if err != nil {
+		m.Println(err.Error())
+		m.audit("exec", command, auditBlocked, nil)
+		return ExecResult{}, err
+	}
+	m.audit("exec", command, decision, executed.Code)
+
+	content := "I ran this command in the exec pane with tmuxai exec:\n" + command
+	if executed.Code != nil {
+		content += fmt.Sprintf("\nIt exited with code %d and printed:\n%s", *executed.Code, strings.TrimSpace(executed.Output))
+	}
+	m.appendMessages(ChatMessage{Content: content, FromUser: true, Timestamp: time.Now()})
+
+	return ExecResult{Command: command, ExitCode: executed.Code, Output: executed.Output}, nil
+}
+
+// dispatchExec handles the exec command of the control socket
+func (cs *ControlServer) dispatchExec(req ControlRequest) ControlResponse {
+	if strings.TrimSpace(req.Args) == "" {
+		return ControlResponse{Error: "exec requires a command"}
+	}
+	result, err := cs.manager.execExternal(req.Args)
+	if err != nil {
+		return ControlResponse{Error: err.Error()}
+	}
+	data, _ := json.Marshal(result)
+	return ControlResponse{OK: true, Result: data}
+}
@@ -0,0 +1,105 @@
+// Unit tests for commands run with tmuxai exec in exec_command.go
+package internal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: the running instance applies its own rules and confirmation to tmuxai exec
+func TestDispatchExec(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ExecConfirm = true
+	cfg.CommandRules = []config.CommandRule{{Pattern: `^rm\s`, Action: "block"}}
+	m := NewManagerForPane(cfg, "", nil)
+	var asked []string
+	m.ConfirmFunc = func(content, prompt string) (bool, string) {
+		asked = append(asked, content)
+		return false, ""
+	}
+	cs := &ControlServer{manager: m}
+
+	cases := []struct {
+		args, err string
+	}{
+		{"  ", "exec requires a command"},
+		{"rm -rf /", "blocked by command_rules ^rm\\s"},
+		{"make deploy", "command not confirmed"},
+	}
+	for _, c := range cases {
+		resp := cs.dispatch(ControlRequest{Command: "exec", Args: c.args})
+		if resp.OK || !strings.Contains(resp.Error, c.err) {
+			t.Errorf("exec %q: expected error %q, got %+v", c.args, c.err, resp)
+		}
+	}
+	if len(asked) != 1 || asked[0] != "make deploy" {
+		t.Errorf("expected only make deploy to be confirmed, asked %v", asked)
+	}
+	if len(m.ExecutedCommands) != 0 {
+		t.Errorf("expected nothing to run, got %+v", m.ExecutedCommands)
+	}
+}
+
+// Test: the on_exec hooks apply to tmuxai exec before the rules, a rewrite included
+func TestDispatchExecHooks(t *testing.T) {
+	dir := t.TempDir()
+	hook := `
+def guard(cmd):
+    if cmd.startswith("curl "):
+        return False
+    return cmd.replace("deploy", "rm -rf build")
+
+on_exec(guard)
+`
+	if err := os.WriteFile(filepath.Join(dir, "guard.star"), []byte(hook), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.CommandRules = []config.CommandRule{{Pattern: `^rm\s`, Action: "block"}}
+	m := NewManagerForPane(cfg, "", nil)
+	m.Output = io.Discard
+	m.Scripts = LoadScripts(m, dir)
+	cs := &ControlServer{manager: m}
+
+	cases := []struct {
+		args, err string
+	}{
+		{"curl https://example.com", "blocked by an on_exec hook"},
+		{"deploy", "blocked by command_rules ^rm\\s"},
+	}
+	for _, c := range cases {
+		resp := cs.dispatch(ControlRequest{Command: "exec", Args: c.args})
+		if resp.OK || !strings.Contains(resp.Error, c.err) {
+			t.Errorf("exec %q: expected error %q, got %+v", c.args, c.err, resp)
+		}
+	}
+}
+
+// Test: tmuxai exec waits for the background startup, which sets up the exec pane
+func TestDispatchExecWaitsForStartup(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.CommandRules = []config.CommandRule{{Pattern: `^curl\s`, Action: "block"}}
+	m := NewManagerForPane(cfg, "", nil)
+	m.Output = io.Discard
+	startup := make(chan struct{})
+	m.startupDone = startup
+	cs := &ControlServer{manager: m}
+
+	done := make(chan ControlResponse, 1)
+	go func() { done <- cs.dispatch(ControlRequest{Command: "exec", Args: "curl https://example.com"}) }()
+	select {
+	case resp := <-done:
+		t.Fatalf("expected exec to wait for the startup, got %+v", resp)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(startup)
+	if resp := <-done; resp.OK || !strings.Contains(resp.Error, "blocked by command_rules") {
+		t.Errorf("expected the command to be blocked after the startup, got %+v", resp)
+	}
+}
@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// hookPayload is written as JSON to the stdin of every hook
+type hookPayload struct {
+	Event    string `json:"event"` // pre_exec, post_exec or watch (a /watch --command)
+	Command  string `json:"command"`
+	Pane     string `json:"pane"`
+	Cwd      string `json:"cwd"`
+	ExitCode *int   `json:"exit_code,omitempty"` // only known when the pane is prepared
+	Output   string `json:"output,omitempty"`
+}
+
+// runPreExecHooks runs the pre_exec hooks; the first failing hook blocks the command
+func (m *Manager) runPreExecHooks(command string) error {
+	if len(m.Config.Hooks.PreExec) == 0 {
+		return nil
+	}
+	payload := hookPayload{Event: "pre_exec", Command: command, Pane: m.ExecPane.Id, Cwd: m.execPaneCwd()}
+	for _, hook := range m.Config.Hooks.PreExec {
+		if out, err := m.runHook(hook, payload); err != nil {
+			if msg := strings.TrimSpace(out); msg != "" {
+				return fmt.Errorf("%s: %s", hook, msg)
+			}
+			return fmt.Errorf("%s: %w", hook, err)
+		}
+	}
+	return nil
+}
+
+// runPostExecHooks runs the post_exec hooks, failures are only logged
+func (m *Manager) runPostExecHooks(command string, exitCode *int, output string) {
+	if len(m.Config.Hooks.PostExec) == 0 {
+		return
+	}
+	payload := hookPayload{
+		Event:    "post_exec",
+		Command:  command,
+		Pane:     m.ExecPane.Id,
+		Cwd:      m.execPaneCwd(),
+		ExitCode: exitCode,
+		Output:   output,
+	}
+	for _, hook := range m.Config.Hooks.PostExec {
+		if out, err := m.runHook(hook, payload); err != nil {
+			logger.Error("post_exec hook %q failed: %v %s", hook, err, out)
+		}
+	}
+}
+
+// runHook runs a hook through sh with the payload as env vars and JSON on stdin
+func (m *Manager) runHook(hook string, payload hookPayload) (string, error) {
+	timeout := time.Duration(m.Config.Hooks.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	input, _ := json.Marshal(payload)
+	exitCode := ""
+	if payload.ExitCode != nil {
+		exitCode = strconv.Itoa(*payload.ExitCode)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(),
+		"TMUXAI_HOOK="+payload.Event,
+		"TMUXAI_COMMAND="+payload.Command,
+		"TMUXAI_PANE="+payload.Pane,
+		"TMUXAI_CWD="+payload.Cwd,
+		"TMUXAI_EXIT_CODE="+exitCode,
+	)
+	out, err := cmd.CombinedOutput()
+	logger.Debug("Hook %s %q exited: %v", payload.Event, hook, err)
+	return string(out), err
+}
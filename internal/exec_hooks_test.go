@@ -0,0 +1,47 @@
+// Unit tests for pre/post exec hooks in exec_hooks.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Test: a failing pre_exec hook blocks the command and reports its output
+func TestRunPreExecHooks_Blocks(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Hooks.PreExec = []string{`case "$TMUXAI_COMMAND" in rm*) echo "no rm"; exit 1;; esac`}
+	m := &Manager{Config: cfg, ExecPane: &system.TmuxPaneDetails{}}
+
+	if err := m.runPreExecHooks("ls -la"); err != nil {
+		t.Errorf("expected ls to pass, got %v", err)
+	}
+	err := m.runPreExecHooks("rm -rf build")
+	if err == nil || !strings.Contains(err.Error(), "no rm") {
+		t.Errorf("expected rm to be blocked with the hook output, got %v", err)
+	}
+}
+
+// Test: post_exec hooks receive the exit code and the JSON payload on stdin
+func TestRunPostExecHooks_Payload(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.out")
+	cfg := config.DefaultConfig()
+	cfg.Hooks.PostExec = []string{`echo "$TMUXAI_EXIT_CODE" > ` + out + ` && cat >> ` + out}
+	m := &Manager{Config: cfg, ExecPane: &system.TmuxPaneDetails{}}
+
+	code := 2
+	m.runPostExecHooks("make test", &code, "FAIL")
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if !strings.HasPrefix(got, "2\n") || !strings.Contains(got, `"command":"make test"`) || !strings.Contains(got, `"output":"FAIL"`) {
+		t.Errorf("unexpected hook input: %q", got)
+	}
+}
@@ -1,7 +1,8 @@
 package internal
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -16,7 +17,7 @@ import (
 func (m *Manager) GetAvailablePane() system.TmuxPaneDetails {
 	panes, _ := m.GetTmuxPanes()
 	for _, pane := range panes {
-		if !pane.IsTmuxAiPane {
+		if !pane.IsTmuxAiPane && !m.isContextPane(pane.Id) {
 			logger.Info("Found available pane: %s", pane.Id)
 			return pane
 		}
@@ -59,21 +60,79 @@ func (m *Manager) PrepareExecPane() {
 	system.TmuxSendCommandToPane(m.ExecPane.Id, "C-l", false)
 }
 
-func (m *Manager) ExecWaitCapture(command string) (CommandExecHistory, error) {
+// execInPane runs a confirmed command in the exec pane with the exec hooks around it
+// and records it for /export-script with the explanation it was run for.
+// In a prepared pane it waits for the command to finish and captures its exit code and output,
+// unless ctx is cancelled first.
+func (m *Manager) execInPane(ctx context.Context, command, explanation string) (ExecutedCommand, error) {
+	if err := m.runPreExecHooks(command); err != nil {
+		return ExecutedCommand{}, fmt.Errorf("Command blocked by a pre_exec hook: %w", err)
+	}
+	m.startStep(command)
+	emitEvent(EventExec, map[string]interface{}{"command": command})
+
+	executed := ExecutedCommand{
+		Command:     command,
+		Explanation: explanation,
+		Cwd:         m.execPaneCwd(),
+		Timestamp:   time.Now(),
+	}
+
+	if !m.ExecPane.IsPrepared {
+		system.TmuxSendCommandToPane(m.ExecPane.Id, command, true)
+		_ = sleepContext(ctx, time.Second)
+		m.ExecutedCommands = append(m.ExecutedCommands, executed)
+		m.finishStep(nil)
+		m.runPostExecHooks(command, nil, "")
+		return executed, nil
+	}
+
+	result, err := m.ExecWaitCapture(ctx, command)
+	if err != nil {
+		m.finishStep(nil)
+		return executed, err
+	}
+	executed.Code = &result.Code
+	executed.Output = result.Output
+	m.ExecutedCommands = append(m.ExecutedCommands, executed)
+	m.finishStep(&result.Code)
+	emitEvent(EventExecOutput, map[string]interface{}{
+		"command": result.Command,
+		"output":  result.Output,
+		"code":    result.Code,
+	})
+	m.runPostExecHooks(command, &result.Code, result.Output)
+	return executed, nil
+}
+
+// ExecWaitCapture runs command in the prepared exec pane and waits for its prompt to
+// come back. When ctx is cancelled it stops waiting and returns the context's error;
+// the command itself keeps running in the pane.
+func (m *Manager) ExecWaitCapture(ctx context.Context, command string) (CommandExecHistory, error) {
 	system.TmuxSendCommandToPane(m.ExecPane.Id, command, true)
 	m.ExecPane.Refresh(m.GetMaxCaptureLines())
 
 	m.Println("")
 
-	animChars := []string{"⋯", "⋱", "⋮", "⋰"}
+	animChars := []string{system.Sym("⋯"), system.Sym("⋱"), system.Sym("⋮"), system.Sym("⋰")}
 	animIndex := 0
-	for !strings.HasSuffix(m.ExecPane.LastLine, "]»") && m.Status != "" {
-		fmt.Printf("\r%s%s ", m.GetPrompt(), animChars[animIndex])
+	animate := system.CursorControl()
+	for !strings.HasSuffix(m.ExecPane.LastLine, "]»") && m.GetStatus() != "" {
+		if animate {
+			fmt.Fprintf(m.out(), "\r%s%s ", m.GetPrompt(), animChars[animIndex])
+		}
 		animIndex = (animIndex + 1) % len(animChars)
-		time.Sleep(500 * time.Millisecond)
+		if sleepContext(ctx, 500*time.Millisecond) != nil {
+			break
+		}
 		m.ExecPane.Refresh(m.GetMaxCaptureLines())
 	}
-	fmt.Print("\r\033[K")
+	if animate {
+		fmt.Fprint(m.out(), "\r\033[K")
+	}
+	if err := ctx.Err(); err != nil {
+		return CommandExecHistory{}, err
+	}
 
 	m.parseExecPaneCommandHistory()
 	cmd := m.ExecHistory[len(m.ExecHistory)-1]
@@ -81,24 +140,28 @@ func (m *Manager) ExecWaitCapture(command string) (CommandExecHistory, error) {
 	return cmd, nil
 }
 
-func (m *Manager) parseExecPaneCommandHistory() {
-	m.ExecPane.Refresh(m.GetMaxCaptureLines())
+// execPromptRe matches the prepared prompt: status code (group 1), optionally the command
+// (group 2). Making the command part optional handles prompts that only show status (like
+// the last line). ` ?` allows zero or one space after »
+var execPromptRe = regexp.MustCompile(`.*\[(\d+)\]» ?(.*)$`)
 
+// parseExecHistory splits the capture of a prepared exec pane into the commands run in
+// it, with their output and exit code
+func parseExecHistory(content string) []CommandExecHistory {
 	var history []CommandExecHistory
 
 	var currentCommand *CommandExecHistory
-	var outputBuilder strings.Builder
-
-	// Regex: Capture status code (group 1), optionally capture command (group 2)
-	// Making the command part optional handles prompts that only show status (like the last line).
-	// ` ?` allows zero or one space after »
-	promptRegex := regexp.MustCompile(`.*\[(\d+)\]» ?(.*)$`)
-
-	scanner := bufio.NewScanner(strings.NewReader(m.ExecPane.Content))
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		match := promptRegex.FindStringSubmatch(line)
+	// a bytes.Buffer keeps its memory across Reset, unlike strings.Builder
+	var outputBuilder bytes.Buffer
+
+	// lines are substrings of content, nothing is copied until a command's output is kept
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		var match []string
+		// only prompt lines can match, skip the regexp for plain output
+		if strings.Contains(line, "]»") {
+			match = execPromptRe.FindStringSubmatch(line)
+		}
 
 		if match != nil && len(match) >= 2 { // We need at least the status code match[1]
 			// --- Found a prompt line ---
@@ -117,7 +180,7 @@ func (m *Manager) parseExecPaneCommandHistory() {
 				statusCode, err := strconv.Atoi(statusCodeStr)
 				if err != nil {
 					// This shouldn't happen with \d+ regex but check anyway
-					fmt.Printf("Warning: Could not parse status code '%s' for previous command on line: %s\n", statusCodeStr, line)
+					fmt.Fprintf(humanOutput(), "Warning: Could not parse status code '%s' for previous command on line: %s\n", statusCodeStr, line)
 					currentCommand.Code = -1 // Indicate parsing error
 				} else {
 					currentCommand.Code = statusCode // Assign correct status
@@ -173,10 +236,14 @@ func (m *Manager) parseExecPaneCommandHistory() {
 		history = append(history, *currentCommand)
 	}
 
-	if err := scanner.Err(); err != nil {
-		logger.Error("error reading input: %v", err)
-	}
+	return history
+}
+
+func (m *Manager) parseExecPaneCommandHistory() {
+	m.ExecPane.Refresh(m.GetMaxCaptureLines())
+	history := parseExecHistory(m.ExecPane.Content)
 
 	// Update the manager's command history
 	m.ExecHistory = history
+	m.spillHistory()
 }
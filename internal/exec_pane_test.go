@@ -0,0 +1,54 @@
+// Unit tests and benchmarks for exec history parsing in exec_pane.go
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// preparedCapture returns a prepared exec pane capture with commands commands of
+// outputLines lines each, ending in an idle prompt
+func preparedCapture(commands, outputLines int) string {
+	var b strings.Builder
+	for i := 0; i < commands; i++ {
+		fmt.Fprintf(&b, "[~/src][12:00][%d]» ls dir%d\n", i%2, i)
+		for j := 0; j < outputLines; j++ {
+			fmt.Fprintf(&b, "file-%d-%d.txt  another-file-%d.go  README.md\n", i, j, j)
+		}
+	}
+	b.WriteString("[~/src][12:01][1]»")
+	return b.String()
+}
+
+// Test: commands get the output up to the next prompt and the exit code shown there
+func TestParseExecHistory(t *testing.T) {
+	content := "Last login: today\n[~][0]» echo hi\nhi\n[~][0]» false\n[~][1]» make\nbuilding\ndone\n[~][2]»"
+	want := []CommandExecHistory{
+		{Command: "echo hi", Output: "hi", Code: 0},
+		{Command: "false", Output: "", Code: 1},
+		{Command: "make", Output: "building\ndone", Code: 2},
+	}
+	if got := parseExecHistory(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// Test: a command still running has no exit code yet
+func TestParseExecHistory_Running(t *testing.T) {
+	got := parseExecHistory("[~][0]» sleep 10\nzzz")
+	if len(got) != 1 || got[0].Code != -1 || got[0].Output != "zzz" {
+		t.Errorf("unexpected history: %+v", got)
+	}
+}
+
+// BenchmarkParseExecHistory parses a 200-line capture, what watch and observe mode do
+// every few seconds
+func BenchmarkParseExecHistory(b *testing.B) {
+	content := preparedCapture(20, 9)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parseExecHistory(content)
+	}
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const maxExplainOutputLines = 300
+
+const explainPrompt = `Explain what happened when this command ran in the exec pane: what the output means and, if it failed, the likely cause.
+Then suggest the most likely next steps. Don't run anything unless I ask.
+
+Command: %s
+Exit code: %d
+Output:
+%s`
+
+// explainMessage builds the request for the nth most recent entry of history, 1 being the last
+func explainMessage(history []CommandExecHistory, n int) (string, error) {
+	if len(history) == 0 {
+		return "", fmt.Errorf("%s", i18n.T("No command output to explain yet, /prepare the exec pane so commands and their output are tracked"))
+	}
+	if n < 1 || n > len(history) {
+		return "", fmt.Errorf("%s", i18n.T("Usage: /explain [n], n from 1 (the last command) to %d", len(history)))
+	}
+	h := history[len(history)-n]
+	return fmt.Sprintf(explainPrompt, h.Command, h.Code, system.LimitLines(h.Output, maxExplainOutputLines)), nil
+}
+
+// handleExplainCommand asks the model about the last (or nth last) command of the exec
+// pane and its output, as a turn of the conversation so it can be followed up
+func handleExplainCommand(ctx context.Context, m *Manager, args []string) {
+	n := 1
+	if len(args) > 0 {
+		v, err := strconv.Atoi(args[0])
+		if err != nil {
+			m.Println(i18n.T("Usage: /explain [n]"))
+			return
+		}
+		n = v
+	}
+	if m.ExecPane.IsPrepared {
+		// picks up the commands the user ran themselves since
+		m.parseExecPaneCommandHistory()
+	}
+	message, err := explainMessage(m.ExecHistory, n)
+	if err != nil {
+		m.Println(err.Error())
+		return
+	}
+	m.runRequest(ctx, message)
+}
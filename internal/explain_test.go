@@ -0,0 +1,30 @@
+// Unit tests for /explain in explain.go
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test: n counts back from the last command, out of range and empty history are errors
+func TestExplainMessage(t *testing.T) {
+	history := []CommandExecHistory{
+		{Command: "make build", Output: "ok", Code: 0},
+		{Command: "make test", Output: "FAIL: TestParse", Code: 2},
+	}
+	msg, err := explainMessage(history, 1)
+	if err != nil || !strings.Contains(msg, "Command: make test\nExit code: 2\nOutput:\nFAIL: TestParse") {
+		t.Errorf("unexpected message %q, %v", msg, err)
+	}
+	if msg, _ := explainMessage(history, 2); !strings.Contains(msg, "Command: make build") {
+		t.Errorf("unexpected message %q", msg)
+	}
+	for _, n := range []int{0, 3} {
+		if _, err := explainMessage(history, n); err == nil {
+			t.Errorf("expected an error for n=%d", n)
+		}
+	}
+	if _, err := explainMessage(nil, 1); err == nil {
+		t.Error("expected an error without history")
+	}
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// renderScript turns the executed commands into a runnable shell script, with the
+// AI's explanations as comments
+func renderScript(commands []ExecutedCommand, generated time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env sh\n")
+	sb.WriteString(fmt.Sprintf("# Generated by TmuxAI on %s\n", generated.Format("2006-01-02 15:04:05")))
+	sb.WriteString("# Replays the commands executed during the session. Review before running.\n")
+
+	if len(commands) > 0 && commands[0].Cwd != "" {
+		sb.WriteString("\ncd " + shellQuote(commands[0].Cwd) + " || exit 1\n")
+	}
+
+	lastExplanation := ""
+	for _, cmd := range commands {
+		explanation := strings.TrimSpace(cmd.Explanation)
+		if explanation != "" && explanation != lastExplanation {
+			sb.WriteString("\n")
+			for _, line := range strings.Split(explanation, "\n") {
+				sb.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+			}
+		}
+		lastExplanation = explanation
+
+		if cmd.Code != nil && *cmd.Code != 0 {
+			sb.WriteString(fmt.Sprintf("# exited with code %d during the session\n", *cmd.Code))
+		}
+		sb.WriteString(cmd.Command + "\n")
+	}
+	return sb.String()
+}
+
+// handleExportScriptCommand writes the executed commands to a shell script
+func handleExportScriptCommand(m *Manager, args []string) {
+	if len(m.ExecutedCommands) == 0 {
+		m.Println(i18n.T("No commands have been executed in this session yet"))
+		return
+	}
+
+	now := time.Now()
+	path := fmt.Sprintf("tmuxai-session-%s.sh", now.Format("20060102-150405"))
+	if len(args) > 0 {
+		path = args[0]
+	}
+	if !filepath.IsAbs(path) {
+		if cwd := m.execPaneCwd(); cwd != "" {
+			path = filepath.Join(cwd, path)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(renderScript(m.ExecutedCommands, now)), 0o755); err != nil {
+		m.Println(i18n.T("Failed to write script: %v", err))
+		return
+	}
+	m.Println(i18n.T("Exported %d commands to %s", len(m.ExecutedCommands), path))
+}
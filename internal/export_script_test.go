@@ -0,0 +1,34 @@
+// Unit tests for /export-script rendering in export_script.go
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test: explanations become comments once per group and failed commands are annotated
+func TestRenderScript(t *testing.T) {
+	code := 2
+	commands := []ExecutedCommand{
+		{Command: "ls -la", Explanation: "Let's look around.\nThen build.", Cwd: "/tmp/it's"},
+		{Command: "make", Explanation: "Let's look around.\nThen build.", Code: &code},
+		{Command: "make test", Explanation: "Run the tests"},
+	}
+	got := renderScript(commands, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	expected := []string{
+		"#!/usr/bin/env sh\n",
+		"cd '/tmp/it'\\''s' || exit 1\n",
+		"# Let's look around.\n# Then build.\nls -la\n# exited with code 2 during the session\nmake\n",
+		"\n# Run the tests\nmake test\n",
+	}
+	for _, want := range expected {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected script to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, "# Then build.") != 1 {
+		t.Errorf("expected the shared explanation once, got:\n%s", got)
+	}
+}
@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+const exportUsage = "Usage: /export md|json|html [path]"
+
+// transcriptEntry is a chat message or an executed command of an exported transcript
+type transcriptEntry struct {
+	Kind      string    `json:"kind"` // "message" or "command"
+	Timestamp time.Time `json:"timestamp"`
+	Role      string    `json:"role,omitempty"` // "user" or "assistant"
+	Content   string    `json:"content,omitempty"`
+	Actions   []string  `json:"actions,omitempty"` // what the AI did besides running commands
+	Command   string    `json:"command,omitempty"`
+	Cwd       string    `json:"cwd,omitempty"`
+	ExitCode  *int      `json:"exit_code,omitempty"`
+	Output    string    `json:"output,omitempty"`
+}
+
+// transcript is the whole session in order, as /export writes it
+type transcript struct {
+	ExportedAt time.Time         `json:"exported_at"`
+	Model      string            `json:"model"`
+	Entries    []transcriptEntry `json:"entries"`
+}
+
+// transcript collects the conversation, including what was moved to the session store,
+// and the executed commands with their output. AI responses are reduced to their message
+// and actions; the commands they ran follow as entries of their own.
+func (m *Manager) transcript(now time.Time) transcript {
+	var messages []ChatMessage
+	if m.store != nil {
+		stored, err := m.store.entries()
+		if err != nil {
+			logger.Error("Failed to read the session store: %v", err)
+		}
+		for _, e := range stored {
+			if e.Kind == "message" {
+				messages = append(messages, ChatMessage{Content: e.Content, FromUser: e.FromUser, Timestamp: e.Timestamp})
+			}
+		}
+	}
+	messages = append(messages, m.Messages...)
+
+	var entries []transcriptEntry
+	for _, msg := range messages {
+		entry := transcriptEntry{Kind: "message", Timestamp: msg.Timestamp, Role: "user", Content: strings.TrimSpace(msg.Content)}
+		if !msg.FromUser {
+			entry.Role = "assistant"
+			if r, err := m.parseAIResponse(msg.Content); err == nil {
+				entry.Content = r.Message
+				for _, action := range replayActions(r) {
+					if !strings.HasPrefix(action, "message: ") && !strings.HasPrefix(action, "exec: ") {
+						entry.Actions = append(entry.Actions, action)
+					}
+				}
+			}
+		}
+		if entry.Content != "" || len(entry.Actions) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	for _, cmd := range m.ExecutedCommands {
+		entries = append(entries, transcriptEntry{
+			Kind:      "command",
+			Timestamp: cmd.Timestamp,
+			Command:   cmd.Command,
+			Cwd:       cmd.Cwd,
+			ExitCode:  cmd.Code,
+			Output:    strings.TrimRight(cmd.Output, "\n"),
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.Before(entries[j].Timestamp) })
+	return transcript{ExportedAt: now, Model: m.GetOpenRouterModel(), Entries: entries}
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in text
+func codeFence(text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence
+}
+
+// renderTranscriptMarkdown writes a transcript as a markdown document
+func renderTranscriptMarkdown(t transcript) string {
+	var b strings.Builder
+	b.WriteString("# TmuxAI transcript\n\n")
+	fmt.Fprintf(&b, "Exported %s, model %s\n", t.ExportedAt.Format("2006-01-02 15:04:05"), t.Model)
+	for _, e := range t.Entries {
+		at := e.Timestamp.Format("15:04:05")
+		switch e.Kind {
+		case "command":
+			fmt.Fprintf(&b, "\n## Command · %s\n\n", at)
+			if e.Cwd != "" {
+				fmt.Fprintf(&b, "In `%s`\n\n", e.Cwd)
+			}
+			fence := codeFence(e.Command)
+			fmt.Fprintf(&b, "%ssh\n%s\n%s\n", fence, e.Command, fence)
+			if e.ExitCode != nil {
+				fmt.Fprintf(&b, "\nExit code %d\n", *e.ExitCode)
+			}
+			if e.Output != "" {
+				fence := codeFence(e.Output)
+				fmt.Fprintf(&b, "\n%s\n%s\n%s\n", fence, e.Output, fence)
+			}
+		default:
+			role := "User"
+			if e.Role == "assistant" {
+				role = "TmuxAI"
+			}
+			fmt.Fprintf(&b, "\n## %s · %s\n", role, at)
+			if e.Content != "" {
+				fmt.Fprintf(&b, "\n%s\n", e.Content)
+			}
+			if len(e.Actions) > 0 {
+				b.WriteString("\n")
+				for _, action := range e.Actions {
+					fmt.Fprintf(&b, "- %s\n", action)
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+var transcriptHTML = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.Format("15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>TmuxAI transcript</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 56rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.entry { border-left: 4px solid #ccc; margin: 1rem 0; padding: 0.25rem 1rem; }
+.user { border-color: #4a7bd0; }
+.assistant { border-color: #3a9a5b; }
+.command { border-color: #b07a1a; }
+.meta { color: #777; font-size: 0.85rem; }
+pre { background: #f4f4f4; padding: 0.5rem; overflow-x: auto; white-space: pre-wrap; }
+.content { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>TmuxAI transcript</h1>
+<p class="meta">Exported {{.ExportedAt.Format "2006-01-02 15:04:05"}}, model {{.Model}}</p>
+{{range .Entries}}{{if eq .Kind "command"}}<div class="entry command">
+<div class="meta">Command · {{time .Timestamp}}{{if .Cwd}} · {{.Cwd}}{{end}}{{if .ExitCode}} · exit code {{.ExitCode}}{{end}}</div>
+<pre>$ {{.Command}}</pre>{{if .Output}}
+<pre>{{.Output}}</pre>{{end}}
+</div>
+{{else}}<div class="entry {{.Role}}">
+<div class="meta">{{if eq .Role "user"}}User{{else}}TmuxAI{{end}} · {{time .Timestamp}}</div>
+{{if .Content}}<div class="content">{{.Content}}</div>{{end}}{{if .Actions}}
+<ul>{{range .Actions}}<li>{{.}}</li>{{end}}</ul>{{end}}
+</div>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// renderTranscript writes a transcript in one of the /export formats
+func renderTranscript(t transcript, format string) ([]byte, error) {
+	switch format {
+	case "md":
+		return []byte(renderTranscriptMarkdown(t)), nil
+	case "json":
+		data, err := json.MarshalIndent(t, "", "  ")
+		return append(data, '\n'), err
+	case "html":
+		var buf bytes.Buffer
+		err := transcriptHTML.Execute(&buf, t)
+		return buf.Bytes(), err
+	default:
+		return nil, fmt.Errorf("%s", i18n.T(exportUsage))
+	}
+}
+
+// handleExportCommand writes the session transcript with the executed commands and
+// their output to a file, by default in the exec pane's directory
+func handleExportCommand(m *Manager, args []string) {
+	if len(args) == 0 || len(args) > 2 {
+		m.Println(i18n.T(exportUsage))
+		return
+	}
+	format := strings.ToLower(args[0])
+	now := time.Now()
+	t := m.transcript(now)
+	data, err := renderTranscript(t, format)
+	if err != nil {
+		m.Println(err.Error())
+		return
+	}
+	if len(t.Entries) == 0 {
+		m.Println(i18n.T("Nothing to export yet"))
+		return
+	}
+
+	path := fmt.Sprintf("tmuxai-transcript-%s.%s", now.Format("20060102-150405"), format)
+	if len(args) == 2 {
+		path = args[1]
+	}
+	if !filepath.IsAbs(path) {
+		if cwd := m.execPaneCwd(); cwd != "" {
+			path = filepath.Join(cwd, path)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		m.Println(i18n.T("Failed to write transcript: %v", err))
+		return
+	}
+	m.Println(i18n.T("Exported %d entries to %s", len(t.Entries), path))
+}
@@ -0,0 +1,74 @@
+// Unit tests for /export transcripts in export_transcript.go
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+func transcriptManager(t *testing.T) *Manager {
+	start := time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC)
+	code := 1
+	m := &Manager{
+		Config:           config.DefaultConfig(),
+		SessionOverrides: map[string]interface{}{},
+		Messages: []ChatMessage{
+			{Content: "why does the build fail?", FromUser: true, Timestamp: start},
+			{Content: "Let me build it.\n<ExecCommand>make</ExecCommand>", Timestamp: start.Add(time.Second)},
+			{Content: "The <b> tag is unclosed.\n<RequestAccomplished>1</RequestAccomplished>", Timestamp: start.Add(3 * time.Second)},
+		},
+		ExecutedCommands: []ExecutedCommand{
+			{Command: "make", Cwd: "/src", Code: &code, Output: "main.go:3: ```oops```\n", Timestamp: start.Add(2 * time.Second)},
+		},
+	}
+	m.Config.SessionStore.Dir = t.TempDir()
+	m.store = newSessionStore(m.Config.SessionStore.Dir)
+	m.store.append([]storedEntry{{Kind: "message", FromUser: true, Content: "hello", Timestamp: start.Add(-time.Minute)}})
+	return m
+}
+
+// Test: stored and current messages and the commands are merged in time order, AI
+// responses are reduced to their message and actions
+func TestTranscript(t *testing.T) {
+	tr := transcriptManager(t).transcript(time.Now())
+	var kinds []string
+	for _, e := range tr.Entries {
+		kinds = append(kinds, e.Kind+":"+e.Role)
+	}
+	if got := strings.Join(kinds, " "); got != "message:user message:user message:assistant command: message:assistant" {
+		t.Fatalf("unexpected entries: %s", got)
+	}
+	if e := tr.Entries[2]; e.Content != "Let me build it." || len(e.Actions) != 0 {
+		t.Errorf("unexpected AI entry: %+v", e)
+	}
+	if e := tr.Entries[4]; len(e.Actions) != 1 || e.Actions[0] != "request accomplished" {
+		t.Errorf("unexpected actions: %+v", e.Actions)
+	}
+}
+
+// Test: each format carries the commands and their output, with markdown fences and
+// HTML escaping that survive the content
+func TestRenderTranscript(t *testing.T) {
+	tr := transcriptManager(t).transcript(time.Now())
+
+	md, _ := renderTranscript(tr, "md")
+	if !strings.Contains(string(md), "````\nmain.go:3: ```oops```\n````") || !strings.Contains(string(md), "Exit code 1") {
+		t.Errorf("unexpected markdown:\n%s", md)
+	}
+	page, _ := renderTranscript(tr, "html")
+	if !strings.Contains(string(page), "The &lt;b&gt; tag is unclosed.") || !strings.Contains(string(page), "exit code 1") {
+		t.Errorf("unexpected html:\n%s", page)
+	}
+	data, _ := renderTranscript(tr, "json")
+	var decoded transcript
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Entries) != 5 || decoded.Entries[3].Output != "main.go:3: ```oops```" {
+		t.Errorf("unexpected json: %v\n%s", err, data)
+	}
+	if _, err := renderTranscript(tr, "pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
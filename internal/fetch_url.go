@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const (
+	fetchTimeout     = 20 * time.Second
+	maxFetchBytes    = 2 << 20 // what is downloaded of a page
+	maxFetchTextSize = 24 << 10
+)
+
+// fetchURLText downloads a page and returns its readable text; HTML is stripped down,
+// plain text and JSON are kept as they are
+func fetchURLText(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("not an http(s) URL")
+	}
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "tmuxai")
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9, application/json;q=0.8, */*;q=0.1")
+	resp, err := system.HTTPClient(0).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("server returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes))
+	if err != nil {
+		return "", err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(body)
+		mediaType, _, _ = mime.ParseMediaType(mediaType)
+	}
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return system.HTMLToText(string(body)), nil
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return strings.TrimSpace(string(body)), nil
+	default:
+		return "", fmt.Errorf("unsupported content type %s", mediaType)
+	}
+}
+
+// fetchURLForModel downloads a page the AI asked for, once approved, and puts its text
+// into the conversation; the user only sees how much was fetched. It returns false when
+// the user declined, which ends the turn.
+func (m *Manager) fetchURLForModel(ctx context.Context, rawURL string) bool {
+	approved := true
+	if m.confirmRequired("", m.GetExecConfirm()) {
+		approved, _ = m.confirmAction(rawURL, confirmFetchPrompt, false, "")
+		emitConfirmation(confirmFetchPrompt, rawURL, approved)
+		m.stats.recordConfirmation(approved)
+	}
+	if !approved {
+		return false
+	}
+
+	text, err := fetchURLText(ctx, rawURL)
+	if err != nil {
+		m.tellModel(fmt.Sprintf("FetchUrl %s failed: %v", rawURL, err))
+		return true
+	}
+	note := ""
+	if len(text) > maxFetchTextSize {
+		text = strings.ToValidUTF8(text[:maxFetchTextSize], "")
+		note = fmt.Sprintf("\n(truncated to the first %d bytes)", maxFetchTextSize)
+	}
+	m.Println(fmt.Sprintf("FetchUrl %s: %d characters", rawURL, len([]rune(text))))
+	emitEvent(EventFetch, map[string]interface{}{"url": rawURL, "size": len(text)})
+	m.appendMessages(ChatMessage{
+		Content:   fmt.Sprintf("FetchUrl %s:\n```\n%s\n```%s", rawURL, text, note),
+		FromUser:  false,
+		Timestamp: time.Now(),
+	})
+	return true
+}
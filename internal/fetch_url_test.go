@@ -0,0 +1,74 @@
+// Unit tests for fetching web pages in fetch_url.go
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test: HTML is reduced to text, JSON kept, and errors, binaries and other schemes refused
+func TestFetchURLText(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html><head><script>x()</script></head><body><p>Port &amp; host</p></body></html>")
+		case "/data":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"ok": true}`)
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		url, want, err string
+	}{
+		{srv.URL + "/page", "Port & host", ""},
+		{srv.URL + "/data", `{"ok": true}`, ""},
+		{srv.URL + "/image", "", "unsupported content type image/png"},
+		{srv.URL + "/missing", "", "404"},
+		{"file:///etc/passwd", "", "not an http(s) URL"},
+	}
+	for _, c := range cases {
+		got, err := fetchURLText(context.Background(), c.url)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("%s: expected error %q, got %v", c.url, c.err, err)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("%s: got %q, %v, want %q", c.url, got, err, c.want)
+		}
+	}
+}
+
+// Test: a declined fetch ends the turn without downloading, an approved one adds the page
+func TestFetchURLForModel(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, "plain docs")
+	}))
+	defer srv.Close()
+
+	if newFileEditManager(false).fetchURLForModel(context.Background(), srv.URL) || requests != 0 {
+		t.Fatalf("declined fetch should stop the turn without a request, %d requests", requests)
+	}
+	m := newFileEditManager(true)
+	if !m.fetchURLForModel(context.Background(), srv.URL) {
+		t.Fatal("approved fetch should continue the turn")
+	}
+	if len(m.Messages) != 1 || m.Messages[0].Content != "FetchUrl "+srv.URL+":\n```\nplain docs\n```" {
+		t.Errorf("unexpected messages: %+v", m.Messages)
+	}
+}
@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+const fifoName = "session.fifo"
+
+// FifoInput reads chat messages written to a named pipe from any pane,
+// e.g. echo "explain the last error" > ~/.config/tmuxai/session.fifo
+type FifoInput struct {
+	manager *Manager
+	path    string
+	// info identifies the FIFO this instance created, so Close leaves a replaced path alone
+	info   os.FileInfo
+	closed atomic.Bool
+}
+
+// FifoPath returns the path of the session FIFO in the config dir
+func FifoPath() string {
+	return config.GetConfigFilePath(fifoName)
+}
+
+// StartFifoInput creates the FIFO and starts reading messages from it.
+// Each write (everything until the writer closes the pipe) becomes one message.
+// A stale FIFO is replaced, while one read by another live instance is left alone.
+func StartFifoInput(m *Manager) (*FifoInput, error) {
+	path := FifoPath()
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeNamedPipe == 0 {
+			return nil, fmt.Errorf("%s exists and is not a named pipe", path)
+		}
+		// a non-blocking open for writing only succeeds while a reader has the pipe open;
+		// closing it without writing gives that reader an empty message, which is ignored
+		if w, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+			w.Close()
+			return nil, fmt.Errorf("%s is in use by another tmuxai instance", path)
+		}
+		os.Remove(path)
+	}
+	if err := syscall.Mkfifo(path, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	f := &FifoInput{manager: m, path: path, info: info}
+	go f.read()
+	logger.Info("Reading messages from %s", path)
+	return f, nil
+}
+
+func (f *FifoInput) read() {
+	for !f.closed.Load() {
+		// blocks until a writer opens the pipe
+		file, err := os.OpenFile(f.path, os.O_RDONLY, 0)
+		if err != nil {
+			if !f.closed.Load() {
+				logger.Error("Failed to open %s: %v", f.path, err)
+			}
+			return
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			logger.Error("Failed to read %s: %v", f.path, err)
+			continue
+		}
+		if message := strings.TrimSpace(string(data)); message != "" && !f.closed.Load() {
+			go f.manager.HandleExternalMessage("fifo", message)
+		}
+	}
+}
+
+// Close stops reading and removes the FIFO if it is still the one this instance created
+func (f *FifoInput) Close() error {
+	f.closed.Store(true)
+	if info, err := os.Lstat(f.path); err != nil || !os.SameFile(info, f.info) {
+		return nil
+	}
+	// unblock the pending open in read()
+	if w, err := os.OpenFile(f.path, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+		w.Close()
+	}
+	return os.Remove(f.path)
+}
@@ -0,0 +1,70 @@
+// Unit tests for the session FIFO in fifo_input.go
+package internal
+
+import (
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: a second instance refuses the FIFO of a live one, and closing never removes a FIFO it did not create
+func TestFifoInputOwnership(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := NewManagerForPane(config.DefaultConfig(), "", nil)
+	m.Output = io.Discard
+
+	first, err := StartFifoInput(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// wait for the reader to open the pipe
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if w, err := os.OpenFile(FifoPath(), os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+			w.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the first instance to read the FIFO")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := StartFifoInput(m); err == nil || !strings.Contains(err.Error(), "in use by another tmuxai instance") {
+		t.Fatalf("expected the live FIFO to be refused, got %v", err)
+	}
+	if _, err := os.Lstat(FifoPath()); err != nil {
+		t.Fatalf("expected the FIFO of the first instance to stay, got %v", err)
+	}
+
+	// another instance replaced the path after this one stopped reading
+	os.Remove(FifoPath())
+	if err := syscall.Mkfifo(FifoPath(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+	if _, err := os.Lstat(FifoPath()); err != nil {
+		t.Errorf("expected Close to leave a FIFO it did not create, got %v", err)
+	}
+}
+
+// Test: a stale FIFO without a reader is replaced and removed again on Close
+func TestFifoInputStale(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := syscall.Mkfifo(FifoPath(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManagerForPane(config.DefaultConfig(), "", nil)
+	f, err := StartFifoInput(m)
+	if err != nil {
+		t.Fatalf("expected the stale FIFO to be replaced, got %v", err)
+	}
+	f.Close()
+	if _, err := os.Lstat(FifoPath()); !os.IsNotExist(err) {
+		t.Errorf("expected Close to remove its FIFO, got %v", err)
+	}
+}
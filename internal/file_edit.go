@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/system"
+	"golang.org/x/term"
+)
+
+// FileEdit is a whole new body the AI proposed for a file, relative to the exec pane's directory
+type FileEdit struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// FilePatch replaces parts of a file the AI quoted instead of sending the whole body
+type FilePatch struct {
+	Path  string      `json:"path"`
+	Hunks []PatchHunk `json:"hunks"`
+}
+
+// PatchHunk is one search/replace block of a FilePatch; Search must occur exactly once
+type PatchHunk struct {
+	Search  string `json:"search"`
+	Replace string `json:"replace"`
+}
+
+// maxReadFileBytes caps what a ReadFile puts into the conversation
+const maxReadFileBytes = 64 << 10
+
+// workDir is the directory the file actions work in: the exec pane's, or ours without one
+func (m *Manager) workDir() string {
+	if cwd := m.execPaneCwd(); cwd != "" {
+		return cwd
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// workPath resolves a path the AI gave against the working directory and refuses paths
+// that leave it, also through symlinks
+func (m *Manager) workPath(name string) (string, error) {
+	dir := m.workDir()
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+	if !insideDir(dir, path) {
+		return "", fmt.Errorf("%s is outside the working directory %s", name, dir)
+	}
+	// the nearest existing parent decides where a symlink really points
+	existing := path
+	for {
+		if _, err := os.Lstat(existing); err == nil || existing == dir {
+			break
+		}
+		existing = filepath.Dir(existing)
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("can't resolve the working directory %s: %w", dir, err)
+	}
+	// a dangling symlink can't be followed, but writing through it would create its target
+	realPath, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("can't resolve %s: %w", name, err)
+	}
+	if !insideDir(realDir, realPath) {
+		return "", fmt.Errorf("%s is outside the working directory %s", name, dir)
+	}
+	return path, nil
+}
+
+func insideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// applyFileEdit shows the change as a diff and writes the file once confirmed.
+// It returns false when the user declined, which ends the turn like a declined command.
+func (m *Manager) applyFileEdit(edit FileEdit) bool {
+	path, err := m.workPath(edit.Path)
+	if err != nil {
+		m.tellModel(fmt.Sprintf("WriteFile %s refused: %v", edit.Path, err))
+		return true
+	}
+	return m.writeFileChange("WriteFile", edit.Path, path, edit.Content)
+}
+
+// applyFilePatch applies the hunks of a patch to the file and writes it like a WriteFile.
+// A hunk that doesn't match exactly once fails the whole patch, so the model can retry.
+func (m *Manager) applyFilePatch(patch FilePatch) bool {
+	path, err := m.workPath(patch.Path)
+	if err != nil {
+		m.tellModel(fmt.Sprintf("PatchFile %s refused: %v", patch.Path, err))
+		return true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.tellModel(fmt.Sprintf("PatchFile %s failed: %v", patch.Path, err))
+		return true
+	}
+	content, err := patchContent(string(data), patch.Hunks)
+	if err != nil {
+		m.tellModel(fmt.Sprintf("PatchFile %s failed: %v", patch.Path, err))
+		return true
+	}
+	return m.writeFileChange("PatchFile", patch.Path, path, content)
+}
+
+// patchContent applies search/replace hunks in order
+func patchContent(content string, hunks []PatchHunk) (string, error) {
+	if len(hunks) == 0 {
+		return "", fmt.Errorf("no SEARCH/REPLACE blocks")
+	}
+	for i, h := range hunks {
+		if h.Search == "" {
+			return "", fmt.Errorf("block %d has an empty SEARCH", i+1)
+		}
+		switch n := strings.Count(content, h.Search); n {
+		case 0:
+			return "", fmt.Errorf("the SEARCH text of block %d was not found, read the file again", i+1)
+		case 1:
+			content = strings.Replace(content, h.Search, h.Replace, 1)
+		default:
+			return "", fmt.Errorf("the SEARCH text of block %d occurs %d times, include more lines to make it unique", i+1, n)
+		}
+	}
+	return content, nil
+}
+
+// writeFileChange shows the change to path as a diff and writes it once confirmed;
+// action and name label the result for the user and the model
+func (m *Manager) writeFileChange(action, name, path, content string) bool {
+	mode := os.FileMode(0o644)
+	oldContent, err := os.ReadFile(path)
+	oldName := "a/" + name
+	if os.IsNotExist(err) {
+		oldName = ""
+	} else if err != nil {
+		m.tellModel(fmt.Sprintf("%s %s failed: %v", action, name, err))
+		return true
+	} else if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	diff := system.UnifiedDiff(oldName, "b/"+name, string(oldContent), content)
+	if diff == "" {
+		m.tellModel(fmt.Sprintf("%s %s: no changes", action, name))
+		return true
+	}
+	rendered := m.renderDiff(diff, string(oldContent), content)
+	fmt.Fprint(m.out(), rendered)
+
+	approved := true
+	if m.confirmRequired("", m.GetExecConfirm()) {
+		approved, _ = m.confirmAction(path, confirmWritePrompt, false, rendered)
+		emitConfirmation(confirmWritePrompt, path, approved)
+		m.stats.recordConfirmation(approved)
+	}
+	if !approved {
+		return false
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err == nil {
+		err = os.WriteFile(path, []byte(content), mode)
+	}
+	if err != nil {
+		m.tellModel(fmt.Sprintf("%s %s failed: %v", action, name, err))
+		return true
+	}
+	emitEvent(EventFileWrite, map[string]interface{}{"path": path, "diff": diff})
+	m.tellModel(fmt.Sprintf("%s %s: written", action, name))
+	return true
+}
+
+// readFileForModel puts a file of the working directory into the conversation once
+// approved; the user only sees that it was read. It returns false when the user declined,
+// which ends the turn.
+func (m *Manager) readFileForModel(name string) bool {
+	path, err := m.workPath(name)
+	if err != nil {
+		m.audit("read", name, auditBlocked, nil)
+		m.tellModel(fmt.Sprintf("ReadFile %s refused: %v", name, err))
+		return true
+	}
+	decision := auditAuto
+	if m.confirmRequired("", m.GetExecConfirm()) {
+		approved, _ := m.confirmAction(path, confirmReadPrompt, false, "")
+		emitConfirmation(confirmReadPrompt, path, approved)
+		m.stats.recordConfirmation(approved)
+		if !approved {
+			m.audit("read", path, auditDeclined, nil)
+			return false
+		}
+		decision = auditApproved
+	}
+	m.audit("read", path, decision, nil)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.tellModel(fmt.Sprintf("ReadFile %s failed: %v", name, err))
+		return true
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		m.tellModel(fmt.Sprintf("ReadFile %s: binary file, not shown", name))
+		return true
+	}
+	note := ""
+	if len(data) > maxReadFileBytes {
+		data = data[:maxReadFileBytes]
+		note = fmt.Sprintf("\n(truncated to the first %d bytes)", maxReadFileBytes)
+	}
+	lines := strings.Count(string(data), "\n")
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	m.Println(fmt.Sprintf("ReadFile %s: %d lines", name, lines))
+	emitEvent(EventFileRead, map[string]interface{}{"path": path})
+	m.appendMessages(ChatMessage{
+		Content:   fmt.Sprintf("ReadFile %s:\n```\n%s\n```%s", name, strings.TrimSuffix(string(data), "\n"), note),
+		FromUser:  false,
+		Timestamp: time.Now(),
+	})
+	return true
+}
+
+// renderDiff colors a diff in the configured diff_style; side-by-side needs a wide terminal
+func (m *Manager) renderDiff(diff, oldContent, newContent string) string {
+	if m.Config.DiffStyle == "side-by-side" {
+		width, _, err := term.GetSize(int(os.Stdout.Fd()))
+		if err == nil && width >= 100 {
+			return system.SideBySideDiff(oldContent, newContent, width)
+		}
+	}
+	return system.ColorDiff(diff)
+}
@@ -0,0 +1,173 @@
+// Unit tests for applying proposed file changes in file_edit.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+func newFileEditManager(approve bool) *Manager {
+	cfg := config.DefaultConfig()
+	return &Manager{
+		Config:           cfg,
+		SessionOverrides: map[string]interface{}{},
+		ConfirmFunc:      func(string, string) (bool, string) { return approve, "" },
+	}
+}
+
+// chdirTemp makes a temporary directory the working directory of the file actions
+func chdirTemp(t *testing.T) string {
+	old, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(old) })
+	dir, _ := os.Getwd()
+	return dir
+}
+
+// Test: an approved edit creates missing directories and keeps the mode of an existing file
+func TestApplyFileEdit(t *testing.T) {
+	dir := chdirTemp(t)
+	existing := filepath.Join(dir, "run.sh")
+	os.WriteFile(existing, []byte("echo hi\n"), 0o755)
+	created := filepath.Join(dir, "sub", "new.txt")
+
+	m := newFileEditManager(true)
+	if !m.applyFileEdit(FileEdit{Path: existing, Content: "echo hello\n"}) ||
+		!m.applyFileEdit(FileEdit{Path: created, Content: "new\n"}) {
+		t.Fatal("approved edits should continue the turn")
+	}
+
+	if data, _ := os.ReadFile(existing); string(data) != "echo hello\n" {
+		t.Errorf("existing file not updated: %q", data)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0o755 {
+		t.Errorf("mode changed to %v", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(created); string(data) != "new\n" {
+		t.Errorf("new file not written: %q", data)
+	}
+	if n := len(m.Messages); n != 2 {
+		t.Errorf("expected a result message per edit, got %d", n)
+	}
+}
+
+// Test: a declined edit leaves the file alone and stops the turn
+func TestApplyFileEditDeclined(t *testing.T) {
+	path := filepath.Join(chdirTemp(t), "a.txt")
+	os.WriteFile(path, []byte("old\n"), 0o644)
+
+	if newFileEditManager(false).applyFileEdit(FileEdit{Path: path, Content: "new\n"}) {
+		t.Error("declined edit should stop the turn")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old\n" {
+		t.Errorf("file changed: %q", data)
+	}
+}
+
+// Test: paths outside the working directory are refused, also through a symlink
+func TestWorkPath(t *testing.T) {
+	dir := chdirTemp(t)
+	outside := t.TempDir()
+	os.Symlink(outside, filepath.Join(dir, "link"))
+	m := newFileEditManager(true)
+
+	if path, err := m.workPath("sub/a.txt"); err != nil || path != filepath.Join(dir, "sub", "a.txt") {
+		t.Errorf("relative path: %q, %v", path, err)
+	}
+	for _, name := range []string{"../a.txt", filepath.Join(outside, "a.txt"), "link/a.txt", "link/new/a.txt"} {
+		if _, err := m.workPath(name); err == nil {
+			t.Errorf("%s should be refused", name)
+		}
+	}
+}
+
+// Test: a dangling symlink pointing outside is refused and its target isn't created
+func TestWorkPathDanglingSymlink(t *testing.T) {
+	dir := chdirTemp(t)
+	target := filepath.Join(t.TempDir(), "x")
+	os.Symlink(target, filepath.Join(dir, "link"))
+	m := newFileEditManager(true)
+
+	if _, err := m.workPath("link"); err == nil {
+		t.Error("link should be refused")
+	}
+	m.applyFileEdit(FileEdit{Path: "link", Content: "data\n"})
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to be created, got %v", target, err)
+	}
+}
+
+// Test: a patch replaces each SEARCH block once and fails without writing when a block
+// is missing or ambiguous
+func TestApplyFilePatch(t *testing.T) {
+	dir := chdirTemp(t)
+	path := filepath.Join(dir, "main.go")
+	os.WriteFile(path, []byte("a\nb\nc\nb\n"), 0o644)
+	m := newFileEditManager(true)
+
+	m.applyFilePatch(FilePatch{Path: "main.go", Hunks: []PatchHunk{{Search: "a\n", Replace: "A\n"}, {Search: "c", Replace: ""}}})
+	if data, _ := os.ReadFile(path); string(data) != "A\nb\n\nb\n" {
+		t.Errorf("unexpected content: %q", data)
+	}
+	for _, hunk := range []PatchHunk{{Search: "b", Replace: "x"}, {Search: "missing", Replace: "x"}} {
+		m.applyFilePatch(FilePatch{Path: "main.go", Hunks: []PatchHunk{hunk}})
+	}
+	if data, _ := os.ReadFile(path); string(data) != "A\nb\n\nb\n" {
+		t.Errorf("failed patches changed the file: %q", data)
+	}
+	if n := len(m.Messages); n != 3 {
+		t.Errorf("expected a result message per patch, got %d", n)
+	}
+}
+
+// Test: a read puts the file into the conversation, binary files are not
+func TestReadFileForModel(t *testing.T) {
+	dir := chdirTemp(t)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("line 1\nline 2\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "app.bin"), []byte{0x7f, 0, 1}, 0o644)
+	m := newFileEditManager(true)
+
+	if !m.readFileForModel("notes.txt") || !m.readFileForModel("app.bin") {
+		t.Fatal("expected the approved reads to continue the turn")
+	}
+	if len(m.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(m.Messages))
+	}
+	if got := m.Messages[0].Content; got != "ReadFile notes.txt:\n```\nline 1\nline 2\n```" {
+		t.Errorf("unexpected read result: %q", got)
+	}
+	if got := m.Messages[1].Content; got != "ReadFile app.bin: binary file, not shown" {
+		t.Errorf("unexpected binary result: %q", got)
+	}
+}
+
+// Test: a declined read ends the turn without the file, and reads are in the audit log
+func TestReadFileForModelDeclined(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := chdirTemp(t)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("TOKEN=secret\n"), 0o644)
+	m := newFileEditManager(false)
+	m.Config.AuditLog = true
+
+	if m.readFileForModel(".env") {
+		t.Error("expected a declined read to end the turn")
+	}
+	if len(m.Messages) != 0 {
+		t.Errorf("expected the file to stay out of the conversation, got %+v", m.Messages)
+	}
+	m.ConfirmFunc = func(string, string) (bool, string) { return true, "" }
+	m.readFileForModel(".env")
+
+	entries, err := readAuditEntries(AuditLogPath(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Kind != "read" || entries[0].Decision != auditDeclined || entries[1].Decision != auditApproved {
+		t.Errorf("expected a declined and an approved read in the audit log, got %+v", entries)
+	}
+}
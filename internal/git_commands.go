@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const (
+	maxCommitDiffLines = 1500
+	maxPrDiffLines     = 2000
+)
+
+const commitMessagePrompt = `Write a git commit message for the staged changes below.
+Use a concise imperative subject line under 72 characters, then a blank line and a short body
+explaining what changed and why when it is not obvious from the subject.
+Follow the style of the recent commits. Reply with the commit message only, no code fences or comments.
+
+Recent commits:
+%s
+
+Staged diff:
+%s`
+
+const prDescriptionPrompt = `Draft a pull request for the branch changes below.
+Reply with a title on the first line, a blank line, then a markdown description with a short summary,
+the notable changes and how they were tested if that can be inferred. No code fences around the whole reply.
+
+Commits:
+%s
+
+Diff against %s:
+%s`
+
+// handleCommitCommand generates a commit message for the staged diff and commits after approval
+func handleCommitCommand(m *Manager) {
+	cwd := m.execPaneCwd()
+	if cwd == "" || !system.IsGitRepo(cwd) {
+		m.Println(i18n.T("The exec pane is not inside a git repository"))
+		return
+	}
+
+	diff, err := system.GitRun(cwd, "diff", "--cached")
+	if err != nil {
+		m.Println(i18n.T("Failed to read staged diff: %v", err))
+		return
+	}
+	if strings.TrimSpace(diff) == "" {
+		m.Println(i18n.T("Nothing is staged, stage changes with git add first"))
+		return
+	}
+	recent, _ := system.GitRun(cwd, "log", "--oneline", "-n", "10")
+
+	message, err := m.generateFromPrompt(fmt.Sprintf(commitMessagePrompt, recent, system.LimitLines(diff, maxCommitDiffLines)))
+	if err != nil {
+		m.Println(i18n.T("Failed to generate commit message: %v", err))
+		return
+	}
+
+	fmt.Fprintln(m.out(), m.cosmetics(message))
+	if ok, _ := m.confirmedToExec(message, "Commit with this message?", false); !ok {
+		m.Println(i18n.T("Commit cancelled, run /commit again to regenerate"))
+		return
+	}
+
+	// the message goes through a file so multi-line bodies survive any shell
+	file, err := os.CreateTemp("", "tmuxai-commit-*.txt")
+	if err != nil {
+		m.Println(i18n.T("Failed to write commit message: %v", err))
+		return
+	}
+	// git has read it once execInPane returns
+	defer os.Remove(file.Name())
+	defer file.Close()
+	if _, err := file.WriteString(message + "\n"); err != nil {
+		m.Println(i18n.T("Failed to write commit message: %v", err))
+		return
+	}
+
+	m.SetStatus("running")
+	defer func() { m.SetStatus("") }()
+	command := "git commit -F " + shellQuote(file.Name())
+	executed, err := m.execInPane(context.Background(), command, "Commit the staged changes with the generated message")
+	if err != nil {
+		m.Println(err.Error())
+		m.audit("exec", command, auditBlocked, nil)
+		return
+	}
+	m.audit("exec", command, auditApproved, executed.Code)
+}
+
+// handlePrCommand drafts a pull request title and description from the branch diff
+func handlePrCommand(m *Manager, args []string) {
+	cwd := m.execPaneCwd()
+	if cwd == "" || !system.IsGitRepo(cwd) {
+		m.Println(i18n.T("The exec pane is not inside a git repository"))
+		return
+	}
+
+	base := ""
+	if len(args) > 0 {
+		base = args[0]
+	} else {
+		b, err := system.GitBaseBranch(cwd)
+		if err != nil {
+			m.Println(i18n.T("Could not determine the base branch, use /pr <base>"))
+			return
+		}
+		base = b
+	}
+
+	commits, err := system.GitRun(cwd, "log", "--oneline", base+"..HEAD")
+	if err != nil {
+		m.Println(i18n.T("Failed to read branch commits: %v", err))
+		return
+	}
+	diff, _ := system.GitRun(cwd, "diff", base+"...HEAD")
+	if strings.TrimSpace(commits) == "" && strings.TrimSpace(diff) == "" {
+		m.Println(i18n.T("No changes between %s and HEAD", base))
+		return
+	}
+
+	draft, err := m.generateFromPrompt(fmt.Sprintf(prDescriptionPrompt, commits, base, system.LimitLines(diff, maxPrDiffLines)))
+	if err != nil {
+		m.Println(i18n.T("Failed to draft PR description: %v", err))
+		return
+	}
+
+	fmt.Fprintln(m.out(), m.cosmetics(draft))
+	// keep the draft in the conversation so it can be refined with follow-up messages
+	m.appendMessages(
+		ChatMessage{Content: fmt.Sprintf("Draft a PR description for the changes against %s", base), FromUser: true, Timestamp: time.Now()},
+		ChatMessage{Content: draft, FromUser: false, Timestamp: time.Now()},
+	)
+}
+
+// generateFromPrompt sends a standalone prompt outside of the conversation and returns the trimmed reply
+func (m *Manager) generateFromPrompt(prompt string) (string, error) {
+	s := m.startProgress(39, "Generating")
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	messages := []ChatMessage{{Content: prompt, FromUser: true, Timestamp: time.Now()}}
+	response, err := m.aiClient().GetResponseFromChatMessages(ctx, messages, m.GetOpenRouterModel())
+	if err != nil {
+		return "", err
+	}
+	if m.GetConfig().Debug {
+		debugChatMessages(messages, response)
+	}
+	logger.Debug("Generated: %s", response)
+	return stripCodeFence(response), nil
+}
+
+// stripCodeFence removes a code fence wrapping the whole text
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") {
+		return text
+	}
+	text = strings.TrimSuffix(text, "```")
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(text)
+}
+
+// shellQuote quotes s for POSIX shells and fish
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,24 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// gitContext renders a concise git summary of the exec pane's repository, if any
+func (m *Manager) gitContext() (string, error) {
+	cwd := m.execPaneCwd()
+	if cwd == "" {
+		return "", fmt.Errorf("could not determine exec pane working directory")
+	}
+	summary, err := system.GitSummary(cwd, m.GetGitCommits(), m.GetGitStagedDiff())
+	if errors.Is(err, system.ErrNotGitRepo) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<git_context cwd=\"%s\">\n%s</git_context>\n", cwd, summary), nil
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// HeadlessOptions are the flags of a headless run
+type HeadlessOptions struct {
+	Pane    string // exec pane to run in; default the window's exec pane, or a new session outside tmux
+	Policy  string // approval policy file like in CI mode; default approves whitelisted commands only
+	Timeout int    // seconds for the whole run when no policy sets it
+}
+
+// HeadlessResult is what a headless run prints: the CI report with a success flag
+type HeadlessResult struct {
+	Success bool `json:"success"`
+	*CIReport
+}
+
+// RunHeadless runs a single task to the end without asking anything and returns its
+// result. It works in the given exec pane, the one of the current tmux window, or in a
+// detached session that is removed afterwards.
+func RunHeadless(cfg *config.Config, task string, opts HeadlessOptions) (*HeadlessResult, error) {
+	provider, err := NewChatProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	policy := &CIPolicy{UseWhitelist: true, Timeout: opts.Timeout}
+	if opts.Policy != "" {
+		if policy, err = LoadCIPolicy(opts.Policy); err != nil {
+			return nil, err
+		}
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = 600
+	}
+
+	var m *Manager
+	switch paneId, err := system.TmuxCurrentPaneId(); {
+	case opts.Pane != "":
+		m, err = headlessManager(cfg, provider, paneId, opts.Pane)
+		if err != nil {
+			return nil, err
+		}
+	case err == nil:
+		m = NewManagerForPane(cfg, paneId, provider)
+		m.InitExecPane()
+	default:
+		sessionPane, err := system.TmuxCreateSession()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tmux session: %w", err)
+		}
+		defer system.TmuxKillSession(sessionPane)
+		execPane, err := system.TmuxCreateNewPane(sessionPane)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create exec pane: %w", err)
+		}
+		if m, err = headlessManager(cfg, provider, sessionPane, execPane); err != nil {
+			return nil, err
+		}
+	}
+	// stdout only carries the result
+	m.Output = os.Stderr
+	m.PrepareExecPane()
+
+	report := newCIReport(m, task, opts.Policy)
+	runWithPolicy(m, task, policy, report)
+	return &HeadlessResult{Success: report.Status == CIStatusSuccess, CIReport: report}, nil
+}
+
+// headlessManager creates the manager of a run in a known exec pane
+func headlessManager(cfg *config.Config, provider ChatProvider, paneId, execPane string) (*Manager, error) {
+	panes, err := system.TmuxPanesDetails(execPane)
+	if err != nil || len(panes) == 0 {
+		return nil, fmt.Errorf("failed to read exec pane %s: %v", execPane, err)
+	}
+	if paneId == "" {
+		paneId = execPane
+	}
+	m := NewManagerForPane(cfg, paneId, provider)
+	m.ExecPane = &panes[0]
+	return m, nil
+}
+
+// Text is the result for a person: the final message, or the error of a failed run
+func (r *HeadlessResult) Text() string {
+	if r.Success || r.Error == "" {
+		return r.FinalMessage
+	}
+	return fmt.Sprintf("%s: %s", r.Status, r.Error)
+}
@@ -0,0 +1,42 @@
+// Unit tests for the result of a headless run in headless.go
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Test: the result is the report with a success flag at the top level, and its text is
+// the final message or the reason the run failed
+func TestHeadlessResult(t *testing.T) {
+	code := 0
+	report := &CIReport{
+		Task:         "run the tests",
+		Status:       CIStatusSuccess,
+		Commands:     []CICommand{{Command: "make test", ExitCode: &code, Output: "ok"}},
+		FinalMessage: "All tests pass.",
+	}
+	result := &HeadlessResult{Success: true, CIReport: report}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	if fields["success"] != true || fields["status"] != CIStatusSuccess || fields["final_message"] != "All tests pass." {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+	if commands, _ := fields["commands"].([]interface{}); len(commands) != 1 {
+		t.Errorf("expected the commands in the JSON: %s", data)
+	}
+	if got := result.Text(); got != "All tests pass." {
+		t.Errorf("unexpected text: %q", got)
+	}
+
+	report.Status, report.Error = CIStatusTimeout, "run exceeded 600s"
+	result.Success = false
+	if got := result.Text(); got != "timeout: run exceeded 600s" {
+		t.Errorf("unexpected text of a failed run: %q", got)
+	}
+}
@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// inputHistory is the chat input history kept across sessions, like a shell HISTFILE.
+// Entries are deduplicated with the newest occurrence kept, and capped at size.
+type inputHistory struct {
+	path  string
+	size  int
+	lines []string
+}
+
+// newInputHistory loads the history file configured under history
+func newInputHistory(cfg config.HistoryConfig) *inputHistory {
+	path := cfg.File
+	if path == "" {
+		path = config.GetConfigFilePath("history")
+	} else if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	h := &inputHistory{path: path, size: cfg.Size}
+	h.lines = h.load()
+	return h
+}
+
+func (h *inputHistory) Len() int {
+	return len(h.lines)
+}
+
+func (h *inputHistory) At(i int) string {
+	return h.lines[i]
+}
+
+// Add records a line and saves it, merging entries written by other sessions meanwhile
+func (h *inputHistory) Add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	h.lines = appendHistory(h.lines, line, h.size)
+	if h.size <= 0 {
+		return
+	}
+
+	merged := appendHistory(h.load(), line, h.size)
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(encodeHistory(merged)), 0o600); err != nil {
+		logger.Error("Failed to save input history: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		logger.Error("Failed to save input history: %v", err)
+		return
+	}
+	h.lines = merged
+}
+
+func (h *inputHistory) load() []string {
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	for _, line := range decodeHistory(string(data)) {
+		lines = appendHistory(lines, line, 0)
+	}
+	if h.size > 0 && len(lines) > h.size {
+		lines = lines[len(lines)-h.size:]
+	}
+	return lines
+}
+
+// appendHistory moves line to the end, dropping earlier duplicates and the oldest
+// entries beyond size (0 for no limit)
+func appendHistory(lines []string, line string, size int) []string {
+	kept := lines[:0:0]
+	for _, l := range lines {
+		if l != line {
+			kept = append(kept, l)
+		}
+	}
+	kept = append(kept, line)
+	if size > 0 && len(kept) > size {
+		kept = kept[len(kept)-size:]
+	}
+	return kept
+}
+
+// encodeHistory writes one entry per line, escaping the newlines of multiline input
+func encodeHistory(lines []string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(escaper.Replace(line))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func decodeHistory(data string) []string {
+	var lines []string
+	for _, raw := range strings.Split(data, "\n") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		var b strings.Builder
+		for i := 0; i < len(raw); i++ {
+			// other backslashes are kept as is, older files were written unescaped
+			if raw[i] == '\\' && i+1 < len(raw) && (raw[i+1] == 'n' || raw[i+1] == '\\') {
+				i++
+				if raw[i] == 'n' {
+					b.WriteByte('\n')
+				} else {
+					b.WriteByte('\\')
+				}
+				continue
+			}
+			b.WriteByte(raw[i])
+		}
+		lines = append(lines, b.String())
+	}
+	return lines
+}
+
+// findHistory returns the newest entry at or before from containing query,
+// or notFound when there is none
+func findHistory(h *inputHistory, query string, from, notFound int) int {
+	for i := min(from, h.Len()-1); i >= 0; i-- {
+		if strings.Contains(h.At(i), query) {
+			return i
+		}
+	}
+	return notFound
+}
@@ -0,0 +1,56 @@
+// Unit tests for the persistent input history in input_history.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: duplicates move to the end and the oldest entries beyond size are dropped
+func TestAppendHistory(t *testing.T) {
+	got := appendHistory([]string{"a", "b", "c"}, "a", 0)
+	if want := []string{"b", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("dedup: got %v, want %v", got, want)
+	}
+	got = appendHistory([]string{"a", "b", "c"}, "d", 2)
+	if want := []string{"c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cap: got %v, want %v", got, want)
+	}
+}
+
+// Test: multiline entries and backslashes survive a round trip through the file
+func TestInputHistoryPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	os.WriteFile(path, []byte("old entry\ngrep \\d+\n"), 0o600)
+
+	h := newInputHistory(config.HistoryConfig{File: path, Size: 3})
+	if h.Len() != 2 || h.At(1) != `grep \d+` {
+		t.Fatalf("unexpected entries from an unescaped file: %q", h.lines)
+	}
+	h.Add("line one\nline two")
+	h.Add(`C:\new`)
+
+	reloaded := newInputHistory(config.HistoryConfig{File: path, Size: 3})
+	want := []string{`grep \d+`, "line one\nline two", `C:\new`}
+	if !reflect.DeepEqual(reloaded.lines, want) {
+		t.Errorf("got %q, want %q", reloaded.lines, want)
+	}
+}
+
+// Test: reverse search finds the newest match at or before the start position
+func TestFindHistory(t *testing.T) {
+	h := &inputHistory{lines: []string{"git status", "ls", "git log"}}
+	if got := findHistory(h, "git", 2, 3); got != 2 {
+		t.Errorf("expected newest match 2, got %d", got)
+	}
+	if got := findHistory(h, "git", 1, 1); got != 0 {
+		t.Errorf("expected older match 0, got %d", got)
+	}
+	if got := findHistory(h, "docker", 2, 3); got != 3 {
+		t.Errorf("expected not found, got %d", got)
+	}
+}
@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/cloudwego/eino/schema"
+)
+
+// maxJSONRetries is how often a response that doesn't validate is sent back to the model
+const maxJSONRetries = 2
+
+const jsonRetryPrompt = `Your response was not valid: %v
+Respond again with a single JSON object of the form {"message": "...", "actions": [...]} and nothing else.`
+
+// jsonResponse is a response in JSON mode: the message and the actions, named and shaped
+// like the tools of tool calling
+type jsonResponse struct {
+	Message string       `json:"message"`
+	Actions []jsonAction `json:"actions"`
+}
+
+type jsonAction struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// jsonModePrompt explains the JSON response and lists the actions with their arguments
+func jsonModePrompt() string {
+	var actions []string
+	for _, t := range builtinTools {
+		var args []string
+		if params, err := t.info.ParamsOneOf.ToOpenAPIV3(); err == nil && params != nil {
+			for name := range params.Properties {
+				args = append(args, name)
+			}
+			sort.Strings(args)
+		}
+		actions = append(actions, fmt.Sprintf("%s(%s)", t.info.Name, strings.Join(args, ", ")))
+	}
+	return `
+
+==== JSON responses ====
+Respond with a single JSON object and nothing else, instead of writing the XML tags:
+{"message": "what you tell the user", "actions": [{"name": "exec_command", "arguments": {"command": "ls -la"}}]}
+The actions are ` + strings.Join(actions, ", ") + `,
+each MCP tool as mcp_<server>__<tool> and each custom tool as tool_<name> with the arguments of its schema; send_keys takes a list of keys.
+The boolean actions take empty arguments. The same rules apply to the actions as to the tags; leave actions empty when there is nothing to do.
+==== End of JSON responses ====
+`
+}
+
+// jsonResponseSchema is the schema of a JSON mode response with the names of the offered tools
+func jsonResponseSchema(tools []*schema.ToolInfo) map[string]interface{} {
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"message": map[string]interface{}{"type": "string"},
+			"actions": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name":      map[string]interface{}{"type": "string", "enum": names},
+						"arguments": map[string]interface{}{"type": "object"},
+					},
+					"required":             []string{"name", "arguments"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"message", "actions"},
+		"additionalProperties": false,
+	}
+}
+
+// decodeJSONResponse validates a JSON mode response against the offered tools and writes
+// it in the text protocol, like the calls of tool calling
+func decodeJSONResponse(response string, tools []*schema.ToolInfo, mcpTools map[string]McpToolCall, plugins map[string]string) (string, error) {
+	text := strings.TrimSpace(response)
+	// some models wrap the object in a code block despite the response format
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(strings.TrimPrefix(text, "```json"), "```")
+		text = strings.TrimSpace(strings.TrimSuffix(text, "```"))
+	}
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.DisallowUnknownFields()
+	var r jsonResponse
+	if err := dec.Decode(&r); err != nil {
+		return "", fmt.Errorf("not a JSON response object: %w", err)
+	}
+	if dec.More() {
+		return "", fmt.Errorf("text follows the JSON object")
+	}
+	if strings.TrimSpace(r.Message) == "" && len(r.Actions) == 0 {
+		return "", fmt.Errorf("both message and actions are empty")
+	}
+
+	calls := make([]schema.ToolCall, 0, len(r.Actions))
+	for i, action := range r.Actions {
+		var info *schema.ToolInfo
+		for _, t := range tools {
+			if t.Name == action.Name {
+				info = t
+				break
+			}
+		}
+		if info == nil {
+			return "", fmt.Errorf("action %d: unknown action %q", i+1, action.Name)
+		}
+		if action.Arguments == nil {
+			action.Arguments = map[string]interface{}{}
+		}
+		if params, err := info.ParamsOneOf.ToOpenAPIV3(); err == nil && params != nil {
+			if err := params.VisitJSON(action.Arguments); err != nil {
+				return "", fmt.Errorf("action %d (%s): %v", i+1, action.Name, err)
+			}
+		}
+		data, err := json.Marshal(action.Arguments)
+		if err != nil {
+			return "", fmt.Errorf("action %d (%s): %v", i+1, action.Name, err)
+		}
+		calls = append(calls, schema.ToolCall{Function: schema.FunctionCall{Name: action.Name, Arguments: string(data)}})
+	}
+	return strings.TrimSpace(r.Message + renderToolCalls(calls, mcpTools, plugins)), nil
+}
+
+// requestJSONResponse asks the model for a JSON mode response, with structured output
+// when the provider has it, and returns it in the text protocol. A response that doesn't
+// validate is sent back with the error, up to maxJSONRetries times.
+func (m *Manager) requestJSONResponse(ctx context.Context, sending []ChatMessage) (string, error) {
+	tools, mcpTools, plugins := m.requestTools()
+	jsonSchema := jsonResponseSchema(tools)
+	for attempt := 0; ; attempt++ {
+		var response string
+		var err error
+		if provider, ok := m.AiClient.(JSONSchemaProvider); ok {
+			response, err = provider.GetJSONResponseFromChatMessages(ctx, sending, m.GetOpenRouterModel(), "tmuxai_response", jsonSchema)
+		} else {
+			response, err = m.AiClient.GetResponseFromChatMessages(ctx, sending, m.GetOpenRouterModel())
+		}
+		if err != nil {
+			return "", err
+		}
+		text, err := decodeJSONResponse(response, tools, mcpTools, plugins)
+		if err == nil {
+			return text, nil
+		}
+		logger.Error("Invalid JSON response: %v\n%s", err, response)
+		if attempt >= maxJSONRetries {
+			return "", fmt.Errorf("no valid JSON response after %d attempts: %w", attempt+1, err)
+		}
+		m.Println(i18n.T("AI response didn't match the JSON schema, trying again..."))
+		// the retry is only sent, the history keeps the valid response
+		sending = append(sending[:len(sending):len(sending)],
+			ChatMessage{Content: response, FromUser: false, Timestamp: time.Now()},
+			ChatMessage{Content: fmt.Sprintf(jsonRetryPrompt, err), FromUser: true, Timestamp: time.Now()})
+	}
+}
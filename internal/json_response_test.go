@@ -0,0 +1,66 @@
+// Unit tests for JSON mode responses in json_response.go
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/cloudwego/eino/schema"
+)
+
+func builtinToolInfos() []*schema.ToolInfo {
+	var tools []*schema.ToolInfo
+	for _, t := range builtinTools {
+		tools = append(tools, t.info)
+	}
+	return tools
+}
+
+// Test: a valid response is written as tags, invalid ones are refused with the reason
+func TestDecodeJSONResponse(t *testing.T) {
+	tools := builtinToolInfos()
+	text, err := decodeJSONResponse("```json\n"+`{"message": "Listing files", "actions": [
+		{"name": "exec_command", "arguments": {"command": "ls -la"}},
+		{"name": "send_keys", "arguments": {"keys": ["q", "Enter"]}}
+	]}`+"\n```", tools, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := &Manager{Config: config.DefaultConfig()}
+	r, _ := m.parseAIResponse(text)
+	if r.Message != "Listing files" || len(r.ExecCommand) != 1 || r.ExecCommand[0] != "ls -la" || len(r.SendKeys) != 2 {
+		t.Errorf("unexpected response from %q: %+v", text, r)
+	}
+
+	for response, want := range map[string]string{
+		`<ExecCommand>ls</ExecCommand>`:                                           "not a JSON response object",
+		`{"message": "hi", "actions": [], "extra": 1}`:                            "not a JSON response object",
+		`{"message": "hi", "actions": []} and more`:                               "text follows",
+		`{"message": "", "actions": []}`:                                          "empty",
+		`{"message": "", "actions": [{"name": "rm_rf", "arguments": {}}]}`:        "unknown action",
+		`{"message": "", "actions": [{"name": "exec_command", "arguments": {}}]}`: "exec_command",
+	} {
+		if _, err := decodeJSONResponse(response, tools, nil, nil); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error with %q for %s, got %v", want, response, err)
+		}
+	}
+}
+
+// Test: a malformed response is sent back with the error and the next valid one is used
+func TestRequestJSONResponseRetries(t *testing.T) {
+	cfg := config.DefaultConfig()
+	provider, _ := NewMockProvider(config.MockConfig{Responses: []string{
+		"Sure, let me check.",
+		`{"message": "Checking", "actions": [{"name": "request_accomplished", "arguments": {}}]}`,
+	}})
+	m := &Manager{Config: cfg, AiClient: provider}
+	text, err := m.requestJSONResponse(context.Background(), []ChatMessage{{Content: "check", FromUser: true}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "Checking\n<RequestAccomplished>1</RequestAccomplished>" {
+		t.Errorf("unexpected response: %q", text)
+	}
+}
@@ -0,0 +1,322 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/nyaosorg/go-readline-ny"
+	"github.com/nyaosorg/go-readline-ny/keys"
+	"github.com/nyaosorg/go-readline-ny/moji"
+)
+
+const killRingSize = 30
+
+// killRing is the clipboard of the chat input; it keeps the last kills so
+// yank-pop can cycle through them
+type killRing struct {
+	entries []string
+	pos     int
+}
+
+func (r *killRing) Write(text string) error {
+	if text == "" {
+		return nil
+	}
+	r.entries = append(r.entries, text)
+	if len(r.entries) > killRingSize {
+		r.entries = r.entries[len(r.entries)-killRingSize:]
+	}
+	r.pos = len(r.entries) - 1
+	return nil
+}
+
+func (r *killRing) Read() (string, error) {
+	if len(r.entries) == 0 {
+		return "", nil
+	}
+	return r.entries[r.pos], nil
+}
+
+// rotate moves to the previous kill
+func (r *killRing) rotate() {
+	if len(r.entries) > 0 {
+		r.pos = (r.pos - 1 + len(r.entries)) % len(r.entries)
+	}
+}
+
+// lineEditor adds the emacs kill ring and a vi mode on top of the readline editor
+type lineEditor struct {
+	vi   bool
+	ring *killRing
+
+	// range inserted by the last yank, for yank-pop
+	yankPos, yankLen int
+	yanking, yanked  bool
+
+	normal  bool // vi command mode
+	pending byte // vi operator waiting for a motion: d, c or y
+
+	out io.Writer // the terminal, for the cursor shape
+}
+
+func newLineEditor(mode string, out io.Writer) *lineEditor {
+	return &lineEditor{vi: mode == "vi", ring: &killRing{}, out: out}
+}
+
+// bind installs the key bindings of the configured editing mode
+func (e *lineEditor) bind(editor *readline.Editor) {
+	editor.Clipboard = e.ring
+	editor.AfterCommand = func(*readline.Buffer) {
+		e.yanked = e.yanking
+		e.yanking = false
+	}
+
+	editor.BindKey(keys.CtrlY, e.command("YANK", func(B *readline.Buffer) { e.yank(B) }))
+	editor.BindKey(keys.AltY, e.command("YANK_POP", e.yankPop))
+	editor.BindKey(keys.AltD, e.command("KILL_WORD", func(B *readline.Buffer) {
+		e.kill(B, B.Cursor, nextWordStart(B, B.Cursor))
+	}))
+	editor.BindKey(keys.Code("\x1b\x7f"), // Alt+Backspace
+		e.command("BACKWARD_KILL_WORD", func(B *readline.Buffer) {
+			e.kill(B, prevWordStart(B, B.Cursor), B.Cursor)
+		}))
+
+	if !e.vi {
+		return
+	}
+	editor.BindKey(keys.Escape, e.command("VI_COMMAND_MODE", func(B *readline.Buffer) {
+		if !e.normal {
+			e.setNormal(true)
+			e.moveTo(B, B.Cursor-1)
+		}
+		e.pending = 0
+	}))
+	for c := byte(0x20); c < 0x7f; c++ {
+		key := string(c)
+		editor.BindKey(keys.Code(key), readline.AnonymousCommand(func(ctx context.Context, B *readline.Buffer) readline.Result {
+			if !e.normal {
+				return readline.SelfInserter(key).Call(ctx, B)
+			}
+			return e.viCommand(ctx, B, key[0])
+		}))
+	}
+}
+
+// reset starts every line in insert mode
+func (e *lineEditor) reset() {
+	if e.vi && e.normal {
+		e.setNormal(false)
+	}
+	e.pending = 0
+}
+
+func (e *lineEditor) command(name string, fn func(B *readline.Buffer)) readline.Command {
+	return readline.NewGoCommand(name, func(ctx context.Context, B *readline.Buffer) readline.Result {
+		fn(B)
+		return readline.CONTINUE
+	})
+}
+
+// setNormal switches vi modes, showing a block cursor in command mode
+func (e *lineEditor) setNormal(normal bool) {
+	e.normal = normal
+	if !system.CursorControl() {
+		return
+	}
+	if normal {
+		fmt.Fprint(e.out, "\x1b[2 q")
+	} else {
+		fmt.Fprint(e.out, "\x1b[0 q")
+	}
+}
+
+func (e *lineEditor) moveTo(B *readline.Buffer, pos int) {
+	last := len(B.Buffer)
+	if e.normal && last > 0 {
+		last-- // the command mode cursor sits on a character
+	}
+	B.Cursor = max(0, min(pos, last))
+	B.RepaintAfterPrompt()
+}
+
+// kill moves the text between from and to into the kill ring
+func (e *lineEditor) kill(B *readline.Buffer, from, to int) {
+	from, to = max(0, min(from, to)), min(len(B.Buffer), max(from, to))
+	if from == to {
+		return
+	}
+	e.ring.Write(B.SubString(from, to))
+	B.Delete(from, to-from)
+	e.moveTo(B, from)
+}
+
+func (e *lineEditor) yank(B *readline.Buffer) bool {
+	text, _ := e.ring.Read()
+	if text == "" {
+		return false
+	}
+	e.yankPos = B.Cursor
+	e.yankLen = B.InsertString(B.Cursor, text)
+	e.yanking = true
+	e.moveTo(B, e.yankPos+e.yankLen)
+	return true
+}
+
+// yankPop replaces the text just yanked with the previous kill
+func (e *lineEditor) yankPop(B *readline.Buffer) {
+	if !e.yanked || len(e.ring.entries) < 2 {
+		return
+	}
+	B.Delete(e.yankPos, e.yankLen)
+	e.ring.rotate()
+	text, _ := e.ring.Read()
+	e.yankLen = B.InsertString(e.yankPos, text)
+	e.yanking = true
+	e.moveTo(B, e.yankPos+e.yankLen)
+}
+
+// viCommand runs a key typed in vi command mode
+func (e *lineEditor) viCommand(ctx context.Context, B *readline.Buffer, key byte) readline.Result {
+	if op := e.pending; op != 0 {
+		e.pending = 0
+		from, to := B.Cursor, B.Cursor
+		if op == 'c' && key == 'w' {
+			key = 'e' // cw changes to the end of the word like in vim
+		}
+		if key == op {
+			from, to = 0, len(B.Buffer) // dd, cc, yy work on the whole line
+		} else if target, ok := viMotion(B, key); ok {
+			from, to = min(B.Cursor, target), max(B.Cursor, target)
+			if key == 'e' || key == '$' {
+				to++ // inclusive motions
+			}
+		}
+		if op == 'y' {
+			e.ring.Write(B.SubString(from, min(to, len(B.Buffer))))
+			return readline.CONTINUE
+		}
+		e.kill(B, from, to)
+		if op == 'c' {
+			e.setNormal(false)
+			e.moveTo(B, from)
+		}
+		return readline.CONTINUE
+	}
+
+	if target, ok := viMotion(B, key); ok {
+		e.moveTo(B, target)
+		return readline.CONTINUE
+	}
+
+	insertAt := func(pos int) {
+		e.setNormal(false)
+		e.moveTo(B, pos)
+	}
+	switch key {
+	case 'i':
+		insertAt(B.Cursor)
+	case 'a':
+		insertAt(B.Cursor + 1)
+	case 'I':
+		insertAt(0)
+	case 'A':
+		insertAt(len(B.Buffer))
+	case 'x':
+		e.kill(B, B.Cursor, B.Cursor+1)
+	case 'X':
+		e.kill(B, B.Cursor-1, B.Cursor)
+	case 's':
+		e.kill(B, B.Cursor, B.Cursor+1)
+		insertAt(B.Cursor)
+	case 'S':
+		e.kill(B, 0, len(B.Buffer))
+		insertAt(0)
+	case 'D':
+		e.kill(B, B.Cursor, len(B.Buffer))
+	case 'C':
+		e.kill(B, B.Cursor, len(B.Buffer))
+		insertAt(len(B.Buffer))
+	case 'd', 'c', 'y':
+		e.pending = key
+	case 'p', 'P':
+		if len(e.ring.entries) == 0 {
+			break
+		}
+		if key == 'p' && len(B.Buffer) > 0 {
+			B.Cursor++
+		}
+		if e.yank(B) {
+			e.moveTo(B, e.yankPos+e.yankLen-1)
+		}
+	case 'u':
+		readline.CmdUndo.Call(ctx, B)
+		e.moveTo(B, B.Cursor)
+	case 'k':
+		return readline.CmdPreviousHistory.Call(ctx, B)
+	case 'j':
+		return readline.CmdNextHistory.Call(ctx, B)
+	case '/':
+		return readline.CmdISearchBackward.Call(ctx, B)
+	}
+	return readline.CONTINUE
+}
+
+// viMotion returns where a vi motion key moves the cursor
+func viMotion(B *readline.Buffer, key byte) (int, bool) {
+	switch key {
+	case 'h':
+		return B.Cursor - 1, true
+	case 'l', ' ':
+		return B.Cursor + 1, true
+	case '0':
+		return 0, true
+	case '^':
+		pos := 0
+		for pos < len(B.Buffer) && isSpaceCell(B, pos) {
+			pos++
+		}
+		return pos, true
+	case '$':
+		return len(B.Buffer) - 1, true
+	case 'w':
+		return nextWordStart(B, B.Cursor), true
+	case 'b':
+		return prevWordStart(B, B.Cursor), true
+	case 'e':
+		pos := B.Cursor + 1
+		for pos < len(B.Buffer) && isSpaceCell(B, pos) {
+			pos++
+		}
+		for pos+1 < len(B.Buffer) && !isSpaceCell(B, pos+1) {
+			pos++
+		}
+		return min(pos, len(B.Buffer)-1), true
+	}
+	return 0, false
+}
+
+func isSpaceCell(B *readline.Buffer, pos int) bool {
+	return moji.IsSpaceMoji(B.Buffer[pos].Moji)
+}
+
+func nextWordStart(B *readline.Buffer, pos int) int {
+	for pos < len(B.Buffer) && !isSpaceCell(B, pos) {
+		pos++
+	}
+	for pos < len(B.Buffer) && isSpaceCell(B, pos) {
+		pos++
+	}
+	return pos
+}
+
+func prevWordStart(B *readline.Buffer, pos int) int {
+	for pos > 0 && isSpaceCell(B, pos-1) {
+		pos--
+	}
+	for pos > 0 && !isSpaceCell(B, pos-1) {
+		pos--
+	}
+	return pos
+}
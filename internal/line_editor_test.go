@@ -0,0 +1,34 @@
+// Unit tests for the chat input kill ring in line_editor.go
+package internal
+
+import "testing"
+
+// Test: the newest kill is yanked and rotate cycles back through older ones
+func TestKillRing(t *testing.T) {
+	ring := &killRing{}
+	if text, _ := ring.Read(); text != "" {
+		t.Fatalf("empty ring yanked %q", text)
+	}
+	ring.Write("one")
+	ring.Write("")
+	ring.Write("two")
+
+	if text, _ := ring.Read(); text != "two" {
+		t.Errorf("expected newest kill, got %q", text)
+	}
+	ring.rotate()
+	if text, _ := ring.Read(); text != "one" {
+		t.Errorf("expected previous kill after rotate, got %q", text)
+	}
+	ring.rotate()
+	if text, _ := ring.Read(); text != "two" {
+		t.Errorf("expected rotate to wrap around, got %q", text)
+	}
+
+	for i := 0; i < killRingSize+5; i++ {
+		ring.Write("x")
+	}
+	if len(ring.entries) != killRingSize {
+		t.Errorf("expected ring capped at %d, got %d", killRingSize, len(ring.entries))
+	}
+}
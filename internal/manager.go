@@ -1,15 +1,21 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
 	"github.com/alvinunreal/tmuxai/logger"
 	"github.com/alvinunreal/tmuxai/system"
-	"github.com/fatih/color"
+	"golang.org/x/term"
 )
 
 type AIResponse struct {
@@ -23,6 +29,14 @@ type AIResponse struct {
 	NoComment              bool
 	// 新增MCP工具调用支持
 	McpToolCalls []McpToolCall
+	FileEdits    []FileEdit
+	FilePatches  []FilePatch
+	FileReads    []string
+	FetchURLs    []string
+	// PluginToolCalls are calls of the executables in the tools dir
+	PluginToolCalls []PluginToolCall
+	// Delegations are subtasks handed to sub-agents
+	Delegations []string
 }
 
 // MCP工具调用结构体
@@ -32,6 +46,18 @@ type McpToolCall struct {
 	Arguments  map[string]interface{} `json:"arguments"`
 }
 
+// ExecutedCommand is a command the agent ran in the exec pane during the session
+type ExecutedCommand struct {
+	Command     string
+	Explanation string // the AI message the command was suggested with
+	Cwd         string
+	Code        *int   // only known when the pane is prepared
+	Output      string // only captured when the pane is prepared
+	Timestamp   time.Time
+	Undo        bool // run by /undo to reverse an earlier command
+	Undone      bool // reversed by /undo
+}
+
 // Parsed only when pane is prepared
 type CommandExecHistory struct {
 	Command string
@@ -42,26 +68,112 @@ type CommandExecHistory struct {
 // Manager represents the TmuxAI manager agent
 type Manager struct {
 	Config           *config.Config
-	AiClient         *AiClient
-	Status           string // running, waiting, done
+	AiClient         ChatProvider
 	PaneId           string
 	ExecPane         *system.TmuxPaneDetails
 	Messages         []ChatMessage
 	ExecHistory      []CommandExecHistory
-	WatchMode        bool
-	OS               string
+	ExecutedCommands []ExecutedCommand      // commands run by the agent, for /export-script
 	SessionOverrides map[string]interface{} // session-only config overrides
 	McpServers       []config.McpServer     // currently selected MCP servers for this session
 	// 新增MCP客户端
 	McpClient *McpClient
+	// Scripts holds commands and hooks loaded from the scripts dir
+	Scripts *ScriptEngine
+	// Plugins are the executables of the tools dir offered to the model
+	Plugins []PluginTool
+	// FlagOverrides applies the command-line flags to a config, so a reloaded config keeps them
+	FlagOverrides func(cfg *config.Config)
+	// ConfirmFunc resolves confirmations without prompting when set (CI mode)
+	ConfirmFunc func(content, prompt string) (bool, string)
+	// Output receives the human-readable output, see out
+	Output io.Writer
+
+	// waitingSince is when the pending model call started, zero when idle; guarded by stateMu
+	waitingSince time.Time
+	// tui is the full-screen interface when it is running, nil for the readline chat
+	tui *TUIInterface
+	// sharePaneId is the pane showing the read-only transcript mirror, if any, and
+	// stopShare ends the mirror and removes its file; both guarded by stateMu
+	sharePaneId string
+	stopShare   func()
+	// header is the status line in the chat pane's border, nil when off
+	header *statusHeader
+	// alwaysApproved holds the confirmations answered with "always" this session
+	alwaysApproved map[string]bool
+	// approvedPatterns holds the regexes approved with "pattern" this session, by prompt
+	approvedPatterns map[string][]*regexp.Regexp
+	// commandRules is the compiled command_rules, whitelist and blacklist
+	commandRules *commandRules
+	// alwaysConfirm asks for every action of a turn, whatever the *_confirm settings say; set by /undo
+	alwaysConfirm bool
+	// lastAIMessage is the explanation of the response being confirmed, shown by "view"
+	lastAIMessage string
+	// store holds the history moved out of memory, nil until the first spill
+	store *sessionStore
+	// steps lists the commands run for the current request, nil outside of runRequest
+	steps *stepChecklist
+	// queue holds the requests of /queue
+	queue taskQueue
+	// tasks holds the latest requests with their steps, for /tasks
+	tasks []*requestTask
+	// subAgents are the sub-agents started by /delegate and DelegateTask; guarded by subAgentsMu
+	subAgents   []*subAgent
+	subAgentsMu sync.Mutex
+	// agentLabel names a sub-agent in its prompt, "" for the main agent
+	agentLabel string
+
+	// turnMu serializes agent turns coming from the chat and from external inputs
+	turnMu sync.Mutex
+	// stateMu guards status, watchMode, waitingSince, Messages, SessionOverrides, Config, AiClient, McpServers and McpClient, see state.go
+	stateMu sync.RWMutex
+	// status is the agent status: running, waiting, done, or "" when idle
+	status string
+	// watchMode is on while /watch runs
+	watchMode bool
+	// chatReading is true while the readline chat waits for input, chatPrompt is the
+	// question of another turn its next line answers; both guarded by stateMu
+	chatReading bool
+	chatPrompt  *chatPrompt
+	// recoveryOnce saves the crash snapshot once when a panic unwinds several turns
+	recoveryOnce sync.Once
+	// shutdownOnce makes Shutdown run once, whether it is reached by /exit, a signal or Start returning
+	shutdownOnce sync.Once
+	// termState is the terminal mode from before Start, restored when a signal ends the process
+	termState *term.State
+	// startupDone is closed once the exec pane and scripts are set up, nil when they were
+	// set up synchronously
+	startupDone chan struct{}
+	// osInfo is the local OS description, looked up once by osDetails
+	osInfo string
+	osOnce sync.Once
+	// stats collects the numbers shown by /stats
+	stats sessionStats
+	// prices are the model prices for /cost, fetched on first use
+	prices priceList
+	// contextPanes are the ids of the read-only panes added with /context add
+	contextPanes []string
+	// watchOpts are the options of the running /watch
+	watchOpts watchOptions
+	// telemetry counts usage while the user opted in, nil otherwise; guarded by stateMu
+	telemetry *telemetry
 }
 
 // NewManager creates a new manager agent
 // 在 NewManager 函数中修复 MCP 客户端初始化
 func NewManager(cfg *config.Config) (*Manager, error) {
-	if cfg.OpenRouter.APIKey == "" {
-		fmt.Println("OpenRouter API key is required. Set it in the config file or as an environment variable: TMUXAI_OPENROUTER_API_KEY")
-		return nil, fmt.Errorf("OpenRouter API key is required")
+	provider, err := NewChatProvider(cfg)
+	if errors.Is(err, ErrNoAPIKey) {
+		if cfg.ProviderSection() == nil {
+			fmt.Fprintln(humanOutput(), i18n.T("OpenRouter API key is required. Set it in the config file or as an environment variable: TMUXAI_OPENROUTER_API_KEY"))
+		} else {
+			fmt.Fprintln(humanOutput(), i18n.T("API key for %s is required. Set %s", cfg.Provider, cfg.APIKeyHint()))
+		}
+		return nil, err
+	}
+	if err != nil {
+		fmt.Fprintln(humanOutput(), err)
+		return nil, err
 	}
 
 	paneId, err := system.TmuxCurrentPaneId()
@@ -93,76 +205,232 @@ func NewManager(cfg *config.Config) (*Manager, error) {
 		os.Exit(0)
 	}
 
-	aiClient := NewAiClient(&cfg.OpenRouter)
-	os := system.GetOSDetails()
-
-	// 初始化空的 MCP 客户端（不连接任何服务器）
-	mcpClient := NewMcpClient([]config.McpServer{})
+	manager := NewManagerForPane(cfg, paneId, provider)
+	manager.initInBackground(ScriptsDir(), PluginToolsDir())
+	return manager, nil
+}
 
-	manager := &Manager{
+// NewManagerForPane creates a manager bound to an existing tmux pane without
+// bootstrapping a tmux session, picking an exec pane or loading user scripts.
+// It is the entry point for embedding the agent loop in other programs.
+func NewManagerForPane(cfg *config.Config, paneId string, provider ChatProvider) *Manager {
+	m := &Manager{
 		Config:           cfg,
-		AiClient:         aiClient,
+		AiClient:         provider,
 		PaneId:           paneId,
 		Messages:         []ChatMessage{},
 		ExecHistory:      []CommandExecHistory{},
 		ExecPane:         &system.TmuxPaneDetails{},
-		OS:               os,
 		SessionOverrides: make(map[string]interface{}),
 		McpServers:       []config.McpServer{}, // 改为空数组，用户需要主动选择
-		McpClient:        mcpClient,
+		// 初始化空的 MCP 客户端（不连接任何服务器）
+		McpClient: NewMcpClient([]config.McpServer{}, McpClientOptions{}),
 	}
-	manager.InitExecPane()
-	return manager, nil
+	m.stats.start()
+	m.reloadRules()
+	m.applyTheme(cfg.Theme.Preset)
+	system.SetASCIIOnly(cfg.ASCII)
+	system.SetCaptureTTL(time.Duration(cfg.CaptureCacheTTL) * time.Millisecond)
+	if err := system.ConfigureHTTP(cfg.HTTP.CAFile, cfg.HTTP.InsecureSkipVerify); err != nil {
+		logger.Error("Invalid http settings: %v", err)
+	}
+	if err := i18n.SetLanguage(cfg.Language); err != nil {
+		logger.Error("Invalid language: %v", err)
+	}
+	return m
+}
+
+// applyTheme switches the colors to a preset with the configured overrides
+func (m *Manager) applyTheme(preset string) {
+	theme, err := system.LoadTheme(preset, m.Config.Theme.Colors)
+	if err != nil {
+		logger.Error("Invalid theme colors: %v", err)
+	}
+	system.SetTheme(theme)
 }
 
 // Start starts the manager agent
 func (m *Manager) Start(initMessage string) error {
-	cliInterface := NewCLIInterface(m)
+	defer m.recoverPanic()
+	stopSignals := m.handleSignals()
+	defer stopSignals()
+	defer m.Shutdown()
+
+	var ui interface{ Start(string) error } = NewCLIInterface(m)
+	if m.Config.Interface == "tui" && !JSONEventsEnabled() && system.CursorControl() {
+		if tuiAvailable {
+			ui = NewTUIInterface(m)
+		} else {
+			logger.Info("interface is tui but the binary was built without it")
+			m.Println(i18n.T("This build has no TUI (build with -tags tui), using the chat"))
+		}
+	}
+	m.offerRecovery()
+	m.announceUpdate()
+	m.startTelemetry()
+
+	if m.Config.ControlSocket {
+		controlServer, err := StartControlServer(m)
+		if err != nil {
+			logger.Error("Control socket disabled: %v", err)
+		} else {
+			defer controlServer.Close()
+		}
+	}
+	if m.Config.Debug && m.Config.PprofAddr != "" {
+		pprofServer, err := startPprof(m.Config.PprofAddr)
+		if err != nil {
+			logger.Error("pprof disabled: %v", err)
+		} else {
+			defer pprofServer.Close()
+		}
+	}
+	if m.Config.FifoInput {
+		fifo, err := StartFifoInput(m)
+		if err != nil {
+			logger.Error("FIFO input disabled: %v", err)
+		} else {
+			defer fifo.Close()
+		}
+	}
+	if m.Config.ConfigReload {
+		watcher, err := StartConfigWatcher(m)
+		if err != nil {
+			logger.Error("Config reload disabled: %v", err)
+		} else {
+			defer watcher.Close()
+		}
+	}
+
+	m.startStatusHeader()
+	defer m.stopStatusHeader()
+
 	if initMessage != "" {
 		logger.Info("Initial task provided: %s", initMessage)
 	}
-	if err := cliInterface.Start(initMessage); err != nil {
+	if err := ui.Start(initMessage); err != nil {
 		logger.Error("Failed to start CLI interface: %v", err)
 		return err
 	}
 	return nil
 }
 
+// HandleExternalMessage processes a message or /command that arrived from outside the
+// chat input (API, control socket, ...). It waits for any running turn to finish first.
+func (m *Manager) HandleExternalMessage(source, message string) {
+	defer m.recoverPanic()
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+
+	logger.Info("External message from %s: %s", source, message)
+	fmt.Fprintf(m.out(), "\n%s[%s] %s\n", m.GetPrompt(), source, message)
+
+	if m.IsMessageSubcommand(message) {
+		m.ProcessSubCommand(message)
+		return
+	}
+
+	m.runRequest(context.Background(), message)
+}
+
+// RunTurn runs one turn for message like the chat does, waiting for the running turn
+// first. It returns true when the model reports the request as accomplished.
+func (m *Manager) RunTurn(ctx context.Context, message string) bool {
+	defer m.recoverPanic()
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+	m.ready()
+
+	m.SetStatus("running")
+	defer m.SetStatus("")
+	return m.ProcessUserMessage(ctx, message)
+}
+
 func (m *Manager) Println(msg string) {
-	fmt.Println(m.GetPrompt() + msg)
+	fmt.Fprintln(m.out(), system.WrapText(m.GetPrompt()+msg, system.TerminalWidth()))
+}
+
+// out is where human-readable output goes: Output when set, humanOutput otherwise
+func (m *Manager) out() io.Writer {
+	if m.Output != nil {
+		return m.Output
+	}
+	return humanOutput()
+}
+
+// tellModel tells the user and the model how an action went, e.g. a file edit, a fetch
+// or a rule block
+func (m *Manager) tellModel(result string) {
+	m.Println(result)
+	m.appendMessages(ChatMessage{
+		Content:   result,
+		FromUser:  false,
+		Timestamp: time.Now(),
+	})
+}
+
+// highlightTheme returns the configured chroma style, or "" when highlighting is off
+func (m *Manager) highlightTheme() string {
+	if !m.GetHighlightEnabled() {
+		return ""
+	}
+	return m.GetHighlightTheme()
 }
 
-func (m *Manager) GetConfig() *config.Config {
-	return m.Config
+// cosmetics formats markdown code in AI output with the configured theme and wraps
+// it to the terminal width
+func (m *Manager) cosmetics(message string) string {
+	return system.WrapText(system.CosmeticsTheme(message, m.highlightTheme()), system.TerminalWidth())
 }
 
-// getPrompt returns the prompt string with color
+// highlightCode highlights a command or snippet shown for confirmation
+func (m *Manager) highlightCode(language, code string) string {
+	highlighted, err := system.HighlightCodeTheme(language, code, m.highlightTheme())
+	if err != nil {
+		return code
+	}
+	return highlighted
+}
+
+// GetPrompt renders the prompt_format template with color
 func (m *Manager) GetPrompt() string {
-	tmuxaiColor := color.New(color.FgGreen, color.Bold)
-	arrowColor := color.New(color.FgYellow, color.Bold)
-	stateColor := color.New(color.FgMagenta, color.Bold)
+	theme := system.CurrentTheme()
+	return renderPrompt(system.Sym(m.GetPromptFormat()), theme.PromptArrow.Sprint, map[string]func() string{
+		"name": func() string { return theme.Prompt.Sprint("CNP-AI" + m.agentLabel) },
+		"state": func() string {
+			if symbol := m.stateSymbol(); symbol != "" {
+				return theme.PromptState.Sprint("[" + symbol + "]")
+			}
+			return ""
+		},
+		"model": func() string { return theme.PromptState.Sprint(m.GetOpenRouterModel()) },
+		"tokens": func() string {
+			return theme.PromptState.Sprintf("~%d", m.contextTokens())
+		},
+		"context": func() string {
+			if m.GetMaxContextSize() <= 0 {
+				return ""
+			}
+			return theme.PromptState.Sprintf("%d%%", m.contextTokens()*100/m.GetMaxContextSize())
+		},
+	})
+}
 
-	var stateSymbol string
-	switch m.Status {
+// stateSymbol returns the prompt marker for the agent status
+func (m *Manager) stateSymbol() string {
+	if m.GetWatchMode() {
+		return system.Sym("∞")
+	}
+	switch m.GetStatus() {
 	case "running":
-		stateSymbol = "▶"
+		return system.Sym("▶")
 	case "waiting":
-		stateSymbol = "?"
+		return "?"
 	case "done":
-		stateSymbol = "✓"
+		return system.Sym("✓")
 	default:
-		stateSymbol = ""
-	}
-	if m.WatchMode {
-		stateSymbol = "∞"
-	}
-
-	prompt := tmuxaiColor.Sprint("CNP-AI")
-	if stateSymbol != "" {
-		prompt += " " + stateColor.Sprint("["+stateSymbol+"]")
+		return ""
 	}
-	prompt += arrowColor.Sprint(" » ")
-	return prompt
 }
 
 func (ai *AIResponse) String() string {
@@ -0,0 +1,225 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+// mcpTokenDir returns where /mcp login keeps the OAuth tokens; tests override it
+var mcpTokenDir = func() string {
+	return config.GetConfigFilePath("mcp_tokens")
+}
+
+// mcpCredentials is what /mcp login keeps for a server: the client it registered, if
+// any, and the token
+type mcpCredentials struct {
+	ClientID     string           `json:"client_id,omitempty"`
+	ClientSecret string           `json:"client_secret,omitempty"`
+	Token        *transport.Token `json:"token,omitempty"`
+}
+
+// mcpTokenPath is the credentials file of a server
+func mcpTokenPath(server string) string {
+	return filepath.Join(mcpTokenDir(), toolNameRe.ReplaceAllString(server, "_")+".json")
+}
+
+func loadMcpCredentials(server string) mcpCredentials {
+	var creds mcpCredentials
+	if data, err := os.ReadFile(mcpTokenPath(server)); err == nil {
+		json.Unmarshal(data, &creds)
+	}
+	return creds
+}
+
+func saveMcpCredentials(server string, creds mcpCredentials) error {
+	if err := os.MkdirAll(mcpTokenDir(), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(mcpTokenPath(server), data, 0o600)
+}
+
+// fileTokenStore keeps the OAuth token of a server on disk, so tokens refreshed during
+// a session are there for the next one
+type fileTokenStore struct {
+	server string
+	mu     sync.Mutex
+}
+
+func (s *fileTokenStore) GetToken() (*transport.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds := loadMcpCredentials(s.server)
+	if creds.Token == nil {
+		return nil, errors.New("no token available")
+	}
+	return creds.Token, nil
+}
+
+func (s *fileTokenStore) SaveToken(token *transport.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds := loadMcpCredentials(s.server)
+	if token.ExpiresAt.IsZero() && token.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	creds.Token = token
+	return saveMcpCredentials(s.server, creds)
+}
+
+// mcpOAuthConfig is the OAuth config of a server, using the client /mcp login
+// registered when none is configured
+func mcpOAuthConfig(server config.McpServer, redirectURI string) transport.OAuthConfig {
+	cfg := server.OAuth
+	clientID, secret := os.ExpandEnv(cfg.ClientID), os.ExpandEnv(cfg.ClientSecret)
+	if clientID == "" {
+		creds := loadMcpCredentials(server.Name)
+		clientID, secret = creds.ClientID, creds.ClientSecret
+	}
+	return transport.OAuthConfig{
+		ClientID:     clientID,
+		ClientSecret: secret,
+		RedirectURI:  redirectURI,
+		Scopes:       cfg.Scopes,
+		TokenStore:   &fileTokenStore{server: server.Name},
+		PKCEEnabled:  true,
+	}
+}
+
+// mcpHeaders returns the headers of a server with ${VAR} expanded, and its api_key as
+// a bearer token unless an Authorization header is set
+func mcpHeaders(server config.McpServer) map[string]string {
+	headers := map[string]string{}
+	for key, value := range server.Headers {
+		headers[key] = os.ExpandEnv(value)
+	}
+	if key := os.ExpandEnv(server.APIKey); key != "" {
+		found := false
+		for name := range headers {
+			found = found || strings.EqualFold(name, "Authorization")
+		}
+		if !found {
+			headers["Authorization"] = "Bearer " + key
+		}
+	}
+	return headers
+}
+
+// mcpAuthError turns the error of a server that wants OAuth authorization into a hint
+func mcpAuthError(server string, err error) error {
+	if client.IsOAuthAuthorizationRequiredError(err) || errors.Is(err, transport.ErrOAuthAuthorizationRequired) {
+		return fmt.Errorf("authorization required, run /mcp login %s", server)
+	}
+	return err
+}
+
+// mcpLogin authorizes tmuxai with a server in the browser (authorization code flow with
+// PKCE) and stores the token. Without a configured client one is registered first.
+// show prints the URL to open in case the browser doesn't start.
+func mcpLogin(ctx context.Context, server config.McpServer, show func(authURL string)) error {
+	if server.URL == "" {
+		return fmt.Errorf("MCP server '%s' has no url", server.Name)
+	}
+	redirectURI := server.OAuth.RedirectURI
+	addr := "127.0.0.1:0"
+	if redirectURI != "" {
+		u, err := url.Parse(redirectURI)
+		if err != nil {
+			return fmt.Errorf("invalid oauth.redirect_uri: %w", err)
+		}
+		addr = u.Host
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for the OAuth callback: %w", err)
+	}
+	defer listener.Close()
+	if redirectURI == "" {
+		redirectURI = fmt.Sprintf("http://%s/callback", listener.Addr())
+	}
+
+	oauth := mcpOAuthConfig(server, redirectURI)
+	if server.OAuth.ClientID == "" {
+		// a registered client is tied to its redirect uri, which changes between logins
+		oauth.ClientID, oauth.ClientSecret = "", ""
+	}
+	handler := transport.NewOAuthHandler(oauth)
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	handler.SetBaseURL(serverURL.Scheme + "://" + serverURL.Host)
+	if oauth.ClientID == "" {
+		if err := handler.RegisterClient(ctx, "tmuxai"); err != nil {
+			return fmt.Errorf("failed to register a client, set oauth.client_id: %w", err)
+		}
+		creds := loadMcpCredentials(server.Name)
+		creds.ClientID, creds.ClientSecret = handler.GetClientID(), handler.GetClientSecret()
+		if err := saveMcpCredentials(server.Name, creds); err != nil {
+			return err
+		}
+	}
+
+	verifier, err := client.GenerateCodeVerifier()
+	if err != nil {
+		return err
+	}
+	state, err := client.GenerateState()
+	if err != nil {
+		return err
+	}
+	authURL, err := handler.GetAuthorizationURL(ctx, state, client.GenerateCodeChallenge(verifier))
+	if err != nil {
+		return fmt.Errorf("failed to build the authorization url: %w", err)
+	}
+
+	type callback struct{ code, state, err string }
+	callbacks := make(chan callback, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("code") == "" && q.Get("error") == "" {
+			http.NotFound(w, r)
+			return
+		}
+		select {
+		case callbacks <- callback{code: q.Get("code"), state: q.Get("state"), err: q.Get("error")}:
+		default:
+		}
+		fmt.Fprintln(w, "TmuxAI: authorization received, you can close this window.")
+	})}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	show(authURL)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(5 * time.Minute):
+		return errors.New("timed out waiting for the authorization")
+	case cb := <-callbacks:
+		if cb.err != "" {
+			return fmt.Errorf("authorization failed: %s", cb.err)
+		}
+		if err := handler.ProcessAuthorizationResponse(ctx, cb.code, cb.state, verifier); err != nil {
+			return fmt.Errorf("failed to exchange the authorization code: %w", err)
+		}
+	}
+	return nil
+}
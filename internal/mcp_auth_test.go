@@ -0,0 +1,71 @@
+// Unit tests for MCP authentication in mcp_auth.go
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: api_key becomes a bearer token unless an Authorization header is configured,
+// and ${VAR} is expanded in header values
+func TestMcpHeaders(t *testing.T) {
+	t.Setenv("MCP_TEST_TOKEN", "secret")
+	got := mcpHeaders(config.McpServer{APIKey: "${MCP_TEST_TOKEN}", Headers: map[string]string{"X-Team": "${MCP_TEST_TOKEN}-team"}})
+	if got["Authorization"] != "Bearer secret" || got["X-Team"] != "secret-team" {
+		t.Errorf("unexpected headers %v", got)
+	}
+	got = mcpHeaders(config.McpServer{APIKey: "key", Headers: map[string]string{"authorization": "Token abc"}})
+	if len(got) != 1 || got["authorization"] != "Token abc" {
+		t.Errorf("the configured header should win, got %v", got)
+	}
+}
+
+// Test: /mcp login registers a client, exchanges the code of the callback and stores
+// the token with the client for the next connections
+func TestMcpLogin(t *testing.T) {
+	dir := t.TempDir()
+	defer func(orig func() string) { mcpTokenDir = orig }(mcpTokenDir)
+	mcpTokenDir = func() string { return dir }
+
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/register":
+			json.NewEncoder(w).Encode(map[string]string{"client_id": "registered-id"})
+		case "/token":
+			r.ParseForm()
+			if r.Form.Get("code") != "the-code" || r.Form.Get("client_id") != "registered-id" || r.Form.Get("code_verifier") == "" {
+				http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "tok", "token_type": "bearer", "expires_in": 3600})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer auth.Close()
+
+	server := config.McpServer{Name: "remote", Type: "http", URL: auth.URL + "/mcp", OAuth: config.McpOAuthConfig{Enabled: true}}
+	err := mcpLogin(context.Background(), server, func(authURL string) {
+		u, _ := url.Parse(authURL)
+		q := u.Query()
+		// what the browser does once the user approved
+		go http.Get(q.Get("redirect_uri") + "?code=the-code&state=" + url.QueryEscape(q.Get("state")))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	creds := loadMcpCredentials("remote")
+	if creds.ClientID != "registered-id" || creds.Token == nil || creds.Token.AccessToken != "tok" || creds.Token.ExpiresAt.IsZero() {
+		t.Errorf("unexpected credentials %+v", creds)
+	}
+	if cfg := mcpOAuthConfig(server, ""); cfg.ClientID != "registered-id" {
+		t.Errorf("the registered client is not used, got %q", cfg.ClientID)
+	}
+}
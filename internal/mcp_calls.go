@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// runMcpToolCalls asks about the calls the tool policy wants confirmed one after another,
+// then runs the approved ones concurrently, at most mcp.parallel_calls at a time, and
+// returns their results for the history in the order of the calls
+func (m *Manager) runMcpToolCalls(ctx context.Context, calls []McpToolCall) []ChatMessage {
+	results := make([]string, len(calls))
+	var approved []int
+	for i, call := range calls {
+		if ok, reason := m.approveToolCall(call); !ok {
+			results[i] = fmt.Sprintf("MCP tool %s.%s was not called: %s", call.ServerName, call.ToolName, reason)
+			continue
+		}
+		approved = append(approved, i)
+	}
+
+	workers := max(m.Config.Mcp.ParallelCalls, 1)
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for _, i := range approved {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, call McpToolCall) {
+			defer func() { <-sem; wg.Done() }()
+			result, err := m.McpClient.CallTool(ctx, call.ServerName, call.ToolName, call.Arguments)
+			m.stats.recordToolCall(call.ServerName, call.ToolName)
+			emitEvent(EventToolCall, map[string]interface{}{
+				"server":    call.ServerName,
+				"tool":      call.ToolName,
+				"arguments": call.Arguments,
+				"result":    result,
+				"error":     errorString(err),
+			})
+			if err != nil {
+				results[i] = fmt.Sprintf("MCP tool %s.%s failed: %v", call.ServerName, call.ToolName, err)
+			} else {
+				results[i] = fmt.Sprintf("MCP tool %s.%s result: %s", call.ServerName, call.ToolName, result)
+			}
+		}(i, calls[i])
+	}
+	wg.Wait()
+
+	var messages []ChatMessage
+	for _, result := range results {
+		// calls not started before a cancel leave no result
+		if result != "" {
+			messages = append(messages, ChatMessage{Content: result, FromUser: false, Timestamp: time.Now()})
+		}
+	}
+	return messages
+}
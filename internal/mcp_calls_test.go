@@ -0,0 +1,68 @@
+// Unit tests for running MCP tool calls in mcp_calls.go
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newInProcessMcpClient serves a "slow" tool on server fs that sleeps for its ms argument
+func newInProcessMcpClient(t *testing.T) *McpClient {
+	s := server.NewMCPServer("test", "1.0.0")
+	// CallTool prefixes the tool name with the server's
+	s.AddTool(mcp.NewTool("fs-slow"), func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ms := req.GetInt("ms", 0)
+		time.Sleep(time.Duration(ms) * time.Millisecond)
+		return mcp.NewToolResultText(req.GetString("id", "")), nil
+	})
+	c, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	mc := NewMcpClient(nil, McpClientOptions{})
+	mc.clients["fs"] = c
+	t.Cleanup(func() { mc.Close() })
+	return mc
+}
+
+// Test: approved calls run concurrently, results keep the order of the calls and denied
+// calls are reported in their place
+func TestRunMcpToolCalls(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Mcp.ParallelCalls = 3
+	cfg.Mcp.ToolPolicy = []config.McpToolRule{{Tool: "fs.denied", Action: "deny"}}
+	m := &Manager{Config: cfg, McpClient: newInProcessMcpClient(t)}
+
+	calls := []McpToolCall{
+		{ServerName: "fs", ToolName: "slow", Arguments: map[string]interface{}{"id": "first", "ms": 300}},
+		{ServerName: "fs", ToolName: "denied"},
+		{ServerName: "fs", ToolName: "slow", Arguments: map[string]interface{}{"id": "second", "ms": 300}},
+		{ServerName: "fs", ToolName: "slow", Arguments: map[string]interface{}{"id": "third", "ms": 10}},
+	}
+	started := time.Now()
+	messages := m.runMcpToolCalls(context.Background(), calls)
+	if elapsed := time.Since(started); elapsed > 550*time.Millisecond {
+		t.Errorf("calls took %s, expected them to overlap", elapsed)
+	}
+	if len(messages) != 4 {
+		t.Fatalf("got %d messages", len(messages))
+	}
+	for i, want := range []string{"result: first", "not called: denied", "result: second", "result: third"} {
+		if !strings.Contains(messages[i].Content, want) {
+			t.Errorf("message %d = %q, want %q", i, messages[i].Content, want)
+		}
+	}
+}
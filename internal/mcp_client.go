@@ -2,12 +2,18 @@ package internal
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/alvinunreal/tmuxai/config"
 	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,81 +21,381 @@ import (
 
 type McpClient struct {
 	clients map[string]*client.Client
+	procs   map[*client.Client]*stdioProcess // of the stdio servers
+	servers map[string]config.McpServer
+	status  map[string]*McpServerStatus
+	tools   map[string]cachedTools
+	opts    McpClientOptions
+	stop    chan struct{}
+	closed  bool
 	mu      sync.RWMutex
+
+	connectMu sync.Mutex // one lazy connection at a time, so a server isn't started twice
+}
+
+// McpClientOptions tune how McpClient connects and caches; the zero value connects up
+// front, without health checks or caching
+type McpClientOptions struct {
+	HealthCheck  time.Duration // between pings, servers that fail reconnect with backoff
+	Lazy         bool          // connect on first use instead of up front
+	ToolCacheTTL time.Duration // how long listed tools are reused
+	CacheFile    string        // keeps listed tools across sessions, so lazy servers aren't started to describe them
+}
+
+// cachedTools is the tool list of a server as it was fetched
+type cachedTools struct {
+	Fingerprint string     `json:"fingerprint"` // of the server's config, a changed server isn't served stale tools
+	Tools       []mcp.Tool `json:"tools"`
+	Fetched     time.Time  `json:"fetched"`
+}
+
+// McpServerStatus is the connection state of a server, kept up to date by the health checks
+type McpServerStatus struct {
+	Name      string
+	Connected bool
+	Latency   time.Duration // of the last ping
+	LastError string
+	LastCheck time.Time
+	Failures  int       // connection attempts failed in a row
+	NextRetry time.Time // when a disconnected server is tried again
+	Restarts  int       // of a stdio server whose process exited, in a row
+	Stopped   bool      // the stdio server exited more than retry_count times in a row, it isn't restarted
+}
+
+// maxMcpBackoff caps the wait between reconnection attempts
+const maxMcpBackoff = 5 * time.Minute
+
+// mcpBackoff is the wait before the next reconnection attempt: the health check interval,
+// doubled with each failure in a row
+func mcpBackoff(interval time.Duration, failures int) time.Duration {
+	wait := interval
+	for i := 1; i < failures && wait < maxMcpBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxMcpBackoff)
 }
 
-func NewMcpClient(servers []config.McpServer) *McpClient {
+// NewMcpClient connects to the servers, or only prepares them with opts.Lazy. With a
+// health check interval, connected servers are pinged that often and servers that failed
+// or dropped are reconnected with backoff; Close stops the checks.
+func NewMcpClient(servers []config.McpServer, opts McpClientOptions) *McpClient {
 	mc := &McpClient{
 		clients: make(map[string]*client.Client),
+		procs:   make(map[*client.Client]*stdioProcess),
+		servers: make(map[string]config.McpServer),
+		status:  make(map[string]*McpServerStatus),
+		tools:   make(map[string]cachedTools),
+		opts:    opts,
+		stop:    make(chan struct{}),
 	}
 
+	saved := loadToolCache(opts.CacheFile)
 	for _, server := range servers {
-		var trans transport.Interface
-		var err error
-
-		// 创建传输层
-		switch server.Type {
-		case "stdio":
-			// 将 map[string]string 转换为 []string 格式
-			var envSlice []string
-			for key, value := range server.Env {
-				envSlice = append(envSlice, fmt.Sprintf("%s=%s", key, value))
-			}
-			trans = transport.NewStdio(server.Command, envSlice, server.Args...)
-		case "sse":
-			trans, err = transport.NewSSE(server.URL)
-			if err != nil {
-				logger.Error("Failed to create SSE transport for server %s: %v", server.Name, err)
-				continue
-			}
-		case "streamable-http", "streamableHTTP", "http":
-			trans, err = transport.NewStreamableHTTP(server.URL)
-			if err != nil {
-				logger.Error("Failed to create StreamableHTTP transport for server %s: %v", server.Name, err)
-				continue
-			}
-		default:
-			logger.Error("Unsupported MCP server type: %s for server %s", server.Type, server.Name)
-			continue
+		mc.servers[server.Name] = server
+		mc.status[server.Name] = &McpServerStatus{Name: server.Name}
+		if cached, ok := saved[server.Name]; ok && cached.Fingerprint == mcpFingerprint(server) {
+			mc.tools[server.Name] = cached
+		}
+		if !opts.Lazy {
+			mc.reconnect(server.Name, opts.HealthCheck)
 		}
+	}
+	if opts.HealthCheck > 0 && len(servers) > 0 {
+		go mc.monitor(opts.HealthCheck)
+	}
+	return mc
+}
+
+// mcpFingerprint identifies how a server is started
+func mcpFingerprint(server config.McpServer) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%q\x00%s", server.Type, server.Command, server.Args, server.URL)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// loadToolCache reads the tool lists saved by earlier sessions
+func loadToolCache(path string) map[string]cachedTools {
+	cache := map[string]cachedTools{}
+	if path == "" {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		logger.Error("Ignoring invalid MCP tool cache %s: %v", path, err)
+	}
+	return cache
+}
 
-		// 创建客户端
-		mcpClient := client.NewClient(trans)
+// saveToolCache merges the tool lists of this client into the cache file, which other
+// sessions with other servers share
+func (mc *McpClient) saveToolCache() {
+	if mc.opts.CacheFile == "" {
+		return
+	}
+	cache := loadToolCache(mc.opts.CacheFile)
+	mc.mu.RLock()
+	for name, cached := range mc.tools {
+		cache[name] = cached
+	}
+	mc.mu.RUnlock()
+	data, err := json.Marshal(cache)
+	if err == nil {
+		err = os.WriteFile(mc.opts.CacheFile, data, 0o600)
+	}
+	if err != nil {
+		logger.Error("Failed to save the MCP tool cache: %v", err)
+	}
+}
 
-		// 启动客户端
-		err = mcpClient.Start(context.Background())
+// connectMcpServer starts the transport of a server and initializes the session; for a
+// stdio server it also returns the process
+func (mc *McpClient) connectMcpServer(server config.McpServer) (*client.Client, *stdioProcess, error) {
+	var trans transport.Interface
+	var proc *stdioProcess
+	var err error
+
+	// 创建传输层
+	switch server.Type {
+	case "stdio":
+		// 将 map[string]string 转换为 []string 格式
+		var envSlice []string
+		for key, value := range server.Env {
+			envSlice = append(envSlice, fmt.Sprintf("%s=%s", key, value))
+		}
+		proc = &stdioProcess{server: server.Name}
+		trans = transport.NewStdioWithOptions(server.Command, envSlice, server.Args, transport.WithCommandFunc(proc.command), transport.WithCommandLogger(stdioLogger{}))
+	case "sse":
+		// no overall timeout, the event stream stays open; calls are bounded by their context
+		opts := []transport.ClientOption{transport.WithHTTPClient(system.HTTPClient(0)), transport.WithHeaders(mcpHeaders(server))}
+		if server.OAuth.Enabled {
+			opts = append(opts, transport.WithOAuth(mcpOAuthConfig(server, server.OAuth.RedirectURI)))
+		}
+		trans, err = transport.NewSSE(server.URL, opts...)
 		if err != nil {
-			logger.Error("Failed to start MCP client for server %s: %v", server.Name, err)
-			continue
+			return nil, nil, fmt.Errorf("failed to create SSE transport: %w", err)
+		}
+	case "streamable-http", "streamableHTTP", "http":
+		opts := []transport.StreamableHTTPCOption{transport.WithHTTPBasicClient(system.HTTPClient(0)), transport.WithHTTPHeaders(mcpHeaders(server))}
+		if server.OAuth.Enabled {
+			opts = append(opts, transport.WithHTTPOAuth(mcpOAuthConfig(server, server.OAuth.RedirectURI)))
+		}
+		trans, err = transport.NewStreamableHTTP(server.URL, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create StreamableHTTP transport: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported MCP server type: %s", server.Type)
+	}
+
+	// 创建客户端
+	mcpClient := client.NewClient(trans)
+
+	// 启动客户端
+	if err := mcpClient.Start(context.Background()); err != nil {
+		if proc != nil && proc.cmd != nil && proc.cmd.Process != nil {
+			system.SignalProcessGroup(proc.cmd, true)
 		}
+		return nil, nil, fmt.Errorf("failed to start MCP client: %w", mcpAuthError(server.Name, err))
+	}
 
-		// 设置通知处理（可选）
-		mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
-			// 处理通知，目前为空实现
-		})
+	// 设置通知处理（可选）
+	mcpClient.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method == mcp.MethodNotificationToolsListChanged {
+			mc.mu.Lock()
+			delete(mc.tools, server.Name)
+			mc.mu.Unlock()
+		}
+	})
+	mcpClient.OnConnectionLost(func(err error) {
+		mc.drop(server.Name, mcpClient, err)
+	})
 
-		// 初始化客户端
-		_, err = mcpClient.Initialize(context.Background(), mcp.InitializeRequest{})
+	// 初始化客户端
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := mcpClient.Initialize(ctx, mcp.InitializeRequest{}); err != nil {
+		closeMcpClient(mcpClient, proc)
+		return nil, nil, fmt.Errorf("failed to initialize MCP client: %w", mcpAuthError(server.Name, err))
+	}
+	return mcpClient, proc, nil
+}
+
+// reconnect connects to a server that isn't connected, recording the outcome in its status
+func (mc *McpClient) reconnect(name string, interval time.Duration) {
+	mc.mu.RLock()
+	server := mc.servers[name]
+	mc.mu.RUnlock()
+
+	c, proc, err := mc.connectMcpServer(server)
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	status := mc.status[name]
+	status.LastCheck = time.Now()
+	if err != nil || mc.closed {
+		if c != nil {
+			go closeMcpClient(c, proc)
+			return
+		}
+		logger.Error("Failed to connect to MCP server %s: %v", name, err)
+		status.Failures++
+		status.LastError = err.Error()
+		status.NextRetry = time.Now().Add(mcpBackoff(interval, status.Failures))
+		return
+	}
+	if status.Failures > 0 {
+		logger.Info("Reconnected to MCP server %s after %d failed attempts", name, status.Failures)
+	} else {
+		logger.Info("Successfully connected to MCP server: %s", name)
+	}
+	mc.clients[name] = c
+	status.Connected = true
+	status.Failures = 0
+	status.NextRetry = time.Time{}
+	status.Stopped = false
+	if proc != nil {
+		mc.procs[c] = proc
+		go mc.superviseStdio(name, c, proc)
+	}
+}
+
+// Reconnect connects a server of the client that isn't connected, e.g. after /mcp login
+func (mc *McpClient) Reconnect(serverName string) {
+	mc.connectMu.Lock()
+	defer mc.connectMu.Unlock()
+	mc.mu.RLock()
+	_, known := mc.servers[serverName]
+	_, connected := mc.clients[serverName]
+	mc.mu.RUnlock()
+	if known && !connected {
+		mc.reconnect(serverName, mc.opts.HealthCheck)
+	}
+}
+
+// drop forgets the connection to a server that failed, the next health check reconnects
+// it. It returns false when the connection was closed or dropped already.
+func (mc *McpClient) drop(name string, c *client.Client, err error) bool {
+	mc.mu.Lock()
+	if mc.closed || mc.clients[name] != c {
+		mc.mu.Unlock()
+		return false
+	}
+	delete(mc.clients, name)
+	proc := mc.procs[c]
+	delete(mc.procs, c)
+	status := mc.status[name]
+	status.Connected = false
+	status.LastError = err.Error()
+	mc.mu.Unlock()
+
+	logger.Error("Lost connection to MCP server %s: %v", name, err)
+	// closing may wait on the transport's reader, which can be the caller
+	go closeMcpClient(c, proc)
+	return true
+}
+
+// monitor runs the health checks until Close
+func (mc *McpClient) monitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-mc.stop:
+			return
+		case <-ticker.C:
+			mc.checkHealth()
+		}
+	}
+}
+
+// checkHealth pings the connected servers and reconnects the others once their backoff ran out
+func (mc *McpClient) checkHealth() {
+	mc.mu.RLock()
+	names := make([]string, 0, len(mc.servers))
+	for name := range mc.servers {
+		names = append(names, name)
+	}
+	mc.mu.RUnlock()
+
+	for _, name := range names {
+		mc.mu.RLock()
+		c := mc.clients[name]
+		status := mc.status[name]
+		// lazy servers not used yet stay unconnected
+		due := !status.LastCheck.IsZero() && !status.Stopped && !time.Now().Before(status.NextRetry)
+		mc.mu.RUnlock()
+
+		if c == nil {
+			if due {
+				mc.Reconnect(name)
+			}
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		started := time.Now()
+		err := c.Ping(ctx)
+		cancel()
 		if err != nil {
-			logger.Error("Failed to initialize MCP client for server %s: %v", server.Name, err)
+			mc.drop(name, c, fmt.Errorf("ping failed: %w", err))
 			continue
 		}
-
-		// 存储客户端
-		mc.clients[server.Name] = mcpClient
-		logger.Info("Successfully connected to MCP server: %s", server.Name)
+		mc.mu.Lock()
+		status.Latency = time.Since(started)
+		status.LastCheck = time.Now()
+		mc.mu.Unlock()
 	}
+}
 
-	return mc
+// Status returns the connection state of each server, in name order
+func (mc *McpClient) Status() []McpServerStatus {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	statuses := make([]McpServerStatus, 0, len(mc.status))
+	for _, status := range mc.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
 }
 
-func (mc *McpClient) CallTool(serverName, toolName string, arguments map[string]interface{}) (string, error) {
+// connected returns the client of a connected server, connecting a lazy one on first
+// use, or why there is none
+func (mc *McpClient) connected(serverName string) (*client.Client, error) {
 	mc.mu.RLock()
-	client, exists := mc.clients[serverName]
+	c, ok := mc.clients[serverName]
+	status, known := mc.status[serverName]
+	untried := known && status.LastCheck.IsZero()
 	mc.mu.RUnlock()
-	logger.Info("CallTool clients: %v", mc.clients)
-	if !exists {
-		return "", fmt.Errorf("MCP server '%s' not found", serverName)
+	if ok {
+		return c, nil
+	}
+	if untried {
+		mc.connectMu.Lock()
+		mc.mu.RLock()
+		untried = status.LastCheck.IsZero()
+		mc.mu.RUnlock()
+		if untried {
+			mc.reconnect(serverName, mc.opts.HealthCheck)
+		}
+		mc.connectMu.Unlock()
+		return mc.connected(serverName)
+	}
+
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	if known && status.LastError != "" {
+		return nil, fmt.Errorf("MCP server '%s' is not connected: %s", serverName, status.LastError)
+	}
+	return nil, fmt.Errorf("MCP server '%s' not found", serverName)
+}
+
+// CallTool runs a tool on a connected server; cancelling ctx abandons the call
+func (mc *McpClient) CallTool(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (string, error) {
+	client, err := mc.connected(serverName)
+	if err != nil {
+		return "", err
 	}
 	toolName = serverName + "-" + toolName
 
@@ -100,7 +406,7 @@ func (mc *McpClient) CallTool(serverName, toolName string, arguments map[string]
 		},
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	result, err := client.CallTool(ctx, request)
@@ -127,13 +433,32 @@ func (mc *McpClient) CallTool(serverName, toolName string, arguments map[string]
 }
 
 func (mc *McpClient) ListTools(serverName string) ([]string, error) {
+	tools, err := mc.Tools(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	var toolNames []string
+	for _, tool := range tools {
+		toolNames = append(toolNames, tool.Name)
+	}
+
+	return toolNames, nil
+}
+
+// Tools returns the tools of a server with their input schemas, from the cache while
+// it is fresh; a lazy server is only connected once it isn't
+func (mc *McpClient) Tools(serverName string) ([]mcp.Tool, error) {
 	mc.mu.RLock()
-	client, exists := mc.clients[serverName]
+	cached, ok := mc.tools[serverName]
 	mc.mu.RUnlock()
+	if ok && time.Since(cached.Fetched) < mc.opts.ToolCacheTTL {
+		return cached.Tools, nil
+	}
 
-	fmt.Println("MCP clients:", mc.clients)
-	if !exists {
-		return nil, fmt.Errorf("MCP server '%s' not found", serverName)
+	client, err := mc.connected(serverName)
+	if err != nil {
+		return nil, err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -143,33 +468,61 @@ func (mc *McpClient) ListTools(serverName string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tools for server '%s': %v", serverName, err)
 	}
+	if mc.opts.ToolCacheTTL > 0 {
+		mc.mu.Lock()
+		mc.tools[serverName] = cachedTools{Fingerprint: mcpFingerprint(mc.servers[serverName]), Tools: response.Tools, Fetched: time.Now()}
+		mc.mu.Unlock()
+		mc.saveToolCache()
+	}
+	return response.Tools, nil
+}
 
-	var toolNames []string
-	for _, tool := range response.Tools {
-		toolNames = append(toolNames, tool.Name)
+// Prompts returns the prompt templates of a connected server, none when it doesn't offer
+// the prompts capability
+func (mc *McpClient) Prompts(serverName string) ([]mcp.Prompt, error) {
+	client, err := mc.connected(serverName)
+	if err != nil {
+		return nil, err
+	}
+	if client.GetServerCapabilities().Prompts == nil {
+		return nil, nil
 	}
 
-	return toolNames, nil
-}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-func (mc *McpClient) GetToolInfo(serverName, toolName string) (map[string]interface{}, error) {
-	mc.mu.RLock()
-	client, exists := mc.clients[serverName]
-	mc.mu.RUnlock()
+	response, err := client.ListPrompts(ctx, mcp.ListPromptsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts for server '%s': %v", serverName, err)
+	}
+	return response.Prompts, nil
+}
 
-	if !exists {
-		return nil, fmt.Errorf("MCP server '%s' not found", serverName)
+// GetPrompt fills in a prompt template of a connected server
+func (mc *McpClient) GetPrompt(ctx context.Context, serverName, promptName string, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	client, err := mc.connected(serverName)
+	if err != nil {
+		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	tools, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+	request := mcp.GetPromptRequest{Params: mcp.GetPromptParams{Name: promptName, Arguments: arguments}}
+	result, err := client.GetPrompt(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list tools from server '%s': %v", serverName, err)
+		return nil, fmt.Errorf("failed to get prompt '%s' from server '%s': %v", promptName, serverName, err)
 	}
+	return result, nil
+}
 
-	for _, tool := range tools.Tools {
+func (mc *McpClient) GetToolInfo(serverName, toolName string) (map[string]interface{}, error) {
+	tools, err := mc.Tools(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tool := range tools {
 		if tool.Name == toolName {
 			toolInfo := map[string]interface{}{
 				"name":        tool.Name,
@@ -203,13 +556,27 @@ func (mc *McpClient) GetConnectedServers() []string {
 
 func (mc *McpClient) Close() error {
 	mc.mu.Lock()
-	defer mc.mu.Unlock()
-
-	for serverName, client := range mc.clients {
-		if err := client.Close(); err != nil {
-			fmt.Printf("Error closing MCP client for server %s: %v\n", serverName, err)
-		}
+	if mc.closed {
+		mc.mu.Unlock()
+		return nil
 	}
+	mc.closed = true
+	close(mc.stop)
+	clients, procs := mc.clients, mc.procs
 	mc.clients = make(map[string]*client.Client)
+	mc.procs = make(map[*client.Client]*stdioProcess)
+	mc.mu.Unlock()
+
+	// outside the lock, a transport reporting the lost connection takes it; stdio servers
+	// stop in parallel, each may take its grace period
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *client.Client) {
+			defer wg.Done()
+			closeMcpClient(c, procs[c])
+		}(c)
+	}
+	wg.Wait()
 	return nil
 }
@@ -0,0 +1,70 @@
+// Unit tests for the connection handling in mcp_client.go
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test: the wait doubles with each failure, starting at the interval and capped
+func TestMcpBackoff(t *testing.T) {
+	for failures, want := range map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 4: 4 * time.Minute, 9: maxMcpBackoff} {
+		if got := mcpBackoff(30*time.Second, failures); got != want {
+			t.Errorf("mcpBackoff(30s, %d) = %s, want %s", failures, got, want)
+		}
+	}
+}
+
+// Test: a server that fails to start stays in the status with its error and a retry
+// time, and calls to it report the error instead of an unknown server
+func TestMcpClientFailedServer(t *testing.T) {
+	mc := NewMcpClient([]config.McpServer{{Name: "broken", Type: "stdio", Command: "/nonexistent/mcp-server"}}, McpClientOptions{HealthCheck: time.Minute})
+	defer mc.Close()
+
+	statuses := mc.Status()
+	if len(statuses) != 1 || statuses[0].Connected || statuses[0].Failures != 1 || statuses[0].LastError == "" {
+		t.Fatalf("unexpected status %+v", statuses)
+	}
+	if statuses[0].NextRetry.Before(time.Now().Add(59 * time.Second)) {
+		t.Errorf("retry at %s, expected a minute from now", statuses[0].NextRetry)
+	}
+	if _, err := mc.ListTools("broken"); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected a not connected error, got %v", err)
+	}
+	if _, err := mc.ListTools("other"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+// Test: a lazy server isn't started while its cached tools are fresh, and a cache entry
+// of a server started differently is ignored
+func TestMcpClientToolCache(t *testing.T) {
+	server := config.McpServer{Name: "fs", Type: "stdio", Command: "/nonexistent/mcp-server"}
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+	cache := map[string]cachedTools{"fs": {Fingerprint: mcpFingerprint(server), Tools: []mcp.Tool{{Name: "read_file"}}, Fetched: time.Now()}}
+	data, _ := json.Marshal(cache)
+	os.WriteFile(cacheFile, data, 0o600)
+
+	mc := NewMcpClient([]config.McpServer{server}, McpClientOptions{Lazy: true, ToolCacheTTL: time.Hour, CacheFile: cacheFile})
+	defer mc.Close()
+	if names, err := mc.ListTools("fs"); err != nil || len(names) != 1 || names[0] != "read_file" {
+		t.Errorf("got %v, %v from the cache", names, err)
+	}
+	if s := mc.Status()[0]; !s.LastCheck.IsZero() {
+		t.Errorf("the lazy server was started: %+v", s)
+	}
+
+	server.Args = []string{"--other"}
+	mc2 := NewMcpClient([]config.McpServer{server}, McpClientOptions{Lazy: true, ToolCacheTTL: time.Hour, CacheFile: cacheFile})
+	defer mc2.Close()
+	if _, err := mc2.ListTools("fs"); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Errorf("expected the changed server to be started and fail, got %v", err)
+	}
+}
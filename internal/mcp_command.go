@@ -1,18 +1,22 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
 	"github.com/alvinunreal/tmuxai/system"
-	"github.com/fatih/color"
 )
 
-func handleMcpCommand(m *Manager, args []string) {
+func handleMcpCommand(ctx context.Context, m *Manager, args []string) {
 	subcommand := "list"
 	if len(args) > 0 {
-		subcommand = args[0]
+		subcommand = strings.ToLower(args[0])
 	}
 
 	switch subcommand {
@@ -20,15 +24,21 @@ func handleMcpCommand(m *Manager, args []string) {
 		selectMcpServers(m)
 	case "current":
 		showCurrentMcpServers(m)
+	case "status":
+		showMcpStatus(m)
+	case "login":
+		loginMcpServer(ctx, m, args[1:])
+	case "logout":
+		logoutMcpServer(m, args[1:])
 	case "help":
 		showMcpHelp(m)
 	default:
-		m.Println(fmt.Sprintf("Unknown /mcp subcommand: %s. Use '/mcp help' for more info.", subcommand))
+		m.Println(i18n.T("Unknown /mcp subcommand: %s. Use '/mcp help' for more info.", subcommand))
 	}
 }
 
 func showMcpHelp(m *Manager) {
-	helpText := `
+	helpText := i18n.T(`
 /mcp: Manage MCP (Multi-Context Prompts) servers for the current session.
 
 Available subcommands:
@@ -39,15 +49,24 @@ Available subcommands:
   /mcp current
     Show the list of MCP servers currently selected for this session.
 
+  /prompt [server] [name [key=value|value ...]]
+    List the prompt templates the selected servers offer, or fill one in and send it as a request.
+
+  /mcp status
+    Show the connection state, ping latency and last error of each selected server.
+
+  /mcp login <server>
+    Authorize with a server that has oauth enabled, in the browser; /mcp logout <server> forgets the token.
+
   /mcp help
     Show this help message.
-`
+`)
 	m.Println(helpText)
 }
 
 func selectMcpServers(m *Manager) {
 	if len(m.Config.Mcp.Servers) == 0 {
-		m.Println("No MCP servers configured. Please add servers to your config file.")
+		m.Println(i18n.T("No MCP servers configured. Please add servers to your config file."))
 		return
 	}
 
@@ -58,14 +77,21 @@ func selectMcpServers(m *Manager) {
 
 	// Create a map of currently selected server names for quick lookup
 	selectedNames := make(map[string]struct{})
-	for _, server := range m.McpServers {
+	for _, server := range m.selectedMcpServers() {
 		selectedNames[server.Name] = struct{}{}
 	}
 
-	// Run fzf to let the user select/deselect servers
-	newlySelectedNames, err := system.InteractiveSelect(serverNames, selectedNames)
+	// Let the user select/deselect servers
+	var newlySelectedNames []string
+	var err error
+	m.withTerminal(func() {
+		newlySelectedNames, err = system.InteractiveSelect(i18n.T("Select MCP servers"), serverNames, selectedNames)
+	})
+	if errors.Is(err, system.ErrSelectionCancelled) {
+		return
+	}
 	if err != nil {
-		m.Println(fmt.Sprintf("Error running interactive selection: %v", err))
+		m.Println(i18n.T("Error running interactive selection: %v", err))
 		return
 	}
 
@@ -76,41 +102,135 @@ func selectMcpServers(m *Manager) {
 			updatedMcpServers = append(updatedMcpServers, server)
 		}
 	}
-	m.McpServers = updatedMcpServers
-
-	// 关闭旧的 MCP 客户端连接
-	m.McpClient.Close()
-
-	// 重新初始化 MCP 客户端，只连接选中的服务器
-	m.McpClient = NewMcpClient(updatedMcpServers)
+	// Reconnect the MCP client to the selected servers only, then close the old connections
+	oldClient := m.McpClient
+	m.setMcpServers(updatedMcpServers, m.newMcpClient(updatedMcpServers))
+	oldClient.Close()
 
 	showCurrentMcpServers(m)
 }
 
 func showCurrentMcpServers(m *Manager) {
 	if len(m.McpServers) == 0 {
-		m.Println("No MCP servers are currently selected for this session.")
+		m.Println(i18n.T("No MCP servers are currently selected for this session."))
 		return
 	}
 
 	var serverNames []string
 	for _, server := range m.McpServers {
-		// 尝试获取服务器的工具列表
+		// Try to list the tools of the server
 		tools, err := m.McpClient.ListTools(server.Name)
 		if err != nil {
-			fmt.Println("Error listing tools:", err.Error())
-			serverNames = append(serverNames, fmt.Sprintf("%s (tools: unavailable)", server.Name))
+			fmt.Fprintln(m.out(), i18n.T("Error listing tools: %v", err))
+			serverNames = append(serverNames, i18n.T("%s (tools: unavailable)", server.Name))
 		} else {
-			serverNames = append(serverNames, fmt.Sprintf("%s (tools: %d available)", server.Name, len(tools)))
+			serverNames = append(serverNames, i18n.T("%s (tools: %d available)", server.Name, len(tools)))
 		}
 	}
 
-	arrowColor := color.New(color.FgYellow, color.Bold)
-	serverList := arrowColor.Sprint(strings.Join(serverNames, ", "))
-	message := fmt.Sprintf("🧰 Current MCP servers for this session: %s", serverList)
+	serverList := system.CurrentTheme().Highlight.Sprint(strings.Join(serverNames, ", "))
+	message := system.Sym(i18n.T("🧰 Current MCP servers for this session: %s", serverList))
 	m.Println(message)
 }
 
+// newMcpClient connects to servers as mcp configures it
+func (m *Manager) newMcpClient(servers []config.McpServer) *McpClient {
+	cfg := m.Config.Mcp
+	return NewMcpClient(servers, McpClientOptions{
+		HealthCheck:  time.Duration(cfg.HealthCheckInterval) * time.Second,
+		Lazy:         cfg.Lazy,
+		ToolCacheTTL: time.Duration(cfg.ToolCacheTTL) * time.Second,
+		CacheFile:    config.GetConfigFilePath("mcp_tools_cache.json"),
+	})
+}
+
+// showMcpStatus lists the selected servers with their connection state, as the health
+// checks last saw it
+func showMcpStatus(m *Manager) {
+	statuses := m.McpClient.Status()
+	if len(statuses) == 0 {
+		m.Println(i18n.T("No MCP servers are currently selected for this session."))
+		return
+	}
+	theme := system.CurrentTheme()
+	var b strings.Builder
+	for _, s := range statuses {
+		if s.Connected {
+			state := i18n.T("connected")
+			if s.Latency > 0 {
+				state = i18n.T("connected, ping %s", s.Latency.Round(time.Millisecond))
+			}
+			fmt.Fprintf(&b, "  %s %s %s", theme.Success.Sprint(system.Sym("●")), s.Name, theme.Muted.Sprint(state))
+		} else if s.LastCheck.IsZero() {
+			fmt.Fprintf(&b, "  %s %s %s", theme.Neutral.Sprint(system.Sym("○")), s.Name, theme.Muted.Sprint(i18n.T("not connected yet, connects on first use")))
+		} else {
+			state := i18n.T("disconnected")
+			if s.Stopped {
+				state = i18n.T("stopped, select it again with /mcp to restart it")
+			} else if !s.NextRetry.IsZero() {
+				state = i18n.T("disconnected, %d failed attempts, retry in %s", s.Failures, max(time.Until(s.NextRetry), 0).Round(time.Second))
+			}
+			fmt.Fprintf(&b, "  %s %s %s", theme.Error.Sprint(system.Sym("○")), s.Name, theme.Muted.Sprint(state))
+		}
+		if !s.LastCheck.IsZero() {
+			fmt.Fprintf(&b, " %s", theme.Muted.Sprint(i18n.T("(checked %s ago)", time.Since(s.LastCheck).Round(time.Second))))
+		}
+		b.WriteString("\n")
+		if s.LastError != "" {
+			fmt.Fprintf(&b, "    %s %s\n", theme.Label.Sprint(i18n.T("Last error:")), s.LastError)
+		}
+	}
+	fmt.Fprint(m.out(), b.String())
+	if m.Config.Mcp.HealthCheckInterval <= 0 {
+		m.Println(i18n.T("Health checks are off, set mcp.health_check_interval to reconnect dropped servers"))
+	}
+}
+
+// loginMcpServer runs the OAuth flow of a server and reconnects it when it is selected
+func loginMcpServer(ctx context.Context, m *Manager, args []string) {
+	if len(args) != 1 {
+		m.Println(i18n.T("Usage: /mcp login <server>"))
+		return
+	}
+	server, found := findMcpServer(m.Config, args[0])
+	if !found {
+		m.Println(i18n.T("No MCP server named %s in the config", args[0]))
+		return
+	}
+	if !server.OAuth.Enabled {
+		m.Println(i18n.T("MCP server %s doesn't use OAuth, set oauth.enabled for it", server.Name))
+		return
+	}
+	err := mcpLogin(ctx, server, func(authURL string) {
+		m.Println(i18n.T("Authorize TmuxAI in the browser, waiting for the callback (Ctrl+C cancels):"))
+		fmt.Fprintln(m.out(), authURL)
+		if err := system.OpenBrowser(authURL); err != nil {
+			m.Println(i18n.T("Could not open a browser, open the url above yourself"))
+		}
+	})
+	if err != nil {
+		m.Println(i18n.T("Login to %s failed: %v", server.Name, err))
+		return
+	}
+	m.Println(i18n.T("Logged in to %s", server.Name))
+	if m.McpClient != nil && !m.McpClient.IsConnected(server.Name) {
+		m.McpClient.Reconnect(server.Name)
+	}
+}
+
+// logoutMcpServer forgets the OAuth token of a server
+func logoutMcpServer(m *Manager, args []string) {
+	if len(args) != 1 {
+		m.Println(i18n.T("Usage: /mcp logout <server>"))
+		return
+	}
+	if err := os.Remove(mcpTokenPath(args[0])); err != nil && !os.IsNotExist(err) {
+		m.Println(i18n.T("Failed to remove the token: %v", err))
+		return
+	}
+	m.Println(i18n.T("Logged out of %s", args[0]))
+}
+
 func findMcpServer(cfg *config.Config, name string) (config.McpServer, bool) {
 	for _, server := range cfg.Mcp.Servers {
 		if server.Name == name {
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Actions of mcp.tool_policy
+const (
+	toolPolicyAuto    = "auto"    // run without asking
+	toolPolicyConfirm = "confirm" // ask first, showing the arguments
+	toolPolicyDeny    = "deny"    // never run, nor offer to the model
+)
+
+// toolPolicy returns the action of the first mcp.tool_policy rule matching server.tool;
+// tools no rule matches run without asking, unknown actions ask
+func (m *Manager) toolPolicy(server, tool string) string {
+	name := server + "." + tool
+	for _, rule := range m.Config.Mcp.ToolPolicy {
+		if ok, _ := path.Match(rule.Tool, name); !ok {
+			continue
+		}
+		switch rule.Action {
+		case toolPolicyAuto, toolPolicyConfirm, toolPolicyDeny:
+			return rule.Action
+		default:
+			logger.Error("Unknown action %q in mcp.tool_policy for %s, asking instead", rule.Action, rule.Tool)
+			return toolPolicyConfirm
+		}
+	}
+	return toolPolicyAuto
+}
+
+// approveToolCall applies the tool policy to a call, asking the user when it says so.
+// Approving always holds for the tool whatever its arguments.
+func (m *Manager) approveToolCall(call McpToolCall) (approved bool, reason string) {
+	name := call.ServerName + "." + call.ToolName
+	policy := m.toolPolicy(call.ServerName, call.ToolName)
+	if policy == toolPolicyAuto && m.alwaysConfirm {
+		policy = toolPolicyConfirm
+	}
+	switch policy {
+	case toolPolicyDeny:
+		m.Println(i18n.T("MCP tool %s is denied by mcp.tool_policy", name))
+		return false, "denied by mcp.tool_policy"
+	case toolPolicyConfirm:
+		args, _ := json.MarshalIndent(call.Arguments, "", "  ")
+		fmt.Fprintln(m.out(), system.CurrentTheme().Label.Sprint(i18n.T("MCP tool:"))+" "+name)
+		fmt.Fprintln(m.out(), m.highlightCode("json", string(args)))
+		approved, _ = m.confirmAction(name, confirmToolPrompt, false, string(args))
+		emitConfirmation(confirmToolPrompt, name, approved)
+		m.stats.recordConfirmation(approved)
+		if !approved {
+			return false, "the user declined it"
+		}
+	}
+	return true, ""
+}
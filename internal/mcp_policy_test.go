@@ -0,0 +1,52 @@
+// Unit tests for the MCP tool policy in mcp_policy.go
+package internal
+
+import (
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: the first matching rule wins, tools without a rule run and unknown actions ask
+func TestToolPolicy(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Mcp.ToolPolicy = []config.McpToolRule{
+		{Tool: "github.create_issue", Action: "auto"},
+		{Tool: "github.create_*", Action: "confirm"},
+		{Tool: "*.delete_*", Action: "deny"},
+		{Tool: "fs.move", Action: "maybe"},
+	}
+	m := &Manager{Config: cfg}
+	for name, want := range map[[2]string]string{
+		{"github", "create_issue"}: toolPolicyAuto,
+		{"github", "create_pr"}:    toolPolicyConfirm,
+		{"fs", "delete_file"}:      toolPolicyDeny,
+		{"fs", "read_file"}:        toolPolicyAuto,
+		{"fs", "move"}:             toolPolicyConfirm,
+	} {
+		if got := m.toolPolicy(name[0], name[1]); got != want {
+			t.Errorf("toolPolicy(%s.%s) = %s, want %s", name[0], name[1], got, want)
+		}
+	}
+}
+
+// Test: denied calls are refused without asking, confirmed ones follow the answer
+func TestApproveToolCall(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Mcp.ToolPolicy = []config.McpToolRule{{Tool: "fs.write", Action: "confirm"}, {Tool: "fs.rm", Action: "deny"}}
+	asked := 0
+	m := &Manager{Config: cfg, ConfirmFunc: func(content, prompt string) (bool, string) {
+		asked++
+		return prompt == confirmToolPrompt && content == "fs.write", ""
+	}}
+
+	if ok, _ := m.approveToolCall(McpToolCall{ServerName: "fs", ToolName: "rm"}); ok || asked != 0 {
+		t.Errorf("denied call approved=%v after %d prompts", ok, asked)
+	}
+	if ok, _ := m.approveToolCall(McpToolCall{ServerName: "fs", ToolName: "write", Arguments: map[string]interface{}{"path": "a"}}); !ok || asked != 1 {
+		t.Errorf("confirmed call approved=%v after %d prompts", ok, asked)
+	}
+	if ok, _ := m.approveToolCall(McpToolCall{ServerName: "fs", ToolName: "read"}); !ok || asked != 1 {
+		t.Errorf("unmatched call approved=%v after %d prompts", ok, asked)
+	}
+}
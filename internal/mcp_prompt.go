@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const promptUsage = "Usage: /prompt [server] [name [key=value|value ...]]"
+
+// handlePromptCommand lists the prompt templates of the selected MCP servers, or fills
+// one in and sends it as a request
+func handlePromptCommand(ctx context.Context, m *Manager, args []string) {
+	if len(m.McpServers) == 0 || m.McpClient == nil {
+		m.Println(i18n.T("No MCP servers are currently selected for this session."))
+		return
+	}
+	if len(args) < 2 {
+		server := ""
+		if len(args) == 1 {
+			server = args[0]
+		}
+		m.printMcpPrompts(server)
+		return
+	}
+
+	server, name := args[0], args[1]
+	prompts, err := m.McpClient.Prompts(server)
+	if err != nil {
+		m.Println(err.Error())
+		return
+	}
+	var prompt *mcp.Prompt
+	for i := range prompts {
+		if prompts[i].Name == name {
+			prompt = &prompts[i]
+			break
+		}
+	}
+	if prompt == nil {
+		m.Println(i18n.T("No prompt %s on MCP server %s, /prompt %s lists them", name, server, server))
+		return
+	}
+	arguments, err := promptArguments(prompt.Arguments, args[2:])
+	if err != nil {
+		m.Println(err.Error())
+		return
+	}
+	result, err := m.McpClient.GetPrompt(ctx, server, name, arguments)
+	if err != nil {
+		m.Println(err.Error())
+		return
+	}
+	message := promptText(result.Messages)
+	if message == "" {
+		m.Println(i18n.T("Prompt %s returned no text", name))
+		return
+	}
+	m.runRequest(ctx, message)
+}
+
+// promptArguments maps the arguments of /prompt to those the template declares:
+// key=value sets one by name, plain values fill the rest in order
+func promptArguments(declared []mcp.PromptArgument, args []string) (map[string]string, error) {
+	values := map[string]string{}
+	var positional []string
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if ok && declaresArgument(declared, key) {
+			values[key] = value
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	for _, a := range declared {
+		if len(positional) == 0 {
+			break
+		}
+		if _, set := values[a.Name]; !set {
+			values[a.Name] = positional[0]
+			positional = positional[1:]
+		}
+	}
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("%s", i18n.T("Too many arguments: %s", strings.Join(positional, " ")))
+	}
+	var missing []string
+	for _, a := range declared {
+		if _, set := values[a.Name]; a.Required && !set {
+			missing = append(missing, a.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s", i18n.T("Missing required arguments: %s", strings.Join(missing, ", ")))
+	}
+	return values, nil
+}
+
+func declaresArgument(declared []mcp.PromptArgument, name string) bool {
+	for _, a := range declared {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// promptText joins the text of a filled-in prompt into one request; the messages of
+// other roles than the user's are quoted with their role, so the model sees the workflow
+// as the server wrote it
+func promptText(messages []mcp.PromptMessage) string {
+	var parts []string
+	for _, msg := range messages {
+		var text string
+		switch content := msg.Content.(type) {
+		case mcp.TextContent:
+			text = content.Text
+		case mcp.EmbeddedResource:
+			if resource, ok := content.Resource.(mcp.TextResourceContents); ok {
+				text = fmt.Sprintf("%s:\n%s", resource.URI, resource.Text)
+			}
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if msg.Role != mcp.RoleUser {
+			text = fmt.Sprintf("[%s]\n%s", msg.Role, text)
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// printMcpPrompts lists the prompts of one selected server, or of all of them, with
+// their arguments; optional ones are in brackets
+func (m *Manager) printMcpPrompts(server string) {
+	theme := system.CurrentTheme()
+	var b strings.Builder
+	found := false
+	for _, s := range m.McpServers {
+		if server != "" && s.Name != server {
+			continue
+		}
+		found = true
+		prompts, err := m.McpClient.Prompts(s.Name)
+		if err != nil {
+			fmt.Fprintf(&b, "%s %s\n", theme.Label.Sprint(s.Name+":"), theme.Error.Sprint(err.Error()))
+			continue
+		}
+		if len(prompts) == 0 {
+			fmt.Fprintf(&b, "%s %s\n", theme.Label.Sprint(s.Name+":"), theme.Muted.Sprint(i18n.T("no prompts")))
+			continue
+		}
+		fmt.Fprintf(&b, "%s\n", theme.Label.Sprint(s.Name+":"))
+		for _, p := range prompts {
+			line := "  " + p.Name
+			for _, a := range p.Arguments {
+				if a.Required {
+					line += " <" + a.Name + ">"
+				} else {
+					line += " [" + a.Name + "]"
+				}
+			}
+			b.WriteString(line)
+			if p.Description != "" {
+				fmt.Fprintf(&b, " %s", theme.Muted.Sprint(p.Description))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if !found {
+		m.Println(i18n.T("MCP server %s is not selected, /mcp current lists the selected ones", server))
+		return
+	}
+	fmt.Fprint(m.out(), b.String())
+	m.Println(i18n.T(promptUsage))
+}
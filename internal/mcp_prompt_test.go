@@ -0,0 +1,44 @@
+// Unit tests for the MCP prompt helpers in mcp_prompt.go
+package internal
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Test: key=value sets an argument by name, plain values fill the others in order and
+// missing required arguments are reported
+func TestPromptArguments(t *testing.T) {
+	declared := []mcp.PromptArgument{{Name: "file", Required: true}, {Name: "style"}, {Name: "lang"}}
+
+	got, err := promptArguments(declared, []string{"lang=go", "main.go", "terse"})
+	want := map[string]string{"file": "main.go", "style": "terse", "lang": "go"}
+	if err != nil || !maps.Equal(got, want) {
+		t.Errorf("got %v, %v, want %v", got, err, want)
+	}
+	// an = in a value is kept when the key isn't an argument
+	if got, _ := promptArguments(declared, []string{"a=b"}); got["file"] != "a=b" {
+		t.Errorf("got %v", got)
+	}
+	if _, err := promptArguments(declared, []string{"style=long"}); err == nil || !strings.Contains(err.Error(), "file") {
+		t.Errorf("expected the missing file argument, got %v", err)
+	}
+	if _, err := promptArguments(declared, []string{"a", "b", "c", "d"}); err == nil {
+		t.Error("expected an error for too many arguments")
+	}
+}
+
+// Test: the prompt messages are joined, with other roles than the user's labelled
+func TestPromptText(t *testing.T) {
+	text := promptText([]mcp.PromptMessage{
+		{Role: mcp.RoleUser, Content: mcp.NewTextContent("Review the diff")},
+		{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("I'll look at the tests first.")},
+		{Role: mcp.RoleUser, Content: mcp.NewImageContent("aGk=", "image/png")},
+	})
+	if want := "Review the diff\n\n[assistant]\nI'll look at the tests first."; text != want {
+		t.Errorf("got %q, want %q", text, want)
+	}
+}
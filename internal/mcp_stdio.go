@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+const (
+	// defaultStdioRestarts is how often a stdio server that exits is restarted in a row
+	// when its retry_count is 0
+	defaultStdioRestarts = 3
+	// stdioGracePeriod is how long a stdio server gets to exit after its stdin closes,
+	// and again after SIGTERM before SIGKILL
+	stdioGracePeriod = 2 * time.Second
+	// stdioStableRun is how long a stdio server must run for its restarts to count anew
+	stdioStableRun = time.Minute
+)
+
+// stdioProcess is the process of a stdio MCP server
+type stdioProcess struct {
+	server  string
+	cmd     *exec.Cmd
+	started time.Time
+}
+
+// command builds the server's process in a process group of its own, so stopping it
+// reaches the children it starts; for transport.WithCommandFunc
+func (p *stdioProcess) command(ctx context.Context, command string, env []string, args []string) (*exec.Cmd, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Env = append(os.Environ(), env...)
+	system.SetProcessGroup(cmd)
+	p.cmd, p.started = cmd, time.Now()
+	return cmd, nil
+}
+
+// stdioLogger sends what the stdio transport logs to the log file instead of the terminal
+type stdioLogger struct{}
+
+func (stdioLogger) Infof(format string, v ...any)  { logger.Info(format, v...) }
+func (stdioLogger) Errorf(format string, v ...any) { logger.Error(format, v...) }
+
+// closeMcpClient closes a connection. A stdio server gets its stdin closed and the grace
+// period to exit, then its process group is terminated, and killed if that doesn't do,
+// so neither the server nor its children outlive tmuxai.
+func closeMcpClient(c *client.Client, p *stdioProcess) {
+	if p == nil || p.cmd == nil {
+		c.Close()
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		c.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(stdioGracePeriod):
+	}
+	if err := system.SignalProcessGroup(p.cmd, false); err != nil {
+		logger.Error("Failed to stop MCP server %s: %v", p.server, err)
+	}
+	select {
+	case <-done:
+	case <-time.After(stdioGracePeriod):
+		system.SignalProcessGroup(p.cmd, true)
+		<-done
+	}
+}
+
+// superviseStdio writes what a stdio server prints to stderr into the log. Once the
+// process exits on its own, it is restarted, up to retry_count times in a row.
+func (mc *McpClient) superviseStdio(name string, c *client.Client, p *stdioProcess) {
+	stdio, ok := c.GetTransport().(*transport.Stdio)
+	if ok {
+		scanner := bufio.NewScanner(stdio.Stderr())
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			logger.Info("MCP server %s: %s", name, scanner.Text())
+		}
+	}
+	// stderr closes when the process exits, or when the connection is closed
+	if !mc.drop(name, c, errors.New("the server process exited")) {
+		return
+	}
+
+	mc.mu.Lock()
+	status := mc.status[name]
+	if time.Since(p.started) > stdioStableRun {
+		status.Restarts = 0
+	}
+	status.Restarts++
+	restarts := status.Restarts
+	limit := mc.servers[name].RetryCount
+	if limit == 0 {
+		limit = defaultStdioRestarts
+	}
+	if restarts > limit {
+		status.Stopped = true
+		status.LastError = fmt.Sprintf("the server process exited %d times in a row, not restarted", restarts)
+		logger.Error("MCP server %s: %s", name, status.LastError)
+		mc.mu.Unlock()
+		return
+	}
+	mc.mu.Unlock()
+
+	logger.Info("Restarting MCP server %s (%d/%d)", name, restarts, limit)
+	select {
+	case <-mc.stop:
+		return
+	case <-time.After(time.Duration(restarts) * time.Second):
+	}
+	mc.Reconnect(name)
+}
@@ -0,0 +1,97 @@
+//go:build !windows
+
+// Unit tests for the stdio MCP server supervision in mcp_stdio.go
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// TestHelperMcpServer is the stdio MCP server of the tests below when run by them: it
+// starts a child that would outlive it, and its "exit" tool crashes it
+func TestHelperMcpServer(t *testing.T) {
+	if os.Getenv("TMUXAI_TEST_MCP_SERVER") == "" {
+		return
+	}
+	child := exec.Command("sleep", "60")
+	child.Start()
+	os.WriteFile(os.Getenv("TMUXAI_TEST_MCP_SERVER"), []byte(strconv.Itoa(child.Process.Pid)), 0o600)
+	fmt.Fprintln(os.Stderr, "helper server started")
+
+	s := server.NewMCPServer("helper", "1.0.0")
+	s.AddTool(mcp.NewTool("srv-exit"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		os.Exit(1)
+		return nil, nil
+	})
+	server.ServeStdio(s)
+	os.Exit(0)
+}
+
+// processGone tells whether a process exited; in a container without an init that
+// reaps orphans it stays a zombie
+func processGone(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+	_, rest, _ := strings.Cut(string(data), ") ")
+	return strings.HasPrefix(rest, "Z")
+}
+
+// Test: a crashed stdio server is restarted, and closing the client stops it along with
+// the children it started
+func TestStdioServerLifecycle(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("needs /proc")
+	}
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	srv := config.McpServer{
+		Name:       "srv",
+		Type:       "stdio",
+		Command:    os.Args[0],
+		Args:       []string{"-test.run=^TestHelperMcpServer$"},
+		Env:        map[string]string{"TMUXAI_TEST_MCP_SERVER": pidFile},
+		RetryCount: 1,
+	}
+	mc := NewMcpClient([]config.McpServer{srv}, McpClientOptions{})
+	if !mc.IsConnected("srv") {
+		t.Fatalf("not connected: %+v", mc.Status())
+	}
+
+	// no answer comes, the server exits
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	mc.CallTool(ctx, "srv", "exit", nil)
+	cancel()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) && !(mc.IsConnected("srv") && mc.Status()[0].Restarts == 1) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if s := mc.Status()[0]; !s.Connected || s.Restarts != 1 {
+		t.Fatalf("expected the server to be restarted once, got %+v", s)
+	}
+
+	data, _ := os.ReadFile(pidFile)
+	child, _ := strconv.Atoi(string(data))
+	if child == 0 || processGone(child) {
+		t.Fatalf("the helper's child %q isn't running", data)
+	}
+	mc.Close()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline) && !processGone(child); {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !processGone(child) {
+		t.Errorf("child %d outlived the server", child)
+	}
+}
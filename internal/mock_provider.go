@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// mockDemoResponses are played when the mock provider has no script: one command in the
+// exec pane, then the request is done
+var mockDemoResponses = []string{
+	"Let me look at the current directory first.\n<ExecCommand>ls -la</ExecCommand>",
+	"These are the files in the current directory. This answer comes from the mock provider, " +
+		"no API was called; set provider: openrouter and an API key to talk to a model.\n" +
+		"<RequestAccomplished>true</RequestAccomplished>",
+}
+
+// mockSeparatorRe splits a mock responses file at lines of ---
+var mockSeparatorRe = regexp.MustCompile(`(?m)^---[ \t]*\r?\n?`)
+
+// MockProvider answers from a script instead of a model, so the whole loop runs without
+// network access or an API key
+type MockProvider struct {
+	responses []string
+	latency   time.Duration
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewMockProvider creates a mock provider from the mock config, reading the responses
+// file when one is set
+func NewMockProvider(cfg config.MockConfig) (*MockProvider, error) {
+	responses := cfg.Responses
+	if cfg.File != "" {
+		data, err := os.ReadFile(cfg.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mock responses: %w", err)
+		}
+		responses = splitMockResponses(string(data))
+	}
+	if len(responses) == 0 {
+		responses = mockDemoResponses
+	}
+	return &MockProvider{responses: responses, latency: time.Duration(cfg.Latency) * time.Millisecond}, nil
+}
+
+// splitMockResponses splits a responses file at lines of ---, dropping empty responses
+func splitMockResponses(data string) []string {
+	var responses []string
+	for _, part := range mockSeparatorRe.Split(data, -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			responses = append(responses, part)
+		}
+	}
+	return responses
+}
+
+// nextResponse returns the next scripted response, starting over after the last
+func (p *MockProvider) nextResponse() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	response := p.responses[p.next%len(p.responses)]
+	p.next++
+	return response
+}
+
+// GetResponseFromChatMessages waits the configured latency and returns the next response
+func (p *MockProvider) GetResponseFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName string) (string, error) {
+	if err := sleepContext(ctx, p.latency); err != nil {
+		return "", err
+	}
+	response := p.nextResponse()
+	logger.Debug("Mock provider answering %d messages: %s", len(chatMessages), response)
+	return response, nil
+}
+
+// StreamResponseFromChatMessages delivers the next response word by word, spread over
+// the configured latency
+func (p *MockProvider) StreamResponseFromChatMessages(ctx context.Context, chatMessages []ChatMessage, modelName string, onDelta func(string)) (string, error) {
+	response := p.nextResponse()
+	words := strings.SplitAfter(response, " ")
+	delay := p.latency / time.Duration(len(words))
+	for _, word := range words {
+		if err := sleepContext(ctx, delay); err != nil {
+			return "", err
+		}
+		onDelta(word)
+	}
+	logger.Debug("Mock provider streamed %d messages: %s", len(chatMessages), response)
+	return response, nil
+}
@@ -0,0 +1,103 @@
+// Unit tests for the scripted provider in mock_provider.go
+package internal
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: a responses file is split at --- lines and played in turn, starting over
+func TestMockProviderFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "responses.txt")
+	script := "first\n<ExecCommand>ls</ExecCommand>\n---\n\n---  \nsecond\n"
+	if err := os.WriteFile(path, []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Provider = "mock"
+	cfg.Mock = config.MockConfig{File: path}
+	provider, err := NewChatProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		response, err := provider.GetResponseFromChatMessages(context.Background(), nil, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, response)
+	}
+	want := []string{"first\n<ExecCommand>ls</ExecCommand>", "second", "first\n<ExecCommand>ls</ExecCommand>"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// Test: streaming delivers the whole response in pieces, the demo plays without a script
+func TestMockProviderStream(t *testing.T) {
+	provider, err := NewMockProvider(config.MockConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed strings.Builder
+	response, err := provider.StreamResponseFromChatMessages(context.Background(), nil, "", func(s string) { streamed.WriteString(s) })
+	if err != nil || response != mockDemoResponses[0] || streamed.String() != response {
+		t.Errorf("got %q (streamed %q), %v", response, streamed.String(), err)
+	}
+}
+
+// Test: the openrouter provider needs a key, unknown providers are rejected
+func TestNewChatProviderErrors(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if _, err := NewChatProvider(cfg); !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("expected ErrNoAPIKey, got %v", err)
+	}
+	cfg.Provider = "bedrock"
+	if _, err := NewChatProvider(cfg); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+// Test: the selected provider's section supplies key, model and endpoint, with the
+// provider's environment variable and URL as fallbacks
+func TestNewChatProviderSections(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-env")
+	cfg := config.DefaultConfig()
+	cfg.Provider = "anthropic"
+	cfg.OpenRouter.Stream = true
+	provider, err := NewChatProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := provider.(*AiClient)
+	if client.config.APIKey != "sk-ant-env" || client.config.BaseURL != "https://api.anthropic.com/v1/" ||
+		client.config.Model != cfg.Anthropic.Model || !client.config.Stream {
+		t.Errorf("unexpected endpoint %+v", client.config)
+	}
+
+	cfg.Provider = "azure"
+	cfg.Azure.APIKey = "key"
+	if _, err := NewChatProvider(cfg); err == nil {
+		t.Error("expected an error without azure.base_url")
+	}
+	cfg.Azure.BaseURL = "https://example.openai.azure.com"
+	provider, err = NewChatProvider(cfg)
+	if err != nil || provider.(*AiClient).azureAPIVersion != config.DefaultAzureAPIVersion {
+		t.Errorf("unexpected azure client %v", err)
+	}
+
+	t.Setenv("OPENAI_API_KEY", "")
+	cfg.Provider = "openai"
+	if _, err := NewChatProvider(cfg); !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("expected ErrNoAPIKey, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Notification kinds, matched against the events of each sink
+const (
+	NotifyWatch  = "watch"  // watch mode commented on new pane content
+	NotifyTask   = "task"   // a long running task finished
+	NotifyBudget = "budget" // a context or cost budget was exceeded
+)
+
+// Alert events, matched against notifications.alerts
+const (
+	AlertDone    = "done"    // a long request finished
+	AlertConfirm = "confirm" // a command or file change waits for approval
+	AlertWatch   = "watch"   // watch mode commented or its pattern matched
+	AlertWaiting = "waiting" // the AI asked the user something
+)
+
+// discordMaxContent is the message length limit of Discord webhooks
+const discordMaxContent = 2000
+
+var notifyClient = system.HTTPClient(10 * time.Second)
+
+// notify sends a notification to every configured sink subscribed to kind.
+// Delivery happens in the background and failures are only logged.
+func (m *Manager) notify(kind, title, message string) {
+	for _, sink := range m.GetConfig().Notifications.Sinks {
+		if !sinkWants(sink, kind) {
+			continue
+		}
+		go func(sink config.NotificationSink) {
+			if err := sendNotification(sink, title, message); err != nil {
+				logger.Error("Failed to send %s notification to %s: %v", kind, sink.Type, err)
+			}
+		}(sink)
+	}
+}
+
+func sinkWants(sink config.NotificationSink, kind string) bool {
+	if len(sink.Events) == 0 {
+		return true
+	}
+	for _, event := range sink.Events {
+		if event == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func sendNotification(sink config.NotificationSink, title, message string) error {
+	var payload interface{}
+	switch sink.Type {
+	case "slack":
+		payload = map[string]string{"text": fmt.Sprintf("*%s*\n%s", title, message)}
+	case "discord":
+		content := fmt.Sprintf("**%s**\n%s", title, message)
+		if len(content) > discordMaxContent {
+			content = content[:discordMaxContent-3] + "..."
+		}
+		payload = map[string]string{"content": content}
+	default:
+		return fmt.Errorf("unknown notification sink type: %s", sink.Type)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := notifyClient.Post(sink.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// alert rings the terminal bell, shows a message in the tmux status line and a desktop
+// notification for the event, as configured, unless only_away is set and the chat pane
+// is in view
+func (m *Manager) alert(event, title, message string) {
+	alerts := m.GetConfig().Notifications.Alerts
+	bell := slices.Contains(alerts.Bell, event) && system.CursorControl()
+	desktop := slices.Contains(alerts.Desktop, event)
+	display := slices.Contains(alerts.Tmux, event) && m.PaneId != ""
+	if !bell && !desktop && !display {
+		return
+	}
+	if alerts.OnlyAway {
+		if inView, err := system.TmuxPaneInView(m.PaneId); err == nil && inView {
+			return
+		}
+	}
+	if bell {
+		fmt.Fprint(m.out(), "\a")
+	}
+	if display {
+		line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+		if len(line) > 120 {
+			line = line[:117] + "..."
+		}
+		if err := system.TmuxDisplayMessage(m.PaneId, title+": "+line); err != nil {
+			logger.Error("Failed to show tmux message: %v", err)
+		}
+	}
+	if desktop {
+		go func() {
+			if err := system.DesktopNotify(title, message); err != nil {
+				logger.Error("Failed to show desktop notification: %v", err)
+			}
+		}()
+	}
+}
+
+// notifyIfLong notifies when a task took longer than the configured threshold
+func (m *Manager) notifyIfLong(task string, started time.Time) {
+	threshold := time.Duration(m.GetConfig().Notifications.LongTaskSeconds) * time.Second
+	elapsed := time.Since(started)
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+	if len(task) > 200 {
+		task = task[:200] + "..."
+	}
+	title := i18n.T("TmuxAI task finished after %s", elapsed.Round(time.Second))
+	m.notify(NotifyTask, title, task)
+	m.alert(AlertDone, title, task)
+}
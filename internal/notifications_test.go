@@ -0,0 +1,47 @@
+// Unit tests for notification sinks in notifications.go
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: slack and discord sinks get their own payload shapes
+func TestSendNotification_Payloads(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	if err := sendNotification(config.NotificationSink{Type: "slack", URL: server.URL}, "Title", "body"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["text"] != "*Title*\nbody" {
+		t.Errorf("unexpected slack payload: %v", got)
+	}
+
+	long := strings.Repeat("x", 3000)
+	if err := sendNotification(config.NotificationSink{Type: "discord", URL: server.URL}, "Title", long); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got["content"]) != discordMaxContent || !strings.HasPrefix(got["content"], "**Title**") {
+		t.Errorf("unexpected discord payload length %d", len(got["content"]))
+	}
+}
+
+// Test: sinks only receive the events they subscribed to
+func TestSinkWants(t *testing.T) {
+	if !sinkWants(config.NotificationSink{}, NotifyWatch) {
+		t.Errorf("expected a sink without events to want everything")
+	}
+	sink := config.NotificationSink{Events: []string{NotifyTask}}
+	if sinkWants(sink, NotifyWatch) || !sinkWants(sink, NotifyTask) {
+		t.Errorf("unexpected event filtering for %v", sink.Events)
+	}
+}
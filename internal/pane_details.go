@@ -9,10 +9,12 @@ import (
 )
 
 func (m *Manager) GetTmuxPanes() ([]system.TmuxPaneDetails, error) {
-	currentPaneId, _ := system.TmuxCurrentPaneId()
-	windowTarget, _ := system.TmuxCurrentWindowTarget()
-	currentPanes, _ := system.TmuxPanesDetails(windowTarget)
+	currentPanes, _ := system.TmuxWindowPanes(m.PaneId)
+	return m.annotatePanes(currentPanes, m.PaneId), nil
+}
 
+// annotatePanes marks the chat and exec pane among panes and fills in the OS
+func (m *Manager) annotatePanes(currentPanes []system.TmuxPaneDetails, currentPaneId string) []system.TmuxPaneDetails {
 	for i := range currentPanes {
 		currentPanes[i].IsTmuxAiPane = currentPanes[i].Id == currentPaneId
 		currentPanes[i].IsTmuxAiExecPane = currentPanes[i].Id == m.ExecPane.Id
@@ -20,33 +22,45 @@ func (m *Manager) GetTmuxPanes() ([]system.TmuxPaneDetails, error) {
 		if currentPanes[i].IsSubShell {
 			currentPanes[i].OS = "OS Unknown (subshell)"
 		} else {
-			currentPanes[i].OS = m.OS
+			currentPanes[i].OS = m.osDetails()
 		}
 
 	}
-	return currentPanes, nil
+	return currentPanes
 }
 
 func (m *Manager) GetTmuxPanesInXml(config *config.Config) string {
-	currentTmuxWindow := strings.Builder{}
-	currentTmuxWindow.WriteString("<current_tmux_window_state>\n")
 	panes, _ := m.GetTmuxPanes()
+	if m.GetWatchMode() && m.watchOpts.scoped() {
+		panes = m.annotatePanes(m.watchedPanes(), m.PaneId)
+	}
 
 	// Filter out tmuxai_pane
 	var filteredPanes []system.TmuxPaneDetails
 	for _, p := range panes {
-		if !p.IsTmuxAiPane {
-			filteredPanes = append(filteredPanes, p)
+		if p.IsTmuxAiPane {
+			continue
 		}
-	}
-	for _, pane := range filteredPanes {
-		if !pane.IsTmuxAiPane {
-			pane.Refresh(m.GetMaxCaptureLines())
-		}
-		if pane.IsTmuxAiExecPane {
-			m.ExecPane = &pane
+		p.Refresh(m.GetMaxCaptureLines())
+		if p.IsTmuxAiExecPane {
+			m.ExecPane = &p
 		}
+		filteredPanes = append(filteredPanes, p)
+	}
+	filteredPanes = append(filteredPanes, m.contextPaneDetails(panes)...)
+	return m.renderPanesXml(filteredPanes)
+}
 
+// renderPanesXml describes the captured panes for the model
+func (m *Manager) renderPanesXml(panes []system.TmuxPaneDetails) string {
+	size := 64
+	for _, pane := range panes {
+		size += len(pane.Content) + 512
+	}
+	var currentTmuxWindow strings.Builder
+	currentTmuxWindow.Grow(size)
+	currentTmuxWindow.WriteString("<current_tmux_window_state>\n")
+	for _, pane := range panes {
 		var title string
 		if pane.IsTmuxAiExecPane {
 			title = "tmuxai_exec_pane"
@@ -54,29 +68,38 @@ func (m *Manager) GetTmuxPanesInXml(config *config.Config) string {
 			title = "read_only_pane"
 		}
 
-		currentTmuxWindow.WriteString(fmt.Sprintf("<%s>\n", title))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - Id: %s\n", pane.Id))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - CurrentPid: %d\n", pane.CurrentPid))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - CurrentCommand: %s\n", pane.CurrentCommand))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - CurrentCommandArgs: %s\n", pane.CurrentCommandArgs))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - Shell: %s\n", pane.Shell))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - OS: %s\n", pane.OS))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - LastLine: %s\n", pane.LastLine))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - IsActive: %d\n", pane.IsActive))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - IsTmuxAiPane: %t\n", pane.IsTmuxAiPane))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - IsTmuxAiExecPane: %t\n", pane.IsTmuxAiExecPane))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - IsPrepared: %t\n", pane.IsPrepared))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - IsSubShell: %t\n", pane.IsSubShell))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - HistorySize: %d\n", pane.HistorySize))
-		currentTmuxWindow.WriteString(fmt.Sprintf(" - HistoryLimit: %d\n", pane.HistoryLimit))
+		w := &currentTmuxWindow
+		fmt.Fprintf(w, "<%s>\n", title)
+		fmt.Fprintf(w, " - Id: %s\n", pane.Id)
+		fmt.Fprintf(w, " - CurrentPid: %d\n", pane.CurrentPid)
+		fmt.Fprintf(w, " - CurrentCommand: %s\n", pane.CurrentCommand)
+		fmt.Fprintf(w, " - CurrentCommandArgs: %s\n", pane.CurrentCommandArgs)
+		fmt.Fprintf(w, " - CurrentPath: %s\n", pane.CurrentPath)
+		fmt.Fprintf(w, " - Shell: %s\n", pane.Shell)
+		fmt.Fprintf(w, " - OS: %s\n", pane.OS)
+		fmt.Fprintf(w, " - LastLine: %s\n", pane.LastLine)
+		fmt.Fprintf(w, " - IsActive: %d\n", pane.IsActive)
+		fmt.Fprintf(w, " - IsTmuxAiPane: %t\n", pane.IsTmuxAiPane)
+		fmt.Fprintf(w, " - IsTmuxAiExecPane: %t\n", pane.IsTmuxAiExecPane)
+		fmt.Fprintf(w, " - IsPrepared: %t\n", pane.IsPrepared)
+		fmt.Fprintf(w, " - IsSubShell: %t\n", pane.IsSubShell)
+		fmt.Fprintf(w, " - HistorySize: %d\n", pane.HistorySize)
+		fmt.Fprintf(w, " - HistoryLimit: %d\n", pane.HistoryLimit)
+		if m.isContextPane(pane.Id) {
+			w.WriteString(" - Note: added by the user as context to read, commands and keys only ever go to the exec pane\n")
+		}
 
-		if !pane.IsTmuxAiPane && pane.Content != "" {
-			currentTmuxWindow.WriteString("<pane_content>\n")
-			currentTmuxWindow.WriteString(pane.Content)
-			currentTmuxWindow.WriteString("\n</pane_content>\n")
+		if pane.Content != "" {
+			budget := m.Config.Context.OtherPaneTokens
+			if pane.IsTmuxAiExecPane {
+				budget = m.Config.Context.ExecPaneTokens
+			}
+			w.WriteString("<pane_content>\n")
+			w.WriteString(limitTokens(pane.Content, budget))
+			w.WriteString("\n</pane_content>\n")
 		}
 
-		currentTmuxWindow.WriteString(fmt.Sprintf("</%s>\n\n", title))
+		fmt.Fprintf(w, "</%s>\n\n", title)
 	}
 
 	currentTmuxWindow.WriteString("</current_tmux_window_state>\n")
@@ -0,0 +1,43 @@
+// Unit tests and benchmarks for the pane description in pane_details.go
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+func testPanes() []system.TmuxPaneDetails {
+	return []system.TmuxPaneDetails{
+		{Id: "%1", CurrentCommand: "zsh", IsTmuxAiExecPane: true, IsPrepared: true, Content: preparedCapture(20, 9)},
+		{Id: "%2", CurrentCommand: "htop", Content: strings.Repeat("  PID USER  PRI  NI  VIRT   RES\n", 200)},
+	}
+}
+
+// Test: each pane is described with its details and content
+func TestRenderPanesXml(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig()}
+	got := m.renderPanesXml(testPanes())
+	for _, want := range []string{
+		"<current_tmux_window_state>\n<tmuxai_exec_pane>\n - Id: %1\n",
+		"<read_only_pane>\n - Id: %2\n - CurrentPid: 0\n - CurrentCommand: htop\n",
+		"<pane_content>\n[~/src][12:00][0]» ls dir0\n",
+		"</read_only_pane>\n\n</current_tmux_window_state>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+// BenchmarkRenderPanesXml builds the pane part of the prompt for two 200-line panes
+func BenchmarkRenderPanesXml(b *testing.B) {
+	m := &Manager{Config: config.DefaultConfig()}
+	panes := testPanes()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.renderPanesXml(panes)
+	}
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const defaultPipeQuestion = "Explain this input and point out anything that looks wrong."
+
+// StdinIsPiped reports whether stdin is a pipe or file rather than a terminal
+func StdinIsPiped() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice == 0
+}
+
+// RunPipeMode answers a single question about piped stdin and prints the answer to stdout.
+// It does not need a tmux session or an exec pane.
+func RunPipeMode(cfg *config.Config, stdin io.Reader, question string) error {
+	provider, err := NewChatProvider(cfg)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	if question == "" {
+		question = defaultPipeQuestion
+	}
+
+	if err := system.ConfigureHTTP(cfg.HTTP.CAFile, cfg.HTTP.InsecureSkipVerify); err != nil {
+		return err
+	}
+	m := &Manager{
+		Config:           cfg,
+		AiClient:         provider,
+		SessionOverrides: make(map[string]interface{}),
+	}
+
+	// keep the tail of the input, which is usually where failures show up in logs
+	input := limitTokens(string(data), m.GetMaxContextSize()/2)
+	logger.Info("Pipe mode: %d bytes of stdin, question: %s", len(data), question)
+
+	messages := []ChatMessage{
+		{
+			Content: m.baseSystemPrompt() + `
+You are running in pipe mode: the user piped some output into you from their shell.
+There is no tmux pane to control, so never use XML tags. Answer in plain text, concisely.`,
+			FromUser:  false,
+			Timestamp: time.Now(),
+		},
+		{
+			Content:   fmt.Sprintf("<stdin>\n%s\n</stdin>\n\n%s", input, question),
+			FromUser:  true,
+			Timestamp: time.Now(),
+		},
+	}
+
+	emitEvent(EventUserMessage, map[string]interface{}{"content": question, "stdin_bytes": len(data)})
+	// there are no action tags to hold back here, so the answer goes out as it arrives
+	if streaming, ok := m.AiClient.(StreamingProvider); ok && cfg.OpenRouter.Stream && !JSONEventsEnabled() {
+		response, err := streaming.StreamResponseFromChatMessages(context.Background(), messages, m.GetOpenRouterModel(), func(delta string) {
+			fmt.Print(delta)
+		})
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(response, "\n") {
+			fmt.Println()
+		}
+		return nil
+	}
+	response, err := m.AiClient.GetResponseFromChatMessages(context.Background(), messages, m.GetOpenRouterModel())
+	if err != nil {
+		emitEvent(EventError, map[string]interface{}{"message": err.Error()})
+		return err
+	}
+
+	if JSONEventsEnabled() {
+		emitEvent(EventAIResponse, map[string]interface{}{"message": response})
+		return nil
+	}
+	fmt.Println(response)
+	return nil
+}
@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const (
+	pluginSchemaTimeout = 5 * time.Second
+	pluginCallTimeout   = time.Minute
+	maxPluginOutput     = 64 << 10
+)
+
+// PluginToolsDir returns the directory executables offered to the model as tools are
+// loaded from
+func PluginToolsDir() string {
+	return config.GetConfigFilePath("tools")
+}
+
+// PluginTool is an executable of the tools dir offered to the model. It describes itself
+// when run with --schema and is called with the JSON arguments on stdin, its stdout is
+// the result.
+type PluginTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	Path        string                 `json:"-"`
+}
+
+// PluginToolCall is a call of a plugin tool by the model
+type PluginToolCall struct {
+	ToolName  string                 `json:"tool_name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// LoadPluginTools asks every executable in dir for its schema. A tool that doesn't
+// answer --schema gets a generated one taking a single input string, so any script
+// works. Tools are sorted by name, the first of the same name wins.
+func LoadPluginTools(dir string) []PluginTool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var tools []PluginTool
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		tool := describePluginTool(filepath.Join(dir, entry.Name()))
+		if seen[tool.Name] {
+			logger.Error("Skipping plugin tool %s, a tool named %s is already loaded", tool.Path, tool.Name)
+			continue
+		}
+		seen[tool.Name] = true
+		tools = append(tools, tool)
+		logger.Info("Loaded plugin tool %s from %s", tool.Name, tool.Path)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// describePluginTool runs an executable with --schema, falling back to the generated schema
+func describePluginTool(path string) PluginTool {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	tool := PluginTool{Path: path}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginSchemaTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--schema").Output()
+	if err == nil {
+		err = json.Unmarshal(out, &tool)
+	}
+	if err != nil {
+		logger.Debug("Plugin tool %s has no schema, generating one: %v", path, err)
+		tool = PluginTool{Path: path}
+	}
+
+	if tool.Name == "" {
+		tool.Name = base
+	}
+	tool.Name = toolNameRe.ReplaceAllString(tool.Name, "_")
+	if tool.Description == "" {
+		tool.Description = fmt.Sprintf("Runs the %s tool", tool.Name)
+	}
+	if tool.Parameters == nil {
+		tool.Parameters = map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"input": map[string]interface{}{"type": "string", "description": "the input of the tool"},
+			},
+		}
+	}
+	return tool
+}
+
+// pluginToolName is the native tool name offered for a plugin tool
+func pluginToolName(name string) string {
+	name = "tool_" + name
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// callPluginTool runs a tool with the arguments as JSON on stdin and returns its stdout;
+// a failing exit status is an error carrying stderr
+func callPluginTool(ctx context.Context, tool PluginTool, args map[string]interface{}) (string, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	input, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, pluginCallTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, tool.Path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(), "TMUXAI_TOOL="+tool.Name)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	out := stdout.String()
+	if len(out) > maxPluginOutput {
+		out = strings.ToValidUTF8(out[:maxPluginOutput], "") + fmt.Sprintf("\n(truncated to the first %d bytes)", maxPluginOutput)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// pluginTool returns the loaded tool of a name
+func (m *Manager) pluginTool(name string) (PluginTool, bool) {
+	for _, tool := range m.Plugins {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return PluginTool{}, false
+}
+
+// runPluginToolCalls runs the calls one after another, asking first when exec_confirm is
+// on, and returns their results for the history
+func (m *Manager) runPluginToolCalls(ctx context.Context, calls []PluginToolCall) []ChatMessage {
+	var messages []ChatMessage
+	for _, call := range calls {
+		if ctx.Err() != nil {
+			break
+		}
+		var result string
+		tool, ok := m.pluginTool(call.ToolName)
+		if !ok {
+			result = fmt.Sprintf("Tool %s does not exist", call.ToolName)
+		} else if !m.approvePluginCall(call) {
+			result = fmt.Sprintf("Tool %s was not called: the user declined it", call.ToolName)
+		} else {
+			out, err := callPluginTool(ctx, tool, call.Arguments)
+			m.stats.recordToolCall("tools", call.ToolName)
+			emitEvent(EventToolCall, map[string]interface{}{
+				"server":    "tools",
+				"tool":      call.ToolName,
+				"arguments": call.Arguments,
+				"result":    out,
+				"error":     errorString(err),
+			})
+			if err != nil {
+				result = fmt.Sprintf("Tool %s failed: %v", call.ToolName, err)
+			} else {
+				result = fmt.Sprintf("Tool %s result: %s", call.ToolName, out)
+			}
+		}
+		messages = append(messages, ChatMessage{Content: result, FromUser: false, Timestamp: time.Now()})
+	}
+	return messages
+}
+
+// approvePluginCall shows a call with its arguments and asks to run it when a
+// confirmation is required
+func (m *Manager) approvePluginCall(call PluginToolCall) bool {
+	if !m.confirmRequired("", m.GetExecConfirm()) {
+		return true
+	}
+	args, _ := json.MarshalIndent(call.Arguments, "", "  ")
+	fmt.Fprintln(m.out(), system.CurrentTheme().Label.Sprint(i18n.T("Tool:"))+" "+call.ToolName)
+	fmt.Fprintln(m.out(), m.highlightCode("json", string(args)))
+	approved, _ := m.confirmAction(call.ToolName, confirmPluginPrompt, false, string(args))
+	emitConfirmation(confirmPluginPrompt, call.ToolName, approved)
+	m.stats.recordConfirmation(approved)
+	return approved
+}
+
+// pluginToolsDescription lists the plugin tools with their parameters for the system prompt
+func (m *Manager) pluginToolsDescription() string {
+	var b strings.Builder
+	for _, tool := range m.Plugins {
+		params, _ := json.Marshal(tool.Parameters)
+		fmt.Fprintf(&b, "- %s: %s Parameters: %s\n", tool.Name, tool.Description, params)
+	}
+	return b.String()
+}
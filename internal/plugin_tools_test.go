@@ -0,0 +1,77 @@
+//go:build !windows
+
+// Unit tests for executable plugin tools in plugin_tools.go
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTool(t *testing.T, dir, name, script string, mode os.FileMode) {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Test: tools describe themselves with --schema, others get the generated schema, and
+// files that aren't executable are skipped
+func TestLoadPluginTools(t *testing.T) {
+	dir := t.TempDir()
+	writeTool(t, dir, "jira.sh", `if [ "$1" = --schema ]; then
+  echo '{"name": "jira", "description": "Look up an issue", "parameters": {"type": "object", "properties": {"issue": {"type": "string"}}}}'
+fi
+`, 0o755)
+	writeTool(t, dir, "wc-words", "wc -w\n", 0o755)
+	writeTool(t, dir, "notes.txt", "", 0o644)
+
+	tools := LoadPluginTools(dir)
+	if len(tools) != 2 {
+		t.Fatalf("expected 2 tools, got %+v", tools)
+	}
+	if tools[0].Name != "jira" || tools[0].Description != "Look up an issue" {
+		t.Errorf("unexpected described tool: %+v", tools[0])
+	}
+	props, _ := tools[1].Parameters["properties"].(map[string]interface{})
+	if tools[1].Name != "wc-words" || props["input"] == nil {
+		t.Errorf("expected a generated schema: %+v", tools[1])
+	}
+}
+
+// Test: the arguments arrive as JSON on stdin, stdout is the result and a failure
+// carries stderr
+func TestCallPluginTool(t *testing.T) {
+	dir := t.TempDir()
+	writeTool(t, dir, "echo", "cat\n", 0o755)
+	writeTool(t, dir, "fail", "echo 'no token' >&2\nexit 3\n", 0o755)
+
+	out, err := callPluginTool(context.Background(), PluginTool{Name: "echo", Path: filepath.Join(dir, "echo")}, map[string]interface{}{"issue": "OPS-1"})
+	if err != nil || out != `{"issue":"OPS-1"}` {
+		t.Errorf("got %q, %v", out, err)
+	}
+	_, err = callPluginTool(context.Background(), PluginTool{Name: "fail", Path: filepath.Join(dir, "fail")}, nil)
+	if err == nil || !strings.Contains(err.Error(), "no token") {
+		t.Errorf("expected the stderr in the error, got %v", err)
+	}
+}
+
+// Test: unknown and declined calls are reported to the model without running anything
+func TestRunPluginToolCalls(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	writeTool(t, dir, "touch", "touch "+marker+"\n", 0o755)
+	m := newFileEditManager(false)
+	m.Plugins = []PluginTool{{Name: "touch", Path: filepath.Join(dir, "touch")}}
+
+	messages := m.runPluginToolCalls(context.Background(), []PluginToolCall{{ToolName: "missing"}, {ToolName: "touch"}})
+	if len(messages) != 2 || messages[0].Content != "Tool missing does not exist" ||
+		messages[1].Content != "Tool touch was not called: the user declined it" {
+		t.Errorf("unexpected results: %+v", messages)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("a declined tool ran")
+	}
+}
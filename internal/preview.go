@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const previewPlaceholder = "<your next message>"
+
+// handlePreviewCommand renders the request that would be sent for the next turn without sending it
+func handlePreviewCommand(m *Manager, args []string) {
+	message := strings.Join(args, " ")
+	if message == "" {
+		message = previewPlaceholder
+	}
+
+	msgs := m.assembleContext(message)
+	formatter := system.NewInfoFormatter()
+
+	labelTotals := map[ContextLabel]int{}
+	var labels []ContextLabel
+	total := 0
+
+	for i, msg := range msgs {
+		role := "assistant"
+		if msg.FromUser {
+			role = "user"
+		}
+		if i == 0 && !msg.FromUser {
+			role = "system"
+		}
+
+		msgTokens := 0
+		for _, item := range msg.Items {
+			msgTokens += system.EstimateTokenCount(item.Content)
+		}
+		fmt.Fprintln(m.out(), formatter.FormatSection(fmt.Sprintf("Message %d: %s (~%d tokens)", i+1, role, msgTokens)))
+
+		for _, item := range msg.Items {
+			tokens := system.EstimateTokenCount(item.Content)
+			if _, seen := labelTotals[item.Label]; !seen {
+				labels = append(labels, item.Label)
+			}
+			labelTotals[item.Label] += tokens
+			total += tokens
+
+			if item.Content == "" {
+				fmt.Fprintln(m.out(), formatter.LabelColor.Sprintf("[%s] trimmed away", item.Label))
+				continue
+			}
+			fmt.Fprintln(m.out(), formatter.LabelColor.Sprintf("[%s] ~%d tokens", item.Label, tokens))
+			fmt.Fprintln(m.out(), item.Content)
+			fmt.Fprintln(m.out())
+		}
+	}
+
+	fmt.Fprintln(m.out(), formatter.FormatSection("Summary"))
+	for _, label := range labels {
+		fmt.Fprint(m.out(), formatter.FormatKeyValue(string(label), fmt.Sprintf("%d tokens", labelTotals[label])))
+	}
+	fmt.Fprint(m.out(), formatter.FormatKeyValue("total", fmt.Sprintf("%d / %d tokens", total, m.GetMaxContextSize())))
+	fmt.Fprint(m.out(), formatter.FormatKeyValue("model", m.GetOpenRouterModel()))
+}
@@ -3,61 +3,92 @@ package internal
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/alvinunreal/tmuxai/i18n"
 	"github.com/alvinunreal/tmuxai/logger"
 	"github.com/alvinunreal/tmuxai/system"
-	"github.com/briandowns/spinner"
 )
 
+// requestResponse asks the model for a response, streaming it into live when set
+func (m *Manager) requestResponse(ctx context.Context, sending []ChatMessage, live *liveResponse) (response string, err error) {
+	started := time.Now()
+	defer func() {
+		var usage *TokenUsage
+		if reporter, ok := m.AiClient.(UsageReporter); ok && err == nil {
+			usage = reporter.LastUsage()
+		}
+		m.stats.recordRequest(time.Since(started), m.GetOpenRouterModel(), sending, response, usage, err)
+	}()
+	if provider, ok := m.AiClient.(ToolCallingProvider); ok && m.Config.OpenRouter.ToolCalling {
+		return m.requestToolCalls(ctx, provider, sending, live)
+	}
+	if m.Config.OpenRouter.JSONMode {
+		return m.requestJSONResponse(ctx, sending)
+	}
+	if live != nil {
+		return m.AiClient.(StreamingProvider).StreamResponseFromChatMessages(ctx, sending, m.GetOpenRouterModel(), live.Write)
+	}
+	return m.AiClient.GetResponseFromChatMessages(ctx, sending, m.GetOpenRouterModel())
+}
+
+// runRequest handles a message from the user as one request, through all the turns
+// the agent needs, and summarizes the steps when it ran more than one command. It
+// reports whether the agent marked the request accomplished.
+func (m *Manager) runRequest(ctx context.Context, message string) (accomplished bool) {
+	return m.runTask(ctx, m.newTask(message), message)
+}
+
+// runTask runs a turn for a task, recording the commands and keys as its steps
+func (m *Manager) runTask(ctx context.Context, task *requestTask, message string) (accomplished bool) {
+	m.ready()
+	started := time.Now()
+	m.SetStatus("running")
+	task.Status = taskRunning
+	m.steps = &task.Steps
+	m.refreshStatusHeader()
+	accomplished = m.ProcessUserMessage(ctx, message)
+	if len(m.steps.Steps) > 1 {
+		m.Println(i18n.T("Steps:"))
+		fmt.Fprint(m.out(), m.steps.render())
+	}
+	m.steps = nil
+	task.finish(ctx.Err() != nil, accomplished)
+	m.SetStatus("")
+	m.spillHistory()
+	m.refreshStatusHeader()
+	m.notifyIfLong(message, started)
+	return accomplished
+}
+
 // Main function to process regular user messages
 // Returns true if the request was accomplished and no further processing should happen
 func (m *Manager) ProcessUserMessage(ctx context.Context, message string) bool {
 	// Check if context management is needed before sending
 	if m.needSquash() {
-		m.Println("Exceeded context size, squashing history...")
-		m.squashHistory()
+		m.Println(i18n.T("Exceeded context size, squashing history..."))
+		m.notify(NotifyBudget, i18n.T("TmuxAI context budget exceeded"), i18n.T("The chat history is being squashed to fit max_context_size."))
+		m.squashHistory(ctx)
 	}
 
-	s := spinner.New(spinner.CharSets[39], 100*time.Millisecond)
-	s.Start()
+	s := m.startProgress(39, "Thinking")
 
 	// check for status change before processing
-	if m.Status == "" {
+	if m.GetStatus() == "" || ctx.Err() != nil {
 		s.Stop()
 		return false
 	}
 
-	currentTmuxWindow := m.GetTmuxPanesInXml(m.Config)
-	execPaneEnv := ""
-	if !m.ExecPane.IsSubShell {
-		execPaneEnv = fmt.Sprintf("Keep in mind, you are working within the shell: %s and OS: %s", m.ExecPane.Shell, m.ExecPane.OS)
-	}
-	currentMessage := ChatMessage{
-		Content:   currentTmuxWindow + "\n\n" + execPaneEnv + "\n\n" + message,
-		FromUser:  true,
-		Timestamp: time.Now(),
-	}
-
-	// build current chat history
-	var history []ChatMessage
-	switch {
-	case m.WatchMode:
-		history = []ChatMessage{m.watchPrompt()}
-	case m.ExecPane.IsPrepared:
-		history = []ChatMessage{m.chatAssistantPrompt(true)}
-	default:
-		history = []ChatMessage{m.chatAssistantPrompt(false)}
-	}
-
-	history = append(history, m.Messages...)
-
-	sending := append(history, currentMessage)
+	currentMessage, sending := m.assembleRequest(message)
+	emitEvent(EventUserMessage, map[string]interface{}{"content": message})
 
-	response, err := m.AiClient.GetResponseFromChatMessages(ctx, sending, m.GetOpenRouterModel())
+	live := m.newLiveResponse(s)
+	response, err := m.requestResponse(ctx, sending, live)
 	if err != nil {
+		live.Finish()
 		s.Stop()
-		m.Status = ""
+		m.SetStatus("")
 
 		if ctx.Err() == context.Canceled {
 			return false
@@ -65,45 +96,51 @@ func (m *Manager) ProcessUserMessage(ctx context.Context, message string) bool {
 
 		// Log both to console and debug file to capture error context
 		errMsg := "Failed to get response from AI: " + err.Error()
-		fmt.Println(errMsg)
+		fmt.Fprintln(m.out(), errMsg)
+		emitEvent(EventError, map[string]interface{}{"message": errMsg})
 
 		// Debug the failed request even when there's an error
 		if m.Config.Debug {
-			debugChatMessages(append(history, currentMessage), "ERROR: "+err.Error())
+			debugChatMessages(sending, "ERROR: "+err.Error())
 		}
 
 		return false
 	}
 
 	// check for status change again
-	if m.Status == "" {
+	if m.GetStatus() == "" {
+		live.Finish()
 		s.Stop()
 		return false
 	}
 
 	r, err := m.parseAIResponse(response)
 	if err != nil {
+		live.Finish()
 		s.Stop()
-		m.Status = ""
+		m.SetStatus("")
 
 		// Log both to console and debug file
 		errMsg := "Failed to parse AI response: " + err.Error()
 		logger.Error("ProcessUserMessage errMsg: %s", errMsg)
+		emitEvent(EventError, map[string]interface{}{"message": errMsg})
 
 		// Debug the failed parsing even when there's an error
 		if m.Config.Debug {
-			debugChatMessages(append(history, currentMessage), "PARSE ERROR: "+response)
+			debugChatMessages(sending, "PARSE ERROR: "+response)
 		}
 
 		return false
 	}
 
 	if m.Config.Debug {
-		debugChatMessages(append(history, currentMessage), response)
+		debugChatMessages(sending, response)
 	}
 
 	logger.Debug("AIResponse: %s", r.String())
+	emitAIResponse(r)
 
+	keptLive := live.Finish()
 	s.Stop()
 
 	responseMsg := ChatMessage{
@@ -113,79 +150,144 @@ func (m *Manager) ProcessUserMessage(ctx context.Context, message string) bool {
 	}
 
 	// Process MCP tool calls
-	for _, toolCall := range r.McpToolCalls {
-		result, err := m.McpClient.CallTool(toolCall.ServerName, toolCall.ToolName, toolCall.Arguments)
-		if err != nil {
-			// 将错误信息添加到对话历史
-			errorMsg := ChatMessage{
-				Content:   fmt.Sprintf("MCP tool %s.%s failed: %v", toolCall.ServerName, toolCall.ToolName, err),
-				FromUser:  false,
-				Timestamp: time.Now(),
-			}
-			m.Messages = append(m.Messages, errorMsg)
-		} else {
-			// 将成功结果添加到对话历史
-			resultMsg := ChatMessage{
-				Content:   fmt.Sprintf("MCP tool %s.%s result: %s", toolCall.ServerName, toolCall.ToolName, result),
-				FromUser:  false,
-				Timestamp: time.Now(),
-			}
-			m.Messages = append(m.Messages, resultMsg)
+	if len(r.McpToolCalls) > 0 {
+		m.appendMessages(m.runMcpToolCalls(ctx, r.McpToolCalls)...)
+		if ctx.Err() != nil {
+			m.SetStatus("")
+			return false
+		}
+	}
+	if len(r.PluginToolCalls) > 0 {
+		m.appendMessages(m.runPluginToolCalls(ctx, r.PluginToolCalls)...)
+		if ctx.Err() != nil {
+			m.SetStatus("")
+			return false
 		}
 	}
 
 	// did AI follow our guidelines?
 	guidelineError, validResponse := m.aiFollowedGuidelines(r)
 	if !validResponse {
-		m.Println("AI didn't follow guidelines, trying again...")
-		m.Messages = append(m.Messages, currentMessage, responseMsg)
+		m.Println(i18n.T("AI didn't follow guidelines, trying again..."))
+		m.appendMessages(currentMessage, responseMsg)
 		return m.ProcessUserMessage(ctx, guidelineError)
 
 	}
 
+	if m.Scripts != nil && r.Message != "" {
+		r.Message = m.Scripts.ProcessResponse(r.Message)
+	}
+	m.lastAIMessage = r.Message
+
 	// colorize code blocks in the response
 	if r.Message != "" {
-		fmt.Println(system.Cosmetics(r.Message))
+		if !keptLive {
+			fmt.Fprintln(m.out(), m.cosmetics(r.Message))
+		}
+		if m.GetWatchMode() && !r.NoComment {
+			m.notify(NotifyWatch, i18n.T("TmuxAI watch alert"), r.Message)
+			m.alert(AlertWatch, i18n.T("TmuxAI watch alert"), r.Message)
+		}
 	}
 
 	// Don't append to history if AI is waiting for the pane or is watch mode no comment
 	if r.ExecPaneSeemsBusy || r.NoComment {
 	} else {
-		m.Messages = append(m.Messages, currentMessage, responseMsg)
+		m.appendMessages(currentMessage, responseMsg)
+	}
+
+	// file edits are shown as a diff and written directly instead of typed into the pane
+	for _, edit := range r.FileEdits {
+		if !m.applyFileEdit(edit) {
+			m.SetStatus("")
+			return false
+		}
+	}
+	for _, patch := range r.FilePatches {
+		if !m.applyFilePatch(patch) {
+			m.SetStatus("")
+			return false
+		}
+	}
+	for _, name := range r.FileReads {
+		if !m.readFileForModel(name) {
+			m.SetStatus("")
+			return false
+		}
+	}
+	for _, u := range r.FetchURLs {
+		if !m.fetchURLForModel(ctx, u) {
+			m.SetStatus("")
+			return false
+		}
+	}
+	for _, task := range r.Delegations {
+		if !m.delegateForModel(task) {
+			m.SetStatus("")
+			return false
+		}
 	}
 
 	// observe/prepared mode
 	for _, execCommand := range r.ExecCommand {
-		code, _ := system.HighlightCode("sh", execCommand)
+		code := m.highlightCode("sh", execCommand)
 		m.Println(code)
 
 		isSafe := false
 		command := execCommand
-		if m.GetExecConfirm() {
-			isSafe, command = m.confirmedToExec(execCommand, "Execute this command?", true)
+		if m.Scripts != nil {
+			var allowed bool
+			if command, allowed = m.Scripts.ProcessExec(command); !allowed {
+				m.Println(i18n.T("Command blocked by an on_exec hook: %s", command))
+				m.audit("exec", execCommand, auditBlocked, nil)
+				continue
+			}
+		}
+		action, rule := m.decideRules(ruleTargetExec, command)
+		if action == ruleBlock {
+			m.blockedByRule(confirmExecPrompt, command, rule)
+			m.audit("exec", command, auditBlocked, nil)
+			continue
+		}
+		decision := auditAuto
+		if m.confirmRequired(action, m.GetExecConfirm()) {
+			isSafe, command = m.confirmedToExec(command, confirmExecPrompt, true)
+			emitConfirmation(confirmExecPrompt, command, isSafe)
+			m.stats.recordConfirmation(isSafe)
+			decision = auditApproved
 		} else {
 			isSafe = true
 		}
 		if isSafe {
-			m.Println("Executing command: " + command)
-			if m.ExecPane.IsPrepared {
-				m.ExecWaitCapture(command)
-			} else {
-				system.TmuxSendCommandToPane(m.ExecPane.Id, command, true)
-				time.Sleep(1 * time.Second)
+			executed, err := m.execInPane(ctx, command, r.Message)
+			if err != nil {
+				m.Println(err.Error())
+				if ctx.Err() != nil {
+					// sent, but the wait for it was cancelled
+					m.audit("exec", command, decision, nil)
+				} else {
+					m.audit("exec", command, auditBlocked, nil)
+				}
+				continue
 			}
+			m.audit("exec", command, decision, executed.Code)
 		} else {
-			m.Status = ""
+			m.audit("exec", command, auditDeclined, nil)
+			m.SetStatus("")
 			return false
 		}
 	}
 
 	// Process SendKeys
-	if len(r.SendKeys) > 0 {
+	keysAction, keysRule := m.decideRules(ruleTargetKeys, r.SendKeys...)
+	if keysAction == ruleBlock {
+		m.blockedByRule(confirmKeysPrompt, strings.Join(r.SendKeys, "\n"), keysRule)
+		m.audit("keys", strings.Join(r.SendKeys, "\n"), auditBlocked, nil)
+	} else if len(r.SendKeys) > 0 {
 		// Show preview of all keys
-		keysPreview := "Keys to send:\n"
+		keysPreview := i18n.T("Keys to send:") + "\n"
 		for i, sendKey := range r.SendKeys {
-			code, _ := system.HighlightCode("txt", sendKey)
+			code := m.highlightCode("txt", sendKey)
 			if i == len(r.SendKeys)-1 {
 				keysPreview += code
 			} else {
@@ -196,69 +298,92 @@ func (m *Manager) ProcessUserMessage(ctx context.Context, message string) bool {
 		m.Println(keysPreview)
 
 		// Determine confirmation message based on number of keys
-		confirmMessage := "Send this key?"
+		confirmMessage := confirmKeyPrompt
 		if len(r.SendKeys) > 1 {
-			confirmMessage = "Send all these keys?"
+			confirmMessage = confirmKeysPrompt
 		}
 
 		// Get confirmation if required
 		allConfirmed := true
-		if m.GetSendKeysConfirm() {
-			allConfirmed, _ = m.confirmedToExec("keys shown above", confirmMessage, true)
+		decision := auditAuto
+		if m.confirmRequired(keysAction, m.GetSendKeysConfirm()) {
+			allConfirmed, _ = m.confirmedToExec(strings.Join(r.SendKeys, "\n"), confirmMessage, false)
+			emitConfirmation(confirmMessage, strings.Join(r.SendKeys, "\n"), allConfirmed)
+			m.stats.recordConfirmation(allConfirmed)
+			decision = auditApproved
 			if !allConfirmed {
-				m.Status = ""
+				m.audit("keys", strings.Join(r.SendKeys, "\n"), auditDeclined, nil)
+				m.SetStatus("")
 				return false
 			}
 		}
 
 		// Send each key with delay
+		emitEvent(EventSendKeys, map[string]interface{}{"keys": r.SendKeys})
 		for _, sendKey := range r.SendKeys {
-			m.Println("Sending keys: " + sendKey)
+			m.Println(i18n.T("Sending keys: %s", sendKey))
 			system.TmuxSendCommandToPane(m.ExecPane.Id, sendKey, false)
-			time.Sleep(1 * time.Second)
+			m.audit("keys", sendKey, decision, nil)
+			m.keysStep(sendKey)
+			if sleepContext(ctx, time.Second) != nil {
+				m.SetStatus("")
+				return false
+			}
 		}
 	}
 
 	if r.ExecPaneSeemsBusy {
-		m.Countdown(m.GetWaitInterval())
-		// Create a new context for this recursive call
-		newCtx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		accomplished := m.ProcessUserMessage(newCtx, "waited for 5 more seconds, here is the current pane(s) content")
+		m.Countdown(ctx, m.GetWaitInterval())
+		accomplished := m.ProcessUserMessage(ctx, "waited for 5 more seconds, here is the current pane(s) content")
 		if accomplished {
 			return true
 		}
 	}
 
 	// observe or prepared mode
-	if r.PasteMultilineContent != "" {
-		code, _ := system.HighlightCode("txt", r.PasteMultilineContent)
-		fmt.Println(code)
+	pasteAction, pasteRule := m.decideRules(ruleTargetPaste, r.PasteMultilineContent)
+	if r.PasteMultilineContent != "" && pasteAction == ruleBlock {
+		m.blockedByRule(confirmPastePrompt, r.PasteMultilineContent, pasteRule)
+		m.audit("paste", r.PasteMultilineContent, auditBlocked, nil)
+	} else if r.PasteMultilineContent != "" {
+		code := m.highlightCode("txt", r.PasteMultilineContent)
+		fmt.Fprintln(m.out(), code)
 
 		isSafe := false
-		if m.GetPasteMultilineConfirm() {
-			isSafe, _ = m.confirmedToExec(r.PasteMultilineContent, "Paste multiline content?", false)
+		decision := auditAuto
+		if m.confirmRequired(pasteAction, m.GetPasteMultilineConfirm()) {
+			isSafe, _ = m.confirmedToExec(r.PasteMultilineContent, confirmPastePrompt, false)
+			emitConfirmation(confirmPastePrompt, r.PasteMultilineContent, isSafe)
+			m.stats.recordConfirmation(isSafe)
+			decision = auditApproved
 		} else {
 			isSafe = true
 		}
 
 		if isSafe {
-			m.Println("Pasting...")
+			m.Println(i18n.T("Pasting..."))
+			emitEvent(EventPaste, map[string]interface{}{"content": r.PasteMultilineContent})
 			system.TmuxSendCommandToPane(m.ExecPane.Id, r.PasteMultilineContent, true)
-			time.Sleep(1 * time.Second)
+			m.audit("paste", r.PasteMultilineContent, decision, nil)
+			if sleepContext(ctx, time.Second) != nil {
+				m.SetStatus("")
+				return false
+			}
 		} else {
-			m.Status = ""
+			m.audit("paste", r.PasteMultilineContent, auditDeclined, nil)
+			m.SetStatus("")
 			return false
 		}
 	}
 
 	if r.RequestAccomplished {
-		m.Status = ""
+		m.SetStatus("")
 		return true
 	}
 
 	if r.WaitingForUserResponse {
-		m.Status = "waiting"
+		m.SetStatus("waiting")
+		m.alert(AlertWaiting, i18n.T("TmuxAI is waiting for your answer"), r.Message)
 		return false
 	}
 
@@ -267,7 +392,7 @@ func (m *Manager) ProcessUserMessage(ctx context.Context, message string) bool {
 		return false
 	}
 
-	if !m.WatchMode {
+	if !m.GetWatchMode() && ctx.Err() == nil {
 		accomplished := m.ProcessUserMessage(ctx, "sending updated pane(s) content")
 		if accomplished {
 			return true
@@ -276,28 +401,15 @@ func (m *Manager) ProcessUserMessage(ctx context.Context, message string) bool {
 	return false
 }
 
-func (m *Manager) startWatchMode(desc string) {
-
-	// check status
-	if m.Status == "" {
-		return
-	}
-
-	m.Countdown(m.GetWaitInterval())
-
-	// Create a new background context since this is a separate process
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	accomplished := m.ProcessUserMessage(ctx, desc)
-	if accomplished {
-		m.WatchMode = false
-		m.Status = ""
-	}
-
-	// we continue running if status is still set
-	if m.Status != "" && m.WatchMode {
-		m.startWatchMode("")
+// sleepContext waits for d, returning early with the context's error when it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -327,7 +439,7 @@ func (m *Manager) aiFollowedGuidelines(r AIResponse) (string, bool) {
 	}
 
 	// Check if only one tag is used
-	tags := []int{len(r.ExecCommand), len(r.SendKeys), len(r.PasteMultilineContent)}
+	tags := []int{len(r.ExecCommand), len(r.SendKeys), len(r.PasteMultilineContent), len(r.FileEdits) + len(r.FilePatches), len(r.FileReads), len(r.FetchURLs), len(r.PluginToolCalls), len(r.Delegations)}
 	count := 0
 	for _, len := range tags {
 		if len > 0 {
@@ -340,7 +452,7 @@ func (m *Manager) aiFollowedGuidelines(r AIResponse) (string, bool) {
 	}
 
 	// watch mode has no xml tags, otherwise should be at least 1 xml tag in response
-	if !m.WatchMode && count+boolCount == 0 {
+	if !m.GetWatchMode() && count+boolCount == 0 {
 		return "You didn't follow the guidelines. You must use at least one XML tag in your response. Pay attention!", false
 	}
 
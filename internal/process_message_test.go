@@ -0,0 +1,37 @@
+// Unit tests for cancellation in process_message.go
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Test: sleepContext returns as soon as the context is cancelled
+func TestSleepContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	started := time.Now()
+	if err := sleepContext(ctx, time.Minute); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(started) > time.Second {
+		t.Error("sleepContext waited despite the cancelled context")
+	}
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// Test: a cancelled watch stops before waiting or asking the model
+func TestStartWatchModeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m := &Manager{status: "running", watchMode: true}
+	m.startWatchMode(ctx, "watch for errors")
+	if m.GetWatchMode() {
+		t.Error("watch mode still on after cancellation")
+	}
+}
@@ -10,38 +10,95 @@ import (
 	"github.com/alvinunreal/tmuxai/logger"
 )
 
+var writeFileRe = regexp.MustCompile(`(?s)<WriteFile\s+path="([^"]+)"\s*>\n?(.*?)</WriteFile>`)
+
+var patchFileRe = regexp.MustCompile(`(?s)<PatchFile\s+path="([^"]+)"\s*>(.*?)</PatchFile>`)
+
+// patchHunkRe is a SEARCH/REPLACE block of a PatchFile; the REPLACE part may be empty
+var patchHunkRe = regexp.MustCompile("(?s)<<<<<<< SEARCH\n(.*?)\n=======\n(?:(.*?)\n)?>>>>>>> REPLACE")
+
+// responseTag is an action tag of a response with its regexps compiled once, parsing
+// runs for every response and every watch mode turn
+type responseTag struct {
+	name     string
+	isBool   bool
+	setField func(*AIResponse, string)
+
+	value      *regexp.Regexp // <Tag>value</Tag>
+	fenced     *regexp.Regexp // the tag in a code block
+	backticked *regexp.Regexp // `<Tag>...</Tag>`
+	bare       *regexp.Regexp // a bool tag without a value: <Tag>, <Tag/>, ```<Tag>```
+	leftover   *regexp.Regexp // lines left with only the tag on them
+}
+
+func newResponseTag(name string, isBool bool, setField func(*AIResponse, string)) responseTag {
+	return responseTag{
+		name:       name,
+		isBool:     isBool,
+		setField:   setField,
+		value:      regexp.MustCompile(fmt.Sprintf(`(?s)<%s>(.*?)</%s>`, name, name)),
+		fenced:     regexp.MustCompile(fmt.Sprintf("(?s)```(?:xml)?\\s*<%s>.*?</%s>\\s*```", name, name)),
+		backticked: regexp.MustCompile(fmt.Sprintf("`<%s>.*?</%s>`", name, name)),
+		bare:       regexp.MustCompile(fmt.Sprintf("(?s)(<%s>\\s*</%s>|<%s>\\s*|```<%s>```|<%s/>)", name, name, name, name, name)),
+		leftover:   regexp.MustCompile(fmt.Sprintf("(?m)^\\s*(<%s>\\s*|```<%s>```)?\\s*$", name, name)),
+	}
+}
+
+var responseTags = []responseTag{
+	newResponseTag("TmuxSendKeys", false, func(r *AIResponse, v string) { r.SendKeys = append(r.SendKeys, v) }),
+	newResponseTag("ExecCommand", false, func(r *AIResponse, v string) { r.ExecCommand = append(r.ExecCommand, v) }),
+	newResponseTag("PasteMultilineContent", false, func(r *AIResponse, v string) { r.PasteMultilineContent = v }),
+	newResponseTag("RequestAccomplished", true, func(r *AIResponse, v string) { r.RequestAccomplished = isTrue(v) }),
+	newResponseTag("ExecPaneSeemsBusy", true, func(r *AIResponse, v string) { r.ExecPaneSeemsBusy = isTrue(v) }),
+	newResponseTag("WaitingForUserResponse", true, func(r *AIResponse, v string) { r.WaitingForUserResponse = isTrue(v) }),
+	newResponseTag("NoComment", true, func(r *AIResponse, v string) { r.NoComment = isTrue(v) }),
+	newResponseTag("ReadFile", false, func(r *AIResponse, v string) { r.FileReads = append(r.FileReads, v) }),
+	newResponseTag("PluginToolCall", false, func(r *AIResponse, v string) {
+		var call PluginToolCall
+		if err := json.Unmarshal([]byte(v), &call); err == nil {
+			r.PluginToolCalls = append(r.PluginToolCalls, call)
+		} else {
+			logger.Error("Invalid PluginToolCall %q: %v", v, err)
+		}
+	}),
+	newResponseTag("FetchUrl", false, func(r *AIResponse, v string) { r.FetchURLs = append(r.FetchURLs, v) }),
+	newResponseTag("DelegateTask", false, func(r *AIResponse, v string) { r.Delegations = append(r.Delegations, v) }),
+	// 新增MCP工具调用标签
+	newResponseTag("McpToolCall", false, func(r *AIResponse, v string) {
+		if toolCall, err := parseMcpToolCall(v); err == nil {
+			r.McpToolCalls = append(r.McpToolCalls, toolCall)
+		}
+	}),
+}
+
+var blankLinesRe = regexp.MustCompile(`\n{2,}`)
+
 func (m *Manager) parseAIResponse(response string) (AIResponse, error) {
 	logger.Info("parseAIResponse response: %s", response)
-	// Tag mapping: tag name -> field
-	type tagInfo struct {
-		name     string
-		isArray  bool
-		isBool   bool
-		setField func(*AIResponse, string)
-	}
-	tags := []tagInfo{
-		{"TmuxSendKeys", true, false, func(r *AIResponse, v string) { r.SendKeys = append(r.SendKeys, v) }},
-		{"ExecCommand", true, false, func(r *AIResponse, v string) { r.ExecCommand = append(r.ExecCommand, v) }},
-		{"PasteMultilineContent", false, false, func(r *AIResponse, v string) { r.PasteMultilineContent = v }},
-		{"RequestAccomplished", false, true, func(r *AIResponse, v string) { r.RequestAccomplished = isTrue(v) }},
-		{"ExecPaneSeemsBusy", false, true, func(r *AIResponse, v string) { r.ExecPaneSeemsBusy = isTrue(v) }},
-		{"WaitingForUserResponse", false, true, func(r *AIResponse, v string) { r.WaitingForUserResponse = isTrue(v) }},
-		{"NoComment", false, true, func(r *AIResponse, v string) { r.NoComment = isTrue(v) }},
-		// 新增MCP工具调用标签
-		{"McpToolCall", true, false, func(r *AIResponse, v string) {
-			if toolCall, err := parseMcpToolCall(v); err == nil {
-				r.McpToolCalls = append(r.McpToolCalls, toolCall)
-			}
-		}},
-	}
 
-	clean := response
-	tagPattern := `(?s)<%s>(.*?)</%s>`
 	r := AIResponse{}
+
+	// WriteFile carries a path attribute and the file body, which is kept verbatim. The
+	// bodies are dropped before the tags are matched, a file may quote the tags itself.
+	for _, match := range writeFileRe.FindAllStringSubmatch(response, -1) {
+		r.FileEdits = append(r.FileEdits, FileEdit{Path: html.UnescapeString(match[1]), Content: match[2]})
+	}
+	clean := writeFileRe.ReplaceAllString(response, "")
+	// PatchFile SEARCH/REPLACE blocks are file content too
+	for _, match := range patchFileRe.FindAllStringSubmatch(clean, -1) {
+		patch := FilePatch{Path: html.UnescapeString(match[1])}
+		for _, hunk := range patchHunkRe.FindAllStringSubmatch(match[2], -1) {
+			patch.Hunks = append(patch.Hunks, PatchHunk{Search: hunk[1], Replace: hunk[2]})
+		}
+		r.FilePatches = append(r.FilePatches, patch)
+	}
+	clean = patchFileRe.ReplaceAllString(clean, "")
 	cleanForMsg := clean
-	for _, t := range tags {
-		reTag := regexp.MustCompile(fmt.Sprintf(tagPattern, t.name, t.name))
-		tagMatches := reTag.FindAllStringSubmatch(clean, -1)
+	for _, t := range responseTags {
+		if !strings.Contains(clean, "<"+t.name) {
+			continue
+		}
+		tagMatches := t.value.FindAllStringSubmatch(clean, -1)
 		for _, m := range tagMatches {
 			// m[0] is the full match, m[1] is the value
 			if len(m) < 2 {
@@ -52,31 +109,19 @@ func (m *Manager) parseAIResponse(response string) (AIResponse, error) {
 			if !t.isBool {
 				val = html.UnescapeString(val)
 			}
-
-			if t.isArray {
-				t.setField(&r, val)
-			} else {
-				t.setField(&r, val)
-			}
+			t.setField(&r, val)
 		}
 		// For message: remove all tag blocks, including code/backtick wrappers
 		// Remove code block: ```xml\n<tag>...</tag>\n```, ```\n<tag>...</tag>\n```
-		cleanForMsg = regexp.MustCompile(fmt.Sprintf("(?s)```(?:xml)?\\s*<%s>.*?</%s>\\s*```", t.name, t.name)).ReplaceAllString(cleanForMsg, "")
+		cleanForMsg = t.fenced.ReplaceAllString(cleanForMsg, "")
 		// Remove single backtick-wrapped tags: `<Tag>...</Tag>`
-		cleanForMsg = regexp.MustCompile(fmt.Sprintf("`<%s>.*?</%s>`", t.name, t.name)).ReplaceAllString(cleanForMsg, "")
+		cleanForMsg = t.backticked.ReplaceAllString(cleanForMsg, "")
 		// Remove plain tag: <Tag>...</Tag>
-		cleanForMsg = reTag.ReplaceAllString(cleanForMsg, "")
-	}
+		cleanForMsg = t.value.ReplaceAllString(cleanForMsg, "")
 
-	// Special handling: tags that may appear as <TagName> or ```<TagName>``` (no value)
-	// Set bool fields to true if such tag is present, even if no value
-	for _, t := range tags {
-		if !t.isBool {
-			continue
-		}
-		// Match <TagName> or ```<TagName>```
-		pat := fmt.Sprintf("(?s)(<%s>\\s*</%s>|<%s>\\s*|```<%s>```|<%s/>)", t.name, t.name, t.name, t.name, t.name)
-		if regexp.MustCompile(pat).MatchString(clean) {
+		// Special handling: tags that may appear as <TagName> or ```<TagName>``` (no value)
+		// Set bool fields to true if such tag is present, even if no value
+		if t.isBool && t.bare.MatchString(clean) {
 			t.setField(&r, "1")
 		}
 	}
@@ -85,10 +130,9 @@ func (m *Manager) parseAIResponse(response string) (AIResponse, error) {
 	msg := strings.TrimSpace(cleanForMsg)
 	msg = collapseBlankLines(msg)
 	// Remove any leftover tag lines (e.g. <TagName>) that may not have been removed
-	for _, t := range tags {
+	for _, t := range responseTags {
 		// Remove lines that are just <TagName> or ```<TagName>```
-		reLeftover := regexp.MustCompile(fmt.Sprintf("(?m)^\\s*(<%s>\\s*|```<%s>```)?\\s*$", t.name, t.name))
-		msg = reLeftover.ReplaceAllString(msg, "")
+		msg = t.leftover.ReplaceAllString(msg, "")
 	}
 	msg = strings.TrimSpace(msg)
 	r.Message = msg
@@ -118,14 +162,5 @@ func isTrue(s string) bool {
 
 // Collapse multiple blank lines to a single newline
 func collapseBlankLines(s string) string {
-	return mustCompile(`\n{2,}`).ReplaceAllString(s, "\n")
-}
-
-// mustCompile is a helper for regexp.MustCompile
-func mustCompile(expr string) *regexp.Regexp {
-	re, err := regexp.Compile(expr)
-	if err != nil {
-		panic(err)
-	}
-	return re
+	return blankLinesRe.ReplaceAllString(s, "\n")
 }
@@ -253,3 +253,95 @@ func TestParseAIResponse_MixedAIAndNonAIXMLTags(t *testing.T) {
 		t.Errorf("got %+v, want %+v", got, want)
 	}
 }
+
+// Test: WriteFile keeps the path attribute and the body verbatim, including XML-like text
+func TestParseAIResponse_WriteFile(t *testing.T) {
+	m := &Manager{}
+	input := "Fixing the comparison.\n<WriteFile path=\"src/cmp.go\">\nif a <b && c > d {\n}\n</WriteFile>"
+	want := AIResponse{
+		Message:   "Fixing the comparison.",
+		FileEdits: []FileEdit{{Path: "src/cmp.go", Content: "if a <b && c > d {\n}\n"}},
+	}
+	got, err := m.parseAIResponse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// Test: action tags quoted inside a WriteFile body are file content, not actions
+func TestParseAIResponse_TagsInsideWriteFile(t *testing.T) {
+	m := &Manager{}
+	body := "Example: <ExecCommand>rm -rf build</ExecCommand> <RequestAccomplished>1</RequestAccomplished>"
+	input := "Documenting the protocol.\n<WriteFile path=\"README.md\">" + body + "</WriteFile>\n<ExecCommand>ls</ExecCommand>"
+	want := AIResponse{
+		Message:     "Documenting the protocol.",
+		ExecCommand: []string{"ls"},
+		FileEdits:   []FileEdit{{Path: "README.md", Content: body}},
+	}
+	got, err := m.parseAIResponse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// Test: PatchFile collects its SEARCH/REPLACE blocks, an empty REPLACE deletes, and
+// ReadFile paths are collected
+func TestParseAIResponse_PatchAndReadFile(t *testing.T) {
+	m := &Manager{}
+	input := "Fixing it.\n<PatchFile path=\"main.go\">\n<<<<<<< SEARCH\nold()\n=======\nnew()\n>>>>>>> REPLACE\n" +
+		"<<<<<<< SEARCH\ndebug()\n=======\n>>>>>>> REPLACE\n</PatchFile>\n<ReadFile>go.mod</ReadFile>"
+	r, err := m.parseAIResponse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Message != "Fixing it." {
+		t.Errorf("unexpected message: %q", r.Message)
+	}
+	want := []PatchHunk{{Search: "old()", Replace: "new()"}, {Search: "debug()", Replace: ""}}
+	if len(r.FilePatches) != 1 || r.FilePatches[0].Path != "main.go" || !reflect.DeepEqual(r.FilePatches[0].Hunks, want) {
+		t.Errorf("unexpected patches: %+v", r.FilePatches)
+	}
+	if !reflect.DeepEqual(r.FileReads, []string{"go.mod"}) {
+		t.Errorf("unexpected reads: %v", r.FileReads)
+	}
+}
+
+// Test: action tags in PatchFile SEARCH/REPLACE blocks are file content, not actions
+func TestParseAIResponse_TagsInsidePatchFile(t *testing.T) {
+	m := &Manager{}
+	input := "Updating the example.\n<PatchFile path=\"docs/protocol.md\">\n<<<<<<< SEARCH\n<ExecCommand>make</ExecCommand>\n=======\n" +
+		"<ExecCommand>rm -rf build</ExecCommand>\n<RequestAccomplished>1</RequestAccomplished>\n>>>>>>> REPLACE\n</PatchFile>"
+	r, err := m.parseAIResponse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.ExecCommand) != 0 || r.RequestAccomplished {
+		t.Errorf("expected no actions from the patch, got commands %v, accomplished %t", r.ExecCommand, r.RequestAccomplished)
+	}
+	want := []PatchHunk{{Search: "<ExecCommand>make</ExecCommand>", Replace: "<ExecCommand>rm -rf build</ExecCommand>\n<RequestAccomplished>1</RequestAccomplished>"}}
+	if len(r.FilePatches) != 1 || !reflect.DeepEqual(r.FilePatches[0].Hunks, want) {
+		t.Errorf("unexpected patches: %+v", r.FilePatches)
+	}
+	if r.Message != "Updating the example." {
+		t.Errorf("unexpected message: %q", r.Message)
+	}
+}
+
+// BenchmarkParseAIResponse parses a typical response with a message and one command
+func BenchmarkParseAIResponse(b *testing.B) {
+	m := &Manager{}
+	input := "The build fails because the `go` toolchain is too old.\n\nLet me check the version first.\n" +
+		"<ExecCommand>go version</ExecCommand>\n<RequestAccomplished>0</RequestAccomplished>"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.parseAIResponse(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
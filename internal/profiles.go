@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// switchProfile loads the config again with a profile applied and switches the session to
+// it, so another model or API key takes effect with the next request; values set with
+// /config set keep precedence.
+func (m *Manager) switchProfile(name string) error {
+	cfg, err := config.LoadProfile(name)
+	if err != nil {
+		return err
+	}
+	if err := m.applyConfig(cfg); err != nil {
+		return err
+	}
+	logger.Info("Switched to profile %s", cfg.Profile)
+	return nil
+}
+
+// handleProfileCommand lists the profiles of the config or switches to one
+func handleProfileCommand(m *Manager, args []string) {
+	if len(args) == 0 {
+		names := m.Config.ProfileNames()
+		if len(names) == 0 {
+			m.Println(i18n.T("No profiles, add them under profiles: in the config file"))
+			return
+		}
+		for _, name := range names {
+			marker := "  "
+			if name == m.Config.Profile {
+				marker = "* "
+			}
+			m.Println(marker + name)
+		}
+		return
+	}
+	if len(args) > 1 {
+		m.Println(i18n.T("Usage: /config profile [name]"))
+		return
+	}
+	if err := m.switchProfile(args[0]); err != nil {
+		m.Println(i18n.T("Failed to switch profile: %v", err))
+		return
+	}
+	m.Println(i18n.T("Switched to profile %s, model %s", strings.ToLower(args[0]), m.GetOpenRouterModel()))
+}
@@ -0,0 +1,57 @@
+// Unit tests for config profiles in profiles.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: switching applies the profile over the config file and unknown profiles change nothing
+func TestSwitchProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "tmuxai")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := `provider: mock
+exec_confirm: false
+openrouter:
+  model: base-model
+profiles:
+  Work:
+    exec_confirm: true
+    openrouter:
+      model: work-model
+      api_key: sk-work-secret
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := &Manager{Config: cfg}
+	if err := m.switchProfile("WORK"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Config.Profile != "work" || !m.Config.ExecConfirm || m.GetOpenRouterModel() != "work-model" || m.AiClient == nil {
+		t.Errorf("expected the work profile to apply, got profile %q exec_confirm %v model %q", m.Config.Profile, m.Config.ExecConfirm, m.GetOpenRouterModel())
+	}
+	if out := m.FormatConfig(); strings.Contains(out, "sk-work-secret") || !strings.Contains(out, "profiles: work") {
+		t.Errorf("expected only the profile names in the config listing, got\n%s", out)
+	}
+
+	if err := m.switchProfile("home"); err == nil || !strings.Contains(err.Error(), "work") {
+		t.Errorf("expected an unknown profile to list the known ones, got %v", err)
+	}
+	if m.Config.Profile != "work" {
+		t.Errorf("expected a failed switch to keep the profile, got %q", m.Config.Profile)
+	}
+}
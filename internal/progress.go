@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/briandowns/spinner"
+)
+
+// aiProgress is the spinner shown while waiting for the model, with the elapsed
+// time so long calls don't look like a hang
+type aiProgress struct {
+	manager *Manager
+	spinner *spinner.Spinner
+}
+
+// startProgress starts the spinner; Stop erases it so the response replaces it
+func (m *Manager) startProgress(charSet int, label string) *aiProgress {
+	started := time.Now()
+	m.setWaitingSince(started)
+
+	if system.ASCIIOnly() {
+		charSet = 9 // | / - \
+	}
+	s := spinner.New(spinner.CharSets[charSet], 100*time.Millisecond)
+	s.PreUpdate = func(s *spinner.Spinner) {
+		s.Suffix = fmt.Sprintf(" %s %s", label, formatElapsed(time.Since(started)))
+	}
+	if system.CursorControl() {
+		s.Start()
+	}
+	return &aiProgress{manager: m, spinner: s}
+}
+
+func (p *aiProgress) Stop() {
+	p.spinner.Stop()
+	p.manager.setWaitingSince(time.Time{})
+}
+
+// formatElapsed renders a duration as 7s or 2m05s
+func formatElapsed(d time.Duration) string {
+	seconds := int(d.Seconds())
+	if seconds < 60 {
+		return fmt.Sprintf("%ds", seconds)
+	}
+	return fmt.Sprintf("%dm%02ds", seconds/60, seconds%60)
+}
@@ -0,0 +1,21 @@
+// Unit tests for the progress spinner in progress.go
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+// Test: elapsed time switches to minutes after a minute
+func TestFormatElapsed(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                       "0s",
+		7500 * time.Millisecond: "7s",
+		125 * time.Second:       "2m05s",
+	}
+	for d, want := range tests {
+		if got := formatElapsed(d); got != want {
+			t.Errorf("formatElapsed(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
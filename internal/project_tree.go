@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// execPaneCwd returns the current working directory of the exec pane
+func (m *Manager) execPaneCwd() string {
+	if m.ExecPane == nil || m.ExecPane.Id == "" {
+		return ""
+	}
+	panes, err := system.TmuxPanesDetails(m.ExecPane.Id)
+	if err != nil || len(panes) == 0 {
+		return m.ExecPane.CurrentPath
+	}
+	m.ExecPane.CurrentPath = panes[0].CurrentPath
+	return m.ExecPane.CurrentPath
+}
+
+// projectTreeContext renders the exec pane's project tree wrapped for the model
+func (m *Manager) projectTreeContext(depth int) (string, error) {
+	cwd := m.execPaneCwd()
+	if cwd == "" {
+		return "", fmt.Errorf("could not determine exec pane working directory")
+	}
+	tree, err := system.ProjectTree(cwd, depth)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("<project_tree cwd=\"%s\">\n%s</project_tree>\n", cwd, tree), nil
+}
+
+// handleTreeCommand prints the project tree and adds it to the chat context
+func handleTreeCommand(m *Manager, args []string) {
+	depth := m.GetProjectTreeDepth()
+	if len(args) > 0 {
+		d, err := strconv.Atoi(args[0])
+		if err != nil || d <= 0 {
+			m.Println(i18n.T("Usage: /tree [depth]"))
+			return
+		}
+		depth = d
+	}
+
+	treeContext, err := m.projectTreeContext(depth)
+	if err != nil {
+		logger.Error("Failed to build project tree: %v", err)
+		m.Println(i18n.T("Failed to build project tree: %v", err))
+		return
+	}
+
+	fmt.Fprintln(m.out(), treeContext)
+	m.appendMessages(ChatMessage{
+		Content:   "Here is the project structure of the exec pane's working directory:\n" + treeContext,
+		FromUser:  true,
+		Timestamp: time.Now(),
+	})
+	m.Println(i18n.T("Project tree added to the context"))
+}
@@ -0,0 +1,43 @@
+package internal
+
+import "strings"
+
+// renderPrompt expands the {placeholders} of a prompt format with values, painting the
+// literal text with literal. A placeholder that renders empty swallows the space after it,
+// so "{name} {state} » " has no double space while idle. Unknown placeholders are kept as is.
+func renderPrompt(format string, literal func(a ...any) string, values map[string]func() string) string {
+	var b strings.Builder
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			b.WriteString(literal(text.String()))
+			text.Reset()
+		}
+	}
+
+	for format != "" {
+		open := strings.IndexByte(format, '{')
+		end := strings.IndexByte(format[max(open, 0):], '}')
+		if open < 0 || end < 0 {
+			text.WriteString(format)
+			break
+		}
+		end += open
+		text.WriteString(format[:open])
+		value, ok := values[format[open+1:end]]
+		if !ok {
+			text.WriteString(format[open : end+1])
+			format = format[end+1:]
+			continue
+		}
+		format = format[end+1:]
+		if rendered := value(); rendered != "" {
+			flush()
+			b.WriteString(rendered)
+		} else {
+			format = strings.TrimPrefix(format, " ")
+		}
+	}
+	flush()
+	return b.String()
+}
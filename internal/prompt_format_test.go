@@ -0,0 +1,27 @@
+// Unit tests for prompt_format rendering in prompt_format.go
+package internal
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Test: placeholders expand, empty ones drop their trailing space and unknown ones stay literal
+func TestRenderPrompt(t *testing.T) {
+	values := map[string]func() string{
+		"name":  func() string { return "CNP-AI" },
+		"state": func() string { return "" },
+		"model": func() string { return "gpt-4o" },
+	}
+	tests := map[string]string{
+		"{name} {state} » ":         "CNP-AI » ",
+		"{model} {state} {cost} $ ": "gpt-4o {cost} $ ",
+		"» ":                        "» ",
+		"{name":                     "{name",
+	}
+	for format, want := range tests {
+		if got := renderPrompt(format, fmt.Sprint, values); got != want {
+			t.Errorf("renderPrompt(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+)
+
+var promptVarRe = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// promptVariables returns the variables available to custom prompt templates.
+// Values are computed lazily since some of them (mcp_tools) query remote servers.
+func (m *Manager) promptVariables() map[string]func() string {
+	return map[string]func() string{
+		"os": func() string {
+			if m.ExecPane != nil && m.ExecPane.OS != "" {
+				return m.ExecPane.OS
+			}
+			return m.osDetails()
+		},
+		"shell": func() string {
+			if m.ExecPane == nil {
+				return ""
+			}
+			return m.ExecPane.Shell
+		},
+		"cwd": func() string {
+			return m.execPaneCwd()
+		},
+		"pane_command": func() string {
+			if m.ExecPane == nil {
+				return ""
+			}
+			return m.ExecPane.CurrentCommand
+		},
+		"mcp_tools": func() string {
+			if len(m.McpServers) == 0 {
+				return "(no MCP servers selected)"
+			}
+			return strings.TrimSpace(m.mcpToolsDescription())
+		},
+	}
+}
+
+// renderPromptTemplate replaces {{variable}} placeholders in a custom prompt with
+// live values; unknown variables are left untouched
+func (m *Manager) renderPromptTemplate(tmpl string) string {
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl
+	}
+	vars := m.promptVariables()
+	cache := map[string]string{}
+	return promptVarRe.ReplaceAllStringFunc(tmpl, func(match string) string {
+		name := promptVarRe.FindStringSubmatch(match)[1]
+		if val, ok := cache[name]; ok {
+			return val
+		}
+		fn, ok := vars[name]
+		if !ok {
+			return match
+		}
+		cache[name] = fn()
+		return cache[name]
+	})
+}
@@ -36,12 +36,23 @@ You are allowed to be proactive, but only when the user asks you to do something
 DO NOT WRITE MORE TEXT AFTER THE TOOL CALLS IN A RESPONSE. You can wait until the next response to summarize the actions you've done.
 `
 	if m.Config.Prompts.BaseSystem != "" {
-		basePrompt = m.Config.Prompts.BaseSystem
+		basePrompt = m.renderPromptTemplate(m.Config.Prompts.BaseSystem)
 	}
 	return basePrompt
 
 }
 
+// teachModePrompt is added for teach_mode, for users learning the tools the commands use
+const teachModePrompt = `
+
+==== Teach mode ====
+The user is learning the tools you use, so explain as you go:
+- Right before every ExecCommand, write one line saying what the command does and why you run it, naming each flag or option it uses.
+- When a command has run, start your next response with a brief interpretation of its output: what the important parts mean and what they tell you.
+Keep both short; this overrides the rule to minimize output only for these explanations.
+==== End of teach mode ====
+`
+
 func (m *Manager) chatAssistantPrompt(prepared bool) ChatMessage {
 	var builder strings.Builder
 	builder.WriteString(m.baseSystemPrompt())
@@ -52,6 +63,11 @@ You have access to the following XML tags to control the tmux pane:
 <TmuxSendKeys>: Use this to send keystrokes to the tmux pane. Supported keys include standard characters, function keys (F1-F12), navigation keys (Up,Down,Left,Right,BSpace,BTab,DC,End,Enter,Escape,Home,IC,NPage,PageDown,PgDn,PPage,PageUp,PgUp,Space,Tab), and modifier keys (C-, M-).
 <ExecCommand>: Use this to execute shell commands in the tmux pane.
 <PasteMultilineContent>: Use this to send multiline content into the tmux pane. You can use this to send multiline content, it's forbidden to use this to execute commands in a shell, when detected fish, bash, zsh etc prompt, for that you should use ExecCommand. Main use for this is when it's vim open and you need to type multiline text, etc.
+<WriteFile path="...">: Use this to create or change a file: give the path relative to the exec pane's directory and the complete new file content. The user reviews a diff and the file is written directly, so prefer it over editors, heredocs or PasteMultilineContent for file changes.
+<PatchFile path="...">: Use this to change part of a larger file. Give one or more blocks of "<<<<<<< SEARCH", the exact current lines, "=======", the new lines and ">>>>>>> REPLACE", each on its own line. The SEARCH text must occur exactly once in the file. The user reviews a diff like for WriteFile.
+<ReadFile>: Use this to read a file under the exec pane's directory, give its relative path. The content is added to the conversation, use it before patching a file you haven't seen.
+<FetchUrl>: Use this to read a web page, e.g. the documentation of an error, give its http(s) URL. The page is reduced to its text and added to the conversation.
+<DelegateTask>: Use this to hand an independent subtask, e.g. running the tests while you fix lint errors, to a sub-agent working in a pane of its own. Describe the subtask completely, the sub-agent doesn't see this conversation. Its result is added to the conversation when it finishes.
 <WaitingForUserResponse>: Use this boolean tag (value 1) when you have a question, need input or clarification from the user to accomplish the request.
 <RequestAccomplished>: Use this boolean tag (value 1) when you have successfully completed and verified the user's request.
 <McpToolCall>: Use this to call MCP tools. Format: {"server_name": "server_name", "tool_name": "tool_name", "arguments": {"key": "value"}}
@@ -60,30 +76,16 @@ You have access to the following XML tags to control the tmux pane:
 	// 添加当前可用的MCP服务器和工具信息
 	if len(m.McpServers) > 0 {
 		builder.WriteString("\nCurrently available MCP servers and their tools:\n")
-		for _, server := range m.McpServers {
-			tools, err := m.McpClient.ListTools(server.Name)
-			if err != nil {
-				builder.WriteString(fmt.Sprintf("- %s (%s): Error listing tools - %v\n", server.Name, server.Type, err))
-				continue
-			}
-
-			builder.WriteString(fmt.Sprintf("- %s (%s):\n", server.Name, server.Type))
-			for _, toolName := range tools {
-				toolInfo, err := m.McpClient.GetToolInfo(server.Name, toolName)
-				if err != nil {
-					builder.WriteString(fmt.Sprintf("  - %s: (description unavailable)\n", toolName))
-				} else {
-					description := "No description"
-					if desc, ok := toolInfo["description"].(string); ok && desc != "" {
-						description = desc
-					}
-					builder.WriteString(fmt.Sprintf("  - %s: %s\n", toolName, description))
-				}
-			}
-		}
+		builder.WriteString(m.mcpToolsDescription())
 		builder.WriteString("\nYou can use <McpToolCall> to invoke these tools when needed. Format: {\"server_name\": \"server_name\", \"tool_name\": \"tool_name\", \"arguments\": {\"key\": \"value\"}}\n")
 	}
 
+	if len(m.Plugins) > 0 {
+		builder.WriteString("\nCustom tools installed by the user:\n")
+		builder.WriteString(m.pluginToolsDescription())
+		builder.WriteString("\nYou can use <PluginToolCall> to run them. Format: {\"tool_name\": \"tool_name\", \"arguments\": {\"key\": \"value\"}}\n")
+	}
+
 	if !prepared {
 		builder.WriteString(`<ExecPaneSeemsBusy>: Use this boolean tag (value 1) when you need to wait for the exec pane to finish before proceeding.`)
 	}
@@ -148,6 +150,23 @@ I'll list the contents of the current directory.
 <ExecCommand>ls -l</ExecCommand>
 </executing_a_command>
 
+<writing_a_file>
+I'll add the missing newline at the end of the greeting.
+<WriteFile path="hello.txt">Hello, world!
+</WriteFile>
+</writing_a_file>
+
+<patching_a_file>
+I'll fix the greeting in the middle of the file.
+<PatchFile path="main.go">
+<<<<<<< SEARCH
+	fmt.Println("Helo")
+=======
+	fmt.Println("Hello")
+>>>>>>> REPLACE
+</PatchFile>
+</patching_a_file>
+
 <calling_mcp_tools>
 I'll search for information using the available MCP tool.
 <McpToolCall>{"server_name": "search_server", "tool_name": "web_search", "arguments": {"query": "golang best practices", "limit": 5}}</McpToolCall>
@@ -176,9 +195,18 @@ I'll wait for it to complete before proceeding.
 
 	builder.WriteString(`</examples_of_responses>`)
 
+	if m.GetTeachMode() {
+		builder.WriteString(teachModePrompt)
+	}
+	if _, ok := m.AiClient.(ToolCallingProvider); ok && m.Config.OpenRouter.ToolCalling {
+		builder.WriteString(toolCallingPrompt)
+	} else if m.Config.OpenRouter.JSONMode {
+		builder.WriteString(jsonModePrompt())
+	}
+
 	// Custom additional prompt
 	if m.Config.Prompts.ChatAssistant != "" {
-		builder.WriteString(m.Config.Prompts.ChatAssistant)
+		builder.WriteString(m.renderPromptTemplate(m.Config.Prompts.ChatAssistant))
 	}
 
 	return ChatMessage{
@@ -204,7 +232,7 @@ If no response is needed, output:
 `, m.baseSystemPrompt())
 
 	if m.Config.Prompts.Watch != "" {
-		chatPrompt = chatPrompt + "\n\n" + m.Config.Prompts.Watch
+		chatPrompt = chatPrompt + "\n\n" + m.renderPromptTemplate(m.Config.Prompts.Watch)
 	}
 
 	return ChatMessage{
@@ -213,3 +241,33 @@ If no response is needed, output:
 		FromUser:  false,
 	}
 }
+
+// mcpToolsDescription lists the tools of the selected MCP servers, one server per block
+func (m *Manager) mcpToolsDescription() string {
+	var builder strings.Builder
+	for _, server := range m.McpServers {
+		tools, err := m.McpClient.ListTools(server.Name)
+		if err != nil {
+			builder.WriteString(fmt.Sprintf("- %s (%s): Error listing tools - %v\n", server.Name, server.Type, err))
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("- %s (%s):\n", server.Name, server.Type))
+		for _, toolName := range tools {
+			if m.toolPolicy(server.Name, toolName) == toolPolicyDeny {
+				continue
+			}
+			toolInfo, err := m.McpClient.GetToolInfo(server.Name, toolName)
+			if err != nil {
+				builder.WriteString(fmt.Sprintf("  - %s: (description unavailable)\n", toolName))
+			} else {
+				description := "No description"
+				if desc, ok := toolInfo["description"].(string); ok && desc != "" {
+					description = desc
+				}
+				builder.WriteString(fmt.Sprintf("  - %s: %s\n", toolName, description))
+			}
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+type queueStatus int
+
+const (
+	queuePending queueStatus = iota
+	queueRunning
+	queueDone
+	queueIncomplete // the agent stopped without marking the request accomplished
+	queueSkipped
+)
+
+// queuedTask is a request waiting in /queue
+type queuedTask struct {
+	request string
+	status  queueStatus
+	elapsed time.Duration
+	outcome string // first line of the agent's last message
+}
+
+// taskQueue holds the requests added with /queue add, worked through by /queue run.
+// Only /queue touches it, and commands are serialized by turnMu.
+type taskQueue struct {
+	tasks []*queuedTask
+}
+
+const queueUsage = "Usage: /queue [add <request>|run|skip [n]|remove <n>|clear]"
+
+// handleQueueCommand manages the task queue
+func handleQueueCommand(ctx context.Context, m *Manager, command string) {
+	fields := strings.Fields(command)
+	if len(fields) < 2 || strings.EqualFold(fields[1], "list") {
+		m.printQueue()
+		return
+	}
+	q := &m.queue
+	switch strings.ToLower(fields[1]) {
+	case "add":
+		_, rest, _ := strings.Cut(strings.TrimSpace(command), " ")
+		_, request, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		request = strings.TrimSpace(request)
+		if request == "" {
+			m.Println(i18n.T(queueUsage))
+			return
+		}
+		q.tasks = append(q.tasks, &queuedTask{request: request})
+		m.Println(i18n.T("Queued as task %d, /queue run works through the queue", len(q.tasks)))
+	case "run":
+		m.runQueue(ctx)
+	case "skip":
+		n := q.next()
+		if len(fields) > 2 {
+			n = q.index(fields[2])
+		}
+		if n < 0 || q.tasks[n].status != queuePending {
+			m.Println(i18n.T("No pending task to skip"))
+			return
+		}
+		q.tasks[n].status = queueSkipped
+		m.Println(i18n.T("Skipped task %d", n+1))
+	case "remove":
+		n := -1
+		if len(fields) > 2 {
+			n = q.index(fields[2])
+		}
+		if n < 0 {
+			m.Println(i18n.T(queueUsage))
+			return
+		}
+		q.tasks = append(q.tasks[:n], q.tasks[n+1:]...)
+		m.Println(i18n.T("Removed task %d", n+1))
+	case "clear":
+		q.tasks = nil
+		m.Println(i18n.T("Queue cleared"))
+	default:
+		m.Println(i18n.T(queueUsage))
+	}
+}
+
+// next returns the index of the first pending task, -1 when there is none
+func (q *taskQueue) next() int {
+	for i, t := range q.tasks {
+		if t.status == queuePending {
+			return i
+		}
+	}
+	return -1
+}
+
+// index parses a 1-based task number, -1 when it is not one
+func (q *taskQueue) index(arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(q.tasks) {
+		return -1
+	}
+	return n - 1
+}
+
+// runQueue works through the pending tasks in order. Ctrl+C pauses the queue: the
+// interrupted task stays pending and /queue run picks it up again.
+func (m *Manager) runQueue(ctx context.Context) {
+	q := &m.queue
+	if q.next() < 0 {
+		m.Println(i18n.T("No pending tasks, add one with /queue add <request>"))
+		return
+	}
+	started := time.Now()
+	ran := 0
+	for n := q.next(); n >= 0; n = q.next() {
+		t := q.tasks[n]
+		t.status = queueRunning
+		m.Println(system.CurrentTheme().Header.Sprint(i18n.T("Task %d/%d: %s", n+1, len(q.tasks), t.request)))
+		m.lastAIMessage = ""
+		taskStarted := time.Now()
+		accomplished := m.runRequest(ctx, t.request)
+		if ctx.Err() != nil {
+			t.status = queuePending
+			m.Println(i18n.T("Queue paused at task %d, /queue run continues, /queue skip skips it", n+1))
+			return
+		}
+		t.elapsed = time.Since(taskStarted)
+		t.outcome, _, _ = strings.Cut(strings.TrimSpace(m.lastAIMessage), "\n")
+		t.status = queueIncomplete
+		if accomplished {
+			t.status = queueDone
+		}
+		ran++
+	}
+
+	m.Println(i18n.T("Queue finished: %d tasks in %s", ran, time.Since(started).Round(time.Second)))
+	m.printQueue()
+	m.notify(NotifyTask, i18n.T("TmuxAI queue finished"), q.summary())
+}
+
+// summary counts the tasks by status
+func (q *taskQueue) summary() string {
+	var done, incomplete, skipped, pending int
+	for _, t := range q.tasks {
+		switch t.status {
+		case queueDone:
+			done++
+		case queueIncomplete:
+			incomplete++
+		case queueSkipped:
+			skipped++
+		default:
+			pending++
+		}
+	}
+	return i18n.T("%d done, %d incomplete, %d skipped, %d pending", done, incomplete, skipped, pending)
+}
+
+// printQueue lists the tasks with their status and, once run, how they ended
+func (m *Manager) printQueue() {
+	q := &m.queue
+	if len(q.tasks) == 0 {
+		m.Println(i18n.T("The queue is empty, add requests with /queue add <request>"))
+		return
+	}
+	theme := system.CurrentTheme()
+	var b strings.Builder
+	for i, t := range q.tasks {
+		var icon string
+		switch t.status {
+		case queuePending:
+			icon = theme.Neutral.Sprint(system.Sym("○"))
+		case queueRunning:
+			icon = theme.Highlight.Sprint(system.Sym("▶"))
+		case queueDone:
+			icon = theme.Success.Sprint(system.Sym("✓"))
+		case queueIncomplete:
+			icon = theme.Error.Sprint(system.Sym("✗"))
+		case queueSkipped:
+			icon = theme.Neutral.Sprint(system.Sym("·"))
+		}
+		fmt.Fprintf(&b, "  %s %d. %s", icon, i+1, t.request)
+		if t.status == queueDone || t.status == queueIncomplete {
+			fmt.Fprintf(&b, " %s", theme.Muted.Sprint("("+t.elapsed.Round(time.Second).String()+")"))
+			if t.outcome != "" {
+				fmt.Fprintf(&b, "\n       %s", theme.Muted.Sprint(t.outcome))
+			}
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprint(m.out(), b.String())
+	m.Println(q.summary())
+}
@@ -0,0 +1,40 @@
+// Unit tests for the task queue in queue.go
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: tasks keep their text, skip takes the next pending one and remove renumbers
+func TestQueueCommands(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig()}
+	ctx := context.Background()
+	handleQueueCommand(ctx, m, "/queue add run the tests  and fix failures")
+	handleQueueCommand(ctx, m, "/queue add bump the version")
+	handleQueueCommand(ctx, m, "/queue add update the changelog")
+	if len(m.queue.tasks) != 3 || m.queue.tasks[0].request != "run the tests  and fix failures" {
+		t.Fatalf("unexpected tasks: %+v", m.queue.tasks)
+	}
+
+	handleQueueCommand(ctx, m, "/queue skip")
+	handleQueueCommand(ctx, m, "/queue skip 3")
+	if m.queue.tasks[0].status != queueSkipped || m.queue.tasks[2].status != queueSkipped || m.queue.next() != 1 {
+		t.Errorf("unexpected statuses after skipping")
+	}
+
+	handleQueueCommand(ctx, m, "/queue remove 1")
+	if len(m.queue.tasks) != 2 || m.queue.tasks[0].request != "bump the version" {
+		t.Errorf("unexpected tasks after remove: %+v", m.queue.tasks)
+	}
+	if got := m.queue.summary(); got != "0 done, 0 incomplete, 1 skipped, 1 pending" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	handleQueueCommand(ctx, m, "/queue clear")
+	if len(m.queue.tasks) != 0 {
+		t.Error("queue not cleared")
+	}
+}
@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"golang.org/x/term"
+)
+
+// recoveryFileName is the snapshot a crashed or saved session leaves in the config dir
+const recoveryFileName = "recovery.json"
+
+// sessionSnapshot is what a crash (or save_on_exit) keeps so the next start can pick the
+// session up again; /save writes the same under a name
+type sessionSnapshot struct {
+	SavedAt     time.Time              `json:"saved_at"`
+	Panic       string                 `json:"panic,omitempty"` // empty when saved on exit
+	Messages    []ChatMessage          `json:"messages"`
+	ExecHistory []CommandExecHistory   `json:"exec_history"`
+	Overrides   map[string]interface{} `json:"overrides,omitempty"`
+	McpServers  []string               `json:"mcp_servers"` // names of the selected servers, null in older snapshots
+	Tasks       []*requestTask         `json:"tasks,omitempty"`
+}
+
+// recoveryPath returns where the recovery snapshot is kept; tests override it
+var recoveryPath = func() string {
+	return config.GetConfigFilePath(recoveryFileName)
+}
+
+// recoverPanic is deferred at the top of every goroutine that runs a turn. It saves the
+// session to the recovery file and panics again, so the crash itself is not hidden.
+func (m *Manager) recoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	m.recoveryOnce.Do(func() {
+		logger.Error("Panic: %v\n%s", r, debug.Stack())
+		path := recoveryPath()
+		if err := m.saveRecoverySnapshot(path, fmt.Sprint(r)); err != nil {
+			logger.Error("Failed to save recovery snapshot: %v", err)
+			return
+		}
+		fmt.Fprintln(os.Stderr, i18n.T("TmuxAI crashed, the session was saved to %s and can be restored on the next start", path))
+	})
+	panic(r)
+}
+
+// saveRecoverySnapshot writes the conversation, exec history and session overrides to path
+func (m *Manager) saveRecoverySnapshot(path, reason string) error {
+	s := m.snapshot()
+	if len(s.Messages) == 0 {
+		return nil
+	}
+	s.Panic = reason
+	return writeSnapshot(path, s)
+}
+
+// snapshot captures the session as it is now
+func (m *Manager) snapshot() sessionSnapshot {
+	servers := []string{}
+	for _, server := range m.selectedMcpServers() {
+		servers = append(servers, server.Name)
+	}
+	return sessionSnapshot{
+		SavedAt:     time.Now(),
+		Messages:    m.MessageHistory(),
+		ExecHistory: m.ExecHistory,
+		Overrides:   m.sessionOverridesSnapshot(),
+		McpServers:  servers,
+		Tasks:       m.tasks,
+	}
+}
+
+// writeSnapshot writes s to path through a temporary file, so a reader never sees half of it
+func writeSnapshot(path string, s sessionSnapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadRecoverySnapshot reads a snapshot, nil when there is none
+func loadRecoverySnapshot(path string) (*sessionSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s sessionSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// restoreSnapshot puts a saved session back; overrides go through setConfigValue so
+// their side effects (theme, language, ...) are applied again
+func (m *Manager) restoreSnapshot(s *sessionSnapshot) {
+	m.setMessages(s.Messages)
+	m.ExecHistory = s.ExecHistory
+	m.tasks = s.Tasks
+	for _, t := range m.tasks {
+		// a crash ended them
+		if t.Status == taskRunning {
+			t.finish(true, false)
+		}
+	}
+	for key, value := range s.Overrides {
+		if err := setConfigValue(m, key, fmt.Sprint(value)); err != nil {
+			logger.Error("Failed to restore override %s: %v", key, err)
+		}
+	}
+	if s.McpServers != nil {
+		var servers []config.McpServer
+		for _, name := range s.McpServers {
+			found := false
+			for _, server := range m.Config.Mcp.Servers {
+				if server.Name == name {
+					servers = append(servers, server)
+					found = true
+					break
+				}
+			}
+			if !found {
+				logger.Error("MCP server %s of the saved session is no longer configured", name)
+			}
+		}
+		oldClient := m.McpClient
+		m.setMcpServers(servers, m.newMcpClient(servers))
+		if oldClient != nil {
+			oldClient.Close()
+		}
+	}
+}
+
+// offerRecovery asks whether to restore the session a crash or the last exit left
+// behind. The snapshot is removed either way, so it is offered only once.
+func (m *Manager) offerRecovery() {
+	if JSONEventsEnabled() || m.ConfirmFunc != nil || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	path := recoveryPath()
+	s, err := loadRecoverySnapshot(path)
+	if err != nil {
+		logger.Error("Failed to read recovery snapshot: %v", err)
+	}
+	if s == nil {
+		return
+	}
+	defer os.Remove(path)
+
+	answer, err := m.readLine(i18n.T("Restore the previous session from %s (%d messages)? [Y/n] ",
+		s.SavedAt.Format("2006-01-02 15:04"), len(s.Messages)), "")
+	if err != nil {
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "", "y", "yes":
+		m.restoreSnapshot(s)
+		logger.Info("Restored session from %s", path)
+		m.Println(i18n.T("Session restored"))
+	}
+}
@@ -0,0 +1,74 @@
+// Unit tests for the crash snapshot in recovery.go
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: a saved snapshot restores messages, exec history and overrides
+func TestRecoverySnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.json")
+	m := &Manager{
+		Config:           config.DefaultConfig(),
+		Messages:         []ChatMessage{{Content: "hello", FromUser: true, Timestamp: time.Now()}},
+		ExecHistory:      []CommandExecHistory{{Command: "ls", Output: "a b", Code: 0}},
+		SessionOverrides: map[string]interface{}{"max_capture_lines": 42, "exec_confirm": false},
+	}
+	if err := m.saveRecoverySnapshot(path, "boom"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := loadRecoverySnapshot(path)
+	if err != nil || s == nil {
+		t.Fatalf("snapshot not loaded: %v", err)
+	}
+	if s.Panic != "boom" {
+		t.Errorf("unexpected panic: %q", s.Panic)
+	}
+	restored := &Manager{Config: config.DefaultConfig()}
+	restored.restoreSnapshot(s)
+	if len(restored.Messages) != 1 || restored.Messages[0].Content != "hello" || !restored.Messages[0].FromUser {
+		t.Errorf("unexpected messages: %+v", restored.Messages)
+	}
+	if len(restored.ExecHistory) != 1 || restored.ExecHistory[0].Command != "ls" {
+		t.Errorf("unexpected exec history: %+v", restored.ExecHistory)
+	}
+	if restored.GetMaxCaptureLines() != 42 || restored.GetExecConfirm() {
+		t.Errorf("overrides not restored: %+v", restored.SessionOverrides)
+	}
+}
+
+// Test: a panic saves the snapshot and still propagates
+func TestRecoverPanicSavesSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.json")
+	saved := recoveryPath
+	recoveryPath = func() string { return path }
+	defer func() { recoveryPath = saved }()
+
+	m := &Manager{Config: config.DefaultConfig(), Messages: []ChatMessage{{Content: "hi"}}}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was swallowed")
+			}
+		}()
+		defer m.recoverPanic()
+		panic("boom")
+	}()
+
+	if s, _ := loadRecoverySnapshot(path); s == nil || len(s.Messages) != 1 {
+		t.Errorf("snapshot not saved: %+v", s)
+	}
+}
+
+// Test: a missing recovery file is not an error
+func TestLoadRecoverySnapshotMissing(t *testing.T) {
+	s, err := loadRecoverySnapshot(filepath.Join(t.TempDir(), "none.json"))
+	if s != nil || err != nil {
+		t.Errorf("expected nothing, got %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Section markers written by debugChatMessages
+const (
+	dumpSentMarker     = "==================    SENT CHAT MESSAGES =================="
+	dumpReceivedMarker = "==================    RECEIVED RESPONSE =================="
+	dumpEndMarker      = "==================    END DEBUG =================="
+)
+
+var dumpMessageRe = regexp.MustCompile(`(?m)^Message (\d+): Role=(\w+), Time=(\S*)\nContent:\n`)
+
+// DebugDump is a conversation read back from a debug file in the config dir
+type DebugDump struct {
+	Messages []ChatMessage
+	Response string
+}
+
+// ReplayResult is a response run through the parser and the guideline checks
+type ReplayResult struct {
+	Source   string     `json:"source"` // "recorded" or "model"
+	Response string     `json:"-"`
+	Parsed   AIResponse `json:"parsed"`
+	Problem  string     `json:"problem,omitempty"` // the guideline the response broke
+}
+
+// ParseDebugDump reconstructs the messages and the response of a debug file
+func ParseDebugDump(data string) (*DebugDump, error) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	_, rest, ok := strings.Cut(data, dumpSentMarker+"\n\n")
+	if !ok {
+		return nil, fmt.Errorf("not a debug dump: no sent messages section")
+	}
+	sent, received, ok := strings.Cut(rest, dumpReceivedMarker+"\n\n")
+	if !ok {
+		return nil, fmt.Errorf("not a debug dump: no received response section")
+	}
+	response, _, _ := strings.Cut(received, "\n\n"+dumpEndMarker)
+
+	// a header only counts when its number is the next one, so content quoting a
+	// header stays content
+	var headers [][]int
+	for _, loc := range dumpMessageRe.FindAllStringSubmatchIndex(sent, -1) {
+		if n, _ := strconv.Atoi(sent[loc[2]:loc[3]]); n == len(headers)+1 {
+			headers = append(headers, loc)
+		}
+	}
+	dump := &DebugDump{Response: response}
+	for i, loc := range headers {
+		end := len(sent)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		timestamp, _ := time.Parse(time.RFC3339, sent[loc[6]:loc[7]])
+		dump.Messages = append(dump.Messages, ChatMessage{
+			Content:   strings.TrimSuffix(sent[loc[1]:end], "\n\n"),
+			FromUser:  sent[loc[4]:loc[5]] == "user",
+			Timestamp: timestamp,
+		})
+	}
+	if len(dump.Messages) == 0 {
+		return nil, fmt.Errorf("not a debug dump: no messages")
+	}
+	return dump, nil
+}
+
+// ReplayDump parses the recorded response of dump and, when provider is set, sends the
+// messages again and parses the new response too. watch checks the responses as
+// answers in watch mode, where no tag is needed.
+func ReplayDump(ctx context.Context, cfg *config.Config, dump *DebugDump, provider ChatProvider, watch bool) ([]ReplayResult, error) {
+	m := &Manager{Config: cfg, watchMode: watch}
+	results := []ReplayResult{m.replayResponse("recorded", dump.Response)}
+	if provider == nil {
+		return results, nil
+	}
+	response, err := provider.GetResponseFromChatMessages(ctx, dump.Messages, m.GetOpenRouterModel())
+	if err != nil {
+		return results, err
+	}
+	return append(results, m.replayResponse("model", response)), nil
+}
+
+func (m *Manager) replayResponse(source, response string) ReplayResult {
+	parsed, _ := m.parseAIResponse(response)
+	problem, _ := m.aiFollowedGuidelines(parsed)
+	return ReplayResult{Source: source, Response: response, Parsed: parsed, Problem: problem}
+}
+
+// PrintReplay shows the messages of dump and what each response parsed into
+func PrintReplay(w io.Writer, dump *DebugDump, results []ReplayResult) {
+	theme := system.CurrentTheme()
+	users := 0
+	for _, msg := range dump.Messages {
+		if msg.FromUser {
+			users++
+		}
+	}
+	fmt.Fprintf(w, "%s %d messages, %d from the user\n", theme.Label.Sprint("dump:"), len(dump.Messages), users)
+	for _, r := range results {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, theme.Header.Sprint(r.Source+" response"))
+		fmt.Fprintln(w, theme.Muted.Sprint(r.Response))
+		fmt.Fprintln(w)
+		for _, line := range replayActions(r.Parsed) {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+		if r.Problem != "" {
+			fmt.Fprintf(w, "%s %s\n", theme.Error.Sprint(system.Sym("✗")), r.Problem)
+		} else {
+			fmt.Fprintf(w, "%s %s\n", theme.Success.Sprint(system.Sym("✓")), "follows the guidelines")
+		}
+	}
+}
+
+// replayActions lists what the agent would do for a parsed response
+func replayActions(r AIResponse) []string {
+	var lines []string
+	if r.Message != "" {
+		lines = append(lines, "message: "+strconv.Quote(r.Message))
+	}
+	for _, c := range r.ExecCommand {
+		lines = append(lines, "exec: "+c)
+	}
+	for _, k := range r.SendKeys {
+		lines = append(lines, "send keys: "+k)
+	}
+	if r.PasteMultilineContent != "" {
+		lines = append(lines, fmt.Sprintf("paste: %d lines", strings.Count(r.PasteMultilineContent, "\n")+1))
+	}
+	for _, e := range r.FileEdits {
+		lines = append(lines, "write file: "+e.Path)
+	}
+	for _, p := range r.FilePatches {
+		lines = append(lines, fmt.Sprintf("patch file: %s (%d blocks)", p.Path, len(p.Hunks)))
+	}
+	for _, name := range r.FileReads {
+		lines = append(lines, "read file: "+name)
+	}
+	for _, u := range r.FetchURLs {
+		lines = append(lines, "fetch: "+u)
+	}
+	for _, c := range r.PluginToolCalls {
+		lines = append(lines, "tool: "+c.ToolName)
+	}
+	for _, task := range r.Delegations {
+		lines = append(lines, "delegate: "+task)
+	}
+	for _, c := range r.McpToolCalls {
+		lines = append(lines, "tool call: "+c.ServerName+"/"+c.ToolName)
+	}
+	if r.RequestAccomplished {
+		lines = append(lines, "request accomplished")
+	}
+	if r.ExecPaneSeemsBusy {
+		lines = append(lines, "exec pane seems busy")
+	}
+	if r.WaitingForUserResponse {
+		lines = append(lines, "waiting for user response")
+	}
+	if r.NoComment {
+		lines = append(lines, "no comment")
+	}
+	return lines
+}
+
+// ReplayGolden is the expected result of a dump as kept next to it in testdata, the
+// recorded result as indented JSON
+func ReplayGolden(results []ReplayResult) ([]byte, error) {
+	data, err := json.MarshalIndent(results[0], "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,73 @@
+// Unit tests for reading and replaying debug dumps in replay.go
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: messages keep their roles and content, a quoted header stays in the content
+func TestParseDebugDump(t *testing.T) {
+	data := "==================    SENT CHAT MESSAGES ==================\n\n" +
+		"Message 1: Role=system, Time=2025-06-01T10:00:00Z\nContent:\nsystem prompt\n\n" +
+		"Message 2: Role=user, Time=2025-06-01T10:00:05Z\nContent:\nwhy does this say\n\n" +
+		"Message 7: Role=user, Time=x\nContent:\nin the log?\n\n" +
+		"==================    RECEIVED RESPONSE ==================\n\n" +
+		"<RequestAccomplished>1</RequestAccomplished>\n\n" +
+		"==================    END DEBUG ==================\n"
+
+	dump, err := ParseDebugDump(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dump.Messages) != 2 || dump.Messages[0].FromUser || !dump.Messages[1].FromUser {
+		t.Fatalf("unexpected messages: %+v", dump.Messages)
+	}
+	if dump.Messages[0].Content != "system prompt" || !strings.HasSuffix(dump.Messages[1].Content, "Content:\nin the log?") {
+		t.Errorf("unexpected content: %q, %q", dump.Messages[0].Content, dump.Messages[1].Content)
+	}
+	if dump.Response != "<RequestAccomplished>1</RequestAccomplished>" {
+		t.Errorf("unexpected response: %q", dump.Response)
+	}
+	if _, err := ParseDebugDump("not a dump"); err == nil {
+		t.Error("expected an error for a file that is not a dump")
+	}
+}
+
+// Test: every dump in testdata/replay parses to the result in its .json file; add new
+// ones with tmuxai replay --golden
+func TestReplayDumps(t *testing.T) {
+	dumps, _ := filepath.Glob(filepath.Join("testdata", "replay", "*.txt"))
+	if len(dumps) == 0 {
+		t.Fatal("no dumps in testdata/replay")
+	}
+	for _, path := range dumps {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(strings.TrimSuffix(path, ".txt") + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dump, err := ParseDebugDump(string(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := ReplayDump(context.Background(), config.DefaultConfig(), dump, nil, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := ReplayGolden(results)
+			if string(got) != string(want) {
+				t.Errorf("got\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
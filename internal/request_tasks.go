@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Statuses of a task
+const (
+	taskRunning     = "running"
+	taskDone        = "done"
+	taskIncomplete  = "incomplete"  // the AI stopped without marking the request accomplished
+	taskInterrupted = "interrupted" // Ctrl+C or a crash; /tasks resume continues it
+)
+
+// maxTasks is how many of the latest tasks are kept
+const maxTasks = 20
+
+const tasksUsage = "Usage: /tasks [resume [n]|targets]"
+
+const resumePrompt = `Resume this task, it was interrupted before it was finished:
+%s
+
+Steps taken so far:
+%s
+Check the panes for the current state first. Don't repeat the steps that are done, continue from where it stopped.`
+
+// requestTask is a request with the commands and keys its turns ran as steps, so it can
+// be reviewed with /tasks and resumed after an interruption
+type requestTask struct {
+	ID      int           `json:"id"`
+	Request string        `json:"request"`
+	Status  string        `json:"status"`
+	Started time.Time     `json:"started"`
+	Steps   stepChecklist `json:"steps"`
+}
+
+// newTask starts tracking a request, dropping the oldest tasks beyond maxTasks
+func (m *Manager) newTask(request string) *requestTask {
+	id := 1
+	if n := len(m.tasks); n > 0 {
+		id = m.tasks[n-1].ID + 1
+	}
+	t := &requestTask{ID: id, Request: request, Status: taskRunning, Started: time.Now()}
+	m.tasks = append(m.tasks, t)
+	if len(m.tasks) > maxTasks {
+		m.tasks = m.tasks[len(m.tasks)-maxTasks:]
+	}
+	return t
+}
+
+// finish sets the status of a task whose turn ended
+func (t *requestTask) finish(interrupted, accomplished bool) {
+	switch {
+	case interrupted:
+		t.Status = taskInterrupted
+		t.Steps.interrupt()
+	case accomplished:
+		t.Status = taskDone
+	default:
+		t.Status = taskIncomplete
+	}
+}
+
+// task returns the task with an ID, or the last interrupted or incomplete one for 0
+func (m *Manager) task(id int) *requestTask {
+	for i := len(m.tasks) - 1; i >= 0; i-- {
+		t := m.tasks[i]
+		if t.ID == id || (id == 0 && (t.Status == taskInterrupted || t.Status == taskIncomplete)) {
+			return t
+		}
+	}
+	return nil
+}
+
+// resumeMessage builds the request continuing a task, listing what its steps did
+func resumeMessage(t *requestTask) string {
+	var steps strings.Builder
+	for i, step := range t.Steps.Steps {
+		status := "sent, result unknown"
+		switch step.Status {
+		case stepDone:
+			status = "done"
+		case stepFailed:
+			status = fmt.Sprintf("failed with exit code %d", step.Code)
+		}
+		what := "ran"
+		if step.Kind == "keys" {
+			what = "sent keys"
+		}
+		fmt.Fprintf(&steps, "%d. %s %s (%s)\n", i+1, what, step.Command, status)
+	}
+	if steps.Len() == 0 {
+		steps.WriteString("(none)\n")
+	}
+	return fmt.Sprintf(resumePrompt, t.Request, steps.String())
+}
+
+// resumeTask runs the request continuing a task; the steps it takes are added to the task
+func (m *Manager) resumeTask(ctx context.Context, t *requestTask) {
+	m.Println(i18n.T("Resuming task %d: %s", t.ID, t.Request))
+	m.runTask(ctx, t, resumeMessage(t))
+}
+
+// handleTasksCommand lists the tasks of the session with their steps, resumes one, or
+// lists the project's task runner targets
+func handleTasksCommand(ctx context.Context, m *Manager, args []string) {
+	if len(args) == 0 {
+		m.printTasks()
+		return
+	}
+	switch strings.ToLower(args[0]) {
+	case "targets":
+		handleTaskTargetsCommand(m)
+	case "resume":
+		id := 0
+		if len(args) > 1 {
+			v, err := strconv.Atoi(args[1])
+			if err != nil || v < 1 {
+				m.Println(i18n.T(tasksUsage))
+				return
+			}
+			id = v
+		}
+		t := m.task(id)
+		if t == nil {
+			m.Println(i18n.T("No task to resume"))
+			return
+		}
+		m.resumeTask(ctx, t)
+	default:
+		m.Println(i18n.T(tasksUsage))
+	}
+}
+
+// printTasks lists the tasks with their status and steps
+func (m *Manager) printTasks() {
+	if len(m.tasks) == 0 {
+		m.Println(i18n.T("No tasks yet. /tasks targets lists the project's Makefile, justfile and package.json targets"))
+		return
+	}
+	theme := system.CurrentTheme()
+	var b strings.Builder
+	for _, t := range m.tasks {
+		var icon string
+		switch t.Status {
+		case taskRunning:
+			icon = theme.Highlight.Sprint(system.Sym("▶"))
+		case taskDone:
+			icon = theme.Success.Sprint(system.Sym("✓"))
+		case taskIncomplete:
+			icon = theme.Error.Sprint(system.Sym("✗"))
+		case taskInterrupted:
+			icon = theme.Neutral.Sprint(system.Sym("⏸"))
+		}
+		request, _, _ := strings.Cut(t.Request, "\n")
+		fmt.Fprintf(&b, "%s %d. %s %s\n", icon, t.ID, request, theme.Muted.Sprint("("+t.Status+")"))
+		for _, line := range strings.Split(strings.TrimSuffix(t.Steps.render(), "\n"), "\n") {
+			if line != "" {
+				b.WriteString("  " + line + "\n")
+			}
+		}
+	}
+	fmt.Fprint(m.out(), b.String())
+	if m.task(0) != nil {
+		m.Println(i18n.T("/tasks resume [n] continues an interrupted or incomplete task"))
+	}
+}
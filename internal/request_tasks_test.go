@@ -0,0 +1,85 @@
+// Unit tests for task tracking in request_tasks.go
+package internal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: a task keeps its steps, an interruption leaves it resumable and the resume request lists them
+func TestTaskResume(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig()}
+	done := m.newTask("check the disk")
+	done.finish(false, true)
+
+	task := m.newTask("deploy the app")
+	m.steps = &task.Steps
+	ok, failed := 0, 1
+	m.startStep("make build")
+	m.finishStep(&ok)
+	m.startStep("make test")
+	m.finishStep(&failed)
+	m.keysStep("q")
+	m.startStep("make deploy")
+	m.steps = nil
+	task.finish(true, false)
+
+	if task.Status != taskInterrupted || task.Steps.Steps[3].Status != stepSent {
+		t.Fatalf("unexpected task after an interruption: %+v", task)
+	}
+	if got := m.task(0); got != task {
+		t.Errorf("expected the interrupted task to be resumed, got %+v", got)
+	}
+	if got := m.task(1); got != done {
+		t.Errorf("expected task 1 by its ID, got %+v", got)
+	}
+
+	msg := resumeMessage(task)
+	for _, want := range []string{
+		"deploy the app",
+		"1. ran make build (done)",
+		"2. ran make test (failed with exit code 1)",
+		"3. sent keys q (sent, result unknown)",
+		"4. ran make deploy (sent, result unknown)",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in\n%s", want, msg)
+		}
+	}
+}
+
+// Test: tasks survive a snapshot, the ones a crash cut short become interrupted
+func TestTaskSnapshot(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig(), SessionOverrides: map[string]interface{}{}}
+	task := m.newTask("migrate the database")
+	task.Steps.Steps = []planStep{{Command: "make migrate", Status: stepRunning}}
+
+	data, err := json.Marshal(m.snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s sessionSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	restored := &Manager{Config: config.DefaultConfig(), SessionOverrides: map[string]interface{}{}}
+	restored.restoreSnapshot(&s)
+
+	if len(restored.tasks) != 1 || restored.tasks[0].Status != taskInterrupted || restored.tasks[0].Steps.Steps[0].Command != "make migrate" {
+		t.Errorf("unexpected restored tasks: %+v", restored.tasks)
+	}
+}
+
+// Test: only the latest maxTasks tasks are kept, IDs keep counting
+func TestNewTaskLimit(t *testing.T) {
+	m := &Manager{}
+	for i := 0; i < maxTasks+3; i++ {
+		m.newTask("request")
+	}
+	if len(m.tasks) != maxTasks || m.tasks[0].ID != 4 || m.tasks[maxTasks-1].ID != maxTasks+3 {
+		t.Errorf("unexpected tasks: %d, first %d", len(m.tasks), m.tasks[0].ID)
+	}
+}
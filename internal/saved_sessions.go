@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// savedSessionsDir returns where /save keeps named sessions; tests override it
+var savedSessionsDir = func() string {
+	return config.GetConfigFilePath("saved_sessions")
+}
+
+var sessionNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// savedSessionPath returns the file of a named session, rejecting names that would
+// leave the directory
+func savedSessionPath(name string) (string, error) {
+	if !sessionNameRe.MatchString(name) {
+		return "", fmt.Errorf("%s", i18n.T("Invalid session name %q, use letters, digits, dots, dashes and underscores", name))
+	}
+	return filepath.Join(savedSessionsDir(), name+".json"), nil
+}
+
+// handleSaveCommand saves the conversation, exec history, session overrides and
+// selected MCP servers under a name, replacing a session saved under it before
+func handleSaveCommand(m *Manager, args []string) {
+	if len(args) != 1 {
+		m.Println(i18n.T("Usage: /save <name>"))
+		return
+	}
+	path, err := savedSessionPath(args[0])
+	if err != nil {
+		m.Println(err.Error())
+		return
+	}
+	s := m.snapshot()
+	if len(s.Messages) == 0 {
+		m.Println(i18n.T("Nothing to save yet"))
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		m.Println(i18n.T("Failed to save session: %v", err))
+		return
+	}
+	if err := writeSnapshot(path, s); err != nil {
+		m.Println(i18n.T("Failed to save session: %v", err))
+		return
+	}
+	m.Println(i18n.T("Session saved as %s (%d messages), /load %s restores it", args[0], len(s.Messages), args[0]))
+}
+
+// handleLoadCommand replaces the current session with a saved one, or lists the saved
+// sessions without a name
+func handleLoadCommand(m *Manager, args []string) {
+	if len(args) == 0 {
+		m.printSavedSessions()
+		return
+	}
+	path, err := savedSessionPath(args[0])
+	if err != nil {
+		m.Println(err.Error())
+		return
+	}
+	s, err := loadRecoverySnapshot(path)
+	if err != nil {
+		m.Println(i18n.T("Failed to load session: %v", err))
+		return
+	}
+	if s == nil {
+		m.Println(i18n.T("No saved session named %s, /load lists them", args[0]))
+		return
+	}
+	m.restoreSnapshot(s)
+	m.Println(i18n.T("Loaded session %s from %s (%d messages)", args[0], s.SavedAt.Format("2006-01-02 15:04"), len(s.Messages)))
+}
+
+// savedSessionNames lists the names of the saved sessions in order
+func savedSessionNames() []string {
+	entries, err := os.ReadDir(savedSessionsDir())
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printSavedSessions lists the saved sessions with when they were saved
+func (m *Manager) printSavedSessions() {
+	names := savedSessionNames()
+	if len(names) == 0 {
+		m.Println(i18n.T("No saved sessions, /save <name> saves the current one"))
+		return
+	}
+	theme := system.CurrentTheme()
+	var b strings.Builder
+	for _, name := range names {
+		path, _ := savedSessionPath(name)
+		s, err := loadRecoverySnapshot(path)
+		if err != nil || s == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s %s\n", name, theme.Muted.Sprint(i18n.T("(%s, %d messages)", s.SavedAt.Format("2006-01-02 15:04"), len(s.Messages))))
+	}
+	fmt.Fprint(m.out(), b.String())
+}
@@ -0,0 +1,52 @@
+// Unit tests for the named sessions of /save and /load in saved_sessions.go
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: a saved session loads back with its overrides and MCP selection, and shows up in the list
+func TestSaveAndLoadSession(t *testing.T) {
+	dir := t.TempDir()
+	saved := savedSessionsDir
+	savedSessionsDir = func() string { return dir }
+	defer func() { savedSessionsDir = saved }()
+
+	m := &Manager{
+		Config:           config.DefaultConfig(),
+		Messages:         []ChatMessage{{Content: "why does deploy fail", FromUser: true, Timestamp: time.Now()}},
+		SessionOverrides: map[string]interface{}{"max_capture_lines": 42},
+	}
+	handleSaveCommand(m, []string{"deploy-debug"})
+	if names := savedSessionNames(); !reflect.DeepEqual(names, []string{"deploy-debug"}) {
+		t.Fatalf("unexpected saved sessions %v", names)
+	}
+
+	loaded := &Manager{Config: config.DefaultConfig(), McpServers: []config.McpServer{{Name: "github"}}}
+	handleLoadCommand(loaded, []string{"deploy-debug"})
+	if len(loaded.Messages) != 1 || loaded.Messages[0].Content != "why does deploy fail" {
+		t.Errorf("unexpected messages %+v", loaded.Messages)
+	}
+	if loaded.GetMaxCaptureLines() != 42 {
+		t.Errorf("override not restored: %+v", loaded.SessionOverrides)
+	}
+	if len(loaded.McpServers) != 0 {
+		t.Errorf("MCP selection not restored: %+v", loaded.McpServers)
+	}
+}
+
+// Test: names that would leave the sessions directory are rejected
+func TestSavedSessionPath(t *testing.T) {
+	for _, name := range []string{"../x", "a/b", ".hidden", ""} {
+		if _, err := savedSessionPath(name); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+	if _, err := savedSessionPath("deploy-debug.v2"); err != nil {
+		t.Error(err)
+	}
+}
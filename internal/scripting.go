@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+	"go.starlark.net/starlark"
+)
+
+// scriptCommand is a custom /command defined in a script
+type scriptCommand struct {
+	help string
+	fn   starlark.Callable
+}
+
+// ScriptEngine holds the commands and hooks registered by the Starlark scripts
+// in the config dir (~/.config/tmuxai/scripts/*.star)
+type ScriptEngine struct {
+	manager       *Manager
+	commands      map[string]scriptCommand
+	responseHooks []starlark.Callable
+	execHooks     []starlark.Callable
+}
+
+// ScriptsDir returns the directory user scripts are loaded from
+func ScriptsDir() string {
+	return config.GetConfigFilePath("scripts")
+}
+
+// LoadScripts executes every *.star file in dir. Scripts register commands and
+// hooks through the predeclared command(), on_response() and on_exec() builtins.
+// A broken script is logged and skipped so it can't prevent startup.
+func LoadScripts(m *Manager, dir string) *ScriptEngine {
+	e := &ScriptEngine{
+		manager:  m,
+		commands: map[string]scriptCommand{},
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.star"))
+	sort.Strings(files)
+	for _, file := range files {
+		if err := e.loadFile(file); err != nil {
+			logger.Error("Failed to load script %s: %v", file, err)
+			continue
+		}
+		logger.Info("Loaded script %s", file)
+	}
+	return e
+}
+
+func (e *ScriptEngine) loadFile(file string) error {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	_, err = starlark.ExecFile(e.thread(filepath.Base(file)), file, src, e.predeclared())
+	return err
+}
+
+func (e *ScriptEngine) thread(name string) *starlark.Thread {
+	return &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			e.manager.Println(msg)
+		},
+	}
+}
+
+// predeclared returns the builtins available to scripts
+func (e *ScriptEngine) predeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"command":      starlark.NewBuiltin("command", e.builtinCommand),
+		"on_response":  starlark.NewBuiltin("on_response", e.builtinOnResponse),
+		"on_exec":      starlark.NewBuiltin("on_exec", e.builtinOnExec),
+		"capture_pane": starlark.NewBuiltin("capture_pane", e.builtinCapturePane),
+		"cwd":          starlark.NewBuiltin("cwd", e.builtinCwd),
+	}
+}
+
+// command(name, fn, help="") registers /name; fn receives the arguments as a list of strings
+func (e *ScriptEngine) builtinCommand(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name, help string
+	var fn starlark.Callable
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "fn", &fn, "help?", &help); err != nil {
+		return nil, err
+	}
+	name = "/" + strings.TrimPrefix(strings.ToLower(name), "/")
+	e.commands[name] = scriptCommand{help: help, fn: fn}
+	return starlark.None, nil
+}
+
+// on_response(fn) registers a post-processor for the AI message text
+func (e *ScriptEngine) builtinOnResponse(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var fn starlark.Callable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &fn); err != nil {
+		return nil, err
+	}
+	e.responseHooks = append(e.responseHooks, fn)
+	return starlark.None, nil
+}
+
+// on_exec(fn) registers a hook called before a command is executed in the exec pane
+func (e *ScriptEngine) builtinOnExec(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var fn starlark.Callable
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &fn); err != nil {
+		return nil, err
+	}
+	e.execHooks = append(e.execHooks, fn)
+	return starlark.None, nil
+}
+
+func (e *ScriptEngine) builtinCapturePane(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	if e.manager.ExecPane == nil || e.manager.ExecPane.Id == "" {
+		return starlark.String(""), nil
+	}
+	content, err := system.TmuxCapturePane(e.manager.ExecPane.Id, e.manager.GetMaxCaptureLines())
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(content), nil
+}
+
+func (e *ScriptEngine) builtinCwd(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.String(e.manager.execPaneCwd()), nil
+}
+
+// Commands returns the sorted names of script commands
+func (e *ScriptEngine) Commands() []string {
+	names := make([]string, 0, len(e.commands))
+	for name := range e.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HelpMessage lists the script commands for /help
+func (e *ScriptEngine) HelpMessage() string {
+	var sb strings.Builder
+	for _, name := range e.Commands() {
+		help := e.commands[name].help
+		if help == "" {
+			help = i18n.T("Script command")
+		}
+		sb.WriteString(fmt.Sprintf("\n- %s: %s", name, help))
+	}
+	return sb.String()
+}
+
+// HasCommand reports whether a script registered the given /command
+func (e *ScriptEngine) HasCommand(name string) bool {
+	_, ok := e.commands[name]
+	return ok
+}
+
+// RunCommand calls a script command. A returned string is sent to the AI as a user message.
+func (e *ScriptEngine) RunCommand(name string, args []string) (string, error) {
+	cmd, ok := e.commands[name]
+	if !ok {
+		return "", fmt.Errorf("unknown script command: %s", name)
+	}
+	list := make([]starlark.Value, len(args))
+	for i, arg := range args {
+		list[i] = starlark.String(arg)
+	}
+	result, err := starlark.Call(e.thread(name), cmd.fn, starlark.Tuple{starlark.NewList(list)}, nil)
+	if err != nil {
+		return "", err
+	}
+	if s, ok := starlark.AsString(result); ok {
+		return s, nil
+	}
+	return "", nil
+}
+
+// ProcessResponse passes the AI message through every on_response hook in load order
+func (e *ScriptEngine) ProcessResponse(message string) string {
+	for _, fn := range e.responseHooks {
+		result, err := starlark.Call(e.thread("on_response"), fn, starlark.Tuple{starlark.String(message)}, nil)
+		if err != nil {
+			logger.Error("on_response hook failed: %v", err)
+			continue
+		}
+		if s, ok := starlark.AsString(result); ok {
+			message = s
+		}
+	}
+	return message
+}
+
+// ProcessExec runs the on_exec hooks for a command about to be executed.
+// A hook may return a replacement command, False to block it, or None to keep it.
+func (e *ScriptEngine) ProcessExec(command string) (string, bool) {
+	for _, fn := range e.execHooks {
+		result, err := starlark.Call(e.thread("on_exec"), fn, starlark.Tuple{starlark.String(command)}, nil)
+		if err != nil {
+			logger.Error("on_exec hook failed: %v", err)
+			continue
+		}
+		if s, ok := starlark.AsString(result); ok {
+			command = s
+		} else if result == starlark.False {
+			return command, false
+		}
+	}
+	return command, true
+}
+
+// runScriptCommand runs a script /command and sends its result, if any, to the AI
+func (m *Manager) runScriptCommand(name string, args []string) {
+	message, err := m.Scripts.RunCommand(name, args)
+	if err != nil {
+		m.Println(i18n.T("Script command %s failed: %v", name, err))
+		return
+	}
+	if strings.TrimSpace(message) == "" {
+		return
+	}
+	m.SetStatus("running")
+	m.ProcessUserMessage(context.Background(), message)
+	m.SetStatus("")
+}
@@ -0,0 +1,60 @@
+// Unit tests for the Starlark script engine in scripting.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadTestScript(t *testing.T, src string) *ScriptEngine {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "test.star"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadScripts(&Manager{}, dir)
+}
+
+// Test: commands, response and exec hooks are registered and called
+func TestLoadScripts_Hooks(t *testing.T) {
+	e := loadTestScript(t, `
+def review(args):
+    return "review " + " ".join(args)
+
+def shout(msg):
+    return msg.upper()
+
+def guard(cmd):
+    if cmd.startswith("rm "):
+        return False
+    return cmd.replace("ls", "ls -la")
+
+command("Review", review, help="Review files")
+on_response(shout)
+on_exec(guard)
+`)
+
+	if !e.HasCommand("/review") {
+		t.Fatalf("expected /review to be registered, got %v", e.Commands())
+	}
+	if got, err := e.RunCommand("/review", []string{"a.go", "b.go"}); err != nil || got != "review a.go b.go" {
+		t.Errorf("RunCommand = %q, %v", got, err)
+	}
+	if got := e.ProcessResponse("done"); got != "DONE" {
+		t.Errorf("ProcessResponse = %q", got)
+	}
+	if got, ok := e.ProcessExec("ls"); !ok || got != "ls -la" {
+		t.Errorf("ProcessExec(ls) = %q, %v", got, ok)
+	}
+	if _, ok := e.ProcessExec("rm -rf /tmp/x"); ok {
+		t.Errorf("expected rm to be blocked")
+	}
+}
+
+// Test: a broken script is skipped instead of failing the load
+func TestLoadScripts_BrokenScript(t *testing.T) {
+	e := loadTestScript(t, `command("x", undefined_fn)`)
+	if len(e.Commands()) != 0 {
+		t.Errorf("expected no commands, got %v", e.Commands())
+	}
+}
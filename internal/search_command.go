@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// handleSearchCommand finds text in the whole session: the history still in memory and
+// what was already moved to the session store
+func handleSearchCommand(m *Manager, args []string) {
+	query := strings.Join(args, " ")
+	if query == "" {
+		m.Println(i18n.T("Usage: /search <text>"))
+		return
+	}
+
+	var matches []storedEntry
+	if m.store != nil {
+		stored, err := m.store.search(query)
+		if err != nil {
+			logger.Error("Failed to search the session store: %v", err)
+		}
+		matches = stored
+	}
+	lower := strings.ToLower(query)
+	for _, msg := range m.Messages {
+		if strings.Contains(strings.ToLower(msg.Content), lower) {
+			matches = append(matches, storedEntry{Kind: "message", Timestamp: msg.Timestamp, FromUser: msg.FromUser, Content: msg.Content})
+		}
+	}
+	for _, h := range m.ExecHistory {
+		entry := storedEntry{Kind: "exec", Command: h.Command, Output: h.Output, Code: h.Code}
+		if strings.Contains(strings.ToLower(entry.text()), lower) {
+			matches = append(matches, entry)
+		}
+	}
+
+	if len(matches) == 0 {
+		m.Println(i18n.T("No matches for %q", query))
+		return
+	}
+	m.Println(i18n.T("%d matches for %q:", len(matches), query))
+	theme := system.CurrentTheme()
+	width := system.TerminalWidth()
+	if width <= 0 {
+		width = 100
+	}
+	for _, entry := range matches {
+		label := "exec"
+		if entry.Kind == "message" {
+			label = "ai"
+			if entry.FromUser {
+				label = "user"
+			}
+		}
+		if !entry.Timestamp.IsZero() {
+			label = entry.Timestamp.Format("Jan 2 15:04") + " " + label
+		}
+		prefix := fmt.Sprintf("  %s  ", label)
+		line := matchingLine(entry.text(), lower)
+		line = ansi.Truncate(line, max(width-ansi.StringWidth(prefix), 20), system.Sym("…"))
+		fmt.Fprintln(m.out(), theme.Neutral.Sprint(prefix)+line)
+	}
+}
+
+// matchingLine returns the first line of text containing query, trimmed
+func matchingLine(text, query string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(strings.ToLower(line), query) {
+			return strings.TrimSpace(line)
+		}
+	}
+	return strings.TrimSpace(strings.SplitN(text, "\n", 2)[0])
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// sessionStoreMaxAge is how long spill files of sessions that didn't shut down cleanly are kept
+const sessionStoreMaxAge = 7 * 24 * time.Hour
+
+// storedEntry is one line of the session store: a chat message or an exec pane command
+type storedEntry struct {
+	Kind      string    `json:"kind"` // "message" or "exec"
+	Timestamp time.Time `json:"timestamp"`
+	FromUser  bool      `json:"from_user,omitempty"`
+	Content   string    `json:"content,omitempty"`
+	Command   string    `json:"command,omitempty"`
+	Output    string    `json:"output,omitempty"`
+	Code      int       `json:"code,omitempty"`
+}
+
+// text is what /search matches against
+func (e storedEntry) text() string {
+	if e.Kind == "exec" {
+		return e.Command + "\n" + e.Output
+	}
+	return e.Content
+}
+
+// sessionStore keeps the history trimmed from memory in a JSON lines file for the
+// session, so long sessions stay small and /search still finds everything
+type sessionStore struct {
+	path string
+	// spilled holds the exec history entries already written; the exec history is
+	// parsed from the pane again and again, so the same entries come back
+	spilled map[uint64]bool
+}
+
+// newSessionStore prepares a store in dir and removes files left by old sessions
+func newSessionStore(dir string) *sessionStore {
+	if dir == "" {
+		dir = config.GetConfigFilePath("sessions")
+	}
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > sessionStoreMaxAge {
+				os.Remove(filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	name := fmt.Sprintf("%s-%d.jsonl", time.Now().Format("20060102-150405"), os.Getpid())
+	return &sessionStore{path: filepath.Join(dir, name), spilled: map[uint64]bool{}}
+}
+
+// append writes entries to the end of the store, creating it on first use
+func (s *sessionStore) append(entries []storedEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// search returns the stored entries containing query, ignoring case
+func (s *sessionStore) search(query string) ([]storedEntry, error) {
+	query = strings.ToLower(query)
+	var matches []storedEntry
+	err := s.each(func(entry storedEntry) {
+		if strings.Contains(strings.ToLower(entry.text()), query) {
+			matches = append(matches, entry)
+		}
+	})
+	return matches, err
+}
+
+// entries returns everything in the store, oldest first
+func (s *sessionStore) entries() ([]storedEntry, error) {
+	var all []storedEntry
+	err := s.each(func(entry storedEntry) { all = append(all, entry) })
+	return all, err
+}
+
+// each calls fn for every entry of the store in order, skipping unreadable lines
+func (s *sessionStore) each(fn func(storedEntry)) error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry storedEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		fn(entry)
+	}
+	return scanner.Err()
+}
+
+// remove deletes the store, on a clean shutdown
+func (s *sessionStore) remove() {
+	os.Remove(s.path)
+}
+
+// execKey identifies an exec history entry across parses of the pane
+func execKey(h CommandExecHistory) uint64 {
+	sum := fnv.New64a()
+	fmt.Fprintf(sum, "%s\x00%s\x00%d", h.Command, h.Output, h.Code)
+	return sum.Sum64()
+}
+
+// spillHistory moves the messages and exec history entries over the session_store
+// limits from memory to the store, oldest first
+func (m *Manager) spillHistory() {
+	limits := m.Config.SessionStore
+	var entries []storedEntry
+
+	if limits.MaxMessages > 0 && len(m.Messages) > limits.MaxMessages {
+		n := len(m.Messages) - limits.MaxMessages
+		for _, msg := range m.Messages[:n] {
+			entries = append(entries, storedEntry{Kind: "message", Timestamp: msg.Timestamp, FromUser: msg.FromUser, Content: msg.Content})
+		}
+		m.setMessages(append([]ChatMessage(nil), m.Messages[n:]...))
+	}
+
+	if limits.MaxExecHistory > 0 && len(m.ExecHistory) > limits.MaxExecHistory {
+		if m.store == nil {
+			m.store = newSessionStore(limits.Dir)
+		}
+		n := len(m.ExecHistory) - limits.MaxExecHistory
+		for _, h := range m.ExecHistory[:n] {
+			key := execKey(h)
+			if m.store.spilled[key] {
+				continue
+			}
+			m.store.spilled[key] = true
+			entries = append(entries, storedEntry{Kind: "exec", Timestamp: time.Now(), Command: h.Command, Output: h.Output, Code: h.Code})
+		}
+		m.ExecHistory = append([]CommandExecHistory(nil), m.ExecHistory[n:]...)
+	}
+
+	if len(entries) == 0 {
+		return
+	}
+	if m.store == nil {
+		m.store = newSessionStore(limits.Dir)
+	}
+	if err := m.store.append(entries); err != nil {
+		logger.Error("Failed to write the session store: %v", err)
+		return
+	}
+	logger.Debug("Moved %d history entries to %s", len(entries), m.store.path)
+}
@@ -0,0 +1,65 @@
+// Unit tests for the on-disk session store in session_store.go
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+func newStoreTestManager(t *testing.T) *Manager {
+	cfg := config.DefaultConfig()
+	cfg.SessionStore = config.SessionStoreConfig{MaxMessages: 3, MaxExecHistory: 2, Dir: t.TempDir()}
+	return &Manager{Config: cfg}
+}
+
+// Test: messages over the limit move to disk, oldest first, and can still be searched
+func TestSpillHistoryMessages(t *testing.T) {
+	m := newStoreTestManager(t)
+	for i := 1; i <= 5; i++ {
+		m.appendMessages(ChatMessage{Content: fmt.Sprintf("message %d", i), FromUser: i%2 == 1})
+	}
+	m.spillHistory()
+
+	if len(m.Messages) != 3 || m.Messages[0].Content != "message 3" {
+		t.Fatalf("unexpected messages in memory: %+v", m.Messages)
+	}
+	matches, err := m.store.search("MESSAGE 1")
+	if err != nil || len(matches) != 1 || !matches[0].FromUser {
+		t.Errorf("spilled message not found: %+v, %v", matches, err)
+	}
+}
+
+// Test: exec history parsed again from the pane is written to disk only once
+func TestSpillHistoryExecOnce(t *testing.T) {
+	m := newStoreTestManager(t)
+	history := []CommandExecHistory{{Command: "ls"}, {Command: "pwd"}, {Command: "make"}}
+	for i := 0; i < 2; i++ {
+		m.ExecHistory = append([]CommandExecHistory(nil), history...)
+		m.spillHistory()
+	}
+
+	if len(m.ExecHistory) != 2 || m.ExecHistory[0].Command != "pwd" {
+		t.Fatalf("unexpected exec history in memory: %+v", m.ExecHistory)
+	}
+	data, err := os.ReadFile(m.store.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 1 {
+		t.Errorf("expected one stored entry, got %d", n)
+	}
+}
+
+// Test: nothing is written while the history is under the limits
+func TestSpillHistoryUnderLimit(t *testing.T) {
+	m := newStoreTestManager(t)
+	m.Messages = []ChatMessage{{Content: "hi"}}
+	m.spillHistory()
+	if m.store != nil {
+		t.Error("store created without anything to spill")
+	}
+}
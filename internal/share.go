@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// shareBacklog is how many past events a new viewer receives
+const shareBacklog = 500
+
+// shareHub fans the event stream out to read-only viewers of the session
+type shareHub struct {
+	mu          sync.Mutex
+	backlog     []Event
+	subscribers map[chan Event]struct{}
+}
+
+var (
+	sharing     *shareHub
+	sharingOnce sync.Once
+)
+
+// sessionShare returns the hub, registering it as an event listener on first use
+func sessionShare() *shareHub {
+	sharingOnce.Do(func() {
+		sharing = &shareHub{subscribers: map[chan Event]struct{}{}}
+		AddEventListener(sharing.publish)
+	})
+	return sharing
+}
+
+// publish is called with the event lock held, so it must never block
+func (h *shareHub) publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.backlog = append(h.backlog, e)
+	if len(h.backlog) > shareBacklog {
+		h.backlog = h.backlog[len(h.backlog)-shareBacklog:]
+	}
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default: // slow viewer, drop the event rather than stall the session
+		}
+	}
+}
+
+// subscribe returns the backlog and a channel of new events
+func (h *shareHub) subscribe() ([]Event, chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch := make(chan Event, 64)
+	h.subscribers[ch] = struct{}{}
+	return append([]Event(nil), h.backlog...), ch
+}
+
+func (h *shareHub) unsubscribe(ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, ch)
+}
+
+// formatShareEvent renders an event as plain text for the transcript mirror
+func formatShareEvent(e Event) string {
+	ts := e.Timestamp.Format("15:04:05")
+	str := func(key string) string {
+		v, _ := e.Data[key].(string)
+		return v
+	}
+	switch e.Type {
+	case EventUserMessage:
+		return fmt.Sprintf("[%s] user: %s\n", ts, str("content"))
+	case EventAIResponse:
+		if str("message") == "" {
+			return ""
+		}
+		return fmt.Sprintf("[%s] ai: %s\n", ts, str("message"))
+	case EventConfirmation:
+		verdict := "declined"
+		if approved, _ := e.Data["approved"].(bool); approved {
+			verdict = "approved"
+		}
+		return fmt.Sprintf("[%s] %s %s: %s\n", ts, str("prompt"), verdict, str("content"))
+	case EventExec:
+		return fmt.Sprintf("[%s] $ %s\n", ts, str("command"))
+	case EventExecOutput:
+		return fmt.Sprintf("[%s] exit %v\n%s\n", ts, e.Data["code"], strings.TrimSpace(str("output")))
+	case EventError:
+		return fmt.Sprintf("[%s] error: %s\n", ts, str("message"))
+	default:
+		return ""
+	}
+}
+
+// mirrorToFile appends the transcript to path until stop is called, which also removes the file
+func mirrorToFile(path string) (stop func(), err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	hub := sessionShare()
+	backlog, ch := hub.subscribe()
+	go func() {
+		defer file.Close()
+		for _, e := range backlog {
+			file.WriteString(formatShareEvent(e))
+		}
+		for e := range ch {
+			file.WriteString(formatShareEvent(e))
+		}
+	}()
+	return func() {
+		// nothing is published to ch once it is unsubscribed
+		hub.unsubscribe(ch)
+		close(ch)
+		os.Remove(path)
+	}, nil
+}
+
+// closeShare kills the mirror window and removes the transcript file, if /share pane opened them
+func (m *Manager) closeShare() {
+	m.stateMu.Lock()
+	paneId, stop := m.sharePaneId, m.stopShare
+	m.sharePaneId, m.stopShare = "", nil
+	m.stateMu.Unlock()
+	if paneId != "" {
+		if err := system.TmuxKillWindow(paneId); err != nil {
+			logger.Error("Failed to close the share window: %v", err)
+		}
+	}
+	if stop != nil {
+		stop()
+	}
+}
+
+// handleShareCommand opens a read-only mirror of the chat transcript in a new tmux window
+func handleShareCommand(m *Manager, args []string) {
+	if len(args) == 0 || args[0] != "pane" {
+		m.Println(i18n.T("Usage: /share pane (in serve mode the transcript is also available at /share on the API server)"))
+		return
+	}
+	m.stateMu.RLock()
+	sharePaneId := m.sharePaneId
+	m.stateMu.RUnlock()
+	if sharePaneId != "" {
+		m.Println(i18n.T("The transcript is already mirrored to pane %s", sharePaneId))
+		return
+	}
+
+	path := config.GetConfigFilePath(fmt.Sprintf("share-%d.log", os.Getpid()))
+	stop, err := mirrorToFile(path)
+	if err != nil {
+		m.Println(i18n.T("Failed to start the transcript mirror: %v", err))
+		return
+	}
+	paneId, err := system.TmuxNewWindowCommand(m.PaneId, "tmuxai-share", "tail -n +1 -f "+shellQuote(path))
+	if err != nil {
+		stop()
+		m.Println(i18n.T("Failed to open the mirror window: %v", err))
+		return
+	}
+	m.stateMu.Lock()
+	m.sharePaneId, m.stopShare = paneId, stop
+	m.stateMu.Unlock()
+	logger.Info("Mirroring transcript to %s in pane %s", path, paneId)
+	m.Println(i18n.T("Transcript mirrored read-only to the tmuxai-share window"))
+}
@@ -0,0 +1,91 @@
+// Unit tests for session sharing in share.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: new subscribers get the backlog and then live events
+func TestShareHubSubscribe(t *testing.T) {
+	hub := &shareHub{subscribers: map[chan Event]struct{}{}}
+	hub.publish(Event{Type: EventExec, Data: map[string]interface{}{"command": "ls"}})
+
+	backlog, ch := hub.subscribe()
+	if len(backlog) != 1 || backlog[0].Type != EventExec {
+		t.Fatalf("unexpected backlog: %+v", backlog)
+	}
+
+	hub.publish(Event{Type: EventError, Data: map[string]interface{}{"message": "boom"}})
+	select {
+	case e := <-ch:
+		if e.Type != EventError {
+			t.Errorf("expected error event, got %s", e.Type)
+		}
+	default:
+		t.Fatal("expected a live event")
+	}
+
+	hub.unsubscribe(ch)
+	hub.publish(Event{Type: EventExec})
+	if len(ch) != 0 {
+		t.Error("unsubscribed channel should not receive events")
+	}
+}
+
+// Test: transcript lines for the event types shown to viewers
+func TestFormatShareEvent(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Type: EventUserMessage, Timestamp: ts, Data: map[string]interface{}{"content": "hi"}}, "[09:30:00] user: hi\n"},
+		{Event{Type: EventExec, Timestamp: ts, Data: map[string]interface{}{"command": "ls"}}, "[09:30:00] $ ls\n"},
+		{Event{Type: EventConfirmation, Timestamp: ts, Data: map[string]interface{}{"prompt": "Execute", "approved": true, "content": "ls"}}, "[09:30:00] Execute approved: ls\n"},
+		{Event{Type: EventAIResponse, Timestamp: ts, Data: map[string]interface{}{"message": ""}}, ""},
+		{Event{Type: EventToolCall, Timestamp: ts}, ""},
+	}
+	for _, tt := range tests {
+		if got := formatShareEvent(tt.event); got != tt.want {
+			t.Errorf("formatShareEvent(%s) = %q, want %q", tt.event.Type, got, tt.want)
+		}
+	}
+}
+
+// Test: the transcript file gets the events until Shutdown stops the mirror and removes it
+func TestMirrorToFileRemovedOnShutdown(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "share.log")
+	stop, err := mirrorToFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	emitEvent(EventExec, map[string]interface{}{"command": "make mirror-test"})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(path)
+		if strings.Contains(string(data), "$ make mirror-test") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the command in the transcript, got %q", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.SaveOnExit = false
+	m := NewManagerForPane(cfg, "", nil)
+	m.stopShare = stop
+	m.Shutdown()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the transcript file to be removed, got %v", err)
+	}
+	emitEvent(EventExec, map[string]interface{}{"command": "ls"})
+}
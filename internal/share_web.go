@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const sharePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>TmuxAI session</title>
+<style>
+body { background: #1e1e1e; color: #ddd; font: 14px/1.5 monospace; margin: 2em; }
+.event { white-space: pre-wrap; margin: 0.4em 0; }
+.time { color: #777; margin-right: 0.6em; }
+.user_message { color: #8ab4f8; }
+.ai_response { color: #ddd; }
+.exec { color: #81c995; }
+.exec_output { color: #aaa; border-left: 2px solid #444; padding-left: 0.6em; }
+.confirmation { color: #fdd663; }
+.error { color: #f28b82; }
+</style>
+</head>
+<body>
+<h3>TmuxAI session (read-only)</h3>
+<div id="log"></div>
+<script>
+const labels = { user_message: "user", ai_response: "ai", exec: "$", exec_output: "output", confirmation: "confirm", error: "error" };
+function text(e) {
+  const d = e.data || {};
+  switch (e.type) {
+    case "user_message": return d.content;
+    case "ai_response": return d.message;
+    case "exec": return d.command;
+    case "exec_output": return "exit " + d.code + "\n" + (d.output || "");
+    case "confirmation": return d.prompt + " " + (d.approved ? "approved" : "declined") + ": " + d.content;
+    case "error": return d.message;
+  }
+  return "";
+}
+const source = new EventSource("/share/events" + location.search);
+source.onmessage = (msg) => {
+  const e = JSON.parse(msg.data);
+  const body = text(e);
+  if (!body) return;
+  const div = document.createElement("div");
+  div.className = "event " + e.type;
+  const time = document.createElement("span");
+  time.className = "time";
+  time.textContent = new Date(e.timestamp).toLocaleTimeString() + " " + labels[e.type];
+  div.appendChild(time);
+  div.appendChild(document.createTextNode(body));
+  document.getElementById("log").appendChild(div);
+  window.scrollTo(0, document.body.scrollHeight);
+};
+</script>
+</body>
+</html>
+`
+
+// newShareToken returns a random token for read-only viewers, separate from the API token
+func newShareToken() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// ShareURL returns the read-only transcript page for viewers
+func (s *APIServer) ShareURL() string {
+	return fmt.Sprintf("http://%s/share?token=%s", s.server.Addr, s.shareToken)
+}
+
+// shareAuth only grants access to the read-only transcript
+func (s *APIServer) shareAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		provided := r.URL.Query().Get("token")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(s.shareToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *APIServer) handleSharePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(sharePage))
+}
+
+// handleShareEvents streams the session events as server-sent events
+func (s *APIServer) handleShareEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	hub := sessionShare()
+	backlog, ch := hub.subscribe()
+	defer hub.unsubscribe(ch)
+
+	write := func(e Event) {
+		data, _ := json.Marshal(e)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	for _, e := range backlog {
+		write(e)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-ch:
+			write(e)
+			flusher.Flush()
+		}
+	}
+}
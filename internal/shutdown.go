@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alvinunreal/tmuxai/logger"
+	"golang.org/x/term"
+)
+
+// Shutdown releases what the session holds: the status header, the session store, the
+// MCP servers and their connections, and the log file. With save_on_exit the session is
+// kept for the next start. Only the first call does anything.
+func (m *Manager) Shutdown() {
+	m.shutdownOnce.Do(func() {
+		logger.Info("Shutting down")
+		if m.GetConfig().SaveOnExit {
+			if err := m.saveRecoverySnapshot(recoveryPath(), ""); err != nil {
+				logger.Error("Failed to save session: %v", err)
+			}
+		}
+		m.stopStatusHeader()
+		m.closeShare()
+		m.sendTelemetry()
+		if m.store != nil {
+			m.store.remove()
+		}
+		m.stateMu.RLock()
+		client := m.McpClient
+		m.stateMu.RUnlock()
+		if client != nil {
+			client.Close()
+		}
+		logger.Flush()
+	})
+}
+
+// exit shuts down and ends the process with code. The readline prompt may hold the
+// terminal in raw mode, so its state from before Start is put back first.
+func (m *Manager) exit(code int) {
+	if m.termState != nil {
+		_ = term.Restore(int(os.Stdin.Fd()), m.termState)
+	}
+	m.Shutdown()
+	os.Exit(code)
+}
+
+// handleSignals shuts down cleanly on SIGTERM and SIGHUP, and on SIGINT while no turn
+// is running; during a turn Ctrl+C only cancels it. The returned func stops handling.
+func (m *Manager) handleSignals() (stop func()) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		m.termState, _ = term.GetState(int(os.Stdin.Fd()))
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-sigChan:
+				if sig == os.Interrupt {
+					if !m.turnMu.TryLock() {
+						continue
+					}
+					m.turnMu.Unlock()
+				}
+				logger.Info("Received %v, shutting down", sig)
+				if m.tui != nil {
+					// the TUI restores the terminal and Start returns normally
+					m.tui.quit()
+					continue
+				}
+				m.exit(128 + int(sig.(syscall.Signal)))
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
@@ -0,0 +1,33 @@
+// Unit tests for the shutdown path in shutdown.go
+package internal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: with save_on_exit the session is kept for the next start, and only once
+func TestShutdownSavesSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.json")
+	saved := recoveryPath
+	recoveryPath = func() string { return path }
+	defer func() { recoveryPath = saved }()
+
+	cfg := config.DefaultConfig()
+	cfg.SaveOnExit = true
+	m := &Manager{Config: cfg, Messages: []ChatMessage{{Content: "hi", FromUser: true}}}
+	m.Shutdown()
+
+	s, err := loadRecoverySnapshot(path)
+	if err != nil || s == nil || len(s.Messages) != 1 || s.Panic != "" {
+		t.Fatalf("session not saved: %+v, %v", s, err)
+	}
+
+	m.appendMessages(ChatMessage{Content: "more"})
+	m.Shutdown()
+	if s, _ := loadRecoverySnapshot(path); len(s.Messages) != 1 {
+		t.Errorf("second Shutdown saved again: %d messages", len(s.Messages))
+	}
+}
@@ -8,22 +8,32 @@ import (
 
 	"github.com/alvinunreal/tmuxai/logger"
 	"github.com/alvinunreal/tmuxai/system"
-	"github.com/briandowns/spinner"
 )
 
-// needSquash checks if the current context size is approaching the max limit
+// needSquash checks if the current context size reached compaction.threshold of the max limit
 func (m *Manager) needSquash() bool {
+	cfg := m.Config.Compaction
+	if !cfg.Auto || cfg.Threshold <= 0 {
+		return false
+	}
+	threshold := m.GetMaxContextSize() * cfg.Threshold / 100
+	return m.contextTokens() > threshold
+}
+
+// contextTokens estimates the token count of the chat history
+func (m *Manager) contextTokens() int {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
 	totalTokens := 0
 	for _, msg := range m.Messages {
 		totalTokens += system.EstimateTokenCount(msg.Content)
 	}
-
-	threshold := int(float64(m.GetMaxContextSize()) * 0.8)
-	return totalTokens > threshold
+	return totalTokens
 }
 
-// manageContext handles context reduction by summarizing chat history
-func (m *Manager) squashHistory() {
+// squashHistory handles context reduction by summarizing chat history; the most recent
+// compaction.keep_messages messages are kept as they are
+func (m *Manager) squashHistory(ctx context.Context) {
 	var systemMessage ChatMessage
 	var assistantBaseMessage ChatMessage
 	var hasSystemMessage bool
@@ -53,12 +63,15 @@ func (m *Manager) squashHistory() {
 		startIdx++
 	}
 
-	// Only summarize if we have messages beyond the base ones
-	if startIdx < len(m.Messages)-1 {
-		messagesToSummarize = m.Messages[startIdx : len(m.Messages)-1] // Exclude the most recent user message
+	keep := min(max(m.Config.Compaction.KeepMessages, 0), len(m.Messages)-startIdx)
+	kept := m.Messages[len(m.Messages)-keep:]
+
+	// Only summarize if we have messages beyond the base ones and the kept ones
+	if startIdx < len(m.Messages)-keep {
+		messagesToSummarize = m.Messages[startIdx : len(m.Messages)-keep]
 
 		// Request summarization from AI
-		summarizedHistory, err := m.summarizeChatHistory(messagesToSummarize)
+		summarizedHistory, err := m.summarizeChatHistory(ctx, messagesToSummarize)
 		if err != nil {
 			logger.Error("Failed to summarize chat history: %v", err)
 			return
@@ -83,16 +96,16 @@ func (m *Manager) squashHistory() {
 			FromUser:  false,
 			Timestamp: time.Now(),
 		})
+		newHistory = append(newHistory, kept...)
 
-		m.Messages = newHistory
-		logger.Debug("Context successfully reduced through summarization")
+		m.setMessages(newHistory)
+		logger.Debug("Context successfully reduced through summarization, %d messages summarized, %d kept", len(messagesToSummarize), len(kept))
 	}
 }
 
 // summarizeChatHistory asks the AI to summarize the chat history
-func (m *Manager) summarizeChatHistory(messages []ChatMessage) (string, error) {
-	s := spinner.New(spinner.CharSets[26], 100*time.Millisecond)
-	s.Start()
+func (m *Manager) summarizeChatHistory(ctx context.Context, messages []ChatMessage) (string, error) {
+	s := m.startProgress(26, "Summarizing history")
 
 	// Convert messages to a readable format for summarization
 	var chatLog strings.Builder
@@ -121,7 +134,7 @@ func (m *Manager) summarizeChatHistory(messages []ChatMessage) (string, error) {
 	}
 
 	// Create a context for the summarization request
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	summary, err := m.AiClient.GetResponseFromChatMessages(ctx, summarizationMessage, m.GetOpenRouterModel())
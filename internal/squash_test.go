@@ -0,0 +1,55 @@
+// Unit tests for the history compaction in squash.go
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: compaction starts at the configured share of max_context_size and only when auto is on
+func TestNeedSquash(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxContextSize = 1000
+	cfg.Compaction.Threshold = 50
+	m := &Manager{Config: cfg, Messages: []ChatMessage{{Content: strings.Repeat("word ", 600)}}}
+	if !m.needSquash() {
+		t.Error("expected compaction over the threshold")
+	}
+	cfg.Compaction.Threshold = 90
+	if m.needSquash() {
+		t.Error("unexpected compaction under the threshold")
+	}
+	cfg.Compaction.Threshold = 50
+	cfg.Compaction.Auto = false
+	if m.needSquash() {
+		t.Error("unexpected compaction with auto off")
+	}
+}
+
+// Test: the system prompt and the last keep_messages messages survive, the rest becomes a summary
+func TestSquashHistoryKeepsRecentMessages(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Compaction.KeepMessages = 2
+	provider, err := NewMockProvider(config.MockConfig{Responses: []string{"they debugged a deploy"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{Config: cfg, AiClient: provider, Messages: []ChatMessage{{Content: "system"}}}
+	for i := 1; i <= 6; i++ {
+		m.Messages = append(m.Messages, ChatMessage{Content: fmt.Sprintf("message %d", i), FromUser: i%2 == 1})
+	}
+
+	m.squashHistory(context.Background())
+	var got []string
+	for _, msg := range m.Messages {
+		got = append(got, msg.Content)
+	}
+	want := []string{"system", "CHAT HISTORY SUMMARY:\nthey debugged a deploy", "message 5", "message 6"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// initInBackground finds the exec pane and loads the user scripts and plugin tools in
+// parallel while the chat comes up; nothing reads them before the first turn, which
+// waits in ready
+func (m *Manager) initInBackground(scriptsDir, toolsDir string) {
+	done := make(chan struct{})
+	m.startupDone = done
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		m.InitExecPane()
+	}()
+	go func() {
+		defer wg.Done()
+		m.Scripts = LoadScripts(m, scriptsDir)
+	}()
+	go func() {
+		defer wg.Done()
+		m.Plugins = LoadPluginTools(toolsDir)
+	}()
+	go func() {
+		wg.Wait()
+		logger.Debug("Background initialization done")
+		close(done)
+	}()
+}
+
+// ready waits for initInBackground, it is called before anything uses the exec pane or
+// the scripts
+func (m *Manager) ready() {
+	if m.startupDone != nil {
+		<-m.startupDone
+	}
+}
+
+// isReady reports whether initInBackground is done, without waiting
+func (m *Manager) isReady() bool {
+	if m.startupDone == nil {
+		return true
+	}
+	select {
+	case <-m.startupDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// osDetails describes the local OS; it is looked up on first use since on macOS it runs sw_vers
+func (m *Manager) osDetails() string {
+	m.osOnce.Do(func() {
+		m.osInfo = system.GetOSDetails()
+	})
+	return m.osInfo
+}
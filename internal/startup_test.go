@@ -0,0 +1,46 @@
+// Unit tests for the background initialization in startup.go
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+// Test: ready blocks until the background initialization is done
+func TestReadyWaitsForStartup(t *testing.T) {
+	done := make(chan struct{})
+	m := &Manager{startupDone: done}
+	if m.isReady() {
+		t.Fatal("ready before startup finished")
+	}
+
+	returned := make(chan struct{})
+	go func() {
+		m.ready()
+		close(returned)
+	}()
+	select {
+	case <-returned:
+		t.Fatal("ready returned before startup finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(done)
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("ready did not return after startup finished")
+	}
+	if !m.isReady() {
+		t.Error("not ready after startup finished")
+	}
+}
+
+// Test: a manager set up synchronously is ready right away
+func TestReadyWithoutBackgroundStartup(t *testing.T) {
+	m := &Manager{}
+	m.ready()
+	if !m.isReady() {
+		t.Error("expected ready")
+	}
+}
@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// The chat loop, the TUI, signal handlers, watch mode and the API and control servers
+// all look at the session state. Turns are serialized by turnMu, so a turn may read
+// Messages, SessionOverrides, Config and AiClient directly; every write, and every read
+// from outside a turn, goes through the accessors below, which hold stateMu.
+
+// GetConfig returns the config of the session; a reload replaces it, it is never changed
+// in place
+func (m *Manager) GetConfig() *config.Config {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.Config
+}
+
+// setConfig switches the session to another config
+func (m *Manager) setConfig(cfg *config.Config) {
+	m.stateMu.Lock()
+	m.Config = cfg
+	m.stateMu.Unlock()
+}
+
+// aiClient returns the chat provider of the session
+func (m *Manager) aiClient() ChatProvider {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.AiClient
+}
+
+// setAiClient switches the session to another chat provider
+func (m *Manager) setAiClient(provider ChatProvider) {
+	m.stateMu.Lock()
+	m.AiClient = provider
+	m.stateMu.Unlock()
+}
+
+// GetStatus returns the agent status: running, waiting, done or "" when idle
+func (m *Manager) GetStatus() string {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.status
+}
+
+// SetStatus sets the agent status
+func (m *Manager) SetStatus(status string) {
+	m.stateMu.Lock()
+	m.status = status
+	m.stateMu.Unlock()
+}
+
+// GetWatchMode reports whether watch mode is on
+func (m *Manager) GetWatchMode() bool {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.watchMode
+}
+
+// SetWatchMode turns watch mode on or off
+func (m *Manager) SetWatchMode(on bool) {
+	m.stateMu.Lock()
+	m.watchMode = on
+	m.stateMu.Unlock()
+}
+
+// stopTurn marks the agent idle and ends watch mode in one step, so the running turn
+// never sees one without the other
+func (m *Manager) stopTurn() {
+	m.stateMu.Lock()
+	m.status = ""
+	m.watchMode = false
+	m.stateMu.Unlock()
+}
+
+// getWaitingSince returns when the pending model call started, zero when idle
+func (m *Manager) getWaitingSince() time.Time {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.waitingSince
+}
+
+// setWaitingSince records when the pending model call started
+func (m *Manager) setWaitingSince(t time.Time) {
+	m.stateMu.Lock()
+	m.waitingSince = t
+	m.stateMu.Unlock()
+}
+
+// appendMessages adds messages to the conversation
+func (m *Manager) appendMessages(msgs ...ChatMessage) {
+	m.stateMu.Lock()
+	m.Messages = append(m.Messages, msgs...)
+	m.stateMu.Unlock()
+}
+
+// setMessages replaces the conversation
+func (m *Manager) setMessages(msgs []ChatMessage) {
+	m.stateMu.Lock()
+	m.Messages = msgs
+	m.stateMu.Unlock()
+}
+
+// MessageHistory returns a copy of the conversation, safe to use while a turn runs
+func (m *Manager) MessageHistory() []ChatMessage {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return append([]ChatMessage(nil), m.Messages...)
+}
+
+// ResetMessages clears the conversation
+func (m *Manager) ResetMessages() {
+	m.setMessages([]ChatMessage{})
+}
+
+// sessionOverride returns the session override for a config key, if set
+func (m *Manager) sessionOverride(key string) (interface{}, bool) {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	value, exists := m.SessionOverrides[key]
+	return value, exists
+}
+
+// setSessionOverride overrides a config key for the rest of the session
+func (m *Manager) setSessionOverride(key string, value interface{}) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	if m.SessionOverrides == nil {
+		m.SessionOverrides = make(map[string]interface{})
+	}
+	m.SessionOverrides[key] = value
+}
+
+// sessionOverridesSnapshot returns a copy of the session overrides
+func (m *Manager) sessionOverridesSnapshot() map[string]interface{} {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	overrides := make(map[string]interface{}, len(m.SessionOverrides))
+	for key, value := range m.SessionOverrides {
+		overrides[key] = value
+	}
+	return overrides
+}
+
+// selectedMcpServers returns the MCP servers selected for the session
+func (m *Manager) selectedMcpServers() []config.McpServer {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.McpServers
+}
+
+// setMcpServers selects the MCP servers for the session along with their client
+func (m *Manager) setMcpServers(servers []config.McpServer, client *McpClient) {
+	m.stateMu.Lock()
+	m.McpServers = servers
+	m.McpClient = client
+	m.stateMu.Unlock()
+}
@@ -0,0 +1,60 @@
+// Unit tests for the guarded session state in state.go
+package internal
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Test: the chat, the TUI and the API can touch the state at the same time (run with -race)
+func TestStateConcurrentAccess(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig(), ExecPane: &system.TmuxPaneDetails{Id: "%1"}}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.SetStatus("running")
+				m.SetWatchMode(true)
+				m.appendMessages(ChatMessage{Content: fmt.Sprintf("%d-%d", i, j)})
+				m.setSessionOverride("max_capture_lines", j)
+				m.stopTurn()
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = m.statusSnapshot()
+				_ = m.stateSymbol()
+				_ = m.MessageHistory()
+				_ = m.GetMaxCaptureLines()
+				_ = m.sessionOverridesSnapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(m.MessageHistory()); got != 400 {
+		t.Errorf("expected 400 messages, got %d", got)
+	}
+	if m.GetStatus() != "" || m.GetWatchMode() {
+		t.Errorf("expected idle state, got %q watch=%v", m.GetStatus(), m.GetWatchMode())
+	}
+}
+
+// Test: MessageHistory returns a copy that later appends don't change
+func TestMessageHistoryIsCopy(t *testing.T) {
+	m := &Manager{}
+	m.appendMessages(ChatMessage{Content: "a"})
+	history := m.MessageHistory()
+	history[0].Content = "changed"
+	m.appendMessages(ChatMessage{Content: "b"})
+	if len(history) != 1 || m.Messages[0].Content != "a" {
+		t.Errorf("history not copied: %+v / %+v", history, m.Messages)
+	}
+}
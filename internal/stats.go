@@ -0,0 +1,258 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// sessionStats collects the numbers /stats shows. The zero value is ready to use, the
+// session starts with the first record.
+type sessionStats struct {
+	mu           sync.Mutex
+	started      time.Time
+	latencies    []time.Duration // of the model requests that succeeded
+	failed       int             // model requests that returned an error
+	inputTokens  int             // of the messages sent, as reported by the API or estimated
+	outputTokens int             // of the responses
+	approved     int             // confirmations answered yes
+	rejected     int             // confirmations answered no
+	tools        map[string]int  // MCP tool calls by server/tool
+	models       map[string]*modelUsage
+	last         *requestUsage // the last request that succeeded
+}
+
+// modelUsage adds up the requests sent to one model
+type modelUsage struct {
+	requests     int
+	inputTokens  int
+	outputTokens int
+	estimated    int // requests the API reported no usage for
+}
+
+// requestUsage is the token count of one request
+type requestUsage struct {
+	model        string
+	inputTokens  int
+	outputTokens int
+	estimated    bool
+}
+
+func (s *sessionStats) start() {
+	if s.started.IsZero() {
+		s.started = time.Now()
+	}
+}
+
+// recordRequest counts a model request with the token usage the API reported, estimated
+// from the text when it reported none
+func (s *sessionStats) recordRequest(latency time.Duration, model string, sending []ChatMessage, response string, usage *TokenUsage, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start()
+	if err != nil {
+		s.failed++
+		return
+	}
+	s.latencies = append(s.latencies, latency)
+	r := &requestUsage{model: model}
+	if usage != nil {
+		r.inputTokens, r.outputTokens = usage.PromptTokens, usage.CompletionTokens
+	} else {
+		r.estimated = true
+		for _, msg := range sending {
+			r.inputTokens += system.EstimateTokenCount(msg.Content)
+		}
+		r.outputTokens = system.EstimateTokenCount(response)
+	}
+	s.inputTokens += r.inputTokens
+	s.outputTokens += r.outputTokens
+	if s.models == nil {
+		s.models = map[string]*modelUsage{}
+	}
+	u := s.models[model]
+	if u == nil {
+		u = &modelUsage{}
+		s.models[model] = u
+	}
+	u.requests++
+	u.inputTokens += r.inputTokens
+	u.outputTokens += r.outputTokens
+	if r.estimated {
+		u.estimated++
+	}
+	s.last = r
+}
+
+// requests returns how many model requests were made, failed ones included
+func (s *sessionStats) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.latencies) + s.failed
+}
+
+// recordConfirmation counts the answer to a confirmation prompt
+func (s *sessionStats) recordConfirmation(approved bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start()
+	if approved {
+		s.approved++
+	} else {
+		s.rejected++
+	}
+}
+
+// recordToolCall counts a call of an MCP tool
+func (s *sessionStats) recordToolCall(server, tool string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start()
+	if s.tools == nil {
+		s.tools = map[string]int{}
+	}
+	s.tools[server+"/"+tool]++
+}
+
+// add counts the requests, confirmations and tool calls of o, e.g. of a finished sub-agent
+func (s *sessionStats) add(o *sessionStats) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o.started.IsZero() {
+		return
+	}
+	s.start()
+	s.latencies = append(s.latencies, o.latencies...)
+	s.failed += o.failed
+	s.inputTokens += o.inputTokens
+	s.outputTokens += o.outputTokens
+	s.approved += o.approved
+	s.rejected += o.rejected
+	for tool, n := range o.tools {
+		if s.tools == nil {
+			s.tools = map[string]int{}
+		}
+		s.tools[tool] += n
+	}
+	for model, ou := range o.models {
+		if s.models == nil {
+			s.models = map[string]*modelUsage{}
+		}
+		u := s.models[model]
+		if u == nil {
+			u = &modelUsage{}
+			s.models[model] = u
+		}
+		u.requests += ou.requests
+		u.inputTokens += ou.inputTokens
+		u.outputTokens += ou.outputTokens
+		u.estimated += ou.estimated
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// formatTokens shortens token counts like 45210 to 45.2k
+func formatTokens(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%.1fk", float64(n)/1000)
+}
+
+// statsLines summarizes the session for /stats
+func (m *Manager) statsLines() []string {
+	s := &m.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start()
+	theme := system.CurrentTheme()
+	label := func(text string) string { return theme.Label.Sprint(text) }
+	elapsed := time.Since(s.started)
+	hours := elapsed.Hours()
+
+	lines := []string{fmt.Sprintf("%s %s", label(i18n.T("Session:")), elapsed.Round(time.Second))}
+
+	executed := len(m.ExecutedCommands)
+	commands := i18n.T("%d executed, %d rejected", executed, s.rejected)
+	ok, failed := 0, 0
+	for _, c := range m.ExecutedCommands {
+		if c.Code == nil {
+			continue
+		}
+		if *c.Code == 0 {
+			ok++
+		} else {
+			failed++
+		}
+	}
+	if ok+failed > 0 {
+		commands += "; " + i18n.T("exit codes %d ok, %d failed (%d%% success)", ok, failed, ok*100/(ok+failed))
+	}
+	lines = append(lines, fmt.Sprintf("%s %s", label(i18n.T("Commands:")), commands))
+
+	requests := i18n.T("%d (%d failed)", len(s.latencies)+s.failed, s.failed)
+	if len(s.latencies) > 0 {
+		sorted := append([]time.Duration(nil), s.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		round := func(d time.Duration) time.Duration { return d.Round(100 * time.Millisecond) }
+		requests += ", " + i18n.T("latency p50 %s, p90 %s, p99 %s",
+			round(percentile(sorted, 50)), round(percentile(sorted, 90)), round(percentile(sorted, 99)))
+	}
+	lines = append(lines, fmt.Sprintf("%s %s", label(i18n.T("Requests:")), requests))
+
+	total := s.inputTokens + s.outputTokens
+	tokens := i18n.T("~%s in, ~%s out", formatTokens(s.inputTokens), formatTokens(s.outputTokens))
+	if hours > 0 {
+		tokens += ", " + i18n.T("~%s per hour", formatTokens(int(float64(total)/hours)))
+	}
+	if cost, priced := m.sessionCost(); priced {
+		tokens += ", " + i18n.T("cost ~$%.2f ($%.2f per hour)", cost, cost/hours)
+	}
+	lines = append(lines, fmt.Sprintf("%s %s", label(i18n.T("Tokens:")), tokens))
+
+	if len(s.tools) > 0 {
+		names := make([]string, 0, len(s.tools))
+		for name := range s.tools {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if s.tools[names[i]] != s.tools[names[j]] {
+				return s.tools[names[i]] > s.tools[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		if len(names) > 5 {
+			names = names[:5]
+		}
+		for i, name := range names {
+			names[i] = fmt.Sprintf("%s %d", name, s.tools[name])
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", label(i18n.T("MCP tools:")), strings.Join(names, ", ")))
+	}
+	return lines
+}
+
+// handleStatsCommand prints the session analytics
+func handleStatsCommand(m *Manager) {
+	for _, line := range m.statsLines() {
+		m.Println(line)
+	}
+}
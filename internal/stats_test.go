@@ -0,0 +1,88 @@
+// Unit tests for the session analytics in stats.go
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: nearest-rank percentiles of sorted latencies
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 10; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Second)
+	}
+	if p := percentile(sorted, 50); p != 5*time.Second {
+		t.Errorf("p50 = %s", p)
+	}
+	if p := percentile(sorted, 90); p != 9*time.Second {
+		t.Errorf("p90 = %s", p)
+	}
+	if p := percentile(sorted, 99); p != 10*time.Second {
+		t.Errorf("p99 = %s", p)
+	}
+	if p := percentile(nil, 50); p != 0 {
+		t.Errorf("p50 of nothing = %s", p)
+	}
+}
+
+// Test: the summary counts commands, exit codes, requests, cost and tools
+func TestStatsLines(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.OpenRouter.InputPrice = 1
+	zero, one := 0, 1
+	m := &Manager{Config: cfg, ExecutedCommands: []ExecutedCommand{{Code: &zero}, {Code: &zero}, {Code: &one}, {}}}
+	m.stats.started = time.Now().Add(-time.Hour)
+	m.stats.recordRequest(2*time.Second, "model", []ChatMessage{{Content: strings.Repeat("word ", 400)}}, "ok", nil, nil)
+	m.stats.recordRequest(time.Second, "model", nil, "", nil, errors.New("timeout"))
+	m.stats.recordConfirmation(true)
+	m.stats.recordConfirmation(false)
+	m.stats.recordToolCall("fs", "read")
+	m.stats.recordToolCall("gh", "search")
+	m.stats.recordToolCall("gh", "search")
+
+	out := strings.Join(m.statsLines(), "\n")
+	for _, want := range []string{
+		"4 executed, 1 rejected",
+		"exit codes 2 ok, 1 failed (66% success)",
+		"2 (1 failed), latency p50 2s",
+		"cost ~$0.00",
+		"gh/search 2, fs/read 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+// Test: reported usage is preferred over estimates and priced per model from OpenRouter's list
+func TestCostLines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"id":"cheap","pricing":{"prompt":"0.000001","completion":"0.000002"}},{"id":"odd","pricing":{}}]}`))
+	}))
+	defer server.Close()
+	cfg := config.DefaultConfig()
+	cfg.OpenRouter.APIKey = "key"
+	cfg.OpenRouter.BaseURL = server.URL
+	m := &Manager{Config: cfg}
+	m.stats.recordRequest(time.Second, "cheap", nil, "", &TokenUsage{PromptTokens: 1000000, CompletionTokens: 500000}, nil)
+	m.stats.recordRequest(time.Second, "odd", []ChatMessage{{Content: "hello"}}, "hi", nil, nil)
+
+	out := strings.Join(m.costLines(), "\n")
+	for _, want := range []string{
+		"cheap: 1 requests, 1000.0k in, 500.0k out, $2.0000",
+		"odd: 1 requests",
+		"Total: 1000.0k in, 500.0k out, $2.0000",
+		"Some token counts are estimated",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
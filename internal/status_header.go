@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// statusHeader is the one-line summary of the session shown in the top border of the
+// chat pane
+type statusHeader struct {
+	restore func()
+	last    string
+}
+
+// startStatusHeader shows the header when status_header is on; stopStatusHeader undoes it
+func (m *Manager) startStatusHeader() {
+	if !m.GetConfig().StatusHeader || m.PaneId == "" {
+		return
+	}
+	restore, err := system.TmuxShowPaneHeader(m.PaneId)
+	if err != nil {
+		logger.Error("Status header disabled: %v", err)
+		return
+	}
+	m.header = &statusHeader{restore: restore}
+	m.refreshStatusHeader()
+}
+
+func (m *Manager) stopStatusHeader() {
+	if m.header == nil {
+		return
+	}
+	m.header.restore()
+	m.header = nil
+}
+
+// refreshStatusHeader updates the header text, it runs once per turn
+func (m *Manager) refreshStatusHeader() {
+	if m.header == nil {
+		return
+	}
+	text := m.statusHeaderText()
+	if text == m.header.last {
+		return
+	}
+	if err := system.TmuxSetPaneTitle(m.PaneId, text); err != nil {
+		logger.Error("Failed to update status header: %v", err)
+		return
+	}
+	m.header.last = text
+}
+
+// statusHeaderText lists the model, context usage, agent state and the exec pane target
+func (m *Manager) statusHeaderText() string {
+	parts := []string{m.GetOpenRouterModel()}
+	if limit := m.GetMaxContextSize(); limit > 0 {
+		parts = append(parts, i18n.T("context %d%%", m.contextTokens()*100/limit))
+	}
+	if m.GetWatchMode() {
+		parts = append(parts, i18n.T("watching"))
+	}
+	if m.GetStatus() != "" {
+		parts = append(parts, i18n.T(m.GetStatus()))
+	}
+	if m.isReady() && m.ExecPane != nil && m.ExecPane.Id != "" {
+		target := i18n.T("exec %s", m.ExecPane.Id)
+		if cwd := m.execPaneCwd(); cwd != "" {
+			target += " " + shortenHome(cwd)
+		}
+		parts = append(parts, target)
+	}
+	return system.Sym(" " + strings.Join(parts, " │ ") + " ")
+}
+
+// shortenHome replaces the home directory at the start of path with ~
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rel, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~/" + rel
+	}
+	return path
+}
@@ -0,0 +1,36 @@
+// Unit tests for the chat pane header in status_header.go
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: the header lists the model, context usage and state
+func TestStatusHeaderText(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.OpenRouter.Model = "test-model"
+	cfg.MaxContextSize = 1000
+	m := &Manager{Config: cfg, status: "running", watchMode: true}
+
+	if got := m.statusHeaderText(); got != " test-model │ context 0% │ watching │ running " {
+		t.Errorf("unexpected header: %q", got)
+	}
+}
+
+// Test: paths under the home directory are shortened with ~
+func TestShortenHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	if got := shortenHome(filepath.Join(home, "src")); got != "~/src" {
+		t.Errorf("unexpected path: %q", got)
+	}
+	if got := shortenHome("/elsewhere"); got != "/elsewhere" {
+		t.Errorf("unexpected path: %q", got)
+	}
+}
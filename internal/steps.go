@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+type stepStatus int
+
+const (
+	stepRunning stepStatus = iota
+	stepDone
+	stepFailed
+	stepSent // sent to an unprepared pane, the exit code is unknown
+)
+
+// planStep is a command run or keys sent for a request
+type planStep struct {
+	Kind    string     `json:"kind,omitempty"` // "keys" for sent keys, empty for commands
+	Command string     `json:"command"`
+	Status  stepStatus `json:"status"`
+	Code    int        `json:"code,omitempty"`
+}
+
+// stepChecklist numbers the commands run for one request, so a multi-command plan
+// reads as a list of steps rather than a wall of output
+type stepChecklist struct {
+	Steps []planStep `json:"steps"`
+}
+
+// startStep adds a running command and prints the checklist so far
+func (m *Manager) startStep(command string) {
+	if m.steps == nil {
+		m.Println(i18n.T("Executing command: %s", command))
+		return
+	}
+	m.steps.Steps = append(m.steps.Steps, planStep{Command: command})
+	fmt.Fprint(m.out(), m.steps.render())
+}
+
+// keysStep records keys sent to the exec pane as a step
+func (m *Manager) keysStep(keys string) {
+	if m.steps == nil {
+		return
+	}
+	m.steps.Steps = append(m.steps.Steps, planStep{Kind: "keys", Command: keys, Status: stepSent})
+}
+
+// interrupt marks a step left running, whose end is unknown, as sent
+func (c *stepChecklist) interrupt() {
+	for i := range c.Steps {
+		if c.Steps[i].Status == stepRunning {
+			c.Steps[i].Status = stepSent
+		}
+	}
+}
+
+// finishStep records how the running command ended; code is nil when it is unknown
+func (m *Manager) finishStep(code *int) {
+	if m.steps == nil || len(m.steps.Steps) == 0 {
+		return
+	}
+	n := len(m.steps.Steps)
+	step := &m.steps.Steps[n-1]
+	theme := system.CurrentTheme()
+	switch {
+	case code == nil:
+		step.Status = stepSent
+	case *code == 0:
+		step.Status = stepDone
+		m.Println(theme.Success.Sprint(system.Sym(i18n.T("✓ Step %d done", n))))
+	default:
+		step.Status, step.Code = stepFailed, *code
+		m.Println(theme.Error.Sprint(system.Sym(i18n.T("✗ Step %d failed (exit %d)", n, *code))))
+	}
+}
+
+// render prints one line per step with its state
+func (c *stepChecklist) render() string {
+	theme := system.CurrentTheme()
+	var b strings.Builder
+	for i, step := range c.Steps {
+		var icon, suffix string
+		switch step.Status {
+		case stepRunning:
+			icon = theme.Highlight.Sprint(system.Sym("▶"))
+		case stepDone:
+			icon = theme.Success.Sprint(system.Sym("✓"))
+		case stepFailed:
+			icon = theme.Error.Sprint(system.Sym("✗"))
+			suffix = theme.Error.Sprint("  " + i18n.T("exit %d", step.Code))
+		case stepSent:
+			icon = theme.Neutral.Sprint(system.Sym("·"))
+		}
+		command := step.Command
+		if step.Kind == "keys" {
+			command = i18n.T("keys: %s", strings.ReplaceAll(command, "\n", " "))
+		}
+		fmt.Fprintf(&b, "  %s %d. %s%s\n", icon, i+1, command, suffix)
+	}
+	return b.String()
+}
@@ -0,0 +1,30 @@
+// Unit tests for the step checklist in steps.go
+package internal
+
+import (
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: steps are numbered in order and show running, done, failed and unknown results
+func TestStepChecklist(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig(), steps: &stepChecklist{}}
+	ok, failed := 0, 2
+
+	m.startStep("ls")
+	m.finishStep(&ok)
+	m.startStep("make test")
+	m.finishStep(&failed)
+	m.startStep("vim notes.txt")
+	m.finishStep(nil)
+	m.startStep("go vet ./...")
+
+	want := "  ✓ 1. ls\n" +
+		"  ✗ 2. make test  exit 2\n" +
+		"  · 3. vim notes.txt\n" +
+		"  ▶ 4. go vet ./...\n"
+	if got := m.steps.render(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/term"
+)
+
+// responseTagNames are the action tags of a response, they are not shown while streaming
+var responseTagNames = []string{
+	"TmuxSendKeys", "ExecCommand", "PasteMultilineContent", "RequestAccomplished",
+	"ExecPaneSeemsBusy", "WaitingForUserResponse", "NoComment", "McpToolCall", "WriteFile",
+	"PatchFile", "ReadFile", "FetchUrl", "PluginToolCall", "DelegateTask",
+}
+
+// liveResponse prints the message part of a streamed response while it arrives, word
+// wrapped to the terminal. Finish erases it again so the final message is printed once,
+// with markdown applied.
+type liveResponse struct {
+	out           io.Writer
+	width, height int
+	onStart       func() // runs before the first output, stops the spinner
+	// show receives the whole visible message instead of it being printed, for the
+	// full-screen interface; "" clears it
+	show func(text string)
+
+	raw    strings.Builder // response received so far
+	shown  int             // bytes of the visible message already printed
+	word   strings.Builder // word held back until we know whether it fits
+	column int
+	lines  int // line breaks printed
+	active bool
+}
+
+// newLiveResponse returns a renderer when streaming is on and the terminal can redraw
+// or the full-screen interface runs, nil otherwise
+func (m *Manager) newLiveResponse(progress *aiProgress) *liveResponse {
+	// a JSON mode response is only shown once it is validated
+	if !m.Config.OpenRouter.Stream || m.Config.OpenRouter.JSONMode || JSONEventsEnabled() {
+		return nil
+	}
+	if _, ok := m.AiClient.(StreamingProvider); !ok {
+		return nil
+	}
+	if m.tui != nil {
+		return &liveResponse{onStart: progress.Stop, show: m.tui.showLive}
+	}
+	if !system.CursorControl() {
+		return nil
+	}
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	return &liveResponse{out: m.out(), width: width, height: height, onStart: progress.Stop}
+}
+
+// Write receives the next piece of the response
+func (l *liveResponse) Write(delta string) {
+	l.raw.WriteString(delta)
+	visible := visibleMessage(l.raw.String())
+	if len(visible) <= l.shown {
+		return
+	}
+	text := visible[l.shown:]
+	l.shown = len(visible)
+	if !l.active {
+		text = strings.TrimLeft(text, " \t\r\n")
+		if text == "" {
+			return
+		}
+		l.onStart()
+		l.active = true
+	}
+	if l.show != nil {
+		l.show(strings.TrimLeft(visible, " \t\r\n"))
+		return
+	}
+
+	for _, r := range text {
+		switch r {
+		case '\n':
+			l.flushWord()
+			l.newline()
+		case ' ', '\t':
+			l.flushWord()
+			if l.column < l.width {
+				fmt.Fprint(l.out, " ")
+				l.column++
+			}
+		case '\r':
+		default:
+			l.word.WriteRune(r)
+		}
+	}
+}
+
+func (l *liveResponse) newline() {
+	fmt.Fprint(l.out, "\n")
+	l.column = 0
+	l.lines++
+}
+
+func (l *liveResponse) flushWord() {
+	word := l.word.String()
+	if word == "" {
+		return
+	}
+	l.word.Reset()
+	w := ansi.StringWidth(word)
+	if l.column > 0 && l.column+w > l.width {
+		l.newline()
+	}
+	fmt.Fprint(l.out, word)
+	// words longer than the terminal wrap on their own; a full line leaves the
+	// cursor on the last column until the next character
+	l.lines += (l.column + w - 1) / l.width
+	l.column = (l.column+w-1)%l.width + 1
+}
+
+// Finish ends the live output. It erases what was printed and returns false so the
+// caller prints the formatted message; when the text has scrolled past the top of the
+// screen it can't be erased, so it is kept and Finish returns true.
+func (l *liveResponse) Finish() (kept bool) {
+	if l == nil || !l.active {
+		return false
+	}
+	l.active = false
+	if l.show != nil {
+		l.show("")
+		return false
+	}
+	if l.lines >= l.height-1 {
+		l.flushWord()
+		fmt.Fprint(l.out, "\n")
+		return true
+	}
+	fmt.Fprint(l.out, "\r")
+	if l.lines > 0 {
+		fmt.Fprintf(l.out, "\033[%dA", l.lines)
+	}
+	fmt.Fprint(l.out, "\033[J")
+	return false
+}
+
+// visibleMessage returns the part of a partial response that can be shown: the text up
+// to the first action tag, or up to a '<' that may still turn into one
+func visibleMessage(raw string) string {
+	offset := 0
+	for {
+		i := strings.IndexByte(raw[offset:], '<')
+		if i < 0 {
+			return raw
+		}
+		i += offset
+		name := raw[i+1:]
+		complete := false
+		if end := strings.IndexAny(name, "> \n"); end >= 0 {
+			name, complete = name[:end], true
+		}
+		for _, tag := range responseTagNames {
+			if name == tag || !complete && strings.HasPrefix(tag, name) {
+				return raw[:i]
+			}
+		}
+		offset = i + 1
+	}
+}
@@ -0,0 +1,70 @@
+// Unit tests for live rendering of streamed responses in stream_render.go
+package internal
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Test: action tags and a trailing '<' that may start one are held back, other '<' are shown
+func TestVisibleMessage(t *testing.T) {
+	tests := map[string]string{
+		"Listing files":                          "Listing files",
+		"Run it\n<ExecCommand>ls</ExecCommand>":  "Run it\n",
+		"Run it <Exec":                           "Run it ",
+		"Run it <":                               "Run it ",
+		"if a < b and <div> then <NoComment>":    "if a < b and <div> then ",
+		"Compare <Examples> with <TmuxSendKeys>": "Compare <Examples> with ",
+	}
+	for raw, want := range tests {
+		if got := visibleMessage(raw); got != want {
+			t.Errorf("visibleMessage(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+// Test: deltas are word wrapped to the width and Finish moves back over the printed lines
+func TestLiveResponseWrapAndErase(t *testing.T) {
+	var out bytes.Buffer
+	started := false
+	l := &liveResponse{out: &out, width: 10, height: 24, onStart: func() { started = true }}
+
+	for _, delta := range []string{"\n hello wo", "rld again", " and more<Exec", "Command>ls</ExecCommand>"} {
+		l.Write(delta)
+	}
+	if !started {
+		t.Fatal("onStart was not called")
+	}
+	if got, want := out.String(), "hello \nworld \nagain and "; got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+
+	out.Reset()
+	if kept := l.Finish(); kept {
+		t.Error("short output should be erased")
+	}
+	if got, want := out.String(), "\r\033[2A\033[J"; got != want {
+		t.Errorf("erase sequence %q, want %q", got, want)
+	}
+}
+
+// Test: with a show sink the whole visible message is handed over and cleared on Finish
+func TestLiveResponseShow(t *testing.T) {
+	var shown []string
+	l := &liveResponse{onStart: func() {}, show: func(text string) { shown = append(shown, text) }}
+	for _, delta := range []string{"\n Checking", " the logs", "<ExecCommand>tail log</ExecCommand>"} {
+		l.Write(delta)
+	}
+	if kept := l.Finish(); kept {
+		t.Error("the streamed text should be replaced by the formatted message")
+	}
+	want := []string{"Checking", "Checking the logs", ""}
+	if len(shown) != len(want) {
+		t.Fatalf("shown %q, want %q", shown, want)
+	}
+	for i := range want {
+		if shown[i] != want[i] {
+			t.Errorf("shown %q, want %q", shown, want)
+		}
+	}
+}
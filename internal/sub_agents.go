@@ -0,0 +1,298 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// Statuses of a sub-agent
+const (
+	agentRunning = "running"
+	agentDone    = "done"
+	agentFailed  = "failed" // stopped without marking its task accomplished
+	agentStopped = "stopped"
+)
+
+const agentsUsage = "Usage: /agents [stop <n>]"
+
+const subAgentPrompt = `You are a sub-agent working on one part of a larger request, in an exec pane of your own while other agents work in theirs.
+Your task: %s
+
+Work only on this task and mark the request accomplished when it is done. Your final message is reported back to the main agent, so make it a short summary of the outcome.`
+
+// subAgent works on a delegated subtask with its own conversation in its own exec pane;
+// its result is added to the main chat when it finishes
+type subAgent struct {
+	ID       int
+	Task     string
+	Pane     string
+	Started  time.Time
+	manager  *Manager
+	cancel   context.CancelFunc
+	status   string // guarded by the parent's subAgentsMu, like result
+	result   string
+	finished time.Time
+}
+
+// runningSubAgents counts the sub-agents still at work
+func (m *Manager) runningSubAgents() int {
+	n := 0
+	for _, a := range m.subAgents {
+		if a.status == agentRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// spawnSubAgent splits a pane off the exec pane and starts a sub-agent on task there.
+// Sub-agents can't ask anything: confirmations follow sub_agents.policy like a headless
+// run, so by default only whitelisted commands run.
+func (m *Manager) spawnSubAgent(task string) (*subAgent, error) {
+	if m.agentLabel != "" {
+		return nil, fmt.Errorf("sub-agents can't delegate")
+	}
+	cfg := m.Config.SubAgents
+	policy := &CIPolicy{UseWhitelist: true}
+	if cfg.Policy != "" {
+		var err error
+		if policy, err = LoadCIPolicy(cfg.Policy); err != nil {
+			return nil, err
+		}
+	}
+
+	m.subAgentsMu.Lock()
+	defer m.subAgentsMu.Unlock()
+	if cfg.Max > 0 && m.runningSubAgents() >= cfg.Max {
+		return nil, fmt.Errorf("%d sub-agents are running already, the most sub_agents.max allows", cfg.Max)
+	}
+
+	id := len(m.subAgents) + 1
+	sub, err := m.newSubAgentManager(id, policy)
+	if err != nil {
+		return nil, err
+	}
+	pane, err := system.TmuxCreateNewPane(m.ExecPane.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a pane: %w", err)
+	}
+	panes, err := system.TmuxPanesDetails(pane)
+	if err != nil || len(panes) == 0 {
+		return nil, fmt.Errorf("failed to read pane %s: %v", pane, err)
+	}
+	system.TmuxSetPaneTitle(pane, fmt.Sprintf("tmuxai agent %d", id))
+	sub.ExecPane = &panes[0]
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 600 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	a := &subAgent{ID: id, Task: task, Pane: pane, Started: time.Now(), manager: sub, cancel: cancel, status: agentRunning}
+	m.subAgents = append(m.subAgents, a)
+	go m.runSubAgent(ctx, a)
+	return a, nil
+}
+
+// newSubAgentManager creates the manager of a sub-agent, without its exec pane yet. It has
+// its own copy of the config with every confirmation on, which the policy then decides, and
+// its own provider, so the token usage of its requests isn't mixed up with the main agent's.
+func (m *Manager) newSubAgentManager(id int, policy *CIPolicy) (*Manager, error) {
+	cfg := *m.Config
+	cfg.ExecConfirm = true
+	cfg.SendKeysConfirm = true
+	cfg.PasteMultilineConfirm = true
+
+	provider, err := NewChatProvider(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	sub := NewManagerForPane(&cfg, m.PaneId, provider)
+	sub.Plugins = m.Plugins
+	sub.Output = m.Output
+	sub.agentLabel = fmt.Sprintf(" agent %d", id)
+	sub.ConfirmFunc = func(content, prompt string) (bool, string) {
+		approved, rule := policy.decide(sub, content, prompt)
+		verdict := "refused"
+		if approved {
+			verdict = "approved"
+		}
+		sub.Println(i18n.T("Policy %s: %s (%s)", verdict, content, rule))
+		return approved, content
+	}
+	return sub, nil
+}
+
+// runSubAgent works through a sub-agent's task and merges its result into the main chat
+func (m *Manager) runSubAgent(ctx context.Context, a *subAgent) {
+	sub := a.manager
+	defer sub.recoverPanic()
+	defer a.cancel()
+	go func() {
+		// stop waiting for the pane once cancelled or timed out
+		<-ctx.Done()
+		sub.SetStatus("")
+	}()
+
+	// the shell of the new pane needs a moment to start
+	_ = sleepContext(ctx, time.Second)
+	sub.PrepareExecPane()
+	task := sub.newTask(a.Task)
+	sub.SetStatus("running")
+	sub.steps = &task.Steps
+	accomplished := sub.ProcessUserMessage(ctx, fmt.Sprintf(subAgentPrompt, a.Task))
+	sub.steps = nil
+	task.finish(ctx.Err() != nil, accomplished)
+	sub.SetStatus("")
+
+	m.subAgentsMu.Lock()
+	switch {
+	case ctx.Err() == context.Canceled:
+		a.status = agentStopped
+	case accomplished:
+		a.status = agentDone
+	default:
+		a.status = agentFailed
+	}
+	a.result = strings.TrimSpace(sub.lastAIMessage)
+	a.finished = time.Now()
+	status := a.status
+	m.subAgentsMu.Unlock()
+	// the requests of the sub-agent count towards the session's usage and cost
+	m.stats.add(&sub.stats)
+
+	logger.Info("Sub-agent %d %s: %s", a.ID, status, a.Task)
+	// a running turn may squash the history, the report is added once it is over
+	m.turnMu.Lock()
+	m.appendMessages(ChatMessage{Content: subAgentReport(a, status, task), FromUser: false, Timestamp: time.Now()})
+	m.turnMu.Unlock()
+	summary, _, _ := strings.Cut(a.result, "\n")
+	m.Println(i18n.T("Sub-agent %d %s: %s", a.ID, status, a.Task))
+	if summary != "" {
+		m.Println("  " + summary)
+	}
+	m.notify(NotifyTask, i18n.T("TmuxAI sub-agent %d %s", a.ID, status), a.Task)
+}
+
+// subAgentReport is the result of a sub-agent as it is added to the main conversation
+func subAgentReport(a *subAgent, status string, task *requestTask) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Sub-agent %d (pane %s) %s its task: %s\n", a.ID, a.Pane, status, a.Task)
+	if len(task.Steps.Steps) > 0 {
+		b.WriteString("Steps:\n")
+		for i, step := range task.Steps.Steps {
+			result := "sent"
+			switch step.Status {
+			case stepDone:
+				result = "done"
+			case stepFailed:
+				result = fmt.Sprintf("exit code %d", step.Code)
+			}
+			fmt.Fprintf(&b, "%d. %s (%s)\n", i+1, step.Command, result)
+		}
+	}
+	if a.result != "" {
+		fmt.Fprintf(&b, "Its final message:\n%s\n", a.result)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// delegateForModel starts a sub-agent on a subtask the AI delegated, once approved, and
+// tells the AI it started. It returns false when the user declined, which ends the turn.
+func (m *Manager) delegateForModel(task string) bool {
+	approved := true
+	if m.confirmRequired("", m.GetExecConfirm()) {
+		approved, _ = m.confirmAction(task, confirmDelegatePrompt, false, "")
+		emitConfirmation(confirmDelegatePrompt, task, approved)
+		m.stats.recordConfirmation(approved)
+	}
+	if !approved {
+		return false
+	}
+	a, err := m.spawnSubAgent(task)
+	if err != nil {
+		m.tellModel(fmt.Sprintf("DelegateTask %q failed: %v", task, err))
+		return true
+	}
+	m.tellModel(fmt.Sprintf("Sub-agent %d started in pane %s on: %s. Its result is added to the conversation when it finishes.", a.ID, a.Pane, task))
+	return true
+}
+
+// handleDelegateCommand starts a sub-agent on a task given by the user
+func handleDelegateCommand(m *Manager, task string) {
+	if task == "" {
+		m.Println(i18n.T("Usage: /delegate <task>"))
+		return
+	}
+	a, err := m.spawnSubAgent(task)
+	if err != nil {
+		m.Println(i18n.T("Failed to start a sub-agent: %v", err))
+		return
+	}
+	m.Println(i18n.T("Sub-agent %d started in pane %s, /agents shows how it is doing", a.ID, a.Pane))
+}
+
+// handleAgentsCommand lists the sub-agents or stops one
+func handleAgentsCommand(m *Manager, args []string) {
+	if len(args) == 0 {
+		m.printSubAgents()
+		return
+	}
+	if len(args) != 2 || !strings.EqualFold(args[0], "stop") {
+		m.Println(i18n.T(agentsUsage))
+		return
+	}
+	id, err := strconv.Atoi(args[1])
+	m.subAgentsMu.Lock()
+	defer m.subAgentsMu.Unlock()
+	if err != nil || id < 1 || id > len(m.subAgents) {
+		m.Println(i18n.T(agentsUsage))
+		return
+	}
+	a := m.subAgents[id-1]
+	if a.status != agentRunning {
+		m.Println(i18n.T("Sub-agent %d is not running", id))
+		return
+	}
+	a.cancel()
+	m.Println(i18n.T("Stopping sub-agent %d", id))
+}
+
+// printSubAgents lists the sub-agents with their status and result
+func (m *Manager) printSubAgents() {
+	m.subAgentsMu.Lock()
+	defer m.subAgentsMu.Unlock()
+	if len(m.subAgents) == 0 {
+		m.Println(i18n.T("No sub-agents yet, start one with /delegate <task>"))
+		return
+	}
+	theme := system.CurrentTheme()
+	var b strings.Builder
+	for _, a := range m.subAgents {
+		var icon string
+		elapsed := time.Since(a.Started)
+		switch a.status {
+		case agentRunning:
+			icon = theme.Highlight.Sprint(system.Sym("▶"))
+		case agentDone:
+			icon = theme.Success.Sprint(system.Sym("✓"))
+			elapsed = a.finished.Sub(a.Started)
+		default:
+			icon = theme.Error.Sprint(system.Sym("✗"))
+			elapsed = a.finished.Sub(a.Started)
+		}
+		fmt.Fprintf(&b, "  %s %d. %s %s\n", icon, a.ID, a.Task,
+			theme.Muted.Sprint(fmt.Sprintf("(%s, pane %s, %s)", a.status, a.Pane, formatElapsed(elapsed))))
+		if summary, _, _ := strings.Cut(a.result, "\n"); summary != "" {
+			fmt.Fprintf(&b, "       %s\n", theme.Muted.Sprint(summary))
+		}
+	}
+	fmt.Fprint(m.out(), b.String())
+}
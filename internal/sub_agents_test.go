@@ -0,0 +1,104 @@
+// Unit tests for sub-agents in sub_agents.go
+package internal
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: no sub-agent is started beyond sub_agents.max, nor by a sub-agent itself
+func TestSpawnSubAgentRefused(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SubAgents.Max = 1
+	m := &Manager{Config: cfg, subAgents: []*subAgent{
+		{ID: 1, status: agentDone},
+		{ID: 2, status: agentRunning},
+	}}
+	if _, err := m.spawnSubAgent("fix lint"); err == nil || !strings.Contains(err.Error(), "sub_agents.max") {
+		t.Errorf("expected the limit to refuse a sub-agent, got %v", err)
+	}
+
+	sub := &Manager{Config: config.DefaultConfig(), agentLabel: " agent 2"}
+	if _, err := sub.spawnSubAgent("run the tests"); err == nil {
+		t.Error("expected a sub-agent not to delegate")
+	}
+}
+
+// Test: a sub-agent confirms everything through its policy, whatever the main config says
+func TestNewSubAgentManager(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ExecConfirm = false
+	cfg.SendKeysConfirm = false
+	cfg.WhitelistPatterns = []string{`^ls\b`}
+	cfg.Provider = "mock"
+	m := &Manager{Config: cfg, PaneId: "%1"}
+	m.AiClient, _ = NewMockProvider(cfg.Mock)
+
+	sub, err := m.newSubAgentManager(2, &CIPolicy{UseWhitelist: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sub.AiClient == nil || sub.AiClient == m.AiClient {
+		t.Error("expected the sub-agent to have a provider of its own")
+	}
+	if !sub.GetExecConfirm() || !sub.GetSendKeysConfirm() || !sub.GetPasteMultilineConfirm() {
+		t.Error("expected the sub-agent to confirm every action")
+	}
+	if cfg.ExecConfirm || cfg.SendKeysConfirm {
+		t.Error("expected the main config to be left alone")
+	}
+	if approved, _ := sub.ConfirmFunc("ls -la", confirmExecPrompt); !approved {
+		t.Error("expected a whitelisted command to be approved")
+	}
+	if approved, _ := sub.ConfirmFunc("rm -rf build", confirmExecPrompt); approved {
+		t.Error("expected a command off the whitelist to be refused")
+	}
+}
+
+// Test: the requests of a finished sub-agent are added to the main agent's usage
+func TestSubAgentStatsAdded(t *testing.T) {
+	var m, sub sessionStats
+	m.recordRequest(time.Second, "model-a", nil, "", &TokenUsage{PromptTokens: 100, CompletionTokens: 10}, nil)
+	sub.recordRequest(time.Second, "model-a", nil, "", &TokenUsage{PromptTokens: 50, CompletionTokens: 5}, nil)
+	sub.recordRequest(time.Second, "model-b", nil, "", nil, errors.New("timeout"))
+	sub.recordConfirmation(false)
+
+	m.add(&sub)
+	if m.requests() != 3 || m.inputTokens != 150 || m.outputTokens != 15 || m.rejected != 1 {
+		t.Errorf("unexpected stats: %d requests, %d in, %d out, %d rejected", m.requests(), m.inputTokens, m.outputTokens, m.rejected)
+	}
+	if u := m.models["model-a"]; u.requests != 2 || u.inputTokens != 150 {
+		t.Errorf("unexpected usage of model-a: %+v", u)
+	}
+	m.add(&sessionStats{})
+	if m.requests() != 3 {
+		t.Errorf("expected empty stats to add nothing, got %d requests", m.requests())
+	}
+}
+
+// Test: the report added to the main chat has the task, the steps and the final message
+func TestSubAgentReport(t *testing.T) {
+	task := &requestTask{Request: "run the tests"}
+	failed := 1
+	task.Steps.Steps = []planStep{
+		{Command: "go vet ./...", Status: stepDone},
+		{Command: "go test ./...", Status: stepFailed, Code: failed},
+	}
+	a := &subAgent{ID: 2, Task: "run the tests", Pane: "%5", result: "TestParse fails in parser_test.go"}
+
+	report := subAgentReport(a, agentFailed, task)
+	for _, want := range []string{
+		"Sub-agent 2 (pane %5) failed its task: run the tests",
+		"1. go vet ./... (done)",
+		"2. go test ./... (exit code 1)",
+		"TestParse fails in parser_test.go",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected %q in\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/nyaosorg/go-readline-ny"
+)
+
+// suggestContextSize is how many recent commands and inputs the model is shown
+const suggestContextSize = 10
+
+// suggestMaxAnswers caps the model answers kept for the session
+const suggestMaxAnswers = 50
+
+const suggestSystemPrompt = `You complete the line a user is typing into TmuxAI, an assistant that runs commands in their tmux panes. ` +
+	`The line is either a request in plain words, a shell command or a /command. ` +
+	`Reply with the completed line only, starting with exactly what was typed, on one line, without quotes or explanations.`
+
+// suggester predicts the rest of the chat input line for the ghost text. The input
+// history and the commands run this session are searched on every keystroke; the
+// model is asked only after a pause in typing and its answers are kept, so a
+// suggestion it made keeps showing while the user types along.
+type suggester struct {
+	m        *Manager
+	history  readline.IHistory
+	debounce time.Duration
+	ask      func(ctx context.Context, prompt string) (string, error) // nil for history only
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	cancel  context.CancelFunc
+	answers []string // completed lines from the model, newest last
+}
+
+func newSuggester(m *Manager, history readline.IHistory) *suggester {
+	current := m.GetConfig()
+	cfg := current.Suggestions
+	s := &suggester{m: m, history: history, debounce: time.Duration(cfg.Debounce) * time.Millisecond}
+	if cfg.Model != "" && current.Provider != "mock" {
+		s.ask = suggestionModel(cfg, current.Endpoint())
+	}
+	return s
+}
+
+// suggestionModel asks the small model of the suggestions config, falling back to the
+// endpoint and key of the selected provider. Unlike AiClient it writes no debug dumps, a dump per
+// pause in typing would bury the ones of real requests.
+func suggestionModel(cfg config.SuggestionsConfig, fallback config.OpenRouterConfig) func(context.Context, string) (string, error) {
+	var once sync.Once
+	var chatModel model.ToolCallingChatModel
+	var initErr error
+	return func(ctx context.Context, prompt string) (string, error) {
+		once.Do(func() {
+			baseURL, apiKey := cfg.BaseURL, cfg.APIKey
+			if baseURL == "" {
+				baseURL = fallback.BaseURL
+			}
+			if apiKey == "" {
+				apiKey = fallback.APIKey
+			}
+			maxTokens := 64
+			var temperature float32
+			chatModel, initErr = openai.NewChatModel(context.Background(), &openai.ChatModelConfig{
+				APIKey:      apiKey,
+				BaseURL:     baseURL,
+				Model:       cfg.Model,
+				MaxTokens:   &maxTokens,
+				Temperature: &temperature,
+				HTTPClient:  system.HTTPClient(0),
+			})
+		})
+		if initErr != nil {
+			return "", fmt.Errorf("failed to create suggestion model: %w", initErr)
+		}
+		response, err := chatModel.Generate(ctx, []*schema.Message{
+			schema.SystemMessage(suggestSystemPrompt),
+			schema.UserMessage(prompt),
+		})
+		if err != nil {
+			return "", err
+		}
+		return response.Content, nil
+	}
+}
+
+// predict returns the rest of the line to show after text, asking the model in the
+// background when nothing is known yet
+func (s *suggester) predict(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	if rest := s.lookup(text); rest != "" {
+		return rest
+	}
+	if s.ask != nil {
+		s.schedule(text)
+	}
+	return ""
+}
+
+// lookup returns the rest of the newest known line starting with text
+func (s *suggester) lookup(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+	for _, line := range s.candidates() {
+		if len(line) > len(text) && strings.HasPrefix(line, text) && !strings.Contains(line, "\n") {
+			return line[len(text):]
+		}
+	}
+	return ""
+}
+
+// candidates lists the lines to complete from, best first: what the user typed
+// before, the slash commands, the commands run this session and the model's answers
+func (s *suggester) candidates() []string {
+	var lines []string
+	if s.history != nil {
+		for i := s.history.Len() - 1; i >= 0; i-- {
+			lines = append(lines, s.history.At(i))
+		}
+	}
+	lines = append(lines, commands...)
+	for i := len(s.m.ExecutedCommands) - 1; i >= 0; i-- {
+		lines = append(lines, s.m.ExecutedCommands[i].Command)
+	}
+	for i := len(s.m.ExecHistory) - 1; i >= 0; i-- {
+		lines = append(lines, s.m.ExecHistory[i].Command)
+	}
+	s.mu.Lock()
+	for i := len(s.answers) - 1; i >= 0; i-- {
+		lines = append(lines, s.answers[i])
+	}
+	s.mu.Unlock()
+	return lines
+}
+
+// schedule asks the model to complete text once the user stops typing; a keystroke
+// meanwhile restarts the wait and cancels a request already sent
+func (s *suggester) schedule(text string) {
+	prompt := s.prompt(text)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	s.cancel = cancel
+	s.timer = time.AfterFunc(s.debounce, func() {
+		defer cancel()
+		answer, err := s.ask(ctx, prompt)
+		if err != nil {
+			logger.Debug("Suggestion request failed: %v", err)
+			return
+		}
+		line := strings.Trim(strings.SplitN(strings.TrimSpace(answer), "\n", 2)[0], "`")
+		if len(line) <= len(text) || !strings.HasPrefix(line, text) {
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.answers = append(s.answers, line)
+		if len(s.answers) > suggestMaxAnswers {
+			s.answers = s.answers[len(s.answers)-suggestMaxAnswers:]
+		}
+	})
+}
+
+// prompt shows the model the recent commands and inputs along with the typed text
+func (s *suggester) prompt(text string) string {
+	var b strings.Builder
+	var recent []string
+	for i := len(s.m.ExecutedCommands) - 1; i >= 0 && len(recent) < suggestContextSize; i-- {
+		recent = append(recent, s.m.ExecutedCommands[i].Command)
+	}
+	if len(recent) > 0 {
+		b.WriteString("Commands run recently, newest first:\n")
+		for _, c := range recent {
+			fmt.Fprintf(&b, "%s\n", c)
+		}
+		b.WriteString("\n")
+	}
+	if s.history != nil && s.history.Len() > 0 {
+		b.WriteString("Lines the user typed before, newest first:\n")
+		for i := s.history.Len() - 1; i >= 0 && i >= s.history.Len()-suggestContextSize; i-- {
+			fmt.Fprintf(&b, "%s\n", s.history.At(i))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Complete this line:\n%s", text)
+	return b.String()
+}
+
+// acceptCommand is bound to Tab: it takes the ghost text when one is shown and
+// completes as before otherwise
+func (s *suggester) acceptCommand(fallback readline.Command) readline.Command {
+	return readline.NewGoCommand("ACCEPT_SUGGESTION_OR_COMPLETE", func(ctx context.Context, B *readline.Buffer) readline.Result {
+		if B.Cursor == len(B.Buffer) {
+			if rest := s.lookup(B.String()); rest != "" {
+				B.InsertAndRepaint(rest)
+				return readline.CONTINUE
+			}
+		}
+		return fallback.Call(ctx, B)
+	})
+}
@@ -0,0 +1,58 @@
+// Unit tests for the ghost-text suggestions in suggest.go
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: the history comes before slash commands and commands run this session
+func TestSuggesterLookup(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig(), ExecutedCommands: []ExecutedCommand{{Command: "git status"}, {Command: "git log --oneline"}}}
+	s := newSuggester(m, &inputHistory{lines: []string{"git stash", "multi\nline", "/help"}})
+
+	tests := map[string]string{
+		"git st":    "ash",
+		"git l":     "og --oneline",
+		"/he":       "lp",
+		"/squ":      "ash",
+		"multi":     "",
+		"git stash": "",
+		"   ":       "",
+	}
+	for text, want := range tests {
+		if got := s.predict(text); got != want {
+			t.Errorf("predict(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+// Test: the model is asked once after a pause and its answer is kept while typing along
+func TestSuggesterModel(t *testing.T) {
+	m := &Manager{Config: config.DefaultConfig()}
+	s := newSuggester(m, nil)
+	asked := make(chan string, 10)
+	s.ask = func(ctx context.Context, prompt string) (string, error) {
+		asked <- prompt
+		return "`docker compose logs -f web`\n", nil
+	}
+	s.debounce = 10 * time.Millisecond
+
+	s.predict("docker")
+	s.predict("docker co")
+	select {
+	case <-asked:
+	case <-time.After(time.Second):
+		t.Fatal("model not asked")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(asked) != 0 {
+		t.Error("model asked for every keystroke")
+	}
+	if got := s.predict("docker compose l"); got != "ogs -f web" {
+		t.Errorf("unexpected suggestion %q", got)
+	}
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// tasksContext lists the task runner targets of the exec pane's cwd so the model
+// prefers the project's own entry points; empty when there are none
+func (m *Manager) tasksContext() string {
+	cwd := m.execPaneCwd()
+	if cwd == "" {
+		return ""
+	}
+	runners := system.DiscoverTaskRunners(cwd)
+	if len(runners) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<project_tasks cwd=\"%s\">\n", cwd))
+	sb.WriteString("Prefer these project entry points over ad-hoc commands when they fit the task:\n")
+	for _, runner := range runners {
+		sb.WriteString(fmt.Sprintf("%s: ", runner.File))
+		commands := make([]string, len(runner.Targets))
+		for i, target := range runner.Targets {
+			commands[i] = runner.Command(target)
+		}
+		sb.WriteString(strings.Join(commands, ", "))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("</project_tasks>\n")
+	return sb.String()
+}
+
+// handleTaskTargetsCommand prints the task runner targets found in the exec pane's cwd
+func handleTaskTargetsCommand(m *Manager) {
+	cwd := m.execPaneCwd()
+	if cwd == "" {
+		m.Println(i18n.T("Could not determine exec pane working directory"))
+		return
+	}
+	runners := system.DiscoverTaskRunners(cwd)
+	if len(runners) == 0 {
+		m.Println(i18n.T("No Makefile, justfile or package.json scripts found in %s", cwd))
+		return
+	}
+
+	formatter := system.NewInfoFormatter()
+	for _, runner := range runners {
+		fmt.Fprintln(m.out(), formatter.FormatSection(fmt.Sprintf("%s (%s)", runner.File, runner.Tool)))
+		for _, target := range runner.Targets {
+			fmt.Fprintln(m.out(), "  "+runner.Command(target))
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+	"golang.org/x/term"
+)
+
+// telemetryEndpoint receives the reports, replaced in tests
+var telemetryEndpoint = "https://tmuxai.dev/api/telemetry"
+
+// telemetryConsent is the answer to the first-run question, kept in telemetry.json in
+// the config dir
+type telemetryConsent struct {
+	Enabled bool   `json:"enabled"`
+	ID      string `json:"id,omitempty"` // random, identifies an install and nothing else
+}
+
+// TelemetryReport is everything telemetry sends, once when the session ends. It holds
+// counts only, never messages, commands, pane content or paths.
+type TelemetryReport struct {
+	ID        string         `json:"id"`
+	Version   string         `json:"version"`
+	OS        string         `json:"os"`
+	Arch      string         `json:"arch"`
+	Provider  string         `json:"provider"`  // openrouter, openai, anthropic, azure, gemini or mock, not the model or URL
+	Interface string         `json:"interface"` // readline or tui
+	Minutes   int            `json:"minutes"`   // session length
+	Commands  map[string]int `json:"commands"`  // built-in slash commands by name
+	Events    map[string]int `json:"events"`    // user_message, exec, tool_call, ... by type
+}
+
+// telemetry counts the usage of a session while the user opted in
+type telemetry struct {
+	mu       sync.Mutex
+	consent  telemetryConsent
+	started  time.Time
+	commands map[string]int
+	events   map[string]int
+	// stopEvents removes the event listener counting events, set with the manager's stateMu held
+	stopEvents func()
+}
+
+func newTelemetry(consent telemetryConsent) *telemetry {
+	return &telemetry{consent: consent, started: time.Now(), commands: map[string]int{}, events: map[string]int{}}
+}
+
+func telemetryPath() string {
+	return config.GetConfigFilePath("telemetry.json")
+}
+
+// telemetryForcedOff reports whether the environment turns telemetry off whatever the
+// user answered, for CI and shared machines
+func telemetryForcedOff() bool {
+	if os.Getenv("DO_NOT_TRACK") != "" && os.Getenv("DO_NOT_TRACK") != "0" {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("TMUXAI_TELEMETRY")) {
+	case "0", "false", "off":
+		return true
+	}
+	return false
+}
+
+// loadTelemetryConsent reads the saved answer, ok is false when the user was never asked
+func loadTelemetryConsent(path string) (consent telemetryConsent, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return consent, false
+	}
+	return consent, json.Unmarshal(data, &consent) == nil
+}
+
+// saveTelemetryConsent stores the answer, giving the install an id on opting in
+func saveTelemetryConsent(path string, enabled bool) (telemetryConsent, error) {
+	consent, _ := loadTelemetryConsent(path)
+	consent.Enabled = enabled
+	if enabled && consent.ID == "" {
+		id := make([]byte, 16)
+		rand.Read(id)
+		consent.ID = hex.EncodeToString(id)
+	}
+	data, err := json.Marshal(consent)
+	if err != nil {
+		return consent, err
+	}
+	return consent, os.WriteFile(path, data, 0o644)
+}
+
+// startTelemetry asks for consent on the first interactive run and starts counting
+// when the user opted in. Nothing is counted or sent otherwise.
+func (m *Manager) startTelemetry() {
+	if telemetryForcedOff() {
+		return
+	}
+	path := telemetryPath()
+	consent, asked := loadTelemetryConsent(path)
+	if !asked {
+		if JSONEventsEnabled() || m.ConfirmFunc != nil || !term.IsTerminal(int(os.Stdin.Fd())) {
+			return
+		}
+		answer, err := m.readLine(i18n.T("Help improve TmuxAI by sending anonymous usage counts (version, provider, how often each command is used, never any content)? Change it any time with /config set telemetry. [y/N] "), "")
+		if err != nil {
+			return
+		}
+		enabled := strings.EqualFold(strings.TrimSpace(answer), "y") || strings.EqualFold(strings.TrimSpace(answer), "yes")
+		if consent, err = saveTelemetryConsent(path, enabled); err != nil {
+			logger.Error("Failed to save the telemetry choice: %v", err)
+		}
+	}
+	m.setTelemetry(consent)
+}
+
+// setTelemetry starts or stops counting for the rest of the session
+func (m *Manager) setTelemetry(consent telemetryConsent) {
+	m.stateMu.Lock()
+	if !consent.Enabled {
+		t := m.telemetry
+		m.telemetry = nil
+		m.stateMu.Unlock()
+		// removed without stateMu held, since listeners run with the event lock held
+		if t != nil {
+			t.stopEvents()
+		}
+		return
+	}
+	defer m.stateMu.Unlock()
+	if m.telemetry == nil {
+		m.telemetry = newTelemetry(consent)
+		t := m.telemetry
+		t.stopEvents = AddEventListener(func(e Event) { t.count(t.events, e.Type) })
+	}
+}
+
+func (m *Manager) getTelemetry() *telemetry {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.telemetry
+}
+
+func (t *telemetry) count(counts map[string]int, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts[name]++
+}
+
+// countCommand counts a built-in slash command by its full name; script commands and
+// typos are counted as "other" since they may say something about the user
+func (m *Manager) countCommand(commandPrefix string) {
+	t := m.getTelemetry()
+	if t == nil {
+		return
+	}
+	name := "other"
+	for _, c := range commands {
+		if prefixMatch(commandPrefix, c) {
+			name = c
+			break
+		}
+	}
+	t.count(t.commands, name)
+}
+
+// report is what the session sends
+func (t *telemetry) report(cfg *config.Config) TelemetryReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	provider := cfg.Provider
+	if provider == "" || (provider != "mock" && !config.IsProvider(provider)) {
+		provider = "openrouter"
+	}
+	r := TelemetryReport{
+		ID:        t.consent.ID,
+		Version:   Version,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Provider:  provider,
+		Interface: cfg.Interface,
+		Minutes:   int(time.Since(t.started).Minutes()),
+		Commands:  map[string]int{},
+		Events:    map[string]int{},
+	}
+	for k, v := range t.commands {
+		r.Commands[k] = v
+	}
+	for k, v := range t.events {
+		r.Events[k] = v
+	}
+	return r
+}
+
+// sendTelemetry posts the report of the session, giving up after a few seconds so
+// exiting is never held up
+func (m *Manager) sendTelemetry() {
+	t := m.getTelemetry()
+	if t == nil || telemetryForcedOff() {
+		return
+	}
+	data, err := json.Marshal(t.report(m.GetConfig()))
+	if err != nil {
+		return
+	}
+	logger.Debug("Sending telemetry: %s", data)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, telemetryEndpoint, bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := system.HTTPClient(0).Do(req)
+	if err != nil {
+		logger.Debug("Telemetry not sent: %v", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// setTelemetryConsent handles /config set telemetry: the choice is saved for the next
+// sessions too
+func (m *Manager) setTelemetryConsent(value string) error {
+	var enabled bool
+	if _, err := fmt.Sscanf(value, "%t", &enabled); err != nil {
+		return fmt.Errorf("invalid boolean value: %s (use true or false)", value)
+	}
+	consent, err := saveTelemetryConsent(telemetryPath(), enabled)
+	if err != nil {
+		return err
+	}
+	m.setTelemetry(consent)
+	return nil
+}
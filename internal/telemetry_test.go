@@ -0,0 +1,86 @@
+// Unit tests for opt-in usage counts in telemetry.go
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: opting in gives the install an id that stays when opting out and in again
+func TestTelemetryConsent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telemetry.json")
+	if _, asked := loadTelemetryConsent(path); asked {
+		t.Fatal("expected no answer yet")
+	}
+	if consent, _ := saveTelemetryConsent(path, false); consent.Enabled || consent.ID != "" {
+		t.Errorf("opting out should not create an id: %+v", consent)
+	}
+	first, _ := saveTelemetryConsent(path, true)
+	saveTelemetryConsent(path, false)
+	second, _ := saveTelemetryConsent(path, true)
+	if !second.Enabled || len(second.ID) != 32 || second.ID != first.ID {
+		t.Errorf("unexpected consent: %+v, first %+v", second, first)
+	}
+}
+
+// Test: the report counts commands by full name and events by type, and is posted once
+func TestTelemetryReport(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("TMUXAI_TELEMETRY", "")
+	var got TelemetryReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+	oldEndpoint := telemetryEndpoint
+	telemetryEndpoint = server.URL
+	defer func() { telemetryEndpoint = oldEndpoint }()
+
+	m := &Manager{Config: config.DefaultConfig()}
+	m.countCommand("/help") // not opted in yet, not counted
+	m.setTelemetry(telemetryConsent{Enabled: true, ID: "abc"})
+	m.countCommand("/sq")
+	m.countCommand("/squash")
+	m.countCommand("/my-secret-script")
+	emitEvent(EventExec, map[string]interface{}{"command": "ls"})
+	m.sendTelemetry()
+
+	if got.ID != "abc" || got.Provider != "openrouter" || got.Interface != "readline" {
+		t.Errorf("unexpected report: %+v", got)
+	}
+	if got.Commands["/squash"] != 2 || got.Commands["other"] != 1 || got.Commands["/help"] != 0 || got.Events[EventExec] != 1 {
+		t.Errorf("unexpected counts: %v, %v", got.Commands, got.Events)
+	}
+
+	m.setTelemetry(telemetryConsent{})
+	if m.getTelemetry() != nil {
+		t.Error("telemetry still on after opting out")
+	}
+}
+
+// Test: opting out removes the event listener, so toggling telemetry doesn't pile them up
+func TestSetTelemetryRemovesListener(t *testing.T) {
+	listeners := func() int {
+		eventMu.Lock()
+		defer eventMu.Unlock()
+		return len(eventListeners)
+	}
+	before := listeners()
+	m := &Manager{Config: config.DefaultConfig()}
+	for i := 0; i < 3; i++ {
+		m.setTelemetry(telemetryConsent{Enabled: true, ID: "abc"})
+		m.setTelemetry(telemetryConsent{Enabled: true, ID: "abc"})
+		if n := listeners(); n != before+1 {
+			t.Fatalf("expected one telemetry listener, got %d more", n-before)
+		}
+		m.setTelemetry(telemetryConsent{})
+	}
+	if n := listeners(); n != before {
+		t.Errorf("expected the listener to be removed, got %d left over", n-before)
+	}
+}
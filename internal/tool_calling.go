@@ -0,0 +1,241 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/cloudwego/eino/schema"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const toolCallingPrompt = `
+
+==== Tools ====
+The actions are also available as tools: exec_command, send_keys, paste_multiline_content, write_file,
+patch_file, read_file, fetch_url, delegate_task, request_accomplished, waiting_for_user_response, exec_pane_seems_busy and no_comment, each MCP tool as
+mcp_<server>__<tool> and each custom tool as tool_<name>. Call the tools instead of writing the XML tags; the same rules apply to them.
+==== End of tools ====
+`
+
+// builtinTool is an action of the XML protocol offered as a native tool; render turns
+// the arguments of a call back into the tag, so the rest of the turn sees one format
+type builtinTool struct {
+	info   *schema.ToolInfo
+	render func(args map[string]interface{}) string
+}
+
+func stringParam(desc string) *schema.ParameterInfo {
+	return &schema.ParameterInfo{Type: schema.String, Desc: desc, Required: true}
+}
+
+func boolTool(name, tag, desc string) builtinTool {
+	return builtinTool{
+		info:   &schema.ToolInfo{Name: name, Desc: desc, ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{})},
+		render: func(map[string]interface{}) string { return fmt.Sprintf("<%s>1</%s>", tag, tag) },
+	}
+}
+
+func valueTag(tag, value string) string {
+	return fmt.Sprintf("<%s>%s</%s>", tag, html.EscapeString(value), tag)
+}
+
+var builtinTools = []builtinTool{
+	{
+		info: &schema.ToolInfo{Name: "exec_command", Desc: "Execute a shell command in the exec pane",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{"command": stringParam("the command line")})},
+		render: func(args map[string]interface{}) string { return valueTag("ExecCommand", argString(args, "command")) },
+	},
+	{
+		info: &schema.ToolInfo{Name: "send_keys", Desc: "Send keystrokes to the exec pane, e.g. characters, Enter, Escape, C-c, M-a",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"keys": {Type: schema.Array, Desc: "the keys in order", ElemInfo: &schema.ParameterInfo{Type: schema.String}, Required: true},
+			})},
+		render: func(args map[string]interface{}) string {
+			var b strings.Builder
+			keys, _ := args["keys"].([]interface{})
+			for _, key := range keys {
+				if s, ok := key.(string); ok {
+					b.WriteString(valueTag("TmuxSendKeys", s))
+				}
+			}
+			return b.String()
+		},
+	},
+	{
+		info: &schema.ToolInfo{Name: "paste_multiline_content", Desc: "Paste multiline text into the exec pane, e.g. into an editor; never to run shell commands",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{"content": stringParam("the text to paste")})},
+		render: func(args map[string]interface{}) string {
+			return valueTag("PasteMultilineContent", argString(args, "content"))
+		},
+	},
+	{
+		info: &schema.ToolInfo{Name: "write_file", Desc: "Create or replace a file with the complete new content; the user reviews a diff",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"path":    stringParam("path relative to the exec pane's directory"),
+				"content": stringParam("the complete new file content"),
+			})},
+		render: func(args map[string]interface{}) string {
+			return fmt.Sprintf("<WriteFile path=\"%s\">%s</WriteFile>", html.EscapeString(argString(args, "path")), argString(args, "content"))
+		},
+	},
+	{
+		info: &schema.ToolInfo{Name: "patch_file", Desc: "Replace a part of a file that occurs exactly once; the user reviews a diff",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+				"path":    stringParam("path relative to the exec pane's directory"),
+				"search":  stringParam("the exact current text, with enough lines to be unique"),
+				"replace": stringParam("the new text"),
+			})},
+		render: func(args map[string]interface{}) string {
+			return fmt.Sprintf("<PatchFile path=\"%s\">\n<<<<<<< SEARCH\n%s\n=======\n%s\n>>>>>>> REPLACE\n</PatchFile>",
+				html.EscapeString(argString(args, "path")), strings.TrimSuffix(argString(args, "search"), "\n"), strings.TrimSuffix(argString(args, "replace"), "\n"))
+		},
+	},
+	{
+		info: &schema.ToolInfo{Name: "read_file", Desc: "Read a file under the exec pane's directory into the conversation",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{"path": stringParam("path relative to the exec pane's directory")})},
+		render: func(args map[string]interface{}) string { return valueTag("ReadFile", argString(args, "path")) },
+	},
+	{
+		info: &schema.ToolInfo{Name: "fetch_url", Desc: "Download a web page and add its readable text to the conversation",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{"url": stringParam("the http(s) URL")})},
+		render: func(args map[string]interface{}) string { return valueTag("FetchUrl", argString(args, "url")) },
+	},
+	{
+		info: &schema.ToolInfo{Name: "delegate_task", Desc: "Hand an independent subtask to a sub-agent working in its own pane",
+			ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{"task": stringParam("the complete description of the subtask")})},
+		render: func(args map[string]interface{}) string { return valueTag("DelegateTask", argString(args, "task")) },
+	},
+	boolTool("request_accomplished", "RequestAccomplished", "Call when the request is completed and verified"),
+	boolTool("waiting_for_user_response", "WaitingForUserResponse", "Call when you asked the user a question or need their input"),
+	boolTool("exec_pane_seems_busy", "ExecPaneSeemsBusy", "Call to wait for the command running in the exec pane to finish"),
+	boolTool("no_comment", "NoComment", "Call in watch mode when nothing is worth saying"),
+}
+
+func argString(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+var toolNameRe = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// mcpToolName is the tool name offered for an MCP tool, within the 64 characters and
+// the characters function names allow
+func mcpToolName(server, tool string) string {
+	name := "mcp_" + toolNameRe.ReplaceAllString(server, "_") + "__" + toolNameRe.ReplaceAllString(tool, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// openAPISchema converts a JSON schema to the parameters of a tool definition
+func openAPISchema(jsonSchema interface{}) *schema.ParamsOneOf {
+	params := &openapi3.Schema{}
+	if data, err := json.Marshal(jsonSchema); err == nil {
+		json.Unmarshal(data, params)
+	}
+	if params.Type == "" {
+		params.Type = openapi3.TypeObject
+	}
+	return schema.NewParamsOneOfByOpenAPIV3(params)
+}
+
+// requestTools returns the tools offered with a request: the actions, the plugin tools
+// and the tools of the connected MCP servers, with the MCP or plugin tool behind each
+// offered name
+func (m *Manager) requestTools() ([]*schema.ToolInfo, map[string]McpToolCall, map[string]string) {
+	tools := make([]*schema.ToolInfo, 0, len(builtinTools))
+	for _, t := range builtinTools {
+		tools = append(tools, t.info)
+	}
+	plugins := map[string]string{}
+	for _, tool := range m.Plugins {
+		name := pluginToolName(tool.Name)
+		tools = append(tools, &schema.ToolInfo{Name: name, Desc: tool.Description, ParamsOneOf: openAPISchema(tool.Parameters)})
+		plugins[name] = tool.Name
+	}
+	mcpTools := map[string]McpToolCall{}
+	if m.McpClient == nil {
+		return tools, mcpTools, plugins
+	}
+	for _, server := range m.McpServers {
+		serverTools, err := m.McpClient.Tools(server.Name)
+		if err != nil {
+			logger.Error("Failed to list tools of MCP server %s: %v", server.Name, err)
+			continue
+		}
+		for _, tool := range serverTools {
+			if m.toolPolicy(server.Name, tool.Name) == toolPolicyDeny {
+				continue
+			}
+			name := mcpToolName(server.Name, tool.Name)
+			tools = append(tools, &schema.ToolInfo{Name: name, Desc: tool.Description, ParamsOneOf: openAPISchema(tool.InputSchema)})
+			mcpTools[name] = McpToolCall{ServerName: server.Name, ToolName: tool.Name}
+		}
+	}
+	return tools, mcpTools, plugins
+}
+
+// renderToolCalls writes tool calls as the tags of the text protocol, calls of unknown
+// tools are dropped
+func renderToolCalls(calls []schema.ToolCall, mcpTools map[string]McpToolCall, plugins map[string]string) string {
+	var b strings.Builder
+	for _, call := range calls {
+		args := map[string]interface{}{}
+		if strings.TrimSpace(call.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+				logger.Error("Invalid arguments for tool %s: %v", call.Function.Name, err)
+				continue
+			}
+		}
+		if target, ok := mcpTools[call.Function.Name]; ok {
+			target.Arguments = args
+			data, err := json.Marshal(target)
+			if err != nil {
+				continue
+			}
+			b.WriteString("\n" + valueTag("McpToolCall", string(data)))
+			continue
+		}
+		if tool, ok := plugins[call.Function.Name]; ok {
+			data, err := json.Marshal(PluginToolCall{ToolName: tool, Arguments: args})
+			if err != nil {
+				continue
+			}
+			b.WriteString("\n" + valueTag("PluginToolCall", string(data)))
+			continue
+		}
+		found := false
+		for _, t := range builtinTools {
+			if t.info.Name == call.Function.Name {
+				b.WriteString("\n" + t.render(args))
+				found = true
+				break
+			}
+		}
+		if !found {
+			logger.Error("The model called an unknown tool: %s", call.Function.Name)
+		}
+	}
+	return b.String()
+}
+
+// requestToolCalls asks the model with the actions offered as tools and returns the
+// response in the text protocol, so confirmation, history and replay stay the same
+func (m *Manager) requestToolCalls(ctx context.Context, provider ToolCallingProvider, sending []ChatMessage, live *liveResponse) (string, error) {
+	tools, mcpTools, plugins := m.requestTools()
+	var onDelta func(string)
+	if live != nil {
+		onDelta = live.Write
+	}
+	content, calls, err := provider.GetToolCallsFromChatMessages(ctx, sending, m.GetOpenRouterModel(), tools, onDelta)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(content + renderToolCalls(calls, mcpTools, plugins)), nil
+}
@@ -0,0 +1,56 @@
+// Unit tests for native tool calls in tool_calling.go
+package internal
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Test: tool calls become the tags of the text protocol and parse back into the same actions
+func TestRenderToolCalls(t *testing.T) {
+	call := func(name, args string) schema.ToolCall {
+		return schema.ToolCall{Function: schema.FunctionCall{Name: name, Arguments: args}}
+	}
+	mcpTools := map[string]McpToolCall{mcpToolName("git hub", "search"): {ServerName: "git hub", ToolName: "search"}}
+	text := "Checking.\n" + renderToolCalls([]schema.ToolCall{
+		call("exec_command", `{"command": "grep -c '<b>' a.html && echo ok"}`),
+		call("send_keys", `{"keys": ["q", "Enter"]}`),
+		call("write_file", `{"path": "notes.txt", "content": "a < b\n"}`),
+		call("patch_file", `{"path": "main.go", "search": "old()\n", "replace": "new()"}`),
+		call("read_file", `{"path": "go.mod"}`),
+		call("mcp_git_hub__search", `{"query": "tmux"}`),
+		call("tool_jira", `{"issue": "OPS-1"}`),
+		call("request_accomplished", ``),
+		call("format_disk", `{}`),
+	}, mcpTools, map[string]string{"tool_jira": "jira"})
+
+	r, err := (&Manager{}).parseAIResponse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.ExecCommand) != 1 || r.ExecCommand[0] != "grep -c '<b>' a.html && echo ok" {
+		t.Errorf("unexpected commands: %q", r.ExecCommand)
+	}
+	if len(r.SendKeys) != 2 || r.SendKeys[1] != "Enter" {
+		t.Errorf("unexpected keys: %q", r.SendKeys)
+	}
+	if len(r.FileEdits) != 1 || r.FileEdits[0].Path != "notes.txt" || r.FileEdits[0].Content != "a < b\n" {
+		t.Errorf("unexpected file edits: %+v", r.FileEdits)
+	}
+	if len(r.FilePatches) != 1 || len(r.FilePatches[0].Hunks) != 1 || r.FilePatches[0].Hunks[0] != (PatchHunk{Search: "old()", Replace: "new()"}) {
+		t.Errorf("unexpected file patches: %+v", r.FilePatches)
+	}
+	if len(r.FileReads) != 1 || r.FileReads[0] != "go.mod" {
+		t.Errorf("unexpected file reads: %q", r.FileReads)
+	}
+	if len(r.McpToolCalls) != 1 || r.McpToolCalls[0].ServerName != "git hub" || r.McpToolCalls[0].Arguments["query"] != "tmux" {
+		t.Errorf("unexpected MCP calls: %+v", r.McpToolCalls)
+	}
+	if len(r.PluginToolCalls) != 1 || r.PluginToolCalls[0].ToolName != "jira" || r.PluginToolCalls[0].Arguments["issue"] != "OPS-1" {
+		t.Errorf("unexpected plugin tool calls: %+v", r.PluginToolCalls)
+	}
+	if !r.RequestAccomplished || r.Message != "Checking." {
+		t.Errorf("unexpected response: %+v", r)
+	}
+}
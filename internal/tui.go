@@ -0,0 +1,619 @@
+//go:build tui
+
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/chzyer/readline"
+	"github.com/fatih/color"
+)
+
+// tuiMaxLines is how much of the transcript the viewport keeps
+const tuiMaxLines = 5000
+
+// tuiAvailable is false in builds without the tui tag, see tui_off.go
+const tuiAvailable = true
+
+// TUIInterface runs the chat full-screen with Bubble Tea: a scrollable transcript,
+// a persistent input box and a status bar. Everything the manager prints to stdout
+// is captured through a pipe and rendered into the transcript.
+type TUIInterface struct {
+	manager *Manager
+	program *tea.Program
+	stdout  *os.File // the terminal, while os.Stdout feeds the transcript
+
+	mu     sync.Mutex
+	cancel context.CancelFunc // cancels the running turn
+}
+
+type (
+	tuiOutputMsg string
+	tuiLiveMsg   string // the response being streamed, "" once it is complete
+	tuiSubmitMsg string
+	tuiTickMsg   time.Time
+	tuiAnswer    struct {
+		text string
+		err  error
+	}
+	// tuiAskMsg turns the input box into a prompt whose answer is sent to reply
+	tuiAskMsg struct {
+		prompt  string
+		prefill string
+		reply   chan tuiAnswer
+	}
+)
+
+func NewTUIInterface(manager *Manager) *TUIInterface {
+	return &TUIInterface{manager: manager}
+}
+
+// Start runs the TUI until the user quits
+func (t *TUIInterface) Start(initMessage string) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture output: %w", err)
+	}
+	prevColorOutput := color.Output
+	t.stdout = os.Stdout
+	os.Stdout = w
+	color.Output = w
+	defer func() {
+		os.Stdout = t.stdout
+		color.Output = prevColorOutput
+		w.Close()
+	}()
+
+	t.program = tea.NewProgram(
+		newTUIModel(t, initMessage),
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+		tea.WithOutput(t.stdout),
+	)
+	go t.forwardOutput(r)
+
+	t.manager.tui = t
+	defer func() { t.manager.tui = nil }()
+
+	_, err = t.program.Run()
+	return err
+}
+
+// forwardOutput sends captured output to the transcript, never splitting
+// an escape sequence or a multi-byte character between two messages
+func (t *TUIInterface) forwardOutput(r io.Reader) {
+	buf := make([]byte, 4096)
+	pending := ""
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			var text string
+			text, pending = splitIncomplete(pending + string(buf[:n]))
+			if text != "" {
+				t.program.Send(tuiOutputMsg(text))
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (t *TUIInterface) processInput(input string) {
+	defer t.manager.recoverPanic()
+	t.manager.turnMu.Lock()
+	defer t.manager.turnMu.Unlock()
+	defer t.manager.refreshStatusHeader()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.mu.Lock()
+	t.cancel = cancel
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.cancel = nil
+		t.mu.Unlock()
+		cancel()
+	}()
+
+	if t.manager.IsMessageSubcommand(input) {
+		t.manager.ProcessSubCommandContext(ctx, input)
+		return
+	}
+
+	t.manager.runRequest(ctx, input)
+}
+
+// interrupt cancels the running turn like Ctrl+C does in the readline chat
+// showLive shows the response being streamed below the transcript
+func (t *TUIInterface) showLive(text string) {
+	t.program.Send(tuiLiveMsg(text))
+}
+
+func (t *TUIInterface) interrupt() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cancel != nil {
+		t.cancel()
+		t.manager.stopTurn()
+	}
+}
+
+// ask shows a prompt in the input box and waits for the answer
+func (t *TUIInterface) ask(prompt, prefill string) (string, error) {
+	reply := make(chan tuiAnswer, 1)
+	t.program.Send(tuiAskMsg{prompt: prompt, prefill: prefill, reply: reply})
+	answer := <-reply
+	return answer.text, answer.err
+}
+
+// quit stops the program from outside the event loop
+func (t *TUIInterface) quit() {
+	t.program.Quit()
+}
+
+// withTerminal hands the terminal to fn, for interactive pickers that draw their own UI
+func (m *Manager) withTerminal(fn func()) {
+	if m.tui == nil {
+		fn()
+		return
+	}
+	if err := m.tui.program.ReleaseTerminal(); err != nil {
+		logger.Error("Failed to release the terminal: %v", err)
+		return
+	}
+	captured := os.Stdout
+	os.Stdout = m.tui.stdout
+	defer func() {
+		os.Stdout = captured
+		m.tui.program.RestoreTerminal()
+	}()
+	fn()
+}
+
+type tuiModel struct {
+	tui         *TUIInterface
+	initMessage string
+
+	viewport viewport.Model
+	input    textinput.Model
+	lines    []string // transcript, the last line is still being written
+	live     string   // streamed response shown after the transcript until it is printed
+	width    int
+
+	asking  *tuiAskMsg
+	history *inputHistory
+	histPos int
+
+	// reverse history search started with Ctrl+R
+	searching   bool
+	searchQuery string
+	searchPos   int
+	searchSaved string // input to restore when the search is cancelled
+}
+
+func newTUIModel(t *TUIInterface, initMessage string) *tuiModel {
+	input := textinput.New()
+	input.Prompt = t.manager.GetPrompt()
+	input.Focus()
+
+	history := newInputHistory(t.manager.GetConfig().History)
+	return &tuiModel{
+		tui:         t,
+		initMessage: initMessage,
+		viewport:    viewport.New(0, 0),
+		input:       input,
+		lines:       []string{"Type '/help' for a list of commands, '/exit' to quit", ""},
+		history:     history,
+		histPos:     history.Len(),
+	}
+}
+
+func tuiTick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tuiTickMsg(t) })
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	cmds := []tea.Cmd{textinput.Blink, tuiTick()}
+	if m.initMessage != "" {
+		message := m.initMessage
+		cmds = append(cmds, func() tea.Msg { return tuiSubmitMsg(message) })
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.setPrompt(m.input.Prompt)
+		m.viewport.Width = msg.Width
+		m.viewport.Height = max(msg.Height-2, 1) // status bar and input line
+		m.refreshTranscript(true)
+		return m, nil
+
+	case tuiOutputMsg:
+		follow := m.viewport.AtBottom()
+		m.lines = appendOutput(m.lines, string(msg))
+		m.refreshTranscript(follow)
+		return m, nil
+
+	case tuiLiveMsg:
+		follow := m.viewport.AtBottom()
+		m.live = string(msg)
+		m.refreshTranscript(follow)
+		return m, nil
+
+	case tuiSubmitMsg:
+		return m, m.submit(string(msg))
+
+	case tuiAskMsg:
+		m.asking = &msg
+		m.setPrompt(msg.prompt)
+		m.input.SetValue(msg.prefill)
+		m.input.CursorEnd()
+		return m, nil
+
+	case tuiTickMsg:
+		if m.asking == nil && !m.searching {
+			m.setPrompt(m.tui.manager.GetPrompt())
+		}
+		return m, tuiTick()
+
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		if m.searching {
+			return m, m.updateSearch(msg)
+		}
+		switch msg.Type {
+		case tea.KeyCtrlR:
+			if m.asking == nil {
+				m.startSearch()
+			}
+			return m, nil
+		case tea.KeyCtrlC:
+			switch {
+			case m.asking != nil:
+				m.answer("", readline.ErrInterrupt)
+			case m.input.Value() != "":
+				m.input.Reset()
+			default:
+				m.tui.interrupt()
+			}
+			return m, nil
+		case tea.KeyCtrlD:
+			if m.asking == nil && m.input.Value() == "" {
+				return m, tea.Quit
+			}
+		case tea.KeyEnter:
+			value := m.input.Value()
+			if m.asking != nil {
+				m.answer(value, nil)
+				return m, nil
+			}
+			if strings.TrimSpace(value) == "" {
+				return m, nil
+			}
+			m.addHistory(value)
+			m.input.Reset()
+			return m, m.submit(value)
+		case tea.KeyUp, tea.KeyDown:
+			if m.asking == nil {
+				m.browseHistory(msg.Type == tea.KeyUp)
+			}
+			return m, nil
+		case tea.KeyTab:
+			if m.asking == nil {
+				m.complete()
+			}
+			return m, nil
+		case tea.KeyPgUp:
+			m.viewport.PageUp()
+			return m, nil
+		case tea.KeyPgDown:
+			m.viewport.PageDown()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// submit echoes the input into the transcript and runs it in the background
+func (m *tuiModel) submit(input string) tea.Cmd {
+	m.echo(m.tui.manager.GetPrompt() + input)
+
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "exit" || trimmed == "quit" {
+		return tea.Quit
+	}
+	go m.tui.processInput(input)
+	return nil
+}
+
+// answer replies to the pending prompt and gives the input box back to the chat
+func (m *tuiModel) answer(text string, err error) {
+	m.echo(m.asking.prompt + text)
+	m.asking.reply <- tuiAnswer{text: text, err: err}
+	m.asking = nil
+	m.setPrompt(m.tui.manager.GetPrompt())
+	m.input.Reset()
+}
+
+// echo adds a line to the transcript directly; writing to the captured stdout
+// from the event loop could block on the pipe it is draining
+func (m *tuiModel) echo(line string) {
+	m.lines = appendOutput(m.lines, line+"\n")
+	m.refreshTranscript(true)
+}
+
+func (m *tuiModel) setPrompt(prompt string) {
+	m.input.Prompt = prompt
+	if m.width > 0 {
+		m.input.Width = max(m.width-ansi.StringWidth(prompt)-1, 1)
+	}
+}
+
+func (m *tuiModel) refreshTranscript(follow bool) {
+	content := strings.Join(m.lines, "\n") + m.live
+	if m.width > 0 {
+		content = ansi.Wrap(content, m.width, "")
+	}
+	m.viewport.SetContent(content)
+	if follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m *tuiModel) addHistory(line string) {
+	m.history.Add(line)
+	m.histPos = m.history.Len()
+}
+
+func (m *tuiModel) browseHistory(older bool) {
+	if older && m.histPos > 0 {
+		m.histPos--
+	} else if !older && m.histPos < m.history.Len() {
+		m.histPos++
+	}
+	if m.histPos == m.history.Len() {
+		m.input.Reset()
+		return
+	}
+	m.input.SetValue(m.history.At(m.histPos))
+	m.input.CursorEnd()
+}
+
+func (m *tuiModel) startSearch() {
+	m.searching = true
+	m.searchQuery = ""
+	m.searchPos = m.history.Len()
+	m.searchSaved = m.input.Value()
+	m.showSearch()
+}
+
+// updateSearch handles keys while searching: typing narrows the search, Ctrl+R finds
+// older matches, Enter runs the match, Esc edits it and Ctrl+G or Ctrl+C cancel
+func (m *tuiModel) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlR:
+		m.searchPos = findHistory(m.history, m.searchQuery, m.searchPos-1, m.searchPos)
+	case tea.KeyRunes, tea.KeySpace:
+		m.searchQuery += string(msg.Runes)
+		m.searchPos = findHistory(m.history, m.searchQuery, m.searchPos, m.searchPos)
+	case tea.KeyBackspace:
+		if m.searchQuery != "" {
+			_, size := utf8.DecodeLastRuneInString(m.searchQuery)
+			m.searchQuery = m.searchQuery[:len(m.searchQuery)-size]
+			m.searchPos = findHistory(m.history, m.searchQuery, m.history.Len()-1, m.history.Len())
+		}
+	case tea.KeyEnter:
+		value := m.input.Value()
+		m.endSearch(value)
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		m.addHistory(value)
+		m.input.Reset()
+		return m.submit(value)
+	case tea.KeyEsc:
+		m.endSearch(m.input.Value())
+		return nil
+	case tea.KeyCtrlG, tea.KeyCtrlC:
+		m.endSearch(m.searchSaved)
+		return nil
+	default:
+		return nil
+	}
+	m.showSearch()
+	return nil
+}
+
+func (m *tuiModel) showSearch() {
+	m.setPrompt(fmt.Sprintf("(reverse-i-search)`%s': ", m.searchQuery))
+	if m.searchPos < m.history.Len() {
+		m.input.SetValue(m.history.At(m.searchPos))
+	} else {
+		m.input.SetValue("")
+	}
+	m.input.CursorEnd()
+}
+
+func (m *tuiModel) endSearch(value string) {
+	m.searching = false
+	m.setPrompt(m.tui.manager.GetPrompt())
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.histPos = m.history.Len()
+}
+
+// complete expands a /command prefix, listing the candidates when it is ambiguous
+func (m *tuiModel) complete() {
+	value := m.input.Value()
+	if !strings.HasPrefix(value, "/") || strings.Contains(value, " ") {
+		return
+	}
+	all := commands
+	m.tui.manager.ready()
+	if m.tui.manager.Scripts != nil {
+		all = append(append([]string{}, commands...), m.tui.manager.Scripts.Commands()...)
+	}
+	var matches []string
+	for _, c := range all {
+		if strings.HasPrefix(c, value) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return
+	case 1:
+		m.input.SetValue(matches[0] + " ")
+	default:
+		m.input.SetValue(commonPrefix(matches))
+		m.echo(strings.Join(matches, "  "))
+	}
+	m.input.CursorEnd()
+}
+
+func (m *tuiModel) View() string {
+	return m.viewport.View() + "\n" + m.statusBar() + "\n" + m.input.View()
+}
+
+var tuiStatusStyle = lipgloss.NewStyle().Reverse(true)
+
+// statusBar shows the model, the estimated context usage and the agent state
+func (m *tuiModel) statusBar() string {
+	mgr := m.tui.manager
+	state := mgr.GetStatus()
+	if state == "" {
+		state = "idle"
+	}
+	state = i18n.T(state)
+	if since := mgr.getWaitingSince(); !since.IsZero() {
+		state += system.Sym(" · " + i18n.T("waiting for model %s", formatElapsed(time.Since(since))))
+	}
+	if mgr.GetWatchMode() {
+		state += system.Sym(" · " + i18n.T("watching"))
+	}
+	tokens := mgr.contextTokens()
+	parts := []string{
+		mgr.GetOpenRouterModel(),
+		i18n.T("~%d tokens (%d%%)", tokens, tokens*100/max(mgr.GetMaxContextSize(), 1)),
+		state,
+	}
+	if !m.viewport.AtBottom() {
+		parts = append(parts, i18n.T("scroll %d%%", int(m.viewport.ScrollPercent()*100)))
+	}
+	return tuiStatusStyle.Width(m.width).Render(ansi.Truncate(" "+strings.Join(parts, system.Sym(" │ ")), m.width, system.Sym("…")))
+}
+
+// appendOutput writes terminal output into the transcript lines, keeping colors and
+// treating carriage returns and cursor-to-column moves as rewrites of the current line
+func appendOutput(lines []string, text string) []string {
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	current := []byte(lines[len(lines)-1])
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\n':
+			lines[len(lines)-1] = string(current)
+			lines = append(lines, "")
+			current = current[:0:0]
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				continue
+			}
+			current = current[:0]
+		case '\x1b':
+			end := escapeEnd(text, i)
+			switch text[end] {
+			case 'm': // colors
+				current = append(current, text[i:end+1]...)
+			case 'G':
+				current = current[:0]
+			}
+			i = end
+		default:
+			current = append(current, c)
+		}
+	}
+	lines[len(lines)-1] = string(current)
+	if len(lines) > tuiMaxLines {
+		lines = lines[len(lines)-tuiMaxLines:]
+	}
+	return lines
+}
+
+// escapeEnd returns the index of the last byte of the escape sequence starting at i
+func escapeEnd(text string, i int) int {
+	if i+1 >= len(text) || text[i+1] != '[' {
+		return min(i+1, len(text)-1)
+	}
+	for j := i + 2; j < len(text); j++ {
+		if text[j] >= 0x40 && text[j] <= 0x7e {
+			return j
+		}
+	}
+	return len(text) - 1
+}
+
+func escapeComplete(seq string) bool {
+	if len(seq) < 2 {
+		return false
+	}
+	if seq[1] != '[' {
+		return true
+	}
+	return strings.IndexFunc(seq[2:], func(r rune) bool { return r >= 0x40 && r <= 0x7e }) >= 0
+}
+
+// splitIncomplete holds back a trailing escape sequence or UTF-8 character that
+// was cut off at the end of a read
+func splitIncomplete(s string) (string, string) {
+	if i := strings.LastIndexByte(s, '\x1b'); i >= 0 && !escapeComplete(s[i:]) {
+		return s[:i], s[i:]
+	}
+	for n := 1; n <= 3 && n <= len(s); n++ {
+		tail := s[len(s)-n:]
+		if utf8.RuneStart(tail[0]) {
+			if !utf8.FullRuneInString(tail) {
+				return s[:len(s)-n], tail
+			}
+			break
+		}
+	}
+	return s, ""
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,33 @@
+//go:build !tui
+
+package internal
+
+import "errors"
+
+// tuiAvailable is false here: this build leaves out the full-screen interface and its
+// dependencies, build with -tags tui to include it
+const tuiAvailable = false
+
+// TUIInterface is empty without the tui tag; m.tui is never set
+type TUIInterface struct{}
+
+func NewTUIInterface(manager *Manager) *TUIInterface {
+	return &TUIInterface{}
+}
+
+func (t *TUIInterface) Start(initMessage string) error {
+	return errors.New("built without the tui tag")
+}
+
+func (t *TUIInterface) ask(prompt, prefill string) (string, error) {
+	return "", errors.New("built without the tui tag")
+}
+
+func (t *TUIInterface) quit() {}
+
+func (t *TUIInterface) showLive(text string) {}
+
+// withTerminal runs fn; the readline chat never holds the terminal
+func (m *Manager) withTerminal(fn func()) {
+	fn()
+}
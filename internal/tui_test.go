@@ -0,0 +1,52 @@
+//go:build tui
+
+// Unit tests for the transcript output handling in tui.go
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test: newlines, carriage returns and line clears are applied like a terminal would
+func TestAppendOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		text  string
+		want  []string
+	}{
+		{"new lines", nil, "a\nb", []string{"a", "b"}},
+		{"continues last line", []string{"a"}, "b\n", []string{"ab", ""}},
+		{"carriage return rewrites", nil, "50%\r100%\n", []string{"100%", ""}},
+		{"crlf keeps the line", nil, "done\r\n", []string{"done", ""}},
+		{"keeps colors", nil, "\x1b[32mok\x1b[0m", []string{"\x1b[32mok\x1b[0m"}},
+		{"drops cursor control", nil, "\x1b[?25lspin\x1b[K", []string{"spin"}},
+		{"column zero rewrites", nil, "● ○\x1b[0G\x1b[K● ●", []string{"● ●"}},
+	}
+	for _, tt := range tests {
+		if got := appendOutput(tt.lines, tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Test: cut-off escape sequences and characters are held back for the next read
+func TestSplitIncomplete(t *testing.T) {
+	tests := []struct {
+		in, text, rest string
+	}{
+		{"plain", "plain", ""},
+		{"ok\x1b[3", "ok", "\x1b[3"},
+		{"ok\x1b", "ok", "\x1b"},
+		{"ok\x1b[32m", "ok\x1b[32m", ""},
+		{"dot \xe2\x97", "dot ", "\xe2\x97"},
+		{"dot ●", "dot ●", ""},
+	}
+	for _, tt := range tests {
+		text, rest := splitIncomplete(tt.in)
+		if text != tt.text || rest != tt.rest {
+			t.Errorf("splitIncomplete(%q) = %q, %q; want %q, %q", tt.in, text, rest, tt.text, tt.rest)
+		}
+	}
+}
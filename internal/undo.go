@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const (
+	maxUndoOutputLines  = 100
+	maxUndoHistoryItems = 5
+)
+
+const undoPrompt = `Undo the effect of this command you ran in the exec pane: propose the compensating commands, e.g. move a moved file back, restore an overwritten or deleted file from git or a backup, kill a process it started or revert the setting it changed.
+Use its output and the recent commands of the pane below as evidence of what it changed. If it can't be undone safely, or changed nothing that needs undoing, say so and run nothing.
+
+Command: %s
+Directory: %s
+Exit code: %s
+Output:
+%s
+
+Recent commands of the exec pane:
+%s`
+
+// undoTarget returns the index of the last command TmuxAI ran that isn't undone yet nor
+// itself an undo, -1 when there is none
+func undoTarget(executed []ExecutedCommand) int {
+	for i := len(executed) - 1; i >= 0; i-- {
+		if !executed[i].Undo && !executed[i].Undone {
+			return i
+		}
+	}
+	return -1
+}
+
+// undoMessage builds the request to reverse cmd, with the recent pane history as evidence
+func undoMessage(cmd ExecutedCommand, history []CommandExecHistory) string {
+	code := "unknown"
+	if cmd.Code != nil {
+		code = fmt.Sprint(*cmd.Code)
+	}
+	output := strings.TrimSpace(cmd.Output)
+	if output == "" {
+		output = "(not captured)"
+	}
+	if len(history) > maxUndoHistoryItems {
+		history = history[len(history)-maxUndoHistoryItems:]
+	}
+	var recent strings.Builder
+	for _, h := range history {
+		fmt.Fprintf(&recent, "$ %s (exit code %d)\n%s\n", h.Command, h.Code, system.LimitLines(strings.TrimSpace(h.Output), maxUndoOutputLines))
+	}
+	if recent.Len() == 0 {
+		recent.WriteString("(not tracked, the exec pane isn't prepared)\n")
+	}
+	return fmt.Sprintf(undoPrompt, cmd.Command, cmd.Cwd, code, system.LimitLines(output, maxUndoOutputLines), recent.String())
+}
+
+// handleUndoCommand asks the model to reverse the last command TmuxAI ran. Every action
+// of the turn is confirmed, even ones a *_confirm setting, an auto rule or an earlier
+// approval would let through; the commands it runs are marked so the next /undo goes
+// further back instead of undoing the undo.
+func handleUndoCommand(ctx context.Context, m *Manager) {
+	i := undoTarget(m.ExecutedCommands)
+	if i < 0 {
+		m.Println(i18n.T("Nothing to undo, TmuxAI hasn't run a command yet"))
+		return
+	}
+	if m.ExecPane.IsPrepared {
+		m.parseExecPaneCommandHistory()
+	}
+	target := m.ExecutedCommands[i]
+	m.Println(i18n.T("Undoing: %s", target.Command))
+
+	before := len(m.ExecutedCommands)
+	m.alwaysConfirm = true
+	m.runRequest(ctx, undoMessage(target, m.ExecHistory))
+	m.alwaysConfirm = false
+
+	for j := before; j < len(m.ExecutedCommands); j++ {
+		m.ExecutedCommands[j].Undo = true
+	}
+	if len(m.ExecutedCommands) > before {
+		m.ExecutedCommands[i].Undone = true
+	}
+}
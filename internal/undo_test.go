@@ -0,0 +1,77 @@
+// Unit tests for /undo in undo.go
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: the target skips commands run by /undo and the ones they reversed
+func TestUndoTarget(t *testing.T) {
+	executed := []ExecutedCommand{
+		{Command: "mkdir build"},
+		{Command: "mv a.txt b.txt", Undone: true},
+		{Command: "mv b.txt a.txt", Undo: true},
+	}
+	if i := undoTarget(executed); i != 0 {
+		t.Errorf("expected the first command, got %d", i)
+	}
+	if i := undoTarget(nil); i != -1 {
+		t.Errorf("expected no target, got %d", i)
+	}
+}
+
+// Test: the request carries the command, its result and the recent pane history
+func TestUndoMessage(t *testing.T) {
+	code := 0
+	cmd := ExecutedCommand{Command: "mv a.txt b.txt", Cwd: "/work", Code: &code}
+	history := []CommandExecHistory{
+		{Command: "ls", Output: "a.txt", Code: 0},
+		{Command: "mv a.txt b.txt", Code: 0},
+	}
+	msg := undoMessage(cmd, history)
+	for _, want := range []string{"Command: mv a.txt b.txt\nDirectory: /work\nExit code: 0", "$ ls (exit code 0)\na.txt"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in\n%s", want, msg)
+		}
+	}
+
+	msg = undoMessage(ExecutedCommand{Command: "npm start &"}, nil)
+	if !strings.Contains(msg, "Exit code: unknown") || !strings.Contains(msg, "isn't prepared") {
+		t.Errorf("unexpected message for an unprepared pane:\n%s", msg)
+	}
+}
+
+// Test: while /undo runs every kind of action asks, whatever the settings and rules
+func TestAlwaysConfirm(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ExecConfirm = false
+	cfg.PasteMultilineConfirm = false
+	cfg.WhitelistPatterns = []string{`^ls`}
+	m := &Manager{Config: cfg, SessionOverrides: map[string]interface{}{}}
+
+	if m.confirmRequired("", false) {
+		t.Error("expected no confirmation outside /undo")
+	}
+	m.alwaysConfirm = true
+	for _, action := range []string{"", ruleAuto} {
+		if !m.confirmRequired(action, false) {
+			t.Errorf("expected %q to be confirmed during /undo", action)
+		}
+	}
+	for _, prompt := range []string{confirmExecPrompt, confirmPastePrompt, confirmWritePrompt, confirmFetchPrompt, confirmPluginPrompt, confirmDelegatePrompt} {
+		if action, _ := m.ruleAction(prompt, "ls"); action != ruleConfirm {
+			t.Errorf("%s: expected confirm, got %q", prompt, action)
+		}
+	}
+	var asked []string
+	m.ConfirmFunc = func(content, prompt string) (bool, string) {
+		asked = append(asked, content)
+		return false, ""
+	}
+	if approved, _ := m.approveToolCall(McpToolCall{ServerName: "fs", ToolName: "write"}); approved || len(asked) != 1 {
+		t.Errorf("expected the MCP tool call to be asked about, asked %v", asked)
+	}
+}
@@ -0,0 +1,328 @@
+package internal
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+// updateAPI is the GitHub API endpoint of the latest release, replaced in tests
+var updateAPI = "https://api.github.com/repos/alvinunreal/tmuxai/releases/latest"
+
+// updateChecksums is the checksum asset goreleaser adds to every release
+const updateChecksums = "checksums.sha256"
+
+// Release is a GitHub release with its downloads
+type Release struct {
+	TagName string         `json:"tag_name"`
+	HTMLURL string         `json:"html_url"`
+	Assets  []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is one download of a release
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// updateCheck is what the last check found, kept in update-check.json in the config dir
+type updateCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// LatestRelease asks GitHub for the latest release
+func LatestRelease(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, updateAPI, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := system.HTTPClient(15 * time.Second).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release check failed: %s", resp.Status)
+	}
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("release check failed: %w", err)
+	}
+	return &release, nil
+}
+
+// parseVersion reads versions like v1.2.3 or 1.2.3-rc1, the suffix is ignored
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	version, _, _ = strings.Cut(strings.TrimPrefix(version, "v"), "-")
+	fields := strings.Split(version, ".")
+	if len(fields) != 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// versionNewer reports whether latest is a newer release than current; development
+// builds are never out of date
+func versionNewer(latest, current string) bool {
+	l, okLatest := parseVersion(latest)
+	c, okCurrent := parseVersion(current)
+	if !okLatest || !okCurrent {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// announceUpdate prints a one line notice when the last check found a newer release,
+// and checks again in the background once the check interval passed
+func (m *Manager) announceUpdate() {
+	if !m.GetConfig().Updates.Check {
+		return
+	}
+	path := config.GetConfigFilePath("update-check.json")
+	var last updateCheck
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &last)
+	}
+	if versionNewer(last.Latest, Version) {
+		m.Println(system.CurrentTheme().Muted.Sprint(i18n.T("TmuxAI %s is available, run tmuxai self-update", last.Latest)))
+	}
+
+	interval := time.Duration(m.GetConfig().Updates.IntervalHours) * time.Hour
+	if time.Since(last.CheckedAt) < interval {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		release, err := LatestRelease(ctx)
+		if err != nil {
+			logger.Debug("Update check failed: %v", err)
+			return
+		}
+		data, _ := json.Marshal(updateCheck{CheckedAt: time.Now(), Latest: release.TagName})
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			logger.Error("Failed to save the update check: %v", err)
+		}
+	}()
+}
+
+// releaseAssetName is the archive goreleaser builds for the platform
+func releaseAssetName(goos, goarch string) string {
+	name := "tmuxai_" + strings.ToUpper(goos[:1]) + goos[1:] + "_" + goarch
+	if goarch == "arm" {
+		name += "v7"
+	}
+	if goos == "windows" {
+		return name + ".zip"
+	}
+	return name + ".tar.gz"
+}
+
+// SelfUpdate replaces the running binary with the latest release after checking the
+// download against the release checksums
+func SelfUpdate(ctx context.Context, w io.Writer) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	if strings.Contains(exe, "/Cellar/") {
+		return errors.New("tmuxai was installed with Homebrew, run brew upgrade tmuxai")
+	}
+	return selfUpdate(ctx, w, exe)
+}
+
+func selfUpdate(ctx context.Context, w io.Writer, exe string) error {
+	if _, ok := parseVersion(Version); !ok {
+		return fmt.Errorf("this is a development build (%s), self-update only replaces release binaries", Version)
+	}
+	release, err := LatestRelease(ctx)
+	if err != nil {
+		return err
+	}
+	if !versionNewer(release.TagName, Version) {
+		fmt.Fprintln(w, i18n.T("TmuxAI %s is up to date", Version))
+		return nil
+	}
+
+	assetName := releaseAssetName(runtime.GOOS, runtime.GOARCH)
+	var assetURL, checksumsURL string
+	for _, asset := range release.Assets {
+		switch asset.Name {
+		case assetName:
+			assetURL = asset.URL
+		case updateChecksums:
+			checksumsURL = asset.URL
+		}
+	}
+	if assetURL == "" || checksumsURL == "" {
+		return fmt.Errorf("release %s has no %s or %s", release.TagName, assetName, updateChecksums)
+	}
+
+	fmt.Fprintln(w, i18n.T("Downloading %s %s", assetName, release.TagName))
+	checksums, err := downloadAsset(ctx, checksumsURL)
+	if err != nil {
+		return err
+	}
+	want, err := assetChecksum(checksums, assetName)
+	if err != nil {
+		return err
+	}
+	archive, err := downloadAsset(ctx, assetURL)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(archive)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", assetName, got, want)
+	}
+
+	binary, err := extractBinary(archive, strings.HasSuffix(assetName, ".zip"))
+	if err != nil {
+		return err
+	}
+	if err := replaceExecutable(exe, binary); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, i18n.T("Updated %s from %s to %s", exe, Version, release.TagName))
+	return nil
+}
+
+// downloadAsset reads a release download, which may take a while on slow links
+func downloadAsset(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := system.HTTPClient(5 * time.Minute).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of %s failed: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 256<<20))
+}
+
+// assetChecksum finds the sha256 of name in a "<sum>  <name>" checksums file
+func assetChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not listed in %s", name, updateChecksums)
+}
+
+// extractBinary takes the tmuxai executable out of a release archive
+func extractBinary(archive []byte, isZip bool) ([]byte, error) {
+	if isZip {
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if filepath.Base(f.Name) == "tmuxai.exe" {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+		return nil, errors.New("no tmuxai.exe in the archive")
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("no tmuxai binary in the archive")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == "tmuxai" {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// replaceExecutable writes binary next to exe and renames it over exe, so a failed
+// write leaves the old binary in place. Windows can't replace a running binary, it is
+// moved aside first.
+func replaceExecutable(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".tmuxai-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", filepath.Dir(exe), err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), exe)
+}
@@ -0,0 +1,103 @@
+// Unit tests for the release check and self-update in update.go
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// Test: releases compare by number, development builds are never out of date
+func TestVersionNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.3", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0-rc1", "v1.1.0", true},
+		{"v1.1.0", "v1.2.0", false},
+		{"v2.0.0", "dev", false},
+		{"", "v1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := versionNewer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("versionNewer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+	if name := releaseAssetName("linux", "arm"); name != "tmuxai_Linux_armv7.tar.gz" {
+		t.Errorf("unexpected asset name: %s", name)
+	}
+}
+
+// Test: the binary is replaced from a release whose checksum matches, and kept when it doesn't
+func TestSelfUpdate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test release only has a tar.gz")
+	}
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "README.md", Mode: 0o644, Size: 2, Typeflag: tar.TypeReg})
+	tw.Write([]byte("hi"))
+	tw.WriteHeader(&tar.Header{Name: "tmuxai", Mode: 0o755, Size: 11, Typeflag: tar.TypeReg})
+	tw.Write([]byte("new version"))
+	tw.Close()
+	gz.Close()
+
+	assetName := releaseAssetName(runtime.GOOS, runtime.GOARCH)
+	sum := sha256.Sum256(archive.Bytes())
+	checksum := hex.EncodeToString(sum[:])
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprintf(w, `{"tag_name": "v9.0.0", "assets": [
+				{"name": %q, "browser_download_url": "%s/archive"},
+				{"name": "checksums.sha256", "browser_download_url": "%s/checksums"}]}`, assetName, server.URL, server.URL)
+		case "/archive":
+			w.Write(archive.Bytes())
+		case "/checksums":
+			fmt.Fprintf(w, "%s  %s\n", checksum, assetName)
+		}
+	}))
+	defer server.Close()
+
+	oldAPI, oldVersion := updateAPI, Version
+	updateAPI, Version = server.URL+"/latest", "v1.0.0"
+	defer func() { updateAPI, Version = oldAPI, oldVersion }()
+
+	exe := filepath.Join(t.TempDir(), "tmuxai")
+	os.WriteFile(exe, []byte("old version"), 0o755)
+
+	checksum = "0000"
+	if err := selfUpdate(context.Background(), io.Discard, exe); err == nil {
+		t.Error("expected a checksum mismatch")
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old version" {
+		t.Errorf("binary replaced despite the mismatch: %q", data)
+	}
+
+	checksum = hex.EncodeToString(sum[:])
+	if err := selfUpdate(context.Background(), io.Discard, exe); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(exe)
+	info, _ := os.Stat(exe)
+	if string(data) != "new version" || info.Mode().Perm() != 0o755 {
+		t.Errorf("unexpected binary: %q, %v", data, info.Mode())
+	}
+}
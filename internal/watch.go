@@ -0,0 +1,441 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/alvinunreal/tmuxai/logger"
+	"github.com/alvinunreal/tmuxai/system"
+)
+
+const watchUsage = "Usage: /watch [--panes <id,id,...>|all] [--interval 10s] [--max-calls n] [--pattern <regex> [--action comment|notify|command] [--command <cmd>]] <description>"
+
+// Actions of a pattern watch
+const (
+	watchActionComment = "comment" // ask the model about the matching lines
+	watchActionNotify  = "notify"  // desktop notification and notification sinks
+	watchActionCommand = "command" // run a shell command
+)
+
+// watchOptions are the flags of /watch
+type watchOptions struct {
+	panes   []string // pane ids to watch in any window, empty for the chat pane's window
+	all     bool     // every pane of the session
+	pattern *regexp.Regexp
+	action  string // for pattern watches, see the watchAction constants
+	command string // run for watchActionCommand
+	// interval and maxCalls override watch.interval and watch.max_calls when set
+	interval time.Duration
+	maxCalls int
+	desc     string
+}
+
+// scoped reports whether the watch looks at other panes than the chat pane's window
+func (o watchOptions) scoped() bool {
+	return o.all || len(o.panes) > 0
+}
+
+// parseWatchArgs reads the flags in front of the watch description
+func parseWatchArgs(args []string) (watchOptions, error) {
+	var opts watchOptions
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				return opts, fmt.Errorf("%s", i18n.T(watchUsage))
+			}
+			value, args = args[0], args[1:]
+		}
+		switch name {
+		case "--panes":
+			if strings.EqualFold(value, "all") {
+				opts.all = true
+				continue
+			}
+			for _, id := range strings.Split(value, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					opts.panes = append(opts.panes, normalizePaneId(id))
+				}
+			}
+		case "--interval":
+			d, err := time.ParseDuration(value)
+			if n, nerr := strconv.Atoi(value); nerr == nil {
+				d, err = time.Duration(n)*time.Second, nil
+			}
+			if err != nil || d <= 0 {
+				return opts, fmt.Errorf("%s", i18n.T("Invalid interval %s, e.g. 10s or 2m", value))
+			}
+			opts.interval = d
+		case "--max-calls":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return opts, fmt.Errorf("%s", i18n.T("Invalid --max-calls %s, give a positive number", value))
+			}
+			opts.maxCalls = n
+		case "--pattern":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return opts, fmt.Errorf("%s", i18n.T("Invalid pattern: %v", err))
+			}
+			opts.pattern = re
+		case "--action":
+			switch value {
+			case watchActionComment, watchActionNotify, watchActionCommand:
+				opts.action = value
+			default:
+				return opts, fmt.Errorf("%s", i18n.T("Unknown watch action %s, use comment, notify or command", value))
+			}
+		case "--command":
+			opts.command = value
+			if opts.action == "" {
+				opts.action = watchActionCommand
+			}
+		default:
+			return opts, fmt.Errorf("%s", i18n.T("Unknown /watch option %s. %s", name, i18n.T(watchUsage)))
+		}
+	}
+	opts.desc = strings.Join(args, " ")
+	if opts.pattern == nil && (opts.action != "" || opts.command != "") {
+		return opts, fmt.Errorf("%s", i18n.T("--action and --command need --pattern"))
+	}
+	if opts.pattern != nil && opts.action == "" {
+		opts.action = watchActionComment
+	}
+	if opts.action == watchActionCommand && opts.command == "" {
+		return opts, fmt.Errorf("%s", i18n.T("--action command needs --command <cmd>"))
+	}
+	return opts, nil
+}
+
+// handleWatchCommand starts a watch: asking the model about every settled change of the
+// panes, or with --pattern only running the action for new lines that match
+func handleWatchCommand(ctx context.Context, m *Manager, args []string) {
+	opts, err := parseWatchArgs(args)
+	if err != nil {
+		m.Println(err.Error())
+		return
+	}
+	if opts.desc == "" && opts.pattern == nil {
+		m.Println(i18n.T(watchUsage))
+		return
+	}
+	m.startWatch(ctx, opts)
+}
+
+// startWatch runs a watch with its options until it is stopped
+func (m *Manager) startWatch(ctx context.Context, opts watchOptions) {
+	m.watchOpts = opts
+	m.SetStatus("running")
+	m.SetWatchMode(true)
+	if opts.pattern != nil {
+		m.watchPattern(ctx)
+		return
+	}
+	startWatch := `
+1. Find out if there is new content in the pane based on chat history.
+2. Comment only considering the new content in this pane output.
+
+Watch for: ` + opts.desc
+	m.startWatchMode(ctx, startWatch)
+}
+
+// startWatchMode comments on the panes once, then again each time they change and settle,
+// until the watch is stopped or ctx is cancelled. While the panes are idle only tmux is
+// polled, the model is not asked.
+func (m *Manager) startWatchMode(ctx context.Context, desc string) {
+	defer func() { m.SetWatchMode(false) }()
+	if m.GetStatus() == "" || ctx.Err() != nil {
+		return
+	}
+
+	if m.watchOpts.scoped() {
+		desc += "\nSeveral panes are watched: start each comment with the id of the pane it is about, e.g. [%3]."
+	}
+	budget := m.newWatchBudget()
+	hashes := m.watchPaneHashes()
+	last := combinePaneHashes(hashes)
+	message := desc
+	for m.GetStatus() != "" && m.GetWatchMode() {
+		if !budget.wait(ctx) {
+			return
+		}
+		if m.ProcessUserMessage(ctx, message) {
+			m.SetStatus("")
+			return
+		}
+		if m.watchBudgetSpent(budget) {
+			return
+		}
+		if message == desc {
+			m.Println(i18n.T("Watching for changes, Ctrl+C to stop"))
+		}
+
+		var changed bool
+		fingerprint := func() uint64 { return combinePaneHashes(m.watchPaneHashes()) }
+		if last, changed = m.waitForChange(ctx, fingerprint, last); !changed {
+			return
+		}
+		previous := hashes
+		hashes = m.watchPaneHashes()
+		message = "The pane(s) changed, here is the updated content"
+		if ids := changedPanes(previous, hashes); len(ids) > 0 {
+			message = fmt.Sprintf("Pane(s) %s changed, here is the updated content", strings.Join(ids, ", "))
+		}
+	}
+}
+
+// watchPattern polls the watched panes and runs the action of the watch for new lines
+// matching its pattern. The model is only asked when a match asks for a comment.
+func (m *Manager) watchPattern(ctx context.Context) {
+	defer func() { m.SetWatchMode(false) }()
+	poll := time.Duration(max(m.GetConfig().Watch.PollInterval, 50)) * time.Millisecond
+	budget := m.newWatchBudget()
+	previous := m.watchCaptures()
+	m.Println(i18n.T("Watching for %s (%s), Ctrl+C to stop", m.watchOpts.pattern, m.watchOpts.action))
+	for {
+		if sleepContext(ctx, poll) != nil || m.GetStatus() == "" || !m.GetWatchMode() {
+			return
+		}
+		current := m.watchCaptures()
+		ids := make([]string, 0, len(current))
+		for id := range current {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		for _, id := range ids {
+			matches := matchingLines(m.watchOpts.pattern, newLines(previous[id], current[id]))
+			if len(matches) == 0 {
+				continue
+			}
+			if m.watchOpts.action == watchActionComment && !budget.wait(ctx) {
+				return
+			}
+			if m.runWatchAction(ctx, id, matches) {
+				m.SetStatus("")
+				return
+			}
+			if m.watchBudgetSpent(budget) {
+				return
+			}
+		}
+		previous = current
+	}
+}
+
+// runWatchAction handles the lines of a pane that matched the watch pattern. It reports
+// whether the model considers the watch done.
+func (m *Manager) runWatchAction(ctx context.Context, paneId string, matches []string) bool {
+	text := strings.Join(matches, "\n")
+	logger.Info("Watch pattern matched in pane %s: %s", paneId, text)
+	switch m.watchOpts.action {
+	case watchActionNotify:
+		title := i18n.T("TmuxAI watch: %s matched in pane %s", m.watchOpts.pattern, paneId)
+		m.Println(title + "\n" + text)
+		m.notify(NotifyWatch, title, text)
+		// asked for explicitly, so shown whatever notifications.alerts says
+		go func() {
+			if err := system.DesktopNotify(title, text); err != nil {
+				logger.Error("Failed to show desktop notification: %v", err)
+			}
+		}()
+		if err := system.TmuxDisplayMessage(m.PaneId, title); err != nil {
+			logger.Error("Failed to show tmux message: %v", err)
+		}
+	case watchActionCommand:
+		m.Println(i18n.T("Pattern matched in pane %s, running: %s", paneId, m.watchOpts.command))
+		m.alert(AlertWatch, i18n.T("TmuxAI watch: %s matched in pane %s", m.watchOpts.pattern, paneId), text)
+		out, err := m.runHook(m.watchOpts.command, hookPayload{Event: "watch", Command: m.watchOpts.command, Pane: paneId, Output: text})
+		if err != nil {
+			m.Println(i18n.T("Watch command failed: %v %s", err, strings.TrimSpace(out)))
+		}
+	default:
+		message := fmt.Sprintf("New output in pane %s matched the pattern %s:\n%s", paneId, m.watchOpts.pattern, text)
+		if m.watchOpts.desc != "" {
+			message += "\n\nWatch for: " + m.watchOpts.desc
+		}
+		return m.ProcessUserMessage(ctx, message)
+	}
+	return false
+}
+
+// watchBudget spaces and caps the model calls of a watch
+type watchBudget struct {
+	interval time.Duration
+	maxCalls int // 0 for no limit
+	start    int // model requests of the session when the watch started
+	lastCall time.Time
+}
+
+// newWatchBudget applies the /watch flags over the watch config; --max-calls can only
+// lower watch.max_calls, so the config stays a cap for forgotten watches
+func (m *Manager) newWatchBudget() *watchBudget {
+	cfg := m.GetConfig().Watch
+	b := &watchBudget{interval: time.Duration(cfg.Interval) * time.Second, maxCalls: cfg.MaxCalls, start: m.stats.requests()}
+	if m.watchOpts.interval > 0 {
+		b.interval = m.watchOpts.interval
+	}
+	if m.watchOpts.maxCalls > 0 && (b.maxCalls == 0 || m.watchOpts.maxCalls < b.maxCalls) {
+		b.maxCalls = m.watchOpts.maxCalls
+	}
+	return b
+}
+
+// wait sleeps until the interval passed since the last call and counts the next one;
+// false when ctx is cancelled meanwhile
+func (b *watchBudget) wait(ctx context.Context) bool {
+	if remaining := b.interval - time.Since(b.lastCall); !b.lastCall.IsZero() && remaining > 0 {
+		if sleepContext(ctx, remaining) != nil {
+			return false
+		}
+	}
+	b.lastCall = time.Now()
+	return true
+}
+
+// watchBudgetSpent stops the watch once it made its maximum number of model calls
+func (m *Manager) watchBudgetSpent(b *watchBudget) bool {
+	calls := m.stats.requests() - b.start
+	if b.maxCalls <= 0 || calls < b.maxCalls {
+		return false
+	}
+	message := i18n.T("Watch stopped after %d model calls, the limit of watch.max_calls or --max-calls", calls)
+	m.Println(message)
+	m.notify(NotifyBudget, i18n.T("TmuxAI watch budget exhausted"), message)
+	m.SetStatus("")
+	return true
+}
+
+// watchCaptures captures what each watched pane shows
+func (m *Manager) watchCaptures() map[string]string {
+	captures := map[string]string{}
+	for _, pane := range m.watchedPanes() {
+		captures[pane.Id], _ = system.TmuxCapturePane(pane.Id, m.GetMaxCaptureLines())
+	}
+	return captures
+}
+
+// newLines returns the lines of cur below the end of prev, found by the last lines of
+// prev (fewer of them as they scroll out); everything is new when they are gone, e.g.
+// after the pane was cleared
+func newLines(prev, cur string) []string {
+	curLines := strings.Split(strings.TrimRight(cur, "\n "), "\n")
+	prevLines := strings.Split(strings.TrimRight(prev, "\n "), "\n")
+	if strings.TrimSpace(prev) == "" {
+		return curLines
+	}
+	for n := min(3, len(prevLines)); n > 0; n-- {
+		anchor := prevLines[len(prevLines)-n:]
+		for end := len(curLines); end >= n; end-- {
+			if slices.Equal(curLines[end-n:end], anchor) {
+				return curLines[end:]
+			}
+		}
+	}
+	return curLines
+}
+
+// matchingLines returns the lines re matches
+func matchingLines(re *regexp.Regexp, lines []string) []string {
+	var matches []string
+	for _, line := range lines {
+		if re.MatchString(line) {
+			matches = append(matches, line)
+		}
+	}
+	return matches
+}
+
+// watchedPanes lists the panes a watch looks at: those given with --panes, every pane of
+// the session for --panes all, or the chat pane's window. The chat pane is left out,
+// its output changes with each comment.
+func (m *Manager) watchedPanes() []system.TmuxPaneDetails {
+	var panes []system.TmuxPaneDetails
+	switch {
+	case m.watchOpts.all:
+		panes, _ = system.TmuxSessionPanes(m.PaneId)
+	case len(m.watchOpts.panes) > 0:
+		for _, id := range m.watchOpts.panes {
+			details, _ := system.TmuxPanesDetails(id)
+			panes = append(panes, details...)
+		}
+	default:
+		panes, _ = system.TmuxWindowPanes(m.PaneId)
+	}
+	return slices.DeleteFunc(panes, func(p system.TmuxPaneDetails) bool { return p.Id == m.PaneId })
+}
+
+// watchPaneHashes hashes what each watched pane shows
+func (m *Manager) watchPaneHashes() map[string]uint64 {
+	hashes := map[string]uint64{}
+	for _, pane := range m.watchedPanes() {
+		content, _ := system.TmuxCapturePane(pane.Id, m.GetMaxCaptureLines())
+		h := fnv.New64a()
+		io.WriteString(h, content)
+		hashes[pane.Id] = h.Sum64()
+	}
+	return hashes
+}
+
+// combinePaneHashes folds the pane hashes into one fingerprint, independent of map order
+func combinePaneHashes(hashes map[string]uint64) uint64 {
+	ids := make([]string, 0, len(hashes))
+	for id := range hashes {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	h := fnv.New64a()
+	for _, id := range ids {
+		fmt.Fprintf(h, "%s\x00%d\x00", id, hashes[id])
+	}
+	return h.Sum64()
+}
+
+// changedPanes lists the panes whose content differs between two sets of hashes,
+// including panes that appeared or closed
+func changedPanes(before, after map[string]uint64) []string {
+	var ids []string
+	for id, hash := range after {
+		if old, ok := before[id]; !ok || old != hash {
+			ids = append(ids, id)
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// waitForChange polls fingerprint every watch.poll_interval until it differs from last and
+// then stays the same for watch.debounce. It returns the new fingerprint, or false when
+// ctx is cancelled or the watch is stopped first.
+func (m *Manager) waitForChange(ctx context.Context, fingerprint func() uint64, last uint64) (uint64, bool) {
+	poll := time.Duration(max(m.GetConfig().Watch.PollInterval, 50)) * time.Millisecond
+	debounce := time.Duration(m.GetConfig().Watch.Debounce) * time.Millisecond
+
+	current := last
+	var changedAt time.Time
+	for {
+		if sleepContext(ctx, poll) != nil || m.GetStatus() == "" || !m.GetWatchMode() {
+			return last, false
+		}
+		next := fingerprint()
+		if next != current {
+			current, changedAt = next, time.Now()
+		}
+		if current != last && time.Since(changedAt) >= debounce {
+			return current, true
+		}
+	}
+}
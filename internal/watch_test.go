@@ -0,0 +1,157 @@
+// Unit tests for change detection in watch.go
+package internal
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+func newWatchTestManager() *Manager {
+	cfg := config.DefaultConfig()
+	cfg.Watch.PollInterval = 1
+	cfg.Watch.Debounce = 100
+	return &Manager{Config: cfg, status: "running", watchMode: true}
+}
+
+// Test: a change is reported only once the panes stop changing for the debounce time
+func TestWaitForChangeDebounces(t *testing.T) {
+	m := newWatchTestManager()
+	var value atomic.Uint64
+	value.Store(1)
+	go func() {
+		for i := uint64(2); i <= 5; i++ {
+			time.Sleep(20 * time.Millisecond)
+			value.Store(i)
+		}
+	}()
+
+	started := time.Now()
+	got, changed := m.waitForChange(context.Background(), value.Load, 1)
+	if !changed || got != 5 {
+		t.Fatalf("expected the settled value 5, got %d (changed %t)", got, changed)
+	}
+	if elapsed := time.Since(started); elapsed < 180*time.Millisecond {
+		t.Errorf("returned after %v, before the panes settled", elapsed)
+	}
+}
+
+// Test: idle panes never trigger, cancelling stops the wait
+func TestWaitForChangeIdle(t *testing.T) {
+	m := newWatchTestManager()
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	if _, changed := m.waitForChange(ctx, func() uint64 { return 7 }, 7); changed {
+		t.Error("idle panes reported a change")
+	}
+}
+
+// Test: --panes takes a list of ids or all, the rest is the description
+func TestParseWatchArgs(t *testing.T) {
+	opts, err := parseWatchArgs([]string{"--panes", "3,%7", "deploy", "failures"})
+	if err != nil || !reflect.DeepEqual(opts.panes, []string{"%3", "%7"}) || opts.desc != "deploy failures" {
+		t.Errorf("unexpected options %+v, %v", opts, err)
+	}
+	opts, err = parseWatchArgs([]string{"--panes=all", "errors"})
+	if err != nil || !opts.all || !opts.scoped() || opts.desc != "errors" {
+		t.Errorf("unexpected options %+v, %v", opts, err)
+	}
+	if _, err := parseWatchArgs([]string{"--interval"}); err == nil {
+		t.Error("expected an error for a flag without a value")
+	}
+	if opts, _ := parseWatchArgs([]string{"errors"}); opts.scoped() {
+		t.Error("plain /watch should watch the window")
+	}
+}
+
+// Test: the changed panes are named, including panes that appeared or closed
+func TestChangedPanes(t *testing.T) {
+	before := map[string]uint64{"%1": 1, "%2": 2, "%3": 3}
+	after := map[string]uint64{"%1": 1, "%2": 5, "%4": 4}
+	if got := changedPanes(before, after); !reflect.DeepEqual(got, []string{"%2", "%3", "%4"}) {
+		t.Errorf("unexpected changed panes %v", got)
+	}
+	if combinePaneHashes(before) == combinePaneHashes(after) {
+		t.Error("fingerprint did not change")
+	}
+}
+
+// Test: --pattern defaults to comments, --command implies the command action
+func TestParseWatchPattern(t *testing.T) {
+	opts, err := parseWatchArgs(splitArgs(`--pattern "panic:|OOM Killed" --action notify`))
+	if err != nil || opts.pattern.String() != "panic:|OOM Killed" || opts.action != watchActionNotify {
+		t.Errorf("unexpected options %+v, %v", opts, err)
+	}
+	opts, err = parseWatchArgs(splitArgs(`--pattern FAIL --command 'say tests failed' the test runner`))
+	if err != nil || opts.action != watchActionCommand || opts.command != "say tests failed" || opts.desc != "the test runner" {
+		t.Errorf("unexpected options %+v, %v", opts, err)
+	}
+	for _, args := range []string{`--pattern "("`, `--action notify errors`, `--pattern x --action command`, `--pattern x --action page`} {
+		if _, err := parseWatchArgs(splitArgs(args)); err == nil {
+			t.Errorf("expected an error for %s", args)
+		}
+	}
+}
+
+// Test: only lines below the previous capture are new, a cleared pane is new entirely
+func TestNewLines(t *testing.T) {
+	prev := "$ make\nbuilding\nok\n"
+	cases := []struct {
+		cur  string
+		want []string
+	}{
+		{prev, []string{}},
+		{"$ make\nbuilding\nok\npanic: nil map\n\n", []string{"panic: nil map"}},
+		{"building\nok\nstep 2\npanic: boom", []string{"step 2", "panic: boom"}},
+		{"fresh screen", []string{"fresh screen"}},
+	}
+	for _, c := range cases {
+		if got := newLines(prev, c.cur); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("newLines(%q) = %q, want %q", c.cur, got, c.want)
+		}
+	}
+	re := regexp.MustCompile(`panic:|OOMKilled`)
+	if got := matchingLines(re, []string{"ok", "panic: boom", "pod OOMKilled"}); len(got) != 2 {
+		t.Errorf("unexpected matches %q", got)
+	}
+}
+
+// Test: --max-calls only lowers the configured cap and the watch stops once it is spent
+func TestWatchBudget(t *testing.T) {
+	m := newWatchTestManager()
+	m.Config.Watch.MaxCalls = 3
+	m.watchOpts = watchOptions{maxCalls: 10, interval: 50 * time.Millisecond}
+	b := m.newWatchBudget()
+	if b.maxCalls != 3 || b.interval != 50*time.Millisecond {
+		t.Fatalf("unexpected budget %+v", b)
+	}
+
+	started := time.Now()
+	for i := 0; i < 2; i++ {
+		if !b.wait(context.Background()) {
+			t.Fatal("wait cancelled")
+		}
+		m.stats.recordRequest(0, "model", nil, "", nil, nil)
+	}
+	if time.Since(started) < 50*time.Millisecond {
+		t.Error("calls not spaced by the interval")
+	}
+	if m.watchBudgetSpent(b) {
+		t.Error("budget spent after 2 of 3 calls")
+	}
+	m.stats.recordRequest(0, "model", nil, "", nil, nil)
+	if !m.watchBudgetSpent(b) || m.GetStatus() != "" {
+		t.Error("watch not stopped after 3 calls")
+	}
+
+	opts, err := parseWatchArgs([]string{"--interval", "10", "--max-calls", "5", "errors"})
+	if err != nil || opts.interval != 10*time.Second || opts.maxCalls != 5 {
+		t.Errorf("unexpected options %+v, %v", opts, err)
+	}
+}
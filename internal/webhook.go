@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/alvinunreal/tmuxai/config"
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+const maxWebhookBody = 1 << 20
+
+var webhookFuncs = template.FuncMap{
+	"json": func(v interface{}) string {
+		data, _ := json.MarshalIndent(v, "", "  ")
+		return string(data)
+	},
+}
+
+// handleWebhook turns POST /api/webhooks/<name> into a chat message or watch, based on config
+func (s *APIServer) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/api/webhooks/")
+	hook, ok := s.manager.GetConfig().Server.Webhooks[name]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown webhook: %s", name)})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	message, err := renderWebhookMessage(name, hook, body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	watch := false
+	switch hook.Action {
+	case "", "message":
+	case "watch":
+		watch = true
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("unknown webhook action: %s", hook.Action)})
+		return
+	}
+
+	go s.manager.handleWebhookMessage(name, message, watch)
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+// handleWebhookMessage runs a rendered webhook message as a request, or watches for what
+// it describes. The sender controls the text, so it never runs as a /command.
+func (m *Manager) handleWebhookMessage(name, message string, watch bool) {
+	defer m.recoverPanic()
+	m.turnMu.Lock()
+	defer m.turnMu.Unlock()
+
+	source := "webhook:" + name
+	logger.Info("External message from %s: %s", source, message)
+	fmt.Fprintf(m.out(), "\n%s[%s] %s\n", m.GetPrompt(), source, message)
+
+	if watch {
+		m.ready()
+		m.startWatch(context.Background(), watchOptions{desc: message})
+		return
+	}
+	m.runRequest(context.Background(), message)
+}
+
+// renderWebhookMessage renders the webhook template with the decoded JSON payload.
+// Without a template the raw payload is passed along.
+func renderWebhookMessage(name string, hook config.WebhookConfig, body []byte) (string, error) {
+	if hook.Template == "" {
+		return fmt.Sprintf("Webhook %s received, investigate it:\n%s", name, string(body)), nil
+	}
+
+	var payload interface{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return "", fmt.Errorf("invalid JSON payload: %w", err)
+		}
+	}
+
+	tmpl, err := template.New(name).Funcs(webhookFuncs).Option("missingkey=zero").Parse(hook.Template)
+	if err != nil {
+		return "", fmt.Errorf("invalid template for webhook %s: %w", name, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, payload); err != nil {
+		return "", fmt.Errorf("failed to render webhook %s: %w", name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
@@ -0,0 +1,60 @@
+// Unit tests for webhook rendering in webhook.go
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alvinunreal/tmuxai/config"
+)
+
+// Test: template fields are rendered from the JSON payload
+func TestRenderWebhookMessage_Template(t *testing.T) {
+	hook := config.WebhookConfig{Template: "Alert {{ .labels.alertname }} is {{ .status }}"}
+	got, err := renderWebhookMessage("alerts", hook, []byte(`{"status": "firing", "labels": {"alertname": "DiskFull"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Alert DiskFull is firing" {
+		t.Errorf("got %q", got)
+	}
+}
+
+// Test: without a template the raw payload is passed along
+func TestRenderWebhookMessage_NoTemplate(t *testing.T) {
+	got, err := renderWebhookMessage("ci", config.WebhookConfig{}, []byte(`{"job": "build"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(got, `{"job": "build"}`) || !strings.Contains(got, "ci") {
+		t.Errorf("got %q", got)
+	}
+}
+
+// Test: invalid JSON is rejected when a template is used
+func TestRenderWebhookMessage_InvalidJSON(t *testing.T) {
+	hook := config.WebhookConfig{Template: "{{ .status }}"}
+	if _, err := renderWebhookMessage("ci", hook, []byte(`not json`)); err == nil {
+		t.Errorf("expected an error for invalid JSON")
+	}
+}
+
+// Test: a webhook message that looks like a /command is sent to the model, not run
+func TestHandleWebhookMessage_NoCommands(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ExecConfirm = true
+	provider, err := NewMockProvider(config.MockConfig{Responses: []string{"Noted.\n<RequestAccomplished>true</RequestAccomplished>"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManagerForPane(cfg, "", provider)
+
+	m.handleWebhookMessage("alerts", "/config set exec_confirm false", false)
+	if !m.GetExecConfirm() {
+		t.Error("expected the webhook not to change the config")
+	}
+	history := m.MessageHistory()
+	if len(history) == 0 || !strings.Contains(history[0].Content, "/config set exec_confirm false") {
+		t.Errorf("expected the message to go to the model, got %+v", history)
+	}
+}
@@ -5,9 +5,44 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 )
 
+// Level is the minimum severity written to the log
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+var levelNames = []string{"error", "warn", "info", "debug"}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel reads a level name: error, warn, info or debug
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level: %s (use %s)", name, strings.Join(levelNames, ", "))
+}
+
+// Rotation limits the log file; zero values turn a limit off
+type Rotation struct {
+	MaxSizeMB  int // rotate when the file grows past this size
+	MaxAgeDays int // rotate a file older than this at startup and delete older backups
+	MaxBackups int // rotated files kept as tmuxai.log.1, .2, ...
+}
+
 var (
 	instance *Logger
 	once     sync.Once
@@ -15,9 +50,13 @@ var (
 
 // Logger represents a custom logger for TmuxAI
 type Logger struct {
-	logFile *os.File
-	logger  *log.Logger
-	mu      sync.Mutex
+	logFile  *os.File
+	logger   *log.Logger
+	mu       sync.Mutex
+	path     string
+	size     int64
+	level    Level
+	rotation Rotation
 }
 
 // Init initializes the logger
@@ -41,19 +80,90 @@ func newLogger() (*Logger, error) {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
-	logPath := filepath.Join(logDir, "tmuxai.log")
-	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	l := &Logger{path: filepath.Join(logDir, "tmuxai.log"), level: LevelInfo}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Logger) open() error {
+	logFile, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %w", err)
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	l.size = 0
+	if info, err := logFile.Stat(); err == nil {
+		l.size = info.Size()
+	}
+	l.logFile = logFile
+	l.logger = log.New(logFile, "", log.LstdFlags)
+	return nil
+}
+
+// Configure sets the level and the rotation limits; a log file older than MaxAgeDays
+// is rotated right away
+func (l *Logger) Configure(level Level, rotation Rotation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+	l.rotation = rotation
+	if info, err := l.logFile.Stat(); err == nil && info.Size() > 0 && l.tooOld(info.ModTime()) {
+		l.rotate()
 	}
+}
+
+// SetLevel changes the minimum level written to the log
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+func (l *Logger) tooOld(t time.Time) bool {
+	return l.rotation.MaxAgeDays > 0 && time.Since(t) > time.Duration(l.rotation.MaxAgeDays)*24*time.Hour
+}
 
-	logger := log.New(logFile, "", log.LstdFlags)
+// rotate shifts tmuxai.log to tmuxai.log.1 and so on, dropping backups past MaxBackups
+// or MaxAgeDays, and starts a new file. The caller holds l.mu.
+func (l *Logger) rotate() {
+	l.logFile.Close()
+	backups := max(l.rotation.MaxBackups, 0)
+	os.Remove(fmt.Sprintf("%s.%d", l.path, backups))
+	for i := backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if backups > 0 {
+		os.Rename(l.path, l.path+".1")
+	} else {
+		os.Remove(l.path)
+	}
+	for i := 1; i <= backups; i++ {
+		backup := fmt.Sprintf("%s.%d", l.path, i)
+		if info, err := os.Stat(backup); err == nil && l.tooOld(info.ModTime()) {
+			os.Remove(backup)
+		}
+	}
+	if err := l.open(); err != nil {
+		// keep logging somewhere rather than panic on a closed file
+		l.logger = log.New(os.Stderr, "", log.LstdFlags)
+		l.logFile = os.Stderr
+	}
+}
 
-	return &Logger{
-		logFile: logFile,
-		logger:  logger,
-		mu:      sync.Mutex{},
-	}, nil
+// write logs one line when level is enabled and rotates the file once it is full
+func (l *Logger) write(level Level, format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level > l.level {
+		return
+	}
+	line := fmt.Sprintf("[%s] "+format, append([]interface{}{strings.ToUpper(level.String())}, v...)...)
+	l.logger.Print(line)
+	l.size += int64(len(line)) + 20 // timestamp and newline
+	if l.rotation.MaxSizeMB > 0 && l.size >= int64(l.rotation.MaxSizeMB)<<20 {
+		l.rotate()
+	}
 }
 
 // GetInstance returns the singleton logger instance
@@ -64,6 +174,13 @@ func GetInstance() (*Logger, error) {
 	return instance, nil
 }
 
+// Flush writes buffered log lines through to disk
+func (l *Logger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.logFile.Sync()
+}
+
 // Close closes the logger
 func (l *Logger) Close() error {
 	l.mu.Lock()
@@ -73,23 +190,22 @@ func (l *Logger) Close() error {
 
 // Info logs an info message
 func (l *Logger) Info(format string, v ...interface{}) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	l.logger.Printf("[INFO] "+format, v...)
+	l.write(LevelInfo, format, v...)
+}
+
+// Warn logs a warning
+func (l *Logger) Warn(format string, v ...interface{}) {
+	l.write(LevelWarn, format, v...)
 }
 
 // Error logs an error message
 func (l *Logger) Error(format string, v ...interface{}) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	l.logger.Printf("[ERROR] "+format, v...)
+	l.write(LevelError, format, v...)
 }
 
 // Debug logs a debug message
 func (l *Logger) Debug(format string, v ...interface{}) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-	l.logger.Printf("[DEBUG] "+format, v...)
+	l.write(LevelDebug, format, v...)
 }
 
 // Info logs an info message using the singleton instance
@@ -99,6 +215,13 @@ func Info(format string, v ...interface{}) {
 	}
 }
 
+// Warn logs a warning using the singleton instance
+func Warn(format string, v ...interface{}) {
+	if instance != nil {
+		instance.Warn(format, v...)
+	}
+}
+
 // Error logs an error message using the singleton instance
 func Error(format string, v ...interface{}) {
 	if instance != nil {
@@ -112,3 +235,24 @@ func Debug(format string, v ...interface{}) {
 		instance.Debug(format, v...)
 	}
 }
+
+// Flush syncs the log file of the singleton instance
+func Flush() {
+	if instance != nil {
+		_ = instance.Flush()
+	}
+}
+
+// Configure sets the level and rotation of the singleton instance
+func Configure(level Level, rotation Rotation) {
+	if instance != nil {
+		instance.Configure(level, rotation)
+	}
+}
+
+// SetLevel changes the level of the singleton instance
+func SetLevel(level Level) {
+	if instance != nil {
+		instance.SetLevel(level)
+	}
+}
@@ -0,0 +1,83 @@
+// Unit tests for levels and rotation in logger.go
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger(t *testing.T) *Logger {
+	l := &Logger{path: filepath.Join(t.TempDir(), "tmuxai.log"), level: LevelInfo}
+	if err := l.open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// Test: messages below the configured level are dropped
+func TestLoggerLevel(t *testing.T) {
+	l := newTestLogger(t)
+	l.SetLevel(LevelWarn)
+	l.Info("hidden")
+	l.Debug("hidden")
+	l.Warn("shown %d", 1)
+	l.Error("shown %d", 2)
+
+	content, _ := os.ReadFile(l.path)
+	if strings.Contains(string(content), "hidden") {
+		t.Errorf("expected info and debug to be dropped:\n%s", content)
+	}
+	if !strings.Contains(string(content), "[WARN] shown 1") || !strings.Contains(string(content), "[ERROR] shown 2") {
+		t.Errorf("expected warn and error lines:\n%s", content)
+	}
+
+	if level, err := ParseLevel("DEBUG"); err != nil || level != LevelDebug {
+		t.Errorf("unexpected level %v, %v", level, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("expected an error for an unknown level")
+	}
+}
+
+// Test: a full log is rotated into numbered backups, keeping max_backups of them
+func TestLoggerRotateSize(t *testing.T) {
+	l := newTestLogger(t)
+	l.Configure(LevelInfo, Rotation{MaxSizeMB: 1, MaxBackups: 2})
+	line := strings.Repeat("x", 1<<10)
+	for range 3 * 1024 {
+		l.Info("%s", line)
+	}
+
+	for _, name := range []string{l.path, l.path + ".1", l.path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("expected %s: %v", filepath.Base(name), err)
+		}
+	}
+	if _, err := os.Stat(l.path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups")
+	}
+	if info, _ := os.Stat(l.path); info.Size() > 1<<20 {
+		t.Errorf("expected the active log under the limit, got %d bytes", info.Size())
+	}
+}
+
+// Test: a log older than max_age_days is rotated when configured
+func TestLoggerRotateAge(t *testing.T) {
+	l := newTestLogger(t)
+	l.Info("old line")
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(l.path, old, old)
+
+	l.Configure(LevelInfo, Rotation{MaxAgeDays: 1, MaxBackups: 1})
+	content, _ := os.ReadFile(l.path)
+	if strings.Contains(string(content), "old line") {
+		t.Errorf("expected a fresh log after rotation")
+	}
+	if _, err := os.Stat(l.path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("expected the old backup to be deleted for its age")
+	}
+}
@@ -0,0 +1,26 @@
+package system
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer keeps an unusually large capture from pinning its memory in the pool
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// getBuffer returns an empty buffer from the pool; captures run every few seconds in
+// watch mode and reuse the same memory this way
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns a buffer to the pool, the caller must not use it afterwards
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
@@ -0,0 +1,56 @@
+package system
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultCaptureTTL is how long a pane capture is reused when not configured
+const DefaultCaptureTTL = 300 * time.Millisecond
+
+type captureEntry struct {
+	content  string
+	maxLines int
+	at       time.Time
+}
+
+// captureCache keeps recent pane captures, so /info, context assembly and busy
+// detection in the same turn share one capture-pane call per pane
+var captureCache = struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[string]captureEntry
+}{ttl: DefaultCaptureTTL, entries: map[string]captureEntry{}}
+
+// SetCaptureTTL sets how long pane captures are reused, 0 turns the cache off
+func SetCaptureTTL(ttl time.Duration) {
+	captureCache.Lock()
+	defer captureCache.Unlock()
+	captureCache.ttl = ttl
+	clear(captureCache.entries)
+}
+
+// InvalidatePaneCapture drops the cached capture of a pane, after sending it input
+func InvalidatePaneCapture(paneId string) {
+	captureCache.Lock()
+	defer captureCache.Unlock()
+	delete(captureCache.entries, paneId)
+}
+
+func cachedCapture(paneId string, maxLines int) (string, bool) {
+	captureCache.Lock()
+	defer captureCache.Unlock()
+	entry, ok := captureCache.entries[paneId]
+	if !ok || entry.maxLines != maxLines || time.Since(entry.at) >= captureCache.ttl {
+		return "", false
+	}
+	return entry.content, true
+}
+
+func storeCapture(paneId string, maxLines int, content string) {
+	captureCache.Lock()
+	defer captureCache.Unlock()
+	if captureCache.ttl > 0 {
+		captureCache.entries[paneId] = captureEntry{content: content, maxLines: maxLines, at: time.Now()}
+	}
+}
@@ -0,0 +1,43 @@
+// Unit tests for the pane capture cache in capture_cache.go
+package system
+
+import (
+	"testing"
+	"time"
+)
+
+// Test: captures are reused within the TTL for the same line count only
+func TestCaptureCache(t *testing.T) {
+	SetCaptureTTL(time.Minute)
+	defer SetCaptureTTL(DefaultCaptureTTL)
+
+	storeCapture("%1", 200, "content")
+	if got, ok := cachedCapture("%1", 200); !ok || got != "content" {
+		t.Errorf("expected a cache hit, got %q %v", got, ok)
+	}
+	if _, ok := cachedCapture("%1", 100); ok {
+		t.Errorf("expected a miss for a different line count")
+	}
+	InvalidatePaneCapture("%1")
+	if _, ok := cachedCapture("%1", 200); ok {
+		t.Errorf("expected a miss after invalidation")
+	}
+}
+
+// Test: captures expire after the TTL and a zero TTL disables the cache
+func TestCaptureCacheTTL(t *testing.T) {
+	SetCaptureTTL(time.Millisecond)
+	defer SetCaptureTTL(DefaultCaptureTTL)
+
+	storeCapture("%1", 200, "content")
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := cachedCapture("%1", 200); ok {
+		t.Errorf("expected the capture to expire")
+	}
+
+	SetCaptureTTL(0)
+	storeCapture("%1", 200, "content")
+	if _, ok := cachedCapture("%1", 200); ok {
+		t.Errorf("expected no caching with a zero TTL")
+	}
+}
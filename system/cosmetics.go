@@ -9,9 +9,15 @@ import (
 // Cosmetics processes a message string, applying terminal formatting to markdown code blocks
 // (triple backticks, optionally with language) and inline code (single backticks).
 // - Code blocks are highlighted using HighlightCode.
-// - Inline code is rendered with a gray background and yellow text (ANSI codes).
+// - Inline code is rendered with the inline code color of the current theme.
 // All other text is left as-is.
 func Cosmetics(message string) string {
+	return CosmeticsTheme(message, DefaultHighlightTheme)
+}
+
+// CosmeticsTheme is Cosmetics with the chroma style for code blocks,
+// an empty theme prints code blocks without highlighting
+func CosmeticsTheme(message, theme string) string {
 	// Regex for code blocks: ```lang\ncode\n``` (allowing spaces before the backticks)
 	codeBlockRe := regexp.MustCompile(`(?s)\x60{3}([a-zA-Z0-9-_]*)\s*\n(.*?)\s*\n\x60{3}`)
 	// Regex for inline code: `code`
@@ -36,7 +42,7 @@ func Cosmetics(message string) string {
 		code := message[codeStart:codeEnd]
 
 		// Highlight code block
-		highlighted, err := HighlightCode(lang, code)
+		highlighted, err := HighlightCodeTheme(lang, code, theme)
 		if err != nil {
 			// Fallback: print as plain code block
 			highlighted = fmt.Sprintf("\n%s\n", code)
@@ -51,11 +57,7 @@ func Cosmetics(message string) string {
 
 // processInlineCode finds inline code (single backticks) and applies ANSI formatting.
 func processInlineCode(text string, inlineCodeRe *regexp.Regexp) string {
-	const (
-		bgDarkBlue = "\033[48;5;235m"
-		fgCyan     = "\033[38;5;51m"
-		reset      = "\033[0m"
-	)
+	inlineColor := CurrentTheme().InlineCode
 	result := ""
 	lastIndex := 0
 	matches := inlineCodeRe.FindAllStringSubmatchIndex(text, -1)
@@ -66,7 +68,7 @@ func processInlineCode(text string, inlineCodeRe *regexp.Regexp) string {
 		result += text[lastIndex:start]
 		// Add formatted inline code
 		code := text[codeStart:codeEnd]
-		result += bgDarkBlue + fgCyan + code + reset
+		result += inlineColor.Sprint(code)
 		lastIndex = end
 	}
 	// Add any remaining text
@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/fatih/color"
 )
 
 func stripANSI(s string) string {
@@ -12,7 +14,15 @@ func stripANSI(s string) string {
 	return re.ReplaceAllString(s, "")
 }
 
+// forceColor turns colors on for the test, go test output is not a terminal
+func forceColor(t *testing.T, enabled bool) {
+	noColor := color.NoColor
+	color.NoColor = !enabled
+	t.Cleanup(func() { color.NoColor = noColor })
+}
+
 func TestCosmetics(t *testing.T) {
+	forceColor(t, true)
 	ansiPattern := regexp.MustCompile(`\x1b\[[0-9;]*m`)
 	tests := []struct {
 		name        string
@@ -89,3 +99,35 @@ func TestCosmetics(t *testing.T) {
 		})
 	}
 }
+
+// Test: an empty theme leaves code blocks unhighlighted while stripping the fences
+func TestCosmeticsThemeDisabled(t *testing.T) {
+	out := CosmeticsTheme("Run:\n```sh\nls -la\n```", "")
+	if out != "Run:\nls -la" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+// Test: unknown themes fall back to the default style instead of failing
+func TestHighlightCodeThemeFallback(t *testing.T) {
+	got, err := HighlightCodeTheme("sh", "ls -la", "no-such-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := HighlightCode("sh", "ls -la")
+	if got != want {
+		t.Errorf("expected default theme output, got %q", got)
+	}
+	if !IsHighlightTheme("dracula") || IsHighlightTheme("no-such-theme") {
+		t.Error("IsHighlightTheme does not match the chroma registry")
+	}
+}
+
+// Test: with colors disabled (NO_COLOR, dumb or non-TTY output) code is printed as plain text
+func TestCosmeticsNoColor(t *testing.T) {
+	forceColor(t, false)
+	out := Cosmetics("Run `ls`:\n```sh\nls -la\n```")
+	if out != "Run ls:\nls -la" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
@@ -0,0 +1,238 @@
+package system
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// UnifiedDiff returns the changes from oldText to newText as a unified diff,
+// or "" when they are equal. An empty oldName marks a new file.
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+	hunks := diffHunks(ops)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	if oldName == "" {
+		oldName = "/dev/null"
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldLines), hunkRange(h.newStart, h.newLines))
+		for _, op := range h.ops {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// ColorDiff colors a unified diff with the current theme
+func ColorDiff(diff string) string {
+	t := CurrentTheme()
+	var b strings.Builder
+	for _, line := range splitLines(diff) {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			line = t.Label.Sprint(line)
+		case strings.HasPrefix(line, "@@"):
+			line = t.Header.Sprint(line)
+		case strings.HasPrefix(line, "+"):
+			line = t.Success.Sprint(line)
+		case strings.HasPrefix(line, "-"):
+			line = t.Error.Sprint(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// SideBySideDiff renders the changed regions with the old text on the left and the new
+// text on the right, each column fitted to half of width
+func SideBySideDiff(oldText, newText string, width int) string {
+	hunks := diffHunks(diffLines(splitLines(oldText), splitLines(newText)))
+	if len(hunks) == 0 {
+		return ""
+	}
+	t := CurrentTheme()
+	column := max((width-3)/2, 10)
+	cell := func(text string) string {
+		text = ansi.Truncate(strings.ReplaceAll(text, "\t", "    "), column, Sym("…"))
+		return text + strings.Repeat(" ", column-ansi.StringWidth(text))
+	}
+
+	var b strings.Builder
+	for _, h := range hunks {
+		b.WriteString(t.Header.Sprintf("@@ -%s +%s @@", hunkRange(h.oldStart, h.oldLines), hunkRange(h.newStart, h.newLines)) + "\n")
+		ops := h.ops
+		for len(ops) > 0 {
+			if ops[0].kind == ' ' {
+				b.WriteString(cell(ops[0].line) + Sym(" │ ") + ops[0].line + "\n")
+				ops = ops[1:]
+				continue
+			}
+			// pair a run of removed lines with the added lines that follow it
+			var removed, added []string
+			for len(ops) > 0 && ops[0].kind == '-' {
+				removed = append(removed, ops[0].line)
+				ops = ops[1:]
+			}
+			for len(ops) > 0 && ops[0].kind == '+' {
+				added = append(added, ops[0].line)
+				ops = ops[1:]
+			}
+			for i := 0; i < max(len(removed), len(added)); i++ {
+				left, right := cell(""), ""
+				if i < len(removed) {
+					left = t.Error.Sprint(cell(removed[i]))
+				}
+				if i < len(added) {
+					right = t.Success.Sprint(added[i])
+				}
+				b.WriteString(left + Sym(" │ ") + right + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprint(start)
+	}
+	if lines == 0 {
+		start-- // an empty range points at the line before it
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// diffLines computes a shortest edit script with the Myers algorithm
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk the trace back from the end to recover the edits
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{'+', b[y-1]})
+		} else {
+			ops = append(ops, diffOp{'-', a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+type diffHunk struct {
+	oldStart, oldLines int
+	newStart, newLines int
+	ops                []diffOp
+}
+
+// diffHunks groups the changes with diffContext unchanged lines around them
+func diffHunks(ops []diffOp) []diffHunk {
+	var hunks []diffHunk
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine, newLine, i = oldLine+1, newLine+1, i+1
+			continue
+		}
+
+		start := max(0, i-diffContext)
+		h := diffHunk{oldStart: oldLine - (i - start), newStart: newLine - (i - start)}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			} else if j-end > 2*diffContext {
+				break
+			}
+		}
+		end = min(len(ops), end+diffContext+1)
+
+		h.ops = ops[start:end]
+		for _, op := range h.ops {
+			if op.kind != '+' {
+				h.oldLines++
+			}
+			if op.kind != '-' {
+				h.newLines++
+			}
+		}
+		for _, op := range ops[i:end] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		hunks = append(hunks, h)
+		i = end
+	}
+	return hunks
+}
@@ -0,0 +1,27 @@
+// Unit tests for file diffs in diff.go
+package system
+
+import "testing"
+
+// Test: changes far apart get separate hunks with three lines of context and correct ranges
+func TestUnifiedDiff(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	new := "1\nzwei\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	want := "--- a/f\n+++ b/f\n" +
+		"@@ -1,5 +1,5 @@\n 1\n-2\n+zwei\n 3\n 4\n 5\n" +
+		"@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n"
+	if got := UnifiedDiff("a/f", "b/f", old, new); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("a/f", "b/f", old, old); got != "" {
+		t.Errorf("equal texts should give no diff, got %q", got)
+	}
+}
+
+// Test: a new file is diffed against /dev/null with an empty old range
+func TestUnifiedDiffNewFile(t *testing.T) {
+	want := "--- /dev/null\n+++ b/f\n@@ -0,0 +1,2 @@\n+x\n+y\n"
+	if got := UnifiedDiff("", "b/f", "", "x\ny\n"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
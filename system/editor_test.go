@@ -0,0 +1,24 @@
+//go:build !windows
+
+// Unit tests for editing text in the user's editor in editor.go
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test: the text comes back as the editor saved it, $VISUAL taking precedence
+func TestEditInEditor(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "editor")
+	os.WriteFile(script, []byte("#!/bin/sh\nsed -i.bak 's/rm -rf/ls/' \"$1\"\n"), 0o755)
+	t.Setenv("VISUAL", script)
+	t.Setenv("EDITOR", "false")
+	t.Setenv("TMPDIR", t.TempDir())
+
+	got, err := EditInEditor("rm -rf build", ".sh")
+	if err != nil || got != "ls build" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/alvinunreal/tmuxai/i18n"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/fatih/color"
 )
 
@@ -16,17 +18,24 @@ type InfoFormatter struct {
 	WarningColor *color.Color
 	ErrorColor   *color.Color
 	NeutralColor *color.Color
+	MutedColor   *color.Color
+
+	// Width is the terminal width tables wrap to, 0 when output is not a terminal
+	Width int
 }
 
-// NewInfoFormatter creates a new formatter with default color schemes
+// NewInfoFormatter creates a new formatter with the colors of the current theme
 func NewInfoFormatter() *InfoFormatter {
+	t := CurrentTheme()
 	return &InfoFormatter{
-		HeaderColor:  color.New(color.FgCyan, color.Bold),
-		LabelColor:   color.New(color.FgBlue, color.Bold),
-		SuccessColor: color.New(color.FgGreen, color.Bold),
-		WarningColor: color.New(color.FgYellow, color.Bold),
-		ErrorColor:   color.New(color.FgRed, color.Bold),
-		NeutralColor: color.New(color.FgBlue),
+		HeaderColor:  t.Header,
+		LabelColor:   t.Label,
+		SuccessColor: t.Success,
+		WarningColor: t.Warning,
+		ErrorColor:   t.Error,
+		NeutralColor: t.Neutral,
+		MutedColor:   t.Muted,
+		Width:        TerminalWidth(),
 	}
 }
 
@@ -34,14 +43,22 @@ func NewInfoFormatter() *InfoFormatter {
 func (f *InfoFormatter) FormatSection(title string) string {
 	return fmt.Sprintf("%s\n%s\n",
 		f.HeaderColor.Sprint(title),
-		f.NeutralColor.Sprint(strings.Repeat("─", len(title))))
+		f.NeutralColor.Sprint(strings.Repeat(Sym("─"), ansi.StringWidth(strings.TrimLeft(title, "\n")))))
 }
 
 // FormatKeyValue prints a key-value pair with consistent formatting
 func (f *InfoFormatter) FormatKeyValue(key string, value interface{}) string {
-	return fmt.Sprintf("%s %s\n",
-		f.LabelColor.Sprintf("%-16s:", key),
-		fmt.Sprint(value))
+	return f.FormatRow(f.Label(key, 16)+":", 18, value)
+}
+
+// Label colors a table label padded to width columns, wide characters count double
+func (f *InfoFormatter) Label(key string, width int) string {
+	return f.LabelColor.Sprint(key + strings.Repeat(" ", max(width-ansi.StringWidth(key), 0)))
+}
+
+// FormatRow prints a label and its value, long values wrap under the value column
+func (f *InfoFormatter) FormatRow(label string, valueColumn int, value interface{}) string {
+	return WrapIndent(label+" "+fmt.Sprint(value), f.Width, valueColumn) + "\n"
 }
 
 // FormatProgressBar generates a visual indicator for percentage values
@@ -70,12 +87,12 @@ func (f *InfoFormatter) FormatProgressBar(percent float64, width int) string {
 
 	// Generate the filled portion
 	if filled > 0 {
-		bar += barColor.Sprint(strings.Repeat("█", filled))
+		bar += barColor.Sprint(strings.Repeat(Sym("█"), filled))
 	}
 
 	// Generate the empty portion
 	if width-filled > 0 {
-		bar += f.NeutralColor.Sprint(strings.Repeat("░", width-filled))
+		bar += f.NeutralColor.Sprint(strings.Repeat(Sym("░"), width-filled))
 	}
 
 	return fmt.Sprintf("%s %.1f%%", bar, percent)
@@ -84,7 +101,7 @@ func (f *InfoFormatter) FormatProgressBar(percent float64, width int) string {
 // FormatBool formats boolean values with color
 func (f *InfoFormatter) FormatBool(value bool) string {
 	if value {
-		return f.SuccessColor.Sprint("yes")
+		return f.SuccessColor.Sprint(i18n.T("yes"))
 	}
-	return color.New(color.FgMagenta).Sprint("no")
+	return f.MutedColor.Sprint(i18n.T("no"))
 }
@@ -0,0 +1,109 @@
+package system
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	maxGitDirtyFiles    = 30
+	maxGitStagedDiffLen = 200
+)
+
+// ErrNotGitRepo is returned by GitSummary when dir is not inside a git work tree
+var ErrNotGitRepo = errors.New("not a git repository")
+
+// GitRun runs a git command inside dir and returns its trimmed stdout
+func GitRun(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+// IsGitRepo reports whether dir is inside a git work tree
+func IsGitRepo(dir string) bool {
+	out, err := GitRun(dir, "rev-parse", "--is-inside-work-tree")
+	return err == nil && out == "true"
+}
+
+// GitSummary returns a concise description of the repository at dir:
+// current branch, dirty files, the last commits and optionally the staged diff.
+func GitSummary(dir string, commits int, stagedDiff bool) (string, error) {
+	if !IsGitRepo(dir) {
+		return "", fmt.Errorf("%s: %w", dir, ErrNotGitRepo)
+	}
+
+	var builder strings.Builder
+
+	branch, err := GitRun(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		branch = "(no commits yet)"
+	}
+	builder.WriteString(fmt.Sprintf("Branch: %s\n", branch))
+
+	status, _ := GitRun(dir, "status", "--porcelain")
+	if status == "" {
+		builder.WriteString("Working tree: clean\n")
+	} else {
+		lines := strings.Split(status, "\n")
+		builder.WriteString(fmt.Sprintf("Dirty files (%d):\n", len(lines)))
+		for i, line := range lines {
+			if i >= maxGitDirtyFiles {
+				builder.WriteString(fmt.Sprintf("  ... and %d more\n", len(lines)-maxGitDirtyFiles))
+				break
+			}
+			builder.WriteString("  " + line + "\n")
+		}
+	}
+
+	if commits > 0 {
+		log, err := GitRun(dir, "log", "--oneline", "-n", fmt.Sprintf("%d", commits))
+		if err == nil && log != "" {
+			builder.WriteString("Recent commits:\n")
+			for _, line := range strings.Split(log, "\n") {
+				builder.WriteString("  " + line + "\n")
+			}
+		}
+	}
+
+	if stagedDiff {
+		diff, err := GitRun(dir, "diff", "--cached")
+		if err == nil && diff != "" {
+			builder.WriteString("Staged diff:\n")
+			builder.WriteString(LimitLines(diff, maxGitStagedDiffLen) + "\n")
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// LimitLines keeps the first max lines of text and notes how many were cut
+func LimitLines(text string, max int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) <= max {
+		return text
+	}
+	return strings.Join(lines[:max], "\n") + fmt.Sprintf("\n... (%d more lines)", len(lines)-max)
+}
+
+// GitBaseBranch returns the ref the current branch is compared against for pull
+// requests: the remote default branch, falling back to main or master
+func GitBaseBranch(dir string) (string, error) {
+	if ref, err := GitRun(dir, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil && ref != "" {
+		return ref, nil
+	}
+	for _, ref := range []string{"origin/main", "origin/master", "main", "master"} {
+		if _, err := GitRun(dir, "rev-parse", "--verify", "--quiet", ref); err == nil {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine the base branch")
+}
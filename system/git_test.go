@@ -0,0 +1,128 @@
+// Unit tests for the git helpers in git.go
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a repository in a temp dir with the given number of commits
+func gitRepo(t *testing.T, commits int) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		if _, err := GitRun(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "test")
+	run("config", "commit.gpgsign", "false")
+	for i := 1; i <= commits; i++ {
+		if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(fmt.Sprintf("%d\n", i)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "file.txt")
+		run("commit", "-q", "-m", fmt.Sprintf("commit %d", i))
+	}
+	return dir
+}
+
+// Test: IsGitRepo tells work trees apart from plain directories
+func TestIsGitRepo(t *testing.T) {
+	if !IsGitRepo(gitRepo(t, 0)) {
+		t.Errorf("expected a fresh repository to be a git repo")
+	}
+	if IsGitRepo(t.TempDir()) {
+		t.Errorf("expected a plain directory not to be a git repo")
+	}
+}
+
+// Test: GitSummary returns ErrNotGitRepo outside a repository
+func TestGitSummaryNotRepo(t *testing.T) {
+	if _, err := GitSummary(t.TempDir(), 5, false); !errors.Is(err, ErrNotGitRepo) {
+		t.Errorf("expected ErrNotGitRepo, got %v", err)
+	}
+}
+
+// Test: GitSummary lists the branch, dirty files, the last commits and the staged diff
+func TestGitSummary(t *testing.T) {
+	dir := gitRepo(t, 3)
+
+	summary, err := GitSummary(dir, 2, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Branch: main", "Working tree: clean", "commit 3", "commit 2"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "commit 1") {
+		t.Errorf("expected only the last 2 commits, got:\n%s", summary)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("staged\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GitRun(dir, "add", "file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	summary, err = GitSummary(dir, 0, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Dirty files (1):", "M  file.txt", "Staged diff:", "+staged"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("expected summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "Recent commits") {
+		t.Errorf("expected no commits with commits=0, got:\n%s", summary)
+	}
+}
+
+// Test: a repository without commits reports so instead of failing
+func TestGitSummaryNoCommits(t *testing.T) {
+	summary, err := GitSummary(gitRepo(t, 0), 5, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(summary, "Branch: (no commits yet)") {
+		t.Errorf("expected the missing branch to be noted, got:\n%s", summary)
+	}
+}
+
+// Test: GitBaseBranch falls back to the local default branch without a remote
+func TestGitBaseBranch(t *testing.T) {
+	base, err := GitBaseBranch(gitRepo(t, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if base != "main" {
+		t.Errorf("expected main, got %q", base)
+	}
+	if _, err := GitBaseBranch(gitRepo(t, 0)); err == nil {
+		t.Errorf("expected an error without any branch")
+	}
+}
+
+// Test: LimitLines keeps the first lines and counts the rest
+func TestLimitLines(t *testing.T) {
+	if got := LimitLines("a\nb", 2); got != "a\nb" {
+		t.Errorf("expected short text unchanged, got %q", got)
+	}
+	if got := LimitLines("a\nb\nc\nd", 2); got != "a\nb\n... (2 more lines)" {
+		t.Errorf("unexpected limited text: %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package system
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// htmlSkipRe matches elements whose content is never readable text
+	htmlSkipRe = regexp.MustCompile(`(?is)<(script|style|noscript|svg|head|template|iframe)\b.*?</(script|style|noscript|svg|head|template|iframe)\s*>|<!--.*?-->`)
+	// htmlBreakRe matches tags that start a new line of text
+	htmlBreakRe   = regexp.MustCompile(`(?i)<(br|/?p|/?div|/?h[1-6]|/?li|/?tr|/?pre|/?blockquote|/?section|/?article|/?table|/?ul|/?ol|/?dt|/?dd|hr)\b[^>]*>`)
+	htmlTagRe     = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlTitleRe   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlSpacesRe  = regexp.MustCompile(`[ \t\f\v\r\x{a0}]+`)
+	htmlNewlineRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText strips a page down to its readable text: scripts, styles and markup are
+// dropped, block elements become line breaks and entities are decoded. The title, when
+// the page has one, comes first.
+func HTMLToText(page string) string {
+	title := ""
+	if match := htmlTitleRe.FindStringSubmatch(page); match != nil {
+		title = strings.TrimSpace(html.UnescapeString(htmlTagRe.ReplaceAllString(match[1], "")))
+	}
+	text := htmlSkipRe.ReplaceAllString(page, "")
+	text = htmlBreakRe.ReplaceAllString(text, "\n")
+	text = htmlTagRe.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(htmlSpacesRe.ReplaceAllString(line, " "))
+	}
+	text = strings.TrimSpace(htmlNewlineRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+	if title != "" && !strings.HasPrefix(text, title) {
+		text = title + "\n\n" + text
+	}
+	return text
+}
@@ -0,0 +1,19 @@
+// Unit tests for reducing pages to text in html_text.go
+package system
+
+import "testing"
+
+// Test: scripts, styles and markup are dropped, blocks become lines and entities decode
+func TestHTMLToText(t *testing.T) {
+	page := `<!DOCTYPE html><html><head><title>EADDRINUSE &ndash; Docs</title>
+<style>body { color: red }</style><script>var x = "<p>no</p>";</script></head>
+<body><!-- nav --><h1>EADDRINUSE</h1>
+<p>The port is   already <b>in use</b>.<br>Stop the other process &amp; retry.</p>
+
+
+<ul><li>lsof -i :3000</li><li>kill &lt;pid&gt;</li></ul></body></html>`
+	want := "EADDRINUSE – Docs\n\nEADDRINUSE\n\nThe port is already in use.\nStop the other process & retry.\n\nlsof -i :3000\n\nkill <pid>"
+	if got := HTMLToText(page); got != want {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package system
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	httpMu sync.RWMutex
+	// sharedTransport carries every HTTP request TmuxAI makes, so connections to the
+	// model API and to MCP servers are kept alive and reused from one turn to the next
+	sharedTransport = newTransport(nil)
+	httpSettings    string // the TLS options sharedTransport was built with
+)
+
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// ConfigureHTTP sets the TLS options of the shared transport: CA certificates from caFile
+// trusted in addition to the system ones and, for self-signed test servers, skipping
+// verification altogether
+func ConfigureHTTP(caFile string, insecure bool) error {
+	settings := fmt.Sprintf("%s|%t", caFile, insecure)
+	httpMu.RLock()
+	unchanged := settings == httpSettings || httpSettings == "" && caFile == "" && !insecure
+	httpMu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	var tlsConfig *tls.Config
+	if caFile != "" || insecure {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecure}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("reading CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	httpMu.Lock()
+	old := sharedTransport
+	sharedTransport, httpSettings = newTransport(tlsConfig), settings
+	httpMu.Unlock()
+	old.CloseIdleConnections()
+	return nil
+}
+
+// sharedRoundTripper sends requests through the current shared transport, clients made
+// before ConfigureHTTP pick up its options too
+type sharedRoundTripper struct{}
+
+func (sharedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	httpMu.RLock()
+	t := sharedTransport
+	httpMu.RUnlock()
+	return t.RoundTrip(req)
+}
+
+// HTTPClient returns a client on the shared transport. timeout limits a whole request;
+// 0 leaves it to the request's context, which long-lived streams need.
+func HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: sharedRoundTripper{}, Timeout: timeout}
+}
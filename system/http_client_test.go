@@ -0,0 +1,72 @@
+// Unit tests for the shared HTTP client in http_client.go
+package system
+
+import (
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test: requests from different clients reuse one kept-alive connection
+func TestHTTPClientReusesConnections(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	for _, client := range []*http.Client{HTTPClient(time.Second), HTTPClient(0), HTTPClient(time.Second)} {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	if n := conns.Load(); n != 1 {
+		t.Errorf("expected one connection, got %d", n)
+	}
+}
+
+// Test: a CA file makes a server with a private certificate trusted
+func TestConfigureHTTPCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	defer ConfigureHTTP("", false)
+
+	client := HTTPClient(5 * time.Second)
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("untrusted certificate accepted")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureHTTP(caFile, false); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with the CA file failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := ConfigureHTTP(filepath.Join(t.TempDir(), "missing.pem"), false); err == nil {
+		t.Error("expected an error for a missing CA file")
+	}
+}
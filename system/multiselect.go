@@ -0,0 +1,188 @@
+//go:build tui
+
+package system
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const multiSelectHeight = 15
+
+// InteractiveSelect lets the user pick any number of items: Space toggles, typing filters,
+// Ctrl+A toggles all shown items and Enter confirms. preSelected items start checked.
+func InteractiveSelect(title string, items []string, preSelected map[string]struct{}) ([]string, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no items to select")
+	}
+
+	model := newMultiSelect(title, items, preSelected)
+	final, err := tea.NewProgram(model).Run()
+	if err != nil {
+		return nil, err
+	}
+	result := final.(*multiSelect)
+	if result.cancelled {
+		return nil, ErrSelectionCancelled
+	}
+	return result.selectedItems(), nil
+}
+
+// multiSelect is the Bubble Tea model behind InteractiveSelect
+type multiSelect struct {
+	title    string
+	items    []string
+	selected []bool
+	filter   string
+	visible  []int // indexes of the items matching filter
+	cursor   int   // position in visible
+	offset   int   // first visible row shown
+
+	done, cancelled bool
+}
+
+func newMultiSelect(title string, items []string, preSelected map[string]struct{}) *multiSelect {
+	s := &multiSelect{title: title, items: items, selected: make([]bool, len(items))}
+	for i, item := range items {
+		_, s.selected[i] = preSelected[item]
+	}
+	s.applyFilter()
+	return s
+}
+
+func (s *multiSelect) Init() tea.Cmd {
+	return nil
+}
+
+func (s *multiSelect) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return s, nil
+	}
+
+	switch key.Type {
+	case tea.KeyCtrlC:
+		s.cancelled = true
+		return s, tea.Quit
+	case tea.KeyEsc:
+		if s.filter == "" {
+			s.cancelled = true
+			return s, tea.Quit
+		}
+		s.filter = ""
+		s.applyFilter()
+	case tea.KeyEnter:
+		s.done = true
+		return s, tea.Quit
+	case tea.KeyUp, tea.KeyCtrlP:
+		s.move(-1)
+	case tea.KeyDown, tea.KeyCtrlN, tea.KeyTab:
+		s.move(1)
+	case tea.KeyPgUp:
+		s.move(-multiSelectHeight)
+	case tea.KeyPgDown:
+		s.move(multiSelectHeight)
+	case tea.KeySpace:
+		if len(s.visible) > 0 {
+			i := s.visible[s.cursor]
+			s.selected[i] = !s.selected[i]
+		}
+	case tea.KeyCtrlA:
+		s.toggleAll()
+	case tea.KeyBackspace:
+		if s.filter != "" {
+			runes := []rune(s.filter)
+			s.filter = string(runes[:len(runes)-1])
+			s.applyFilter()
+		}
+	case tea.KeyRunes:
+		s.filter += string(key.Runes)
+		s.applyFilter()
+	}
+	return s, nil
+}
+
+// toggleAll selects every shown item, or clears them when all are already selected
+func (s *multiSelect) toggleAll() {
+	all := true
+	for _, i := range s.visible {
+		all = all && s.selected[i]
+	}
+	for _, i := range s.visible {
+		s.selected[i] = !all
+	}
+}
+
+func (s *multiSelect) move(delta int) {
+	if len(s.visible) == 0 {
+		return
+	}
+	s.cursor = max(0, min(s.cursor+delta, len(s.visible)-1))
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	} else if s.cursor >= s.offset+multiSelectHeight {
+		s.offset = s.cursor - multiSelectHeight + 1
+	}
+}
+
+// applyFilter keeps the items containing the filter, ignoring case
+func (s *multiSelect) applyFilter() {
+	query := strings.ToLower(s.filter)
+	s.visible = s.visible[:0]
+	for i, item := range s.items {
+		if strings.Contains(strings.ToLower(item), query) {
+			s.visible = append(s.visible, i)
+		}
+	}
+	s.cursor, s.offset = 0, 0
+}
+
+func (s *multiSelect) selectedItems() []string {
+	var items []string
+	for i, item := range s.items {
+		if s.selected[i] {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (s *multiSelect) View() string {
+	if s.done || s.cancelled {
+		return ""
+	}
+	theme := CurrentTheme()
+
+	var b strings.Builder
+	b.WriteString(theme.Header.Sprint(s.title))
+	if s.filter != "" {
+		b.WriteString("  " + theme.Highlight.Sprint("/"+s.filter))
+	}
+	b.WriteString("\n")
+
+	if len(s.visible) == 0 {
+		b.WriteString(theme.Neutral.Sprint("  "+i18n.T("no matches")) + "\n")
+	}
+	end := min(s.offset+multiSelectHeight, len(s.visible))
+	for row := s.offset; row < end; row++ {
+		i := s.visible[row]
+		check := "[ ]"
+		if s.selected[i] {
+			check = theme.Success.Sprint(Sym("[✓]"))
+		}
+		line := fmt.Sprintf("%s %s", check, s.items[i])
+		if row == s.cursor {
+			b.WriteString(theme.Confirm.Sprint(Sym("▶ ")) + line + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	count := len(s.selectedItems())
+	b.WriteString(theme.Neutral.Sprint(Sym(i18n.T("%d/%d selected · ↑↓ move · space toggle · ctrl+a all · type to filter · enter confirm · esc cancel", count, len(s.items)))))
+	return b.String()
+}
@@ -0,0 +1,65 @@
+//go:build !tui
+
+package system
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
+)
+
+// InteractiveSelect lists the items numbered and reads the numbers to select, separated by
+// spaces or commas. An empty answer keeps preSelected, "-" clears the selection. Builds
+// with the tui tag use a full-screen picker instead.
+func InteractiveSelect(title string, items []string, preSelected map[string]struct{}) ([]string, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no items to select")
+	}
+
+	theme := CurrentTheme()
+	fmt.Println(theme.Header.Sprint(title))
+	var kept []string
+	for i, item := range items {
+		mark := " "
+		if _, ok := preSelected[item]; ok {
+			mark = "x"
+			kept = append(kept, item)
+		}
+		fmt.Printf("  [%s] %d. %s\n", mark, i+1, item)
+	}
+	fmt.Print(i18n.T("Numbers to select (enter keeps the current selection, - clears it): "))
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return nil, ErrSelectionCancelled
+	}
+	return parseSelection(strings.TrimSpace(line), items, kept)
+}
+
+// parseSelection turns an answer like "1, 3" into the chosen items
+func parseSelection(answer string, items, kept []string) ([]string, error) {
+	switch answer {
+	case "":
+		return kept, nil
+	case "-":
+		return nil, nil
+	}
+	var selected []string
+	seen := map[int]bool{}
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(items) {
+			return nil, fmt.Errorf("invalid selection: %s", field)
+		}
+		if !seen[n] {
+			seen[n] = true
+			selected = append(selected, items[n-1])
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,33 @@
+//go:build !tui
+
+// Unit tests for the line-based picker in multiselect_plain.go
+package system
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test: answers select items by number, keep or clear the selection
+func TestParseSelection(t *testing.T) {
+	items := []string{"a", "b", "c"}
+	kept := []string{"b"}
+	tests := []struct {
+		answer string
+		want   []string
+		err    bool
+	}{
+		{"", []string{"b"}, false},
+		{"-", nil, false},
+		{"1, 3", []string{"a", "c"}, false},
+		{"3 3 1", []string{"c", "a"}, false},
+		{"4", nil, true},
+		{"x", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSelection(tt.answer, items, kept)
+		if (err != nil) != tt.err || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSelection(%q) = %v, %v; want %v (error %v)", tt.answer, got, err, tt.want, tt.err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+//go:build tui
+
+// Unit tests for the multi-select widget in multiselect.go
+package system
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func sendKeys(s *multiSelect, keys ...tea.KeyMsg) {
+	for _, key := range keys {
+		s.Update(key)
+	}
+}
+
+func runes(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+// Test: space toggles the item under the cursor and preselected items stay checked
+func TestMultiSelectToggle(t *testing.T) {
+	s := newMultiSelect("pick", []string{"github", "gitlab", "jira"}, map[string]struct{}{"jira": {}})
+	sendKeys(s, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeySpace})
+
+	if got := s.selectedItems(); !slices.Equal(got, []string{"gitlab", "jira"}) {
+		t.Errorf("selected %v", got)
+	}
+}
+
+// Test: typing filters the list, Ctrl+A toggles only the shown items and Esc clears the filter first
+func TestMultiSelectFilter(t *testing.T) {
+	s := newMultiSelect("pick", []string{"github", "GitLab", "jira"}, nil)
+	sendKeys(s, runes("git"), tea.KeyMsg{Type: tea.KeyCtrlA})
+
+	if got := s.selectedItems(); !slices.Equal(got, []string{"github", "GitLab"}) {
+		t.Errorf("select all with filter selected %v", got)
+	}
+
+	sendKeys(s, tea.KeyMsg{Type: tea.KeyCtrlA})
+	if got := s.selectedItems(); len(got) != 0 {
+		t.Errorf("second Ctrl+A should clear, got %v", got)
+	}
+
+	sendKeys(s, tea.KeyMsg{Type: tea.KeyEsc})
+	if s.cancelled || len(s.visible) != 3 {
+		t.Errorf("Esc should clear the filter, cancelled=%v visible=%d", s.cancelled, len(s.visible))
+	}
+	sendKeys(s, tea.KeyMsg{Type: tea.KeyEsc})
+	if !s.cancelled {
+		t.Error("Esc without a filter should cancel")
+	}
+}
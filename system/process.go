@@ -0,0 +1,72 @@
+package system
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+type process struct {
+	ppid    int
+	command string
+}
+
+// processTable is a snapshot of the running processes, read with a single ps call
+// so looking up the commands of many panes costs one process spawn
+type processTable map[int]process
+
+func listProcesses() processTable {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,command=").Output()
+	if err != nil {
+		logger.Error("Failed to list processes: %v", err)
+		return processTable{}
+	}
+	return parseProcesses(string(output))
+}
+
+func parseProcesses(output string) processTable {
+	procs := processTable{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		// keep the command line as ps printed it, after the two number columns
+		command := strings.TrimSpace(line)
+		for range 2 {
+			command = strings.TrimSpace(command[strings.IndexAny(command, " \t"):])
+		}
+		procs[pid] = process{ppid: ppid, command: command}
+	}
+	return procs
+}
+
+// args returns the command line running in a pane: for a login shell (shown with a
+// leading "-") the first child that isn't another shell, otherwise the process itself
+func (t processTable) args(pid int) string {
+	proc, ok := t[pid]
+	if !ok {
+		return ""
+	}
+	if !strings.HasPrefix(proc.command, "-") {
+		return proc.command
+	}
+	// lowest pid first, like pgrep lists them
+	child := 0
+	for childPid, p := range t {
+		if p.ppid == pid && p.command != "" && !strings.HasPrefix(p.command, "-") && (child == 0 || childPid < child) {
+			child = childPid
+		}
+	}
+	if child != 0 {
+		return t[child].command
+	}
+	return proc.command
+}
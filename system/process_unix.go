@@ -0,0 +1,34 @@
+//go:build !windows
+
+package system
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// SetProcessGroup starts cmd in a process group of its own, so KillProcessGroup reaches
+// the children it starts too
+func SetProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// SignalProcessGroup sends SIGTERM, or SIGKILL with force, to the process group of cmd
+func SignalProcessGroup(cmd *exec.Cmd, force bool) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	err := syscall.Kill(-cmd.Process.Pid, sig)
+	if err == syscall.ESRCH {
+		// the group is gone already
+		return nil
+	}
+	return err
+}
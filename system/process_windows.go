@@ -0,0 +1,19 @@
+//go:build windows
+
+package system
+
+import (
+	"os/exec"
+)
+
+// SetProcessGroup does nothing on Windows, where processes have no groups to signal
+func SetProcessGroup(cmd *exec.Cmd) {}
+
+// SignalProcessGroup kills the process of cmd; Windows has no SIGTERM, so it is forced
+// either way and children the process started are not reached
+func SignalProcessGroup(cmd *exec.Cmd, force bool) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
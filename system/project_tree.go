@@ -0,0 +1,159 @@
+package system
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxTreeEntries caps the number of entries rendered by ProjectTree
+const maxTreeEntries = 300
+
+// skippedTreeDirs are never descended into when walking without git
+var skippedTreeDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+	"__pycache__":  true,
+}
+
+type treeNode struct {
+	name     string
+	isDir    bool
+	children map[string]*treeNode
+}
+
+// ProjectTree returns a depth-limited directory tree of dir.
+// Inside a git repository the file list comes from git, so ignored files are skipped;
+// otherwise the directory is walked, skipping hidden and common build directories.
+func ProjectTree(dir string, maxDepth int) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("empty directory")
+	}
+	if maxDepth <= 0 {
+		maxDepth = 1
+	}
+
+	paths, err := gitListFiles(dir)
+	if err != nil {
+		paths, err = walkListFiles(dir, maxDepth)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	root := &treeNode{name: filepath.Base(dir), isDir: true, children: map[string]*treeNode{}}
+	for _, p := range paths {
+		p = filepath.ToSlash(p)
+		isDir := strings.HasSuffix(p, "/")
+		parts := strings.Split(strings.TrimSuffix(p, "/"), "/")
+		cur := root
+		for i, part := range parts {
+			if i >= maxDepth {
+				break
+			}
+			child, ok := cur.children[part]
+			if !ok {
+				child = &treeNode{name: part, isDir: isDir || i < len(parts)-1, children: map[string]*treeNode{}}
+				cur.children[part] = child
+			}
+			cur = child
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(root.name + "/\n")
+	count := 0
+	truncated := writeTreeChildren(&builder, root, "", &count)
+	if truncated {
+		builder.WriteString(fmt.Sprintf("... (truncated after %d entries)\n", maxTreeEntries))
+	}
+	return builder.String(), nil
+}
+
+// writeTreeChildren renders the children of n, directories first, returning true if truncated
+func writeTreeChildren(builder *strings.Builder, n *treeNode, indent string, count *int) bool {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := n.children[names[i]], n.children[names[j]]
+		if a.isDir != b.isDir {
+			return a.isDir
+		}
+		return a.name < b.name
+	})
+
+	for _, name := range names {
+		if *count >= maxTreeEntries {
+			return true
+		}
+		*count++
+		child := n.children[name]
+		if child.isDir {
+			builder.WriteString(indent + "  " + child.name + "/\n")
+			if writeTreeChildren(builder, child, indent+"  ", count) {
+				return true
+			}
+		} else {
+			builder.WriteString(indent + "  " + child.name + "\n")
+		}
+	}
+	return false
+}
+
+// gitListFiles lists tracked and untracked-but-not-ignored files relative to dir
+func gitListFiles(dir string) ([]string, error) {
+	output, err := GitRun(dir, "ls-files", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// walkListFiles lists files under dir up to maxDepth, skipping hidden and build directories
+func walkListFiles(dir string, maxDepth int) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path == dir {
+			return nil
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return nil
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || (d.IsDir() && skippedTreeDirs[name]) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		depth := strings.Count(filepath.ToSlash(rel), "/") + 1
+		if d.IsDir() {
+			if depth >= maxDepth {
+				// keep the directory itself visible even though we don't descend
+				paths = append(paths, rel+"/")
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	return paths, err
+}
@@ -0,0 +1,46 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectTree(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		"main.go",
+		"cli/cli.go",
+		"internal/deep/nested/file.go",
+		".git/config",
+		"node_modules/pkg/index.js",
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tree, err := ProjectTree(dir, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"main.go", "cli/", "cli.go", "internal/", "deep/"}
+	for _, want := range expected {
+		if !strings.Contains(tree, want) {
+			t.Errorf("expected tree to contain %q, got:\n%s", want, tree)
+		}
+	}
+	notExpected := []string{"nested", ".git", "node_modules", "index.js"}
+	for _, unwanted := range notExpected {
+		if strings.Contains(tree, unwanted) {
+			t.Errorf("expected tree not to contain %q, got:\n%s", unwanted, tree)
+		}
+	}
+}
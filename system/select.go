@@ -0,0 +1,6 @@
+package system
+
+import "errors"
+
+// ErrSelectionCancelled is returned when the user leaves a selection with Esc or Ctrl+C
+var ErrSelectionCancelled = errors.New("selection cancelled")
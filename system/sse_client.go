@@ -2,17 +2,32 @@ package system
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// SSEEvent is one server-sent event
+type SSEEvent struct {
+	ID    string // last event id seen on the stream, kept across events
+	Event string // event type, "message" when the server sends none
+	Data  string // data lines joined with \n
+	Retry time.Duration
+}
+
 type SSEClient struct {
 	url     string
 	headers map[string]string
 	timeout time.Duration
+
+	method      string
+	body        []byte
+	lastEventID string
 }
 
 func NewSSEClient(url string, headers map[string]string, timeout time.Duration) *SSEClient {
@@ -20,11 +35,42 @@ func NewSSEClient(url string, headers map[string]string, timeout time.Duration)
 		url:     url,
 		headers: headers,
 		timeout: timeout,
+		method:  http.MethodGet,
 	}
 }
 
+// WithRequest makes the client open the stream with method and body, for endpoints
+// that stream the answer to a POST. A body without a Content-Type header is sent as JSON.
+func (c *SSEClient) WithRequest(method string, body []byte) *SSEClient {
+	c.method = method
+	c.body = body
+	return c
+}
+
+// LastEventID returns the id of the last event received, sent as Last-Event-ID when the
+// client connects again
+func (c *SSEClient) LastEventID() string {
+	return c.lastEventID
+}
+
+// Connect streams the data of each event to onMessage until the stream ends; the
+// OpenAI-style [DONE] marker is left out
 func (c *SSEClient) Connect(ctx context.Context, onMessage func(string)) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", c.url, nil)
+	return c.Stream(ctx, func(ev SSEEvent) {
+		if ev.Data != "[DONE]" {
+			onMessage(ev.Data)
+		}
+	})
+}
+
+// Stream opens the stream and calls onEvent for each event until the stream ends or ctx
+// is cancelled
+func (c *SSEClient) Stream(ctx context.Context, onEvent func(SSEEvent)) error {
+	var body io.Reader
+	if c.body != nil {
+		body = bytes.NewReader(c.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, c.method, c.url, body)
 	if err != nil {
 		return err
 	}
@@ -33,33 +79,83 @@ func (c *SSEClient) Connect(ctx context.Context, onMessage func(string)) error {
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
+	if c.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", c.lastEventID)
+	}
 
 	// 添加自定义头部
 	for k, v := range c.headers {
 		req.Header.Set(k, v)
 	}
 
-	client := &http.Client{Timeout: c.timeout}
-	resp, err := client.Do(req)
+	resp, err := HTTPClient(c.timeout).Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		return fmt.Errorf("SSE connection failed with status: %d", resp.StatusCode)
 	}
 
-	scanner := bufio.NewScanner(resp.Body)
+	return readSSE(resp.Body, c.lastEventID, func(ev SSEEvent) {
+		c.lastEventID = ev.ID
+		onEvent(ev)
+	})
+}
+
+// readSSE parses an event stream as the HTML spec describes it: "field: value" lines,
+// comments starting with ":", events ending at a blank line. lastID is the id to
+// start from. An event cut off by the end of the stream is dropped.
+func readSSE(r io.Reader, lastID string, onEvent func(SSEEvent)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	ev := SSEEvent{ID: lastID}
+	var data strings.Builder
+	hasData := false
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.HasPrefix(line, "data: ") {
-			data := strings.TrimPrefix(line, "data: ")
-			if data != "[DONE]" {
-				onMessage(data)
+		if line == "" {
+			if hasData {
+				ev.Data = data.String()
+				if ev.Event == "" {
+					ev.Event = "message"
+				}
+				onEvent(ev)
 			}
+			ev = SSEEvent{ID: ev.ID}
+			data.Reset()
+			hasData = false
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
 		}
-	}
 
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "event":
+			ev.Event = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				ev.ID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				ev.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
 	return scanner.Err()
 }
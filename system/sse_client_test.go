@@ -0,0 +1,66 @@
+// Unit tests for the event stream client in sse_client.go
+package system
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test: fields, comments, multi-line data and ids are parsed as the spec describes
+func TestReadSSE(t *testing.T) {
+	stream := ": keep-alive\n" +
+		"data: first\n\n" +
+		"event: update\n" +
+		"id: 7\n" +
+		"retry: 1500\n" +
+		"data: line one\n" +
+		"data:line two\n\n" +
+		"event: empty\n\n" + // no data, not dispatched
+		"data: after\r\n\r\n" +
+		"data: cut off"
+
+	var events []SSEEvent
+	if err := readSSE(strings.NewReader(stream), "3", func(ev SSEEvent) { events = append(events, ev) }); err != nil {
+		t.Fatal(err)
+	}
+	want := []SSEEvent{
+		{ID: "3", Event: "message", Data: "first"},
+		{ID: "7", Event: "update", Data: "line one\nline two", Retry: 1500 * time.Millisecond},
+		{ID: "7", Event: "message", Data: "after"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got %+v\nwant %+v", events, want)
+	}
+}
+
+// Test: a POST stream sends its body and remembers the last event id
+func TestSSEClientPost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || string(body) != `{"q":1}` || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "id: a1\ndata: hello\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewSSEClient(server.URL, nil, 5*time.Second).WithRequest(http.MethodPost, []byte(`{"q":1}`))
+	var messages []string
+	if err := client.Connect(context.Background(), func(data string) { messages = append(messages, data) }); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(messages, []string{"hello"}) {
+		t.Errorf("unexpected messages: %v", messages)
+	}
+	if client.LastEventID() != "a1" {
+		t.Errorf("unexpected last event id: %q", client.LastEventID())
+	}
+}
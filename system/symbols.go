@@ -0,0 +1,51 @@
+package system
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+var asciiOnly atomic.Bool
+
+// asciiSymbols replaces the unicode symbols of the interface in ASCII-only mode
+var asciiSymbols = strings.NewReplacer(
+	"▶", ">",
+	"✓", "+",
+	"✗", "x",
+	"∞", "oo",
+	"🧰 ", "",
+	"█", "#",
+	"░", ".",
+	"●", "o",
+	"○", ".",
+	"⏸", "||",
+	"»", ">",
+	"─", "-",
+	"│", "|",
+	"·", "-",
+	"…", "...",
+	"↑↓", "up/down",
+	"⋯", "-",
+	"⋱", "\\",
+	"⋮", "|",
+	"⋰", "/",
+)
+
+// SetASCIIOnly switches the interface symbols to plain ASCII, for limited fonts,
+// serial consoles and screen readers
+func SetASCIIOnly(enabled bool) {
+	asciiOnly.Store(enabled)
+}
+
+// ASCIIOnly reports whether ASCII-only mode is on
+func ASCIIOnly() bool {
+	return asciiOnly.Load()
+}
+
+// Sym returns s with its interface symbols replaced by ASCII in ASCII-only mode
+func Sym(s string) string {
+	if !asciiOnly.Load() {
+		return s
+	}
+	return asciiSymbols.Replace(s)
+}
@@ -0,0 +1,37 @@
+// Unit tests for ASCII-only symbols in symbols.go
+package system
+
+import "testing"
+
+// Test: symbols are kept by default and replaced with ASCII in ASCII-only mode
+func TestSym(t *testing.T) {
+	defer SetASCIIOnly(false)
+
+	if got := Sym("✓ Step 1 done"); got != "✓ Step 1 done" {
+		t.Errorf("expected unicode by default, got %q", got)
+	}
+	SetASCIIOnly(true)
+	cases := map[string]string{
+		"✓ Step 1 done":     "+ Step 1 done",
+		"[∞]":               "[oo]",
+		"🧰 Current servers": "Current servers",
+		"███░░":             "###..",
+		"a │ b…":            "a | b...",
+	}
+	for in, want := range cases {
+		if got := Sym(in); got != want {
+			t.Errorf("Sym(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// Test: the progress bar has no unicode blocks in ASCII-only mode
+func TestFormatProgressBarASCII(t *testing.T) {
+	forceColor(t, false)
+	SetASCIIOnly(true)
+	defer SetASCIIOnly(false)
+
+	if got := NewInfoFormatter().FormatProgressBar(50, 4); got != "##.. 50.0%" {
+		t.Errorf("unexpected progress bar: %q", got)
+	}
+}
@@ -0,0 +1,125 @@
+package system
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TaskRunner holds the targets of one task runner file found in a project
+type TaskRunner struct {
+	Tool    string   // make, just, npm, pnpm, yarn or bun
+	File    string   // file the targets were read from
+	Targets []string // target or script names
+}
+
+// Command returns the shell command that runs target with this runner
+func (t TaskRunner) Command(target string) string {
+	switch t.Tool {
+	case "make", "just":
+		return t.Tool + " " + target
+	case "yarn":
+		return "yarn " + target
+	default:
+		return t.Tool + " run " + target
+	}
+}
+
+var (
+	makeTargetRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.\-/]*)\s*:([^=]|$)`)
+	justRecipeRe = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_\-]*)[^:=]*:([^=]|$)`)
+)
+
+// DiscoverTaskRunners finds Makefiles, justfiles and package.json scripts in dir
+func DiscoverTaskRunners(dir string) []TaskRunner {
+	var runners []TaskRunner
+
+	for _, name := range []string{"GNUmakefile", "makefile", "Makefile"} {
+		if targets := parseTargets(filepath.Join(dir, name), makeTargetRe); len(targets) > 0 {
+			runners = append(runners, TaskRunner{Tool: "make", File: name, Targets: targets})
+			break
+		}
+	}
+
+	for _, name := range []string{"justfile", "Justfile", ".justfile"} {
+		if targets := parseTargets(filepath.Join(dir, name), justRecipeRe); len(targets) > 0 {
+			runners = append(runners, TaskRunner{Tool: "just", File: name, Targets: targets})
+			break
+		}
+	}
+
+	if scripts := packageScripts(filepath.Join(dir, "package.json")); len(scripts) > 0 {
+		runners = append(runners, TaskRunner{Tool: nodePackageManager(dir), File: "package.json", Targets: scripts})
+	}
+
+	return runners
+}
+
+// parseTargets returns the unique names matched by re at the start of unindented lines
+func parseTargets(path string, re *regexp.Regexp) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	seen := map[string]bool{}
+	var targets []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '.' {
+			continue
+		}
+		if strings.HasPrefix(line, "set ") || strings.HasPrefix(line, "alias ") || strings.HasPrefix(line, "export ") {
+			continue
+		}
+		match := re.FindStringSubmatch(line)
+		if match == nil || seen[match[1]] || strings.Contains(match[1], "%") {
+			continue
+		}
+		seen[match[1]] = true
+		targets = append(targets, match[1])
+	}
+	return targets
+}
+
+// packageScripts returns the sorted script names of a package.json
+func packageScripts(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	scripts := make([]string, 0, len(pkg.Scripts))
+	for name := range pkg.Scripts {
+		scripts = append(scripts, name)
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+// nodePackageManager guesses the package manager from the lock file
+func nodePackageManager(dir string) string {
+	locks := []struct{ file, tool string }{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lockb", "bun"},
+		{"bun.lock", "bun"},
+	}
+	for _, lock := range locks {
+		if _, err := os.Stat(filepath.Join(dir, lock.file)); err == nil {
+			return lock.tool
+		}
+	}
+	return "npm"
+}
@@ -0,0 +1,40 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverTaskRunners(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Makefile":     ".PHONY: build test\nVERSION := 1.0\nbuild: deps\n\tgo build ./...\ntest:\n\tgo test ./...\n%.o: %.c\n\tcc $<\n",
+		"justfile":     "set shell := [\"bash\", \"-c\"]\nversion := \"1\"\n# comment\nlint:\n  golangci-lint run\n@release tag:\n  echo {{tag}}\n",
+		"package.json": `{"scripts": {"start": "node .", "dev": "vite"}}`,
+		"yarn.lock":    "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runners := DiscoverTaskRunners(dir)
+	if len(runners) != 3 {
+		t.Fatalf("expected 3 runners, got %+v", runners)
+	}
+
+	expected := []TaskRunner{
+		{Tool: "make", File: "Makefile", Targets: []string{"build", "test"}},
+		{Tool: "just", File: "justfile", Targets: []string{"lint", "release"}},
+		{Tool: "yarn", File: "package.json", Targets: []string{"dev", "start"}},
+	}
+	if !reflect.DeepEqual(runners, expected) {
+		t.Errorf("expected %+v, got %+v", expected, runners)
+	}
+	if cmd := runners[2].Command("dev"); cmd != "yarn dev" {
+		t.Errorf("unexpected command %q", cmd)
+	}
+}
@@ -0,0 +1,20 @@
+package system
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// ColorEnabled reports whether output may be colored; it is off when NO_COLOR is set,
+// TERM is dumb or stdout is not a terminal
+func ColorEnabled() bool {
+	return !color.NoColor
+}
+
+// CursorControl reports whether stdout is a terminal that can redraw lines in place,
+// for spinners, countdowns and the full-screen interface
+func CursorControl() bool {
+	return os.Getenv("TERM") != "dumb" && term.IsTerminal(int(os.Stdout.Fd()))
+}
@@ -0,0 +1,208 @@
+package system
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// Theme holds every color TmuxAI prints with, so the palette can follow the terminal background
+type Theme struct {
+	Name string
+
+	Prompt      *color.Color // "TmuxAI" in the chat prompt
+	PromptArrow *color.Color
+	PromptState *color.Color
+
+	Header  *color.Color // info sections and pane titles
+	Label   *color.Color
+	Success *color.Color
+	Warning *color.Color
+	Error   *color.Color
+	Neutral *color.Color // separators and empty progress bars
+	Muted   *color.Color // "no" values and secondary details
+
+	Confirm    *color.Color // confirmation prompts
+	Highlight  *color.Color // countdown numbers and server lists
+	Pause      *color.Color
+	InlineCode *color.Color // `code` spans in AI output
+
+	CodeTheme string // chroma style used when highlight.theme is not set
+}
+
+// ThemePresets lists the built-in themes
+func ThemePresets() []string {
+	return []string{"dark", "light"}
+}
+
+// NewTheme returns a built-in theme, falling back to dark for unknown names
+func NewTheme(preset string) *Theme {
+	if preset == "light" {
+		return &Theme{
+			Name:        "light",
+			Prompt:      color.New(color.FgGreen, color.Bold),
+			PromptArrow: color.New(color.FgBlue, color.Bold),
+			PromptState: color.New(color.FgMagenta, color.Bold),
+			Header:      color.New(color.FgMagenta, color.Bold),
+			Label:       color.New(color.FgBlue, color.Bold),
+			Success:     color.New(color.FgGreen, color.Bold),
+			Warning:     color.New(38, 5, 130, color.Bold),
+			Error:       color.New(color.FgRed, color.Bold),
+			Neutral:     color.New(color.FgHiBlack),
+			Muted:       color.New(color.FgMagenta),
+			Confirm:     color.New(color.FgBlue, color.Bold),
+			Highlight:   color.New(38, 5, 130, color.Bold),
+			Pause:       color.New(color.FgRed, color.Bold),
+			InlineCode:  color.New(48, 5, 254, 38, 5, 25),
+			CodeTheme:   "github",
+		}
+	}
+	return &Theme{
+		Name:        "dark",
+		Prompt:      color.New(color.FgGreen, color.Bold),
+		PromptArrow: color.New(color.FgYellow, color.Bold),
+		PromptState: color.New(color.FgMagenta, color.Bold),
+		Header:      color.New(color.FgCyan, color.Bold),
+		Label:       color.New(color.FgBlue, color.Bold),
+		Success:     color.New(color.FgGreen, color.Bold),
+		Warning:     color.New(color.FgYellow, color.Bold),
+		Error:       color.New(color.FgRed, color.Bold),
+		Neutral:     color.New(color.FgBlue),
+		Muted:       color.New(color.FgMagenta),
+		Confirm:     color.New(color.FgCyan, color.Bold),
+		Highlight:   color.New(color.FgYellow, color.Bold),
+		Pause:       color.New(color.FgRed, color.Bold),
+		InlineCode:  color.New(48, 5, 235, 38, 5, 51),
+		CodeTheme:   DefaultHighlightTheme,
+	}
+}
+
+// LoadTheme builds a preset with the colors overridden by name, e.g. label: "cyan bold".
+// Invalid overrides are skipped and reported in the returned error.
+func LoadTheme(preset string, overrides map[string]string) (*Theme, error) {
+	t := NewTheme(preset)
+	fields := t.fields()
+	var errs []string
+	for name, spec := range overrides {
+		field, ok := fields[strings.ToLower(name)]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown theme color %q", name))
+			continue
+		}
+		c, err := ParseColor(spec)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		*field = c
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return t, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return t, nil
+}
+
+func (t *Theme) fields() map[string]**color.Color {
+	return map[string]**color.Color{
+		"prompt":       &t.Prompt,
+		"prompt_arrow": &t.PromptArrow,
+		"prompt_state": &t.PromptState,
+		"header":       &t.Header,
+		"label":        &t.Label,
+		"success":      &t.Success,
+		"warning":      &t.Warning,
+		"error":        &t.Error,
+		"neutral":      &t.Neutral,
+		"muted":        &t.Muted,
+		"confirm":      &t.Confirm,
+		"highlight":    &t.Highlight,
+		"pause":        &t.Pause,
+		"inline_code":  &t.InlineCode,
+	}
+}
+
+var colorNames = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+	"gray":    color.FgHiBlack,
+	"grey":    color.FgHiBlack,
+}
+
+var colorStyles = map[string]color.Attribute{
+	"bold":      color.Bold,
+	"faint":     color.Faint,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+	"reverse":   color.ReverseVideo,
+}
+
+// ParseColor reads a color spec of space separated words: a color name (red, hi-blue,
+// gray) or 256-color number for the foreground, the same prefixed with "bg:" for the
+// background, and styles like bold or underline
+func ParseColor(spec string) (*color.Color, error) {
+	var attrs []color.Attribute
+	for _, word := range strings.Fields(strings.ToLower(spec)) {
+		if style, ok := colorStyles[word]; ok {
+			attrs = append(attrs, style)
+			continue
+		}
+		bg := strings.HasPrefix(word, "bg:")
+		name := strings.TrimPrefix(word, "bg:")
+
+		if n, err := strconv.Atoi(name); err == nil && n >= 0 && n <= 255 {
+			base := color.Attribute(38)
+			if bg {
+				base = 48
+			}
+			attrs = append(attrs, base, 5, color.Attribute(n))
+			continue
+		}
+
+		hi := strings.HasPrefix(name, "hi-")
+		fg, ok := colorNames[strings.TrimPrefix(name, "hi-")]
+		if !ok {
+			return nil, fmt.Errorf("unknown color %q", word)
+		}
+		if hi && fg != color.FgHiBlack {
+			fg += color.FgHiBlack - color.FgBlack
+		}
+		if bg {
+			fg += color.BgBlack - color.FgBlack
+		}
+		attrs = append(attrs, fg)
+	}
+	if len(attrs) == 0 {
+		return nil, fmt.Errorf("empty color")
+	}
+	return color.New(attrs...), nil
+}
+
+var (
+	themeMu      sync.RWMutex
+	currentTheme = NewTheme("dark")
+)
+
+// CurrentTheme returns the theme in use
+func CurrentTheme() *Theme {
+	themeMu.RLock()
+	defer themeMu.RUnlock()
+	return currentTheme
+}
+
+// SetTheme changes the theme used by formatters and prompts
+func SetTheme(t *Theme) {
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	currentTheme = t
+}
@@ -0,0 +1,52 @@
+// Unit tests for color themes in theme.go
+package system
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// Test: color specs map names, hi- variants, 256-color numbers and bg: prefixes to SGR codes
+func TestParseColor(t *testing.T) {
+	forceColor(t, true)
+
+	tests := map[string]string{
+		"red":             "\x1b[31m",
+		"hi-blue bold":    "\x1b[94;1m",
+		"gray":            "\x1b[90m",
+		"208 bg:235":      "\x1b[38;5;208;48;5;235m",
+		"bg:white italic": "\x1b[47;3m",
+	}
+	for spec, want := range tests {
+		c, err := ParseColor(spec)
+		if err != nil {
+			t.Errorf("ParseColor(%q): %v", spec, err)
+			continue
+		}
+		if got := c.Sprint("x"); !strings.HasPrefix(got, want) {
+			t.Errorf("ParseColor(%q) = %q, want prefix %q", spec, got, want)
+		}
+	}
+	if _, err := ParseColor("purple"); err == nil {
+		t.Error("expected an error for an unknown color")
+	}
+}
+
+// Test: overrides replace single preset colors and invalid ones are reported without failing the theme
+func TestLoadTheme(t *testing.T) {
+	theme, err := LoadTheme("light", map[string]string{"label": "cyan", "nope": "red", "error": "???"})
+	if err == nil || !strings.Contains(err.Error(), "nope") || !strings.Contains(err.Error(), "error") {
+		t.Errorf("expected errors for both invalid overrides, got %v", err)
+	}
+	if theme.Name != "light" || theme.CodeTheme != "github" {
+		t.Errorf("expected the light preset, got %s/%s", theme.Name, theme.CodeTheme)
+	}
+	if !theme.Label.Equals(color.New(color.FgCyan)) {
+		t.Error("label override was not applied")
+	}
+	if !theme.Error.Equals(NewTheme("light").Error) {
+		t.Error("invalid override should keep the preset color")
+	}
+}
@@ -1,7 +1,6 @@
 package system
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,125 +12,199 @@ import (
 
 // TmuxCreateNewPane creates a new horizontal split pane in the specified window and returns its ID
 func TmuxCreateNewPane(target string) (string, error) {
-	cmd := exec.Command("tmux", "split-window", "-d", "-h", "-t", target, "-P", "-F", "#{pane_id}")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+	out, err := runTmux("split-window", "-d", "-h", "-t", target, "-P", "-F", "#{pane_id}")
 	if err != nil {
-		logger.Error("Failed to create tmux pane: %v, stderr: %s", err, stderr.String())
+		logger.Error("Failed to create tmux pane: %v", err)
 		return "", err
 	}
 
-	paneId := strings.TrimSpace(stdout.String())
+	paneId := strings.TrimSpace(out)
 	return paneId, nil
 }
 
-// TmuxPanesDetails gets details for all panes in a target window
+// TmuxNewWindowCommand opens a background window in the session of target running
+// command and returns the new pane's ID
+func TmuxNewWindowCommand(target, name, command string) (string, error) {
+	out, err := runTmux("new-window", "-d", "-t", target, "-n", name, "-P", "-F", "#{pane_id}", command)
+	if err != nil {
+		logger.Error("Failed to create tmux window: %v", err)
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// paneFormat queries everything TmuxPaneDetails holds in one list-panes call. Fields are
+// tab separated and the path comes last, so it may contain anything but a newline.
+var paneFormat = strings.Join([]string{
+	"#{pane_id}", "#{pane_active}", "#{pane_pid}", "#{pane_current_command}",
+	"#{history_size}", "#{history_limit}", "#{pane_width}", "#{pane_height}",
+	"#{session_id}:#{window_index}", "#{pane_title}", "#{pane_current_path}",
+}, "\t")
+
+// TmuxPanesDetails gets details for all panes in a target window, or for a single pane
+// when the target is a pane ID
 func TmuxPanesDetails(target string) ([]TmuxPaneDetails, error) {
-	cmd := exec.Command("tmux", "list-panes", "-t", target, "-F", "#{pane_id},#{pane_active},#{pane_pid},#{pane_current_command},#{history_size},#{history_limit}")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	panes, err := listPanes(target)
+	if err != nil || !strings.HasPrefix(target, "%") {
+		return panes, err
+	}
+	for _, pane := range panes {
+		if pane.Id == target {
+			return []TmuxPaneDetails{pane}, nil
+		}
+	}
+	return nil, nil
+}
 
-	err := cmd.Run()
+// TmuxWindowPanes gets details for all panes in the window of the given pane
+func TmuxWindowPanes(paneId string) ([]TmuxPaneDetails, error) {
+	return listPanes(paneId)
+}
+
+// TmuxSessionPanes gets details for all panes in all windows of the session of the given pane
+func TmuxSessionPanes(paneId string) ([]TmuxPaneDetails, error) {
+	return listPanes(paneId, "-s")
+}
+
+func listPanes(target string, flags ...string) ([]TmuxPaneDetails, error) {
+	args := append([]string{"list-panes"}, flags...)
+	out, err := runTmux(append(args, "-t", target, "-F", paneFormat)...)
 	if err != nil {
-		logger.Error("Failed to get tmux pane details for target %s %v, stderr: %s", target, err, stderr.String())
+		logger.Error("Failed to get tmux pane details for target %s: %v", target, err)
 		return nil, err
 	}
 
-	output := strings.TrimSpace(stdout.String())
+	output := strings.TrimSpace(out)
 	if output == "" {
 		return nil, fmt.Errorf("no pane details found for target %s", target)
 	}
+	return parsePanes(output, listProcesses()), nil
+}
 
+// parsePanes reads list-panes output in paneFormat, with command args from the process table
+func parsePanes(output string, procs processTable) []TmuxPaneDetails {
 	lines := strings.Split(output, "\n")
 	paneDetails := make([]TmuxPaneDetails, 0, len(lines))
 
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
+		if strings.TrimSpace(line) == "" {
 			continue
 		}
 
-		parts := strings.SplitN(line, ",", 6)
-		if len(parts) < 5 {
+		parts := strings.SplitN(line, "\t", 11)
+		if len(parts) < 11 {
 			logger.Error("Invalid pane details format for line: %s", line)
 			continue
 		}
 
-		id := parts[0]
-
-		// If target starts with '%', it's a pane ID, so only include the matching pane
-		if strings.HasPrefix(target, "%") && id != target {
-			continue
-		}
-
 		active, _ := strconv.Atoi(parts[1])
 		pid, _ := strconv.Atoi(parts[2])
 		historySize, _ := strconv.Atoi(parts[4])
 		historyLimit, _ := strconv.Atoi(parts[5])
-		currentCommandArgs := GetProcessArgs(pid)
-		isSubShell := IsSubShell(parts[3])
+		width, _ := strconv.Atoi(parts[6])
+		height, _ := strconv.Atoi(parts[7])
 
-		paneDetail := TmuxPaneDetails{
-			Id:                 id,
+		paneDetails = append(paneDetails, TmuxPaneDetails{
+			Id:                 parts[0],
 			IsActive:           active,
 			CurrentPid:         pid,
 			CurrentCommand:     parts[3],
-			CurrentCommandArgs: currentCommandArgs,
+			CurrentCommandArgs: procs.args(pid),
 			HistorySize:        historySize,
 			HistoryLimit:       historyLimit,
-			IsSubShell:         isSubShell,
-		}
-
-		paneDetails = append(paneDetails, paneDetail)
+			Width:              width,
+			Height:             height,
+			WindowTarget:       parts[8],
+			Title:              parts[9],
+			CurrentPath:        parts[10],
+			IsSubShell:         IsSubShell(parts[3]),
+		})
 	}
 
-	return paneDetails, nil
+	return paneDetails
 }
 
-// TmuxCapturePane gets the content of a specific pane by ID
+// TmuxCapturePane gets the content of a specific pane by ID, reusing a capture made
+// within the cache TTL
 func TmuxCapturePane(paneId string, maxLines int) (string, error) {
-	cmd := exec.Command("tmux", "capture-pane", "-p", "-t", paneId, "-S", fmt.Sprintf("-%d", maxLines))
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	if content, ok := cachedCapture(paneId, maxLines); ok {
+		return content, nil
+	}
 
-	err := cmd.Run()
-	if err != nil {
-		logger.Error("Failed to capture pane content from %s: %v, stderr: %s", paneId, err, stderr.String())
+	stdout := getBuffer()
+	defer putBuffer(stdout)
+	if err := runTmuxTo(stdout, "capture-pane", "-p", "-t", paneId, "-S", "-"+strconv.Itoa(maxLines)); err != nil {
+		logger.Error("Failed to capture pane content from %s: %v", paneId, err)
 		return "", err
 	}
 
-	content := strings.TrimSpace(stdout.String())
+	content := cleanCapture(stdout.Bytes())
+	storeCapture(paneId, maxLines, content)
 	return content, nil
 }
 
-// Return current tmux window target with session id and window id
-func TmuxCurrentWindowTarget() (string, error) {
-	paneId, err := TmuxCurrentPaneId()
+// TmuxPaneInView reports whether the pane's window is the current window of an attached session
+func TmuxPaneInView(paneId string) (bool, error) {
+	output, err := runTmux("display-message", "-p", "-t", paneId, "#{window_active} #{session_attached}")
 	if err != nil {
-		return "", err
+		return false, fmt.Errorf("failed to get pane visibility: %w", err)
 	}
+	fields := strings.Fields(output)
+	return len(fields) == 2 && fields[0] == "1" && fields[1] != "0", nil
+}
 
-	cmd := exec.Command("tmux", "list-panes", "-t", paneId, "-F", "#{session_id}:#{window_index}")
-	output, err := cmd.Output()
+// TmuxShowPaneHeader turns on the top border of the pane's window with the pane title
+// shown in the pane's own border; restore puts the previous border options back
+func TmuxShowPaneHeader(paneId string) (restore func(), err error) {
+	previous, err := runTmux("show-options", "-wqv", "-t", paneId, "pane-border-status")
 	if err != nil {
-		return "", fmt.Errorf("failed to get window target: %w", err)
+		return nil, fmt.Errorf("failed to read pane-border-status: %w", err)
+	}
+	commands := [][]string{
+		{"set-option", "-p", "-t", paneId, "pane-border-format", "#[reverse]#{pane_title}#[default]"},
+		{"set-option", "-w", "-t", paneId, "pane-border-status", "top"},
+	}
+	for _, args := range commands {
+		if _, err := runTmux(args...); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", args[4], err)
+		}
 	}
 
-	target := strings.TrimSpace(string(output))
-	if target == "" {
-		return "", fmt.Errorf("empty window target returned")
+	return func() {
+		runTmux("set-option", "-pu", "-t", paneId, "pane-border-format")
+		if status := strings.TrimSpace(previous); status != "" {
+			runTmux("set-option", "-w", "-t", paneId, "pane-border-status", status)
+		} else {
+			runTmux("set-option", "-wu", "-t", paneId, "pane-border-status")
+		}
+	}, nil
+}
+
+// TmuxDisplayMessage shows a message in the status line of the clients attached to the
+// pane's session, for display-time milliseconds
+func TmuxDisplayMessage(paneId, message string) error {
+	// display-message expands formats, a literal # is written ##
+	if _, err := runTmux("display-message", "-t", paneId, strings.ReplaceAll(message, "#", "##")); err != nil {
+		return fmt.Errorf("failed to display message: %w", err)
 	}
+	return nil
+}
 
-	if idx := strings.Index(target, "\n"); idx != -1 {
-		target = target[:idx]
+// TmuxSetPaneTitle sets the title of a pane
+func TmuxSetPaneTitle(paneId, title string) error {
+	if _, err := runTmux("select-pane", "-t", paneId, "-T", title); err != nil {
+		return fmt.Errorf("failed to set pane title: %w", err)
 	}
+	return nil
+}
 
-	return target, nil
+// TmuxVersion returns the version reported by tmux -V, like "3.4"
+func TmuxVersion() (string, error) {
+	out, err := runTmux("-V")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(out), "tmux "), nil
 }
 
 func TmuxCurrentPaneId() (string, error) {
@@ -145,16 +218,29 @@ func TmuxCurrentPaneId() (string, error) {
 
 // CreateTmuxSession creates a new tmux session and returns the new pane id
 func TmuxCreateSession() (string, error) {
-	cmd := exec.Command("tmux", "new-session", "-d", "-P", "-F", "#{pane_id}")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		logger.Error("Failed to create tmux session: %v, stderr: %s", err, stderr.String())
+	out, err := runTmux("new-session", "-d", "-P", "-F", "#{pane_id}")
+	if err != nil {
+		logger.Error("Failed to create tmux session: %v", err)
 		return "", err
 	}
 
-	return strings.TrimSpace(stdout.String()), nil
+	return strings.TrimSpace(out), nil
+}
+
+// TmuxKillWindow kills the window the given pane belongs to
+func TmuxKillWindow(paneId string) error {
+	if _, err := runTmux("kill-window", "-t", paneId); err != nil {
+		return fmt.Errorf("failed to kill tmux window: %w", err)
+	}
+	return nil
+}
+
+// TmuxKillSession kills the session the given pane belongs to
+func TmuxKillSession(paneId string) error {
+	if _, err := runTmux("kill-session", "-t", paneId); err != nil {
+		return fmt.Errorf("failed to kill tmux session: %w", err)
+	}
+	return nil
 }
 
 // AttachToTmuxSession attaches to an existing tmux session
@@ -171,6 +257,7 @@ func TmuxAttachSession(paneId string) error {
 }
 
 func TmuxClearPane(paneId string) error {
+	defer InvalidatePaneCapture(paneId)
 	paneDetails, err := TmuxPanesDetails(paneId)
 	if err != nil {
 		logger.Error("Failed to get pane details for %s: %v", paneId, err)
@@ -181,20 +268,17 @@ func TmuxClearPane(paneId string) error {
 		return fmt.Errorf("no pane details found for pane %s", paneId)
 	}
 
-	cmd := exec.Command("tmux", "split-window", "-vp", "100", "-t", paneId)
-	if err := cmd.Run(); err != nil {
+	if _, err := runTmux("split-window", "-vp", "100", "-t", paneId); err != nil {
 		logger.Error("Failed to split window for pane %s: %v", paneId, err)
 		return err
 	}
 
-	cmd = exec.Command("tmux", "clear-history", "-t", paneId)
-	if err := cmd.Run(); err != nil {
+	if _, err := runTmux("clear-history", "-t", paneId); err != nil {
 		logger.Error("Failed to clear history for pane %s: %v", paneId, err)
 		return err
 	}
 
-	cmd = exec.Command("tmux", "kill-pane")
-	if err := cmd.Run(); err != nil {
+	if _, err := runTmux("kill-pane"); err != nil {
 		logger.Error("Failed to kill temporary pane: %v", err)
 		return err
 	}
@@ -205,13 +289,8 @@ func TmuxClearPane(paneId string) error {
 
 // TmuxSelectPane selects a specific pane
 func TmuxSelectPane(paneId string) error {
-	cmd := exec.Command("tmux", "select-pane", "-t", paneId)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		logger.Error("Failed to select tmux pane %s: %v, stderr: %s", paneId, err, stderr.String())
+	if _, err := runTmux("select-pane", "-t", paneId); err != nil {
+		logger.Error("Failed to select tmux pane %s: %v", paneId, err)
 		return err
 	}
 
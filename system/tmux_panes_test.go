@@ -0,0 +1,42 @@
+// Unit tests for pane listing in tmux.go and process lookup in process.go
+package system
+
+import "testing"
+
+// Test: one list-panes line fills every pane field, paths may contain tabs
+func TestParsePanes(t *testing.T) {
+	procs := processTable{42: {ppid: 1, command: "vim main.go"}}
+	output := "%3\t1\t42\tvim\t120\t2000\t80\t24\t$1:2\tmy title\t/home/u/a\tb\n" +
+		"broken line\n"
+
+	panes := parsePanes(output, procs)
+	if len(panes) != 1 {
+		t.Fatalf("expected 1 pane, got %d", len(panes))
+	}
+	p := panes[0]
+	if p.Id != "%3" || p.IsActive != 1 || p.CurrentPid != 42 || p.CurrentCommand != "vim" ||
+		p.HistorySize != 120 || p.HistoryLimit != 2000 || p.Width != 80 || p.Height != 24 ||
+		p.WindowTarget != "$1:2" || p.Title != "my title" || p.CurrentPath != "/home/u/a\tb" {
+		t.Errorf("unexpected pane: %+v", p)
+	}
+	if p.CurrentCommandArgs != "vim main.go" {
+		t.Errorf("unexpected args: %q", p.CurrentCommandArgs)
+	}
+}
+
+// Test: a login shell resolves to the first child that isn't a shell
+func TestProcessTableArgs(t *testing.T) {
+	procs := parseProcesses(`
+  10     1 -zsh
+  12    10 -bash
+  15    10 npm run dev --port 3000
+  20     1 top
+  30     1 -fish
+`)
+	cases := map[int]string{10: "npm run dev --port 3000", 20: "top", 30: "-fish", 99: ""}
+	for pid, want := range cases {
+		if got := procs.args(pid); got != want {
+			t.Errorf("args(%d) = %q, want %q", pid, got, want)
+		}
+	}
+}
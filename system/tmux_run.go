@@ -0,0 +1,102 @@
+package system
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/alvinunreal/tmuxai/logger"
+)
+
+// Classes of tmux failures, matched with errors.Is on the errors of the Tmux functions
+var (
+	ErrTmuxServerNotRunning = errors.New("tmux server not running")
+	ErrTmuxPaneNotFound     = errors.New("tmux pane not found")
+	ErrTmuxPermission       = errors.New("tmux permission denied")
+)
+
+// tmuxRetryDelays are the waits before each retry of a command whose target was not
+// found, which happens for a moment while windows are rearranged
+var tmuxRetryDelays = []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond}
+
+// tmuxCommand runs tmux with args, replaced in tests
+var tmuxCommand = func(stdout, stderr *bytes.Buffer, args ...string) error {
+	cmd := exec.Command("tmux", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// TmuxError is a failed tmux command with what it wrote to stderr
+type TmuxError struct {
+	Args   []string
+	Stderr string
+	Err    error // the error of the command
+	Kind   error // one of the ErrTmux classes, nil when the failure is not recognized
+}
+
+func (e *TmuxError) Error() string {
+	msg := "tmux " + e.Args[0] + ": " + e.Err.Error()
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+func (e *TmuxError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// classifyTmuxError maps the stderr of a failed tmux command to an ErrTmux class
+func classifyTmuxError(stderr string) error {
+	s := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(s, "permission denied"), strings.Contains(s, "access not allowed"):
+		return ErrTmuxPermission
+	case strings.Contains(s, "no server running"), strings.Contains(s, "error connecting to"),
+		strings.Contains(s, "server exited unexpectedly"), strings.Contains(s, "lost server"):
+		return ErrTmuxServerNotRunning
+	case strings.Contains(s, "can't find pane"), strings.Contains(s, "can't find window"),
+		strings.Contains(s, "can't find session"), strings.Contains(s, "no such pane"):
+		return ErrTmuxPaneNotFound
+	}
+	return nil
+}
+
+// runTmux runs tmux with args and returns its output. A command whose target is not
+// found is retried with a short backoff; other failures return at once as a *TmuxError.
+func runTmux(args ...string) (string, error) {
+	var stdout bytes.Buffer
+	err := runTmuxTo(&stdout, args...)
+	return stdout.String(), err
+}
+
+// runTmuxTo is runTmux writing the output to stdout
+func runTmuxTo(stdout *bytes.Buffer, args ...string) error {
+	stderr := getBuffer()
+	defer putBuffer(stderr)
+	for attempt := 0; ; attempt++ {
+		stdout.Reset()
+		stderr.Reset()
+		err := tmuxCommand(stdout, stderr, args...)
+		if err == nil {
+			return nil
+		}
+		tmuxErr := &TmuxError{
+			Args:   args,
+			Stderr: strings.TrimSpace(stderr.String()),
+			Err:    err,
+			Kind:   classifyTmuxError(stderr.String()),
+		}
+		if tmuxErr.Kind != ErrTmuxPaneNotFound || attempt >= len(tmuxRetryDelays) {
+			return tmuxErr
+		}
+		logger.Debug("Retrying tmux %s after: %s", args[0], tmuxErr.Stderr)
+		time.Sleep(tmuxRetryDelays[attempt])
+	}
+}
@@ -0,0 +1,74 @@
+// Unit tests for running tmux with retries in tmux_run.go
+package system
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeTmux replaces tmuxCommand with one answering from stderrs in turn, failing while
+// the answer is not empty
+func fakeTmux(t *testing.T, stderrs ...string) *int {
+	calls := 0
+	oldCommand, oldDelays := tmuxCommand, tmuxRetryDelays
+	tmuxCommand = func(stdout, stderr *bytes.Buffer, args ...string) error {
+		answer := stderrs[min(calls, len(stderrs)-1)]
+		calls++
+		if answer != "" {
+			stderr.WriteString(answer)
+			return errors.New("exit status 1")
+		}
+		stdout.WriteString("%7\n")
+		return nil
+	}
+	tmuxRetryDelays = []time.Duration{0, 0, 0}
+	t.Cleanup(func() { tmuxCommand, tmuxRetryDelays = oldCommand, oldDelays })
+	return &calls
+}
+
+// Test: stderr messages of tmux map to the error classes
+func TestClassifyTmuxError(t *testing.T) {
+	tests := map[string]error{
+		"no server running on /tmp/tmux-1000/default":                 ErrTmuxServerNotRunning,
+		"error connecting to /tmp/tmux-1000/default (No such file)":   ErrTmuxServerNotRunning,
+		"error connecting to /tmp/tmux-0/default (Permission denied)": ErrTmuxPermission,
+		"can't find pane: %99": ErrTmuxPaneNotFound,
+		"can't find window: 4": ErrTmuxPaneNotFound,
+		"unknown option -- z":  nil,
+	}
+	for stderr, want := range tests {
+		if got := classifyTmuxError(stderr); got != want {
+			t.Errorf("%q: got %v, want %v", stderr, got, want)
+		}
+	}
+}
+
+// Test: a missing pane is retried until it shows up
+func TestRunTmuxRetriesPaneNotFound(t *testing.T) {
+	calls := fakeTmux(t, "can't find pane: %7", "can't find pane: %7", "")
+	out, err := runTmux("display-message", "-p", "-t", "%7", "#{pane_id}")
+	if err != nil || out != "%7\n" || *calls != 3 {
+		t.Errorf("got %q, %v after %d calls", out, err, *calls)
+	}
+}
+
+// Test: retries stop after the last delay, other failures are not retried
+func TestRunTmuxGivesUp(t *testing.T) {
+	calls := fakeTmux(t, "can't find pane: %7")
+	_, err := runTmux("send-keys", "-t", "%7", "Enter")
+	if !errors.Is(err, ErrTmuxPaneNotFound) || *calls != 4 {
+		t.Errorf("got %v after %d calls", err, *calls)
+	}
+
+	calls = fakeTmux(t, "no server running on /tmp/tmux-1000/default")
+	_, err = runTmux("list-panes")
+	var tmuxErr *TmuxError
+	if !errors.Is(err, ErrTmuxServerNotRunning) || !errors.As(err, &tmuxErr) || *calls != 1 {
+		t.Errorf("got %v after %d calls", err, *calls)
+	}
+	if tmuxErr.Error() != "tmux list-panes: exit status 1: no server running on /tmp/tmux-1000/default" {
+		t.Errorf("unexpected message: %s", tmuxErr)
+	}
+}
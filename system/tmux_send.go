@@ -1,15 +1,14 @@
 package system
 
 import (
-	"bytes"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/alvinunreal/tmuxai/logger"
 )
 
 func TmuxSendCommandToPane(paneId string, command string, autoenter bool) error {
+	defer InvalidatePaneCapture(paneId)
 	lines := strings.Split(command, "\n")
 	for i, line := range lines {
 
@@ -19,12 +18,8 @@ func TmuxSendCommandToPane(paneId string, command string, autoenter bool) error
 				if strings.HasSuffix(line, ";") {
 					line = line[:len(line)-1] + "\\;"
 				}
-				cmd := exec.Command("tmux", "send-keys", "-t", paneId, "-l", line)
-				var stderr bytes.Buffer
-				cmd.Stderr = &stderr
-				err := cmd.Run()
-				if err != nil {
-					logger.Error("Failed to send command to pane %s: %v, stderr: %s", paneId, err, stderr.String())
+				if _, err := runTmux("send-keys", "-t", paneId, "-l", line); err != nil {
+					logger.Error("Failed to send command to pane %s: %v", paneId, err)
 					return fmt.Errorf("failed to send command to pane: %w", err)
 				}
 
@@ -32,12 +27,8 @@ func TmuxSendCommandToPane(paneId string, command string, autoenter bool) error
 				args := []string{"send-keys", "-t", paneId}
 				processed := processLineWithSpecialKeys(line)
 				args = append(args, processed...)
-				cmd := exec.Command("tmux", args...)
-				var stderr bytes.Buffer
-				cmd.Stderr = &stderr
-				err := cmd.Run()
-				if err != nil {
-					logger.Error("Failed to send command with special keys to pane %s: %v, stderr: %s", paneId, err, stderr.String())
+				if _, err := runTmux(args...); err != nil {
+					logger.Error("Failed to send command with special keys to pane %s: %v", paneId, err)
 					return fmt.Errorf("failed to send command with special keys to pane: %w", err)
 				}
 			}
@@ -46,9 +37,7 @@ func TmuxSendCommandToPane(paneId string, command string, autoenter bool) error
 		// Send Enter key after each line except for empty lines at the end
 		if autoenter {
 			if i < len(lines)-1 || (i == len(lines)-1 && line != "") {
-				enterCmd := exec.Command("tmux", "send-keys", "-t", paneId, "Enter")
-				err := enterCmd.Run()
-				if err != nil {
+				if _, err := runTmux("send-keys", "-t", paneId, "Enter"); err != nil {
 					logger.Error("Failed to send Enter key to pane %s: %v", paneId, err)
 					return fmt.Errorf("failed to send Enter key to pane: %w", err)
 				}
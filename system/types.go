@@ -1,8 +1,11 @@
 package system
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
+
+	"github.com/alvinunreal/tmuxai/i18n"
 )
 
 type TmuxPaneDetails struct {
@@ -10,6 +13,7 @@ type TmuxPaneDetails struct {
 	CurrentPid         int
 	CurrentCommand     string
 	CurrentCommandArgs string
+	CurrentPath        string
 	Content            string
 	Shell              string
 	OS                 string
@@ -21,31 +25,29 @@ type TmuxPaneDetails struct {
 	IsSubShell         bool
 	HistorySize        int
 	HistoryLimit       int
+	Width              int
+	Height             int
+	Title              string
+	WindowTarget       string // session_id:window_index
 }
 
 func (p *TmuxPaneDetails) String() string {
-	// ANSI color codes
-	reset := "\033[0m"
-	green := "\033[32m"
-	cyan := "\033[36m"
-	yellow := "\033[33m"
-	blue := "\033[34m"
-	gray := "\033[90m"
+	t := CurrentTheme()
 
 	// Format true/false values with colors
 	formatBool := func(value bool) string {
 		if value {
-			return fmt.Sprintf("%strue%s", green, reset)
+			return t.Success.Sprint("true")
 		}
-		return fmt.Sprintf("%sfalse%s", gray, reset)
+		return t.Neutral.Sprint("false")
 	}
 
 	// Format the output with colors and clean alignment
-	return fmt.Sprintf("Id: %s%s%s\n", cyan, strings.ReplaceAll(p.Id, "%", ""), reset) +
-		fmt.Sprintf("Command: %s%s%s\n", yellow, p.CurrentCommand, reset) +
-		fmt.Sprintf("Args: %s%s%s\n", gray, p.CurrentCommandArgs, reset) +
-		fmt.Sprintf("Shell: %s%s%s\n", blue, p.Shell, reset) +
-		fmt.Sprintf("OS: %s%s%s\n", gray, p.OS, reset) +
+	return fmt.Sprintf("Id: %s\n", t.Header.Sprint(strings.ReplaceAll(p.Id, "%", ""))) +
+		fmt.Sprintf("Command: %s\n", t.Highlight.Sprint(p.CurrentCommand)) +
+		fmt.Sprintf("Args: %s\n", t.Neutral.Sprint(p.CurrentCommandArgs)) +
+		fmt.Sprintf("Shell: %s\n", t.Label.Sprint(p.Shell)) +
+		fmt.Sprintf("OS: %s\n", t.Neutral.Sprint(p.OS)) +
 		fmt.Sprintf("TmuxAI Pane: %s\n", formatBool(p.IsTmuxAiPane)) +
 		fmt.Sprintf("TmuxAI Exec Pane: %s\n", formatBool(p.IsTmuxAiExecPane)) +
 		fmt.Sprintf("Prepared: %s\n", formatBool(p.IsPrepared)) +
@@ -61,21 +63,18 @@ func (p *TmuxPaneDetails) FormatInfo(f *InfoFormatter) string {
 	case p.IsTmuxAiPane:
 		paneTitle = fmt.Sprintf("%s: TmuxAI", cleanId)
 	case p.IsTmuxAiExecPane:
-		paneTitle = fmt.Sprintf("%s: TmuxAI Exec Pane", cleanId)
+		paneTitle = fmt.Sprintf("%s: %s", cleanId, i18n.T("TmuxAI Exec Pane"))
 	default:
-		paneTitle = fmt.Sprintf("%s: Read Only", cleanId)
+		paneTitle = fmt.Sprintf("%s: %s", cleanId, i18n.T("Read Only"))
 	}
-	builder.WriteString(f.HeaderColor.Sprintf("Pane %s", paneTitle))
+	builder.WriteString(f.HeaderColor.Sprint(i18n.T("Pane %s", paneTitle)))
 	builder.WriteString("\n")
 
 	const labelWidth = 18
 
 	// Helper function for formatted key-value pairs
 	formatLine := func(key string, value any) {
-		builder.WriteString(f.LabelColor.Sprintf("%-*s", labelWidth, key))
-		builder.WriteString("  ")
-		builder.WriteString(value.(string))
-		builder.WriteString("\n")
+		builder.WriteString(f.FormatRow(f.Label(i18n.T(key), labelWidth)+" ", labelWidth+2, value))
 	}
 
 	formatLine("Command", p.CurrentCommand)
@@ -87,6 +86,7 @@ func (p *TmuxPaneDetails) FormatInfo(f *InfoFormatter) string {
 	// Add shell and OS info on separate lines
 	formatLine("Shell", p.Shell)
 	formatLine("OS", p.OS)
+	formatLine("Size", fmt.Sprintf("%dx%d", p.Width, p.Height))
 
 	// Add status flags each on their own line
 	formatLine("TmuxAI", f.FormatBool(p.IsTmuxAiPane))
@@ -100,9 +100,20 @@ func (p *TmuxPaneDetails) FormatInfo(f *InfoFormatter) string {
 func (p *TmuxPaneDetails) Refresh(maxLines int) {
 	content, _ := TmuxCapturePane(p.Id, maxLines)
 	p.Content = content
-	p.LastLine = strings.TrimSpace(strings.Split(p.Content, "\n")[len(strings.Split(p.Content, "\n"))-1])
+	p.LastLine = lastLine(p.Content)
 	p.IsPrepared = strings.HasSuffix(p.LastLine, "»")
 	if IsShellCommand(p.CurrentCommand) {
 		p.Shell = p.CurrentCommand
 	}
 }
+
+// cleanCapture trims the blank lines tmux pads a capture with; only the trimmed
+// content is copied out of the capture buffer
+func cleanCapture(raw []byte) string {
+	return string(bytes.TrimSpace(raw))
+}
+
+// lastLine returns the last line of a capture without splitting the whole content
+func lastLine(content string) string {
+	return strings.TrimSpace(content[strings.LastIndexByte(content, '\n')+1:])
+}
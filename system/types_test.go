@@ -0,0 +1,44 @@
+// Unit tests and benchmarks for capture cleaning in types.go
+package system
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Test: the blank lines tmux pads a capture with are removed
+func TestCleanCapture(t *testing.T) {
+	if got := cleanCapture([]byte("\n  $ ls\nfile\n$ \n\n\n")); got != "$ ls\nfile\n$" {
+		t.Errorf("unexpected capture: %q", got)
+	}
+}
+
+// Test: the last line is found with and without line breaks
+func TestLastLine(t *testing.T) {
+	cases := map[string]string{
+		"":                  "",
+		"[0]» ":             "[0]»",
+		"out\nmore\n [1]» ": "[1]»",
+	}
+	for content, want := range cases {
+		if got := lastLine(content); got != want {
+			t.Errorf("lastLine(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+// BenchmarkCleanCapture cleans a 200-line capture and reads its last line, as every
+// pane refresh does
+func BenchmarkCleanCapture(b *testing.B) {
+	var raw strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&raw, "line %d of some command output with a few words\n", i)
+	}
+	raw.WriteString(strings.Repeat("\n", 20))
+	data := []byte(raw.String())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lastLine(cleanCapture(data))
+	}
+}
@@ -15,58 +15,27 @@ import (
 	"github.com/alecthomas/chroma/formatters"
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/alecthomas/chroma/styles"
-	"github.com/alvinunreal/tmuxai/logger"
 )
 
+// GetProcessArgs returns the command line running as pid, see processTable.args
 func GetProcessArgs(pid int) string {
-	// First check if this is a shell process
-	cmd := exec.Command("ps", "-p", fmt.Sprintf("%d", pid), "-o", "command=")
-	output, err := cmd.Output()
-	if err != nil {
-		logger.Error("Failed to get process command for PID %d: %v", pid, err)
-		return ""
-	}
+	return listProcesses().args(pid)
+}
 
-	cmdOutput := strings.TrimSpace(string(output))
+// DefaultHighlightTheme is the chroma style used when no theme is configured
+const DefaultHighlightTheme = "monokai"
 
-	// If this is a shell process (indicated by starting with "-"),
-	// look for child processes that might be the actual command
-	if strings.HasPrefix(cmdOutput, "-") {
-		// Find child processes
-		cmd = exec.Command("pgrep", "-P", fmt.Sprintf("%d", pid))
-		childOutput, err := cmd.Output()
-		if err == nil && len(childOutput) > 0 {
-			// Get the child PIDs
-			childPids := strings.Split(strings.TrimSpace(string(childOutput)), "\n")
-			if len(childPids) > 0 {
-				for _, childPid := range childPids {
-					if childPid == "" {
-						continue
-					}
+func HighlightCode(language string, code string) (string, error) {
+	return HighlightCodeTheme(language, code, DefaultHighlightTheme)
+}
 
-					// Get the command of this child
-					cmd = exec.Command("ps", "-p", childPid, "-o", "command=")
-					childCmdOutput, err := cmd.Output()
-					if err == nil {
-						childCmd := strings.TrimSpace(string(childCmdOutput))
-						// If this isn't another shell, return it
-						if childCmd != "" && !strings.HasPrefix(childCmd, "-") {
-							return childCmd
-						}
-					}
-				}
-			}
-		}
-	} else if cmdOutput != "" && !strings.HasPrefix(cmdOutput, "-") {
-		// If it's not a shell and has a command, return it
-		return cmdOutput
+// HighlightCodeTheme highlights code with the named chroma style,
+// an empty theme returns the code unchanged
+func HighlightCodeTheme(language, code, theme string) (string, error) {
+	if theme == "" || !ColorEnabled() {
+		return code, nil
 	}
 
-	// If we couldn't find a better command, return the original
-	return cmdOutput
-}
-
-func HighlightCode(language string, code string) (string, error) {
 	// Get the lexer for the specified language
 	lexer := lexers.Get(language)
 	if lexer == nil {
@@ -76,8 +45,11 @@ func HighlightCode(language string, code string) (string, error) {
 		}
 	}
 
-	// Choose a style (theme) - using monokai for good terminal visibility
-	style := styles.Get("monokai")
+	// Unknown theme names fall back to the default style
+	style, ok := styles.Registry[theme]
+	if !ok {
+		style = styles.Get(DefaultHighlightTheme)
+	}
 	if style == nil {
 		style = styles.Fallback
 	}
@@ -104,6 +76,17 @@ func HighlightCode(language string, code string) (string, error) {
 	return buf.String(), nil
 }
 
+// IsHighlightTheme reports whether theme names a known chroma style
+func IsHighlightTheme(theme string) bool {
+	_, ok := styles.Registry[theme]
+	return ok
+}
+
+// HighlightThemes returns the names of all chroma styles
+func HighlightThemes() []string {
+	return styles.Names()
+}
+
 // IsShellCommand checks if the given command is a shell
 func IsShellCommand(command string) bool {
 	shellCommands := []string{
@@ -0,0 +1,75 @@
+package system
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/term"
+)
+
+// hangingIndentRe matches the indentation and list marker a wrapped line continues under
+var hangingIndentRe = regexp.MustCompile(`^\s*(?:[-*+] |\d+[.)] )?`)
+
+// TerminalWidth returns the width of the terminal on stdout, 0 when it is not a terminal.
+// It is read on every call, so output follows pane resizes.
+func TerminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// WrapText wraps each line of text to width at word boundaries, keeping ANSI styles.
+// List items and indented lines continue under their text. A width <= 0 leaves text as is.
+func WrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		indent := 0
+		if prefix := hangingIndentRe.FindString(line); prefix != "" {
+			indent = len(prefix)
+		}
+		lines[i] = wrapLine(line, width, indent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WrapIndent wraps each line of text to width, continuing wrapped lines indent columns in,
+// e.g. under the value column of a key-value table
+func WrapIndent(text string, width, indent int) string {
+	if width <= 0 {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width, indent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func wrapLine(line string, width, indent int) string {
+	if ansi.StringWidth(line) <= width {
+		return line
+	}
+	if indent > width/2 {
+		indent = 0 // too little room left, wrap to the full width
+	}
+	first := ansi.Wrap(line, width, "")
+	head, rest, found := strings.Cut(first, "\n")
+	if !found || indent == 0 {
+		return first
+	}
+	// rewrap what follows the first line into the narrower column
+	rest = strings.ReplaceAll(rest, "\n", " ")
+	pad := strings.Repeat(" ", indent)
+	wrapped := strings.Split(ansi.Wrap(rest, width-indent, ""), "\n")
+	for i := range wrapped {
+		wrapped[i] = pad + wrapped[i]
+	}
+	return head + "\n" + strings.Join(wrapped, "\n")
+}
@@ -0,0 +1,45 @@
+// Unit tests for width-aware wrapping in wrap.go
+package system
+
+import "testing"
+
+// Test: lines wrap at word boundaries and list items continue under their text
+func TestWrapText(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"short line", "short line"},
+		{"aaaa bbbb cccc dddd eeee", "aaaa bbbb\ncccc dddd\neeee"},
+		{"- one two three four five", "- one two\n  three\n  four\n  five"},
+		{"12. one two three four", "12. one\n    two\n    three\n    four"},
+		{"keep\n\nnewlines", "keep\n\nnewlines"},
+	}
+	for _, c := range cases {
+		if got := WrapText(c.in, 10); got != c.want {
+			t.Errorf("WrapText(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+	if got := WrapText("aaaa bbbb cccc", 0); got != "aaaa bbbb cccc" {
+		t.Errorf("expected no wrapping without a width, got %q", got)
+	}
+}
+
+// Test: ANSI styles don't count towards the width
+func TestWrapTextANSI(t *testing.T) {
+	in := "\x1b[31maaaa\x1b[0m bbbb"
+	if got := WrapText(in, 9); got != in {
+		t.Errorf("expected a styled line of 9 columns to fit, got %q", got)
+	}
+}
+
+// Test: table values wrap under the value column
+func TestFormatRow(t *testing.T) {
+	forceColor(t, false)
+	f := NewInfoFormatter()
+	f.Width = 20
+	got := f.FormatRow("Args   ", 8, "one two three four")
+	if want := "Args    one two\n        three four\n"; got != want {
+		t.Errorf("FormatRow = %q, want %q", got, want)
+	}
+}